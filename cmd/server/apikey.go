@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"TransactionTest/config"
+	"TransactionTest/internal/delivery/http/handler"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+	"TransactionTest/internal/repository"
+	"TransactionTest/internal/service"
+	"TransactionTest/internal/storage/postgres"
+)
+
+// runAPIKey управляет API-ключами и печатает результат в stdout в том же JSON, что и /api/admin/keys:
+//
+//	apikey create -name ci -scopes read,transfer
+//	apikey list
+//	apikey revoke -id 3
+//
+// Так выдается первый ключ с правом admin, когда через API это сделать еще нечем.
+// Миграции не применяются: схема должна быть уже развернута сервером.
+func runAPIKey(ctx context.Context, cfg config.Config, appLogger logger.Logger, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: apikey create|list|revoke [flags]")
+	}
+
+	flags := flag.NewFlagSet("apikey "+args[0], flag.ContinueOnError)
+	name := flags.String("name", "", "api key name (create)")
+	scopes := flags.String("scopes", "", "comma separated scopes: read, transfer, admin (create)")
+	id := flags.Int64("id", 0, "api key id (revoke)")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	pool, err := postgres.Connect(ctx, &cfg.Postgres)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer pool.Close()
+
+	apiKeyService := service.NewAPIKeyService(
+		repository.NewAPIKeyRepository(postgres.NewPoolAdapter(pool)),
+		appLogger,
+	)
+
+	var result interface{}
+	switch args[0] {
+	case "create":
+		key, plain, code := apiKeyService.CreateAPIKey(ctx, *name, strings.Split(*scopes, ","))
+		if code != domain.CodeOK {
+			return fmt.Errorf("create returned %s", code)
+		}
+		result = handler.CreatedAPIKeyResponse(key, plain)
+	case "list":
+		keys, code := apiKeyService.ListAPIKeys(ctx)
+		if code != domain.CodeOK {
+			return fmt.Errorf("list returned %s", code)
+		}
+		result = handler.APIKeysResponse(keys)
+	case "revoke":
+		if code := apiKeyService.RevokeAPIKey(ctx, *id); code != domain.CodeOK {
+			return fmt.Errorf("revoke returned %s", code)
+		}
+		result = map[string]int64{"revoked": *id}
+	default:
+		return fmt.Errorf("unknown apikey command %q, expected create, list or revoke", args[0])
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	httpCust "TransactionTest/internal/delivery/http"
 	"TransactionTest/internal/delivery/http/handler"
 	"TransactionTest/internal/logger"
+	"TransactionTest/internal/metrics"
 	"TransactionTest/internal/repository"
 	"TransactionTest/internal/service"
 	"TransactionTest/internal/storage/postgres"
@@ -25,9 +27,22 @@ import (
 )
 
 type Server struct {
-	httpServer *http.Server
-	logger     logger.Logger
-	config     config.Config
+	httpServer    *http.Server
+	metricsServer *http.Server // nil, если метрики отдаются основным сервером или выключены
+	health        *service.HealthService
+	logger        logger.Logger
+	config        config.Config
+
+	jobs     []job
+	stopJobs context.CancelFunc
+	jobsDone sync.WaitGroup
+}
+
+// job периодическая фоновая задача, которая живет вместе с сервером
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context)
 }
 
 func main() {
@@ -43,6 +58,22 @@ func main() {
 		log.Fatalf("Failed to create logger: %v", err)
 	}
 
+	// transaction-test reconcile [-repair] - разовая сверка балансов без запуска сервера
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		if err := runReconcile(ctx, cfg, appLogger, os.Args[2:]); err != nil {
+			appLogger.Fatal(ctx, fmt.Sprintf("Reconciliation failed: %v", err))
+		}
+		return
+	}
+
+	// transaction-test apikey create|list|revoke - управление API-ключами без запуска сервера
+	if len(os.Args) > 1 && os.Args[1] == "apikey" {
+		if err := runAPIKey(ctx, cfg, appLogger, os.Args[2:]); err != nil {
+			appLogger.Fatal(ctx, fmt.Sprintf("API key command failed: %v", err))
+		}
+		return
+	}
+
 	appLogger.Info(ctx, "Starting application...")
 
 	server, err := NewServer(cfg, appLogger)
@@ -50,6 +81,9 @@ func main() {
 		appLogger.Fatal(ctx, fmt.Sprintf("Failed to create server: %v", err))
 	}
 
+	server.StartJobs(ctx)
+	server.StartMetrics(ctx)
+
 	// Запускаем сервер
 	go func() {
 		appLogger.Info(ctx, fmt.Sprintf("Server starting on %s:%d", cfg.Server.Host, cfg.Server.Port))
@@ -63,7 +97,44 @@ func main() {
 
 // NewServer создает новый экземпляр сервера
 func NewServer(cfg config.Config, appLogger logger.Logger) (*Server, error) {
-	ctx := context.WithValue(context.Background(), logger.RequestID, uuid.New().String())
+	// Запуск не связан с запросом: ID в контексте отличает его строки логов и запросы к БД
+	ctx := logger.WithRequestID(context.Background(), "startup-"+uuid.New().String())
+
+	currencies, err := cfg.Currencies.Registry()
+	if err != nil {
+		return nil, fmt.Errorf("invalid currencies config: %w", err)
+	}
+
+	fees, err := cfg.Fees.Build(currencies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fees config: %w", err)
+	}
+
+	limits, err := cfg.Limits.Build()
+	if err != nil {
+		return nil, fmt.Errorf("invalid limits config: %w", err)
+	}
+
+	treasury, err := cfg.Treasury.Build(currencies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid treasury config: %w", err)
+	}
+
+	// Без файла курсов переводы между валютами отклоняются с EXCHANGE_RATE_UNAVAILABLE
+	var rates service.ExchangeRateProvider
+	if cfg.Transfers.ExchangeRatesFile != "" {
+		if cfg.Transfers.ExchangeSpreadBps < 0 || cfg.Transfers.ExchangeSpreadBps >= 10000 {
+			return nil, fmt.Errorf("invalid exchange spread: %d bps", cfg.Transfers.ExchangeSpreadBps)
+		}
+		fileRates, err := config.NewFileRateProvider(cfg.Transfers.ExchangeRatesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load exchange rates: %w", err)
+		}
+		rates = fileRates
+	}
+
+	// Метрики собираются всегда, metrics.Enabled только открывает /metrics
+	appMetrics := metrics.New()
 
 	pool, err := postgres.Connect(ctx, &cfg.Postgres)
 	if err != nil {
@@ -71,7 +142,19 @@ func NewServer(cfg config.Config, appLogger logger.Logger) (*Server, error) {
 	}
 	appLogger.Info(ctx, "Database connection established")
 
-	if err := runMigrations(ctx, cfg, appLogger); err != nil {
+	if err := appMetrics.RegisterPool(pool); err != nil {
+		return nil, fmt.Errorf("failed to register pool metrics: %w", err)
+	}
+
+	// Готовность требует, чтобы база была на версии самой новой миграции из каталога
+	latestMigration, err := migrations.LatestVersion(cfg.Migrations.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find latest migration: %w", err)
+	}
+
+	version, dirty, err := runMigrations(ctx, cfg, appLogger)
+	appMetrics.SetMigrationStatus(version, dirty)
+	if err != nil {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
@@ -79,17 +162,60 @@ func NewServer(cfg config.Config, appLogger logger.Logger) (*Server, error) {
 
 	transactionRepo := repository.NewTransactionRepository(adapter)
 	walletRepo := repository.NewWalletRepository(adapter)
+	ledgerRepo := repository.NewLedgerRepository(adapter)
+	idempotencyRepo := repository.NewIdempotencyRepository(adapter)
+	holdRepo := repository.NewHoldRepository(adapter)
+	scheduledRepo := repository.NewScheduledTransferRepository(adapter)
+	mandateRepo := repository.NewMandateRepository(adapter)
+	apiKeyRepo := repository.NewAPIKeyRepository(adapter)
+	schemaRepo := repository.NewSchemaRepository(adapter)
+
+	transactionService := service.NewTransactionService(transactionRepo, walletRepo, ledgerRepo, idempotencyRepo, currencies, rates, fees, limits, cfg.Transfers, appMetrics, appLogger)
+	walletService := service.NewWalletService(walletRepo, ledgerRepo, transactionRepo, currencies, treasury, limits, appLogger)
+	holdService := service.NewHoldService(holdRepo, walletRepo, transactionRepo, ledgerRepo, currencies, limits, cfg.Transfers, appLogger)
+	scheduledService := service.NewScheduledTransferService(scheduledRepo, transactionService, cfg.Transfers, appLogger)
+	mandateService := service.NewMandateService(mandateRepo, transactionService, cfg.Transfers, appLogger)
+	reconciliationService := service.NewReconciliationService(walletRepo, ledgerRepo, cfg.Transfers, appLogger)
+	treasuryService := service.NewTreasuryService(walletRepo, transactionRepo, ledgerRepo, currencies, treasury, limits, cfg.Transfers, appLogger)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, appLogger)
+	healthService := service.NewHealthService(schemaRepo, latestMigration, appLogger)
+
+	if err = treasuryService.SetupWallets(ctx); err != nil {
+		return nil, fmt.Errorf("failed to set up treasury wallets: %w", err)
+	}
 
-	transactionService := service.NewTransactionService(transactionRepo, walletRepo, appLogger)
-	walletService := service.NewWalletService(walletRepo, appLogger)
-
-	if err = Seeding(ctx, cfg.Seeding.Wallets, walletService.CreateWalletsForSeeding, appLogger); err != nil {
+	if err = Seeding(ctx, cfg.Seeding.Wallets, walletService.CreateWalletsForSeeding, appMetrics, healthService, appLogger); err != nil {
 		return nil, fmt.Errorf("Seeding failed: %w", err)
 	}
 
-	h := handler.NewHandler(transactionService, walletService, appLogger)
+	h := handler.NewHandler(transactionService, walletService, holdService, scheduledService, mandateService, reconciliationService, treasuryService, apiKeyService, healthService, appLogger)
 
-	r := httpCust.NewRouter(h, appLogger)
+	// С auth = nil роутер не проверяет API-ключи
+	var auth httpCust.Authenticator
+	if cfg.Auth.Enabled {
+		auth = apiKeyService
+	} else {
+		appLogger.Warn(ctx, "API key authentication disabled, all endpoints are open")
+	}
+
+	r := httpCust.NewRouter(h, auth, appMetrics, appLogger)
+
+	var metricsServer *http.Server
+	switch {
+	case !cfg.Metrics.Enabled:
+		appLogger.Warn(ctx, "Metrics endpoint disabled")
+	case cfg.Metrics.Addr == "":
+		r.Handle("/metrics", appMetrics.Handler()).Methods(http.MethodGet)
+	default:
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", appMetrics.Handler())
+		metricsServer = &http.Server{
+			Addr:        cfg.Metrics.Addr,
+			Handler:     metricsMux,
+			ReadTimeout: cfg.Server.ReadTimeout,
+			IdleTimeout: cfg.Server.IdleTimeout,
+		}
+	}
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
@@ -99,10 +225,60 @@ func NewServer(cfg config.Config, appLogger logger.Logger) (*Server, error) {
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
+	jobs := []job{
+		{
+			name:     "idempotency-keys-purge",
+			interval: cfg.Transfers.IdempotencyPurgeInterval,
+			run: func(ctx context.Context) {
+				transactionService.PurgeExpiredIdempotencyKeys(ctx)
+			},
+		},
+		{
+			name:     "holds-expire",
+			interval: cfg.Transfers.HoldExpireInterval,
+			run: func(ctx context.Context) {
+				holdService.ExpireHolds(ctx)
+			},
+		},
+		{
+			// Останавливается вместе с остальными задачами в WaitForShutdown,
+			// выполняемый в этот момент перевод доводится до конца
+			name:     "scheduled-transfers",
+			interval: cfg.Transfers.ScheduledPollInterval,
+			run: func(ctx context.Context) {
+				scheduledService.ExecuteDue(ctx)
+			},
+		},
+		{
+			name:     "mandates",
+			interval: cfg.Transfers.MandatePollInterval,
+			run: func(ctx context.Context) {
+				mandateService.ExecuteDue(ctx)
+			},
+		},
+		{
+			name:     "balance-snapshots",
+			interval: cfg.Transfers.BalanceSnapshotInterval,
+			run: func(ctx context.Context) {
+				walletService.SnapshotBalances(ctx)
+			},
+		},
+		{
+			name:     "reconciliation",
+			interval: cfg.Transfers.ReconciliationInterval,
+			run: func(ctx context.Context) {
+				reconciliationService.Reconcile(ctx, cfg.Transfers.ReconciliationRepair)
+			},
+		},
+	}
+
 	return &Server{
-		httpServer: httpServer,
-		logger:     appLogger,
-		config:     cfg,
+		httpServer:    httpServer,
+		metricsServer: metricsServer,
+		health:        healthService,
+		logger:        appLogger,
+		config:        cfg,
+		jobs:          jobs,
 	}, nil
 }
 
@@ -111,6 +287,60 @@ func (s *Server) Start() error {
 	return s.httpServer.ListenAndServe()
 }
 
+// StartMetrics запускает отдельный сервер метрик, если он настроен
+func (s *Server) StartMetrics(ctx context.Context) {
+	if s.metricsServer == nil {
+		return
+	}
+	go func() {
+		s.logger.Info(ctx, fmt.Sprintf("Metrics server starting on %s", s.metricsServer.Addr))
+		if err := s.metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			s.logger.Error(ctx, "Metrics server failed", zap.Error(err))
+		}
+	}()
+}
+
+// StartJobs запускает фоновые задачи. Задачи с неположительным интервалом отключены.
+func (s *Server) StartJobs(ctx context.Context) {
+	jobsCtx, cancel := context.WithCancel(ctx)
+	s.stopJobs = cancel
+
+	for _, j := range s.jobs {
+		if j.interval <= 0 {
+			s.logger.Warn(ctx, "Background job disabled", zap.String("job", j.name))
+			continue
+		}
+
+		s.jobsDone.Add(1)
+		go func(j job) {
+			defer s.jobsDone.Done()
+
+			ticker := time.NewTicker(j.interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-jobsCtx.Done():
+					return
+				case <-ticker.C:
+					// Свой ID у каждого запуска, чтобы связать его строки логов и запросы к БД
+					j.run(logger.WithRequestID(jobsCtx, j.name+"-"+uuid.New().String()))
+				}
+			}
+		}(j)
+	}
+}
+
+// stopBackgroundJobs останавливает фоновые задачи и дожидается их завершения
+func (s *Server) stopBackgroundJobs(ctx context.Context) {
+	if s.stopJobs == nil {
+		return
+	}
+	s.stopJobs()
+	s.jobsDone.Wait()
+	s.logger.Info(ctx, "Background jobs stopped")
+}
+
 // WaitForShutdown ожидает сигнал для graceful shutdown
 func (s *Server) WaitForShutdown(ctx context.Context) {
 	quit := make(chan os.Signal, 1)
@@ -120,6 +350,14 @@ func (s *Server) WaitForShutdown(ctx context.Context) {
 	<-quit
 	s.logger.Info(ctx, "Shutdown signal received")
 
+	// /readyz отвечает 503 до остановки сервера, чтобы балансировщик успел убрать экземпляр,
+	// пока он еще принимает запросы
+	s.health.MarkShuttingDown()
+	if s.config.Server.ShutdownDelay > 0 {
+		s.logger.Info(ctx, "Readiness switched off, waiting before shutdown", zap.Duration("delay", s.config.Server.ShutdownDelay))
+		time.Sleep(s.config.Server.ShutdownDelay)
+	}
+
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -129,10 +367,18 @@ func (s *Server) WaitForShutdown(ctx context.Context) {
 	} else {
 		s.logger.Info(ctx, "Server gracefully stopped")
 	}
+
+	if s.metricsServer != nil {
+		if err := s.metricsServer.Shutdown(shutdownCtx); err != nil {
+			s.logger.Error(ctx, "Metrics server forced to shutdown", zap.Error(err))
+		}
+	}
+
+	s.stopBackgroundJobs(ctx)
 }
 
 // Seeding запускает процесс создания стартового количесвта кошельков
-func Seeding(ctx context.Context, cfg config.WalletsSeedConfig, createStart seeder.CreateWalletsForSeeding, appLogger logger.Logger) error {
+func Seeding(ctx context.Context, cfg config.WalletsSeedConfig, createStart seeder.CreateWalletsForSeeding, appMetrics *metrics.Metrics, healthService *service.HealthService, appLogger logger.Logger) error {
 	seedlog := func(ctx context.Context, err error) {
 		appLogger.Warn(ctx, "Seeding error create wallet", zap.Error(err))
 	}
@@ -140,6 +386,9 @@ func Seeding(ctx context.Context, cfg config.WalletsSeedConfig, createStart seed
 	appLogger.Info(ctx, "Start seeding...")
 
 	err := seeder.SeedWallets(ctx, cfg, createStart, seedlog)
+	status := seeder.StatusOf(err)
+	appMetrics.SetSeedingStatus(status)
+	healthService.SetSeedingStatus(status)
 	if err != nil {
 		switch err {
 		case seeder.ErrDisabled:
@@ -156,8 +405,8 @@ func Seeding(ctx context.Context, cfg config.WalletsSeedConfig, createStart seed
 	return nil
 }
 
-// runMigrations запускает миграции базы данных
-func runMigrations(ctx context.Context, cfg config.Config, appLogger logger.Logger) error {
+// runMigrations запускает миграции базы данных и возвращает версию схемы и признак незавершенной миграции
+func runMigrations(ctx context.Context, cfg config.Config, appLogger logger.Logger) (uint, bool, error) {
 	// Формируем строки подключения
 	connStr := cfg.Postgres.Pool.ConnConfig.ConnString()
 	sourceURL := fmt.Sprintf(
@@ -173,18 +422,26 @@ func runMigrations(ctx context.Context, cfg config.Config, appLogger logger.Logg
 
 	m, err := migrations.New(sourceURL, connStr)
 	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+		return 0, false, fmt.Errorf("failed to create migrate instance: %w", err)
 	}
 
 	appLogger.Info(ctx, "Starting migrations...")
-	if err := m.Up(); err != nil {
-		if err == migrations.ErrNoChange {
-			appLogger.Info(ctx, "No new migrations to apply")
-			return nil
-		}
-		return fmt.Errorf("failed to apply migrations: %w", err)
+	upErr := m.Up()
+	if upErr != nil && upErr != migrations.ErrNoChange {
+		// Версия нужна метрикам и после неудачной миграции: она покажет dirty-состояние
+		version, dirty, _ := m.Version()
+		return version, dirty, fmt.Errorf("failed to apply migrations: %w", upErr)
 	}
 
-	appLogger.Info(ctx, "Migrations applied successfully")
-	return nil
+	version, dirty, err := m.Version()
+	if err != nil && err != migrations.ErrNilVersion {
+		return 0, false, fmt.Errorf("failed to read migration version: %w", err)
+	}
+
+	if upErr == migrations.ErrNoChange {
+		appLogger.Info(ctx, "No new migrations to apply", zap.Uint("version", version))
+	} else {
+		appLogger.Info(ctx, "Migrations applied successfully", zap.Uint("version", version))
+	}
+	return version, dirty, nil
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"TransactionTest/config"
+	"TransactionTest/internal/delivery/http/handler"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+	"TransactionTest/internal/repository"
+	"TransactionTest/internal/service"
+	"TransactionTest/internal/storage/postgres"
+)
+
+// runReconcile выполняет сверку балансов с журналом и печатает отчет в stdout в том же JSON, что и POST /api/admin/reconcile.
+// Миграции не применяются: схема должна быть уже развернута сервером.
+func runReconcile(ctx context.Context, cfg config.Config, appLogger logger.Logger, args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	repair := flags.Bool("repair", false, "write correcting journal entries for found drifts")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	pool, err := postgres.Connect(ctx, &cfg.Postgres)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer pool.Close()
+
+	adapter := postgres.NewPoolAdapter(pool)
+	reconciliationService := service.NewReconciliationService(
+		repository.NewWalletRepository(adapter),
+		repository.NewLedgerRepository(adapter),
+		cfg.Transfers,
+		appLogger,
+	)
+
+	report, code := reconciliationService.Reconcile(ctx, *repair)
+	if code != domain.CodeOK {
+		return fmt.Errorf("reconciliation returned %s", code)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(handler.ReconciliationReportResponse(report))
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"time"
 
+	"TransactionTest/internal/domain"
+
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -19,6 +21,20 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `mapstructure:"ReadTimeout"`
 	WriteTimeout time.Duration `mapstructure:"WriteTimeout"`
 	IdleTimeout  time.Duration `mapstructure:"IdleTimeout"`
+	// ShutdownDelay пауза между переключением /readyz в 503 и остановкой сервера
+	ShutdownDelay time.Duration `mapstructure:"ShutdownDelay"`
+}
+
+// AuthConfig аутентификация запросов API-ключами. Выключенная аутентификация открывает все маршруты,
+// это допустимо только для локальной разработки.
+type AuthConfig struct {
+	Enabled bool `mapstructure:"Enabled"`
+}
+
+// MetricsConfig эндпоинт /metrics в текстовом формате Prometheus
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"Enabled"`
+	Addr    string `mapstructure:"Addr"` // отдельный адрес, например ":9090"; пусто - /metrics на основном сервере
 }
 
 type MigrationConfig struct {
@@ -27,17 +43,175 @@ type MigrationConfig struct {
 }
 
 type WalletsSeedConfig struct {
-	Enabled     bool    `yaml:"Enabled"`
-	FailOnError bool    `yaml:"FailOnError"`
-	Count       int     `yaml:"Count"`
-	Balance     float64 `yaml:"Balance"`
-	MarkerFile  string  `yaml:"Marker_file"`
+	Enabled     bool         `yaml:"Enabled"`
+	FailOnError bool         `yaml:"FailOnError"`
+	Count       int          `yaml:"Count"`
+	Balance     domain.Money `yaml:"Balance"`
+	MarkerFile  string       `yaml:"Marker_file"`
 }
 
 type SeedingConfig struct {
 	Wallets WalletsSeedConfig `yaml:"wallets"`
 }
 
+// TransferConfig настройки проведения переводов
+type TransferConfig struct {
+	MaxRetries int           `mapstructure:"MaxRetries"` // сколько раз повторять перевод при 40001/40P01
+	RetryDelay time.Duration `mapstructure:"RetryDelay"` // базовая пауза между попытками, растет линейно
+
+	IdempotencyTTL           time.Duration `mapstructure:"IdempotencyTTL"`           // сколько хранится ключ идемпотентности
+	IdempotencyPurgeInterval time.Duration `mapstructure:"IdempotencyPurgeInterval"` // как часто удалять истекшие ключи
+
+	// AdminHardDelete админский режим: разрешает физическое удаление транзакций без отмены.
+	// По умолчанию выключен, DELETE /api/transaction/{id} проводит отмену.
+	AdminHardDelete bool `mapstructure:"AdminHardDelete"`
+
+	// Резервы средств (holds)
+	HoldDefaultTTL     time.Duration `mapstructure:"HoldDefaultTTL"`     // срок резерва, если он не задан в запросе
+	HoldMaxTTL         time.Duration `mapstructure:"HoldMaxTTL"`         // максимальный срок резерва
+	HoldExpireInterval time.Duration `mapstructure:"HoldExpireInterval"` // как часто помечать истекшие резервы
+
+	// Отложенные переводы
+	ScheduledPollInterval time.Duration `mapstructure:"ScheduledPollInterval"` // как часто искать наступившие переводы
+	ScheduledBatchSize    int           `mapstructure:"ScheduledBatchSize"`    // сколько переводов выполнять за один проход
+	ScheduledLease        time.Duration `mapstructure:"ScheduledLease"`        // через сколько зависший в running перевод выполняется повторно
+
+	// Регулярные переводы (поручения). ScheduledBatchSize и ScheduledLease действуют и для них.
+	MandatePollInterval time.Duration `mapstructure:"MandatePollInterval"` // как часто искать наступившие запуски
+	MandateMaxAttempts  int           `mapstructure:"MandateMaxAttempts"`  // сколько попыток на один запуск, включая первую
+	MandateRetryDelay   time.Duration `mapstructure:"MandateRetryDelay"`   // пауза перед повтором, растет линейно с номером попытки
+
+	// Снимки балансов для запросов баланса на момент времени
+	BalanceSnapshotInterval time.Duration `mapstructure:"BalanceSnapshotInterval"` // как часто снимать балансы изменившихся кошельков
+
+	// Сверка балансов кошельков с журналом
+	ReconciliationInterval time.Duration `mapstructure:"ReconciliationInterval"` // как часто запускать сверку
+	ReconciliationRepair   bool          `mapstructure:"ReconciliationRepair"`   // вносить найденные расхождения в журнал
+
+	// Переводы между валютами. Без файла курсов такие переводы отклоняются.
+	ExchangeRatesFile string `mapstructure:"ExchangeRatesFile"` // YAML/JSON с курсами, см. config/rates.yml
+	ExchangeSpreadBps int    `mapstructure:"ExchangeSpreadBps"` // спред в базисных пунктах (50 = 0.5%), уменьшает курс для клиента
+}
+
+// CurrencyConfig валюта ISO 4217 и число знаков после запятой (не больше 2)
+type CurrencyConfig struct {
+	Code     string `mapstructure:"Code"`
+	Decimals int    `mapstructure:"Decimals"`
+}
+
+// CurrenciesConfig список допустимых валют кошельков
+type CurrenciesConfig struct {
+	Default   string           `mapstructure:"Default"` // валюта кошелька, если в запросе она не указана
+	Supported []CurrencyConfig `mapstructure:"Supported"`
+}
+
+// Registry собирает справочник валют для сервисов
+func (c CurrenciesConfig) Registry() (*domain.CurrencyRegistry, error) {
+	currencies := make([]domain.Currency, len(c.Supported))
+	for i, cur := range c.Supported {
+		currencies[i] = domain.Currency{Code: cur.Code, Decimals: cur.Decimals}
+	}
+	return domain.NewCurrencyRegistry(c.Default, currencies)
+}
+
+// FeeTierConfig ступень тарифа, применяется к суммам не больше UpTo (0 - без верхней границы)
+type FeeTierConfig struct {
+	UpTo       domain.Money `mapstructure:"UpTo"`
+	Flat       domain.Money `mapstructure:"Flat"`
+	PercentBps int          `mapstructure:"PercentBps"`
+}
+
+// FeeScheduleConfig тариф комиссии за переводы в одной валюте
+type FeeScheduleConfig struct {
+	Currency   string          `mapstructure:"Currency"`
+	Collector  string          `mapstructure:"Collector"` // адрес кошелька-сборщика комиссий в той же валюте
+	Flat       domain.Money    `mapstructure:"Flat"`
+	PercentBps int             `mapstructure:"PercentBps"` // процент в базисных пунктах (100 = 1%)
+	Tiers      []FeeTierConfig `mapstructure:"Tiers"`      // если заданы, заменяют Flat и PercentBps
+	Min        domain.Money    `mapstructure:"Min"`
+	Max        domain.Money    `mapstructure:"Max"` // 0 - без ограничения
+}
+
+// FeesConfig тарифы комиссий за переводы. Переводы в валютах без тарифа проходят без комиссии.
+type FeesConfig struct {
+	Schedules []FeeScheduleConfig `mapstructure:"Schedules"`
+}
+
+// Build проверяет тарифы и собирает справочник для сервисов
+func (c FeesConfig) Build(currencies *domain.CurrencyRegistry) (*domain.FeeSchedules, error) {
+	schedules := make([]domain.FeeSchedule, len(c.Schedules))
+	for i, s := range c.Schedules {
+		tiers := make([]domain.FeeTier, len(s.Tiers))
+		for j, t := range s.Tiers {
+			tiers[j] = domain.FeeTier{UpTo: t.UpTo, Flat: t.Flat, PercentBps: t.PercentBps}
+		}
+		schedules[i] = domain.FeeSchedule{
+			Currency:   s.Currency,
+			Collector:  s.Collector,
+			Flat:       s.Flat,
+			PercentBps: s.PercentBps,
+			Tiers:      tiers,
+			Min:        s.Min,
+			Max:        s.Max,
+		}
+	}
+	return domain.NewFeeSchedules(schedules, currencies)
+}
+
+// LimitsConfig лимиты кошельков по умолчанию, действуют для кошельков без своих лимитов.
+// Суммы в единицах валюты кошелька, 0 - без ограничения.
+type LimitsConfig struct {
+	MaxTransfer     domain.Money  `mapstructure:"MaxTransfer"`     // сумма одного перевода
+	MaxDailyOutflow domain.Money  `mapstructure:"MaxDailyOutflow"` // сумма исходящих переводов с комиссиями за Window
+	MaxDailyCount   int           `mapstructure:"MaxDailyCount"`   // число исходящих переводов за Window
+	MaxBalance      domain.Money  `mapstructure:"MaxBalance"`      // баланс получателя после зачисления
+	Window          time.Duration `mapstructure:"Window"`          // скользящее окно дневных лимитов, по умолчанию 24h
+}
+
+// Build проверяет лимиты и собирает политику для сервисов
+func (c LimitsConfig) Build() (domain.LimitPolicy, error) {
+	policy := domain.LimitPolicy{
+		Defaults: domain.WalletLimits{
+			MaxTransfer:     c.MaxTransfer,
+			MaxDailyOutflow: c.MaxDailyOutflow,
+			MaxDailyCount:   c.MaxDailyCount,
+			MaxBalance:      c.MaxBalance,
+		},
+		Window: c.Window,
+	}
+	if policy.Window == 0 {
+		policy.Window = 24 * time.Hour
+	}
+	if policy.Window < 0 {
+		return domain.LimitPolicy{}, fmt.Errorf("%w: limits window %v must be positive", domain.ErrInvalidInput, c.Window)
+	}
+	if err := policy.Defaults.Validate(); err != nil {
+		return domain.LimitPolicy{}, err
+	}
+	return policy, nil
+}
+
+// TreasuryWalletConfig кошелек казначейства в одной валюте
+type TreasuryWalletConfig struct {
+	Currency string `mapstructure:"Currency"`
+	Address  string `mapstructure:"Address"` // создается при запуске, если его еще нет
+}
+
+// TreasuryConfig кошельки казначейства для пополнений и выводов. Без кошелька в валюте
+// пополнение и вывод кошельков в этой валюте отклоняются.
+type TreasuryConfig struct {
+	Wallets []TreasuryWalletConfig `mapstructure:"Wallets"`
+}
+
+// Build проверяет кошельки казначейства и собирает справочник для сервисов
+func (c TreasuryConfig) Build(currencies *domain.CurrencyRegistry) (*domain.Treasury, error) {
+	wallets := make([]domain.TreasuryWallet, len(c.Wallets))
+	for i, w := range c.Wallets {
+		wallets[i] = domain.TreasuryWallet{Currency: w.Currency, Address: w.Address}
+	}
+	return domain.NewTreasury(wallets, currencies)
+}
+
 type ConnConfig struct {
 	Host           string `mapstructure:"Host"`
 	Port           int    `mapstructure:"Port"`
@@ -76,6 +250,7 @@ type PostgresConfig struct {
 	ConnectRetries    int                `mapstructure:"ConnectRetries"`
 	ConnectRetryDelay time.Duration      `mapstructure:"ConnectRetryDelay"`
 	Schema            string             `mapstructure:"Schema"`
+	ApplicationName   string             `mapstructure:"ApplicationName"` // на время запроса заменяется ID запроса
 }
 
 type LoggerConfig struct {
@@ -89,9 +264,16 @@ func (l *LoggerConfig) Build() (*zap.Logger, error) {
 type Config struct {
 	Postgres   PostgresConfig  `yaml:"postgres"`
 	Server     ServerConfig    `yaml:"server"`
+	Auth       AuthConfig      `yaml:"auth"`
+	Metrics    MetricsConfig   `yaml:"metrics"`
 	Logger     LoggerConfig    `yaml:"logger"`
 	Migrations MigrationConfig `yaml:"migrations"`
 	Seeding    SeedingConfig
+	Transfers  TransferConfig   `yaml:"transfers"`
+	Currencies CurrenciesConfig `yaml:"currencies"`
+	Fees       FeesConfig       `yaml:"fees"`
+	Limits     LimitsConfig     `yaml:"limits"`
+	Treasury   TreasuryConfig   `yaml:"treasury"`
 }
 
 func LoadConfig() (Config, error) {
@@ -112,6 +294,7 @@ func LoadConfig() (Config, error) {
 		DecodeHook: mapstructure.ComposeDecodeHookFunc(
 			mapstructure.StringToTimeDurationHookFunc(), // чтобы мапить durations
 			zapLevelHook, // хук для AtomicLevel
+			moneyHook,    // хук для денежных сумм
 		),
 		Result:  &cfg,
 		TagName: "mapstructure",
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"TransactionTest/internal/domain"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// ratesFile формат файла курсов. YAML или JSON, формат определяется по расширению.
+type ratesFile struct {
+	UpdatedAt time.Time   `mapstructure:"UpdatedAt"` // момент публикации курсов, по умолчанию - время изменения файла
+	Rates     []rateEntry `mapstructure:"Rates"`
+}
+
+type rateEntry struct {
+	From string      `mapstructure:"From"`
+	To   string      `mapstructure:"To"`
+	Rate domain.Rate `mapstructure:"Rate"`
+}
+
+// FileRateProvider источник курсов из файла в config/.
+// Файл перечитывается при изменении, поэтому курсы можно обновлять без перезапуска.
+// Если задан только курс From -> To, обратный курс вычисляется как 1 / Rate.
+type FileRateProvider struct {
+	path string
+
+	mu      sync.RWMutex
+	modTime time.Time
+	rates   map[string]domain.ExchangeRate
+}
+
+func NewFileRateProvider(path string) (*FileRateProvider, error) {
+	p := &FileRateProvider{path: path}
+	if err := p.refresh(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetRate возвращает курс пары from -> to. Нет курса - domain.ErrRateNotFound.
+func (p *FileRateProvider) GetRate(ctx context.Context, from, to string) (domain.ExchangeRate, error) {
+	if err := p.refresh(); err != nil {
+		return domain.ExchangeRate{}, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if rate, ok := p.rates[pairKey(from, to)]; ok {
+		return rate, nil
+	}
+	if rate, ok := p.rates[pairKey(to, from)]; ok {
+		return domain.ExchangeRate{From: from, To: to, Rate: rate.Rate.Inverse(), Timestamp: rate.Timestamp}, nil
+	}
+	return domain.ExchangeRate{}, fmt.Errorf("%w: %s -> %s", domain.ErrRateNotFound, from, to)
+}
+
+// refresh перечитывает файл, если он изменился с прошлого чтения
+func (p *FileRateProvider) refresh() error {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat rates file %s: %w", p.path, err)
+	}
+
+	p.mu.RLock()
+	fresh := p.rates != nil && info.ModTime().Equal(p.modTime)
+	p.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	rates, err := loadRates(p.path, info.ModTime())
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.rates = rates
+	p.modTime = info.ModTime()
+	p.mu.Unlock()
+	return nil
+}
+
+func loadRates(path string, modTime time.Time) (map[string]domain.ExchangeRate, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read rates file %s: %w", path, err)
+	}
+
+	var file ratesFile
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeHookFunc(time.RFC3339),
+			rateHook,
+		),
+		Result:  &file,
+		TagName: "mapstructure",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create rates decoder: %w", err)
+	}
+	if err := dec.Decode(v.AllSettings()); err != nil {
+		return nil, fmt.Errorf("unable to decode rates file %s: %w", path, err)
+	}
+
+	timestamp := file.UpdatedAt
+	if timestamp.IsZero() {
+		timestamp = modTime
+	}
+
+	rates := make(map[string]domain.ExchangeRate, len(file.Rates))
+	for _, r := range file.Rates {
+		from, to := strings.ToUpper(r.From), strings.ToUpper(r.To)
+		if from == "" || to == "" || from == to || r.Rate <= 0 {
+			return nil, fmt.Errorf("%w: bad rate %s -> %s in %s", domain.ErrInvalidRate, r.From, r.To, path)
+		}
+		rates[pairKey(from, to)] = domain.ExchangeRate{From: from, To: to, Rate: r.Rate, Timestamp: timestamp.UTC()}
+	}
+	return rates, nil
+}
+
+func pairKey(from, to string) string {
+	return strings.ToUpper(from) + "/" + strings.ToUpper(to)
+}
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"reflect"
+	"strconv"
+
+	"TransactionTest/internal/domain"
 
 	"go.uber.org/zap"
 )
@@ -23,3 +27,44 @@ func zapLevelHook(from reflect.Type, to reflect.Type, data interface{}) (interfa
 	}
 	return lvl, nil
 }
+
+// moneyHook: число или строка в domain.Money.
+// Без хука mapstructure положил бы 100 как 100 минимальных единиц (1.00).
+func moneyHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(domain.Money(0)) {
+		return data, nil
+	}
+
+	switch v := data.(type) {
+	case string:
+		return domain.ParseMoney(v)
+	case int:
+		return domain.ParseMoney(strconv.Itoa(v))
+	case int64:
+		return domain.ParseMoney(strconv.FormatInt(v, 10))
+	case float64:
+		return domain.ParseMoney(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return nil, fmt.Errorf("cannot decode %T into money", data)
+	}
+}
+
+// rateHook: число или строка в domain.Rate (курс валют)
+func rateHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(domain.Rate(0)) {
+		return data, nil
+	}
+
+	switch v := data.(type) {
+	case string:
+		return domain.ParseRate(v)
+	case int:
+		return domain.ParseRate(strconv.Itoa(v))
+	case int64:
+		return domain.ParseRate(strconv.FormatInt(v, 10))
+	case float64:
+		return domain.ParseRate(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return nil, fmt.Errorf("cannot decode %T into rate", data)
+	}
+}
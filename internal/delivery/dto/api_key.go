@@ -0,0 +1,21 @@
+package dto
+
+// CreateAPIKeyRequest новый API-ключ. scopes - права ключа: read, transfer, admin.
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=read transfer admin"`
+}
+
+type APIKeyResponse struct {
+	Id        int64    `json:"id"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	CreatedAt string   `json:"created_at"`
+	RevokedAt string   `json:"revoked_at,omitempty"`
+}
+
+// CreatedAPIKeyResponse выданный ключ. Поле key возвращается только в этом ответе.
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
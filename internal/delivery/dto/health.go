@@ -0,0 +1,15 @@
+package dto
+
+// HealthCheckResponse результат одной проверки готовности. latency_ms - длительность проверки в миллисекундах.
+type HealthCheckResponse struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Detail    string  `json:"detail,omitempty"`
+}
+
+// HealthResponse состояние сервиса. Checks заполняется только для /readyz.
+type HealthResponse struct {
+	Status string                `json:"status"`
+	Checks []HealthCheckResponse `json:"checks,omitempty"`
+}
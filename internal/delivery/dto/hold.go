@@ -0,0 +1,35 @@
+package dto
+
+import "TransactionTest/internal/domain"
+
+// CreateHoldRequest резерв средств отправителя в пользу получателя
+type CreateHoldRequest struct {
+	From   string       `json:"from" validate:"required,uuid4"`
+	To     string       `json:"to"    validate:"required,uuid4,nefield=From"`
+	Amount domain.Money `json:"amount" validate:"required,gt=0"`
+	// TTLSeconds срок резерва в секундах, без него используется transfers.HoldDefaultTTL
+	TTLSeconds int64 `json:"ttl_seconds" validate:"omitempty,gt=0"`
+}
+
+// CaptureHoldRequest захват резерва. Без amount захватывается вся сумма.
+type CaptureHoldRequest struct {
+	Amount domain.Money `json:"amount" validate:"omitempty,gt=0"`
+}
+
+type HoldResponse struct {
+	Id             int64        `json:"id"`
+	From           string       `json:"from"`
+	To             string       `json:"to"`
+	Amount         domain.Money `json:"amount"`
+	Currency       string       `json:"currency"`
+	Status         string       `json:"status"`
+	CapturedAmount domain.Money `json:"captured_amount"`
+	TransactionId  int64        `json:"transaction_id,omitempty"`
+	CreatedAt      string       `json:"created_at"`
+	ExpiresAt      string       `json:"expires_at"`
+}
+
+type CaptureHoldResponse struct {
+	Message       string `json:"message"`
+	TransactionId int64  `json:"transaction_id"`
+}
@@ -0,0 +1,57 @@
+package dto
+
+import "TransactionTest/internal/domain"
+
+// RecurrenceRequest расписание поручения: каждые interval дней/недель/месяцев или cron-выражение
+type RecurrenceRequest struct {
+	Frequency string `json:"frequency" validate:"required,oneof=daily weekly monthly cron"`
+	// Interval по умолчанию 1, для cron не используется
+	Interval int    `json:"interval" validate:"omitempty,gt=0"`
+	Cron     string `json:"cron" validate:"required_if=Frequency cron,max=100"`
+}
+
+// CreateMandateRequest поручение на регулярный перевод. Время в RFC 3339.
+type CreateMandateRequest struct {
+	From       string            `json:"from" validate:"required,uuid4"`
+	To         string            `json:"to"    validate:"required,uuid4,nefield=From"`
+	Amount     domain.Money      `json:"amount" validate:"required,gt=0"`
+	Convert    bool              `json:"convert"`
+	Recurrence RecurrenceRequest `json:"recurrence"`
+	StartAt    string            `json:"start_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	EndAt      string            `json:"end_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	MaxRuns    int               `json:"max_runs" validate:"omitempty,gt=0"`
+}
+
+type RecurrenceResponse struct {
+	Frequency string `json:"frequency"`
+	Interval  int    `json:"interval,omitempty"`
+	Cron      string `json:"cron,omitempty"`
+}
+
+type MandateResponse struct {
+	Id         int64              `json:"id"`
+	From       string             `json:"from"`
+	To         string             `json:"to"`
+	Amount     domain.Money       `json:"amount"`
+	Convert    bool               `json:"convert"`
+	Recurrence RecurrenceResponse `json:"recurrence"`
+	StartAt    string             `json:"start_at"`
+	EndAt      string             `json:"end_at,omitempty"`
+	MaxRuns    int                `json:"max_runs,omitempty"`
+	Status     string             `json:"status"`
+	RunsCount  int                `json:"runs_count"`
+	NextRunAt  string             `json:"next_run_at,omitempty"`
+	CreatedAt  string             `json:"created_at"`
+}
+
+// MandateRunResponse попытка выполнения поручения
+type MandateRunResponse struct {
+	Id            int64  `json:"id"`
+	Occurrence    int    `json:"occurrence"`
+	Attempt       int    `json:"attempt"`
+	ScheduledAt   string `json:"scheduled_at"`
+	Status        string `json:"status"`
+	TransactionId int64  `json:"transaction_id,omitempty"`
+	FailureCode   string `json:"failure_code,omitempty"`
+	ExecutedAt    string `json:"executed_at"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "TransactionTest/internal/domain"
+
+// WalletDriftResponse кошелек, сохраненный баланс которого расходится с журналом.
+// drift = balance - expected, repaired - расхождение внесено в журнал корректирующей записью.
+type WalletDriftResponse struct {
+	Address  string       `json:"address"`
+	Currency string       `json:"currency"`
+	Balance  domain.Money `json:"balance"`
+	Expected domain.Money `json:"expected"`
+	Drift    domain.Money `json:"drift"`
+	Repaired bool         `json:"repaired"`
+}
+
+// ReconciliationReportResponse отчет сверки балансов с журналом
+type ReconciliationReportResponse struct {
+	StartedAt  string                `json:"started_at"`
+	FinishedAt string                `json:"finished_at"`
+	Repair     bool                  `json:"repair"`
+	Checked    int                   `json:"checked"`
+	Repaired   int                   `json:"repaired"`
+	Drifts     []WalletDriftResponse `json:"drifts"`
+}
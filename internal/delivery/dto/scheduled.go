@@ -0,0 +1,31 @@
+package dto
+
+import "TransactionTest/internal/domain"
+
+// CreateScheduledTransferRequest перевод, который будет выполнен в момент execute_at (RFC 3339)
+type CreateScheduledTransferRequest struct {
+	From      string       `json:"from" validate:"required,uuid4"`
+	To        string       `json:"to"    validate:"required,uuid4,nefield=From"`
+	Amount    domain.Money `json:"amount" validate:"required,gt=0"`
+	Convert   bool         `json:"convert"`
+	ExecuteAt string       `json:"execute_at" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// ScheduledStatusQuery необязательный фильтр ?status списка отложенных переводов
+type ScheduledStatusQuery struct {
+	Status string `validate:"omitempty,oneof=pending running succeeded failed canceled"`
+}
+
+type ScheduledTransferResponse struct {
+	Id            int64        `json:"id"`
+	From          string       `json:"from"`
+	To            string       `json:"to"`
+	Amount        domain.Money `json:"amount"`
+	Convert       bool         `json:"convert"`
+	ExecuteAt     string       `json:"execute_at"`
+	Status        string       `json:"status"`
+	TransactionId int64        `json:"transaction_id,omitempty"`
+	FailureCode   string       `json:"failure_code,omitempty"`
+	CreatedAt     string       `json:"created_at"`
+	ExecutedAt    string       `json:"executed_at,omitempty"`
+}
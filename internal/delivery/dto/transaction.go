@@ -1,9 +1,89 @@
 package dto
 
+import "TransactionTest/internal/domain"
+
 type SendMoneyRequest struct {
-	From   string  `json:"from" validate:"required,uuid4"`
-	To     string  `json:"to"    validate:"required,uuid4,nefield=From"`
-	Amount float64 `json:"amount" validate:"required,gt=0"`
+	From   string       `json:"from" validate:"required,uuid4"`
+	To     string       `json:"to"    validate:"required,uuid4,nefield=From"`
+	Amount domain.Money `json:"amount" validate:"required,gt=0"`
+	// Convert разрешает перевод в кошелек другой валюты по текущему курсу
+	Convert bool   `json:"convert"`
+	Memo    string `json:"memo" validate:"max=255"`
+	// ExternalReference идентификатор во внешней системе, уникален в пределах отправителя
+	ExternalReference string                 `json:"external_reference" validate:"omitempty,max=128,printascii"`
+	Metadata          map[string]interface{} `json:"metadata" validate:"max=50"`
+}
+
+type SendMoneyResponse struct {
+	Message       string            `json:"message"`
+	TransactionId int64             `json:"transaction_id"`
+	Fee           domain.Money      `json:"fee,omitempty"` // комиссия, списанная сверх amount
+	Exchange      *ExchangeResponse `json:"exchange,omitempty"`
+}
+
+// ExchangeResponse детали конвертации перевода между валютами
+type ExchangeResponse struct {
+	ToCurrency string       `json:"to_currency"`
+	ToAmount   domain.Money `json:"to_amount"`
+	Rate       domain.Rate  `json:"rate"`
+	RateAt     string       `json:"rate_at"`
+}
+
+// FundsRequest пополнение или вывод средств кошелька через казначейство
+type FundsRequest struct {
+	Amount            domain.Money           `json:"amount" validate:"required,gt=0"`
+	Memo              string                 `json:"memo" validate:"max=255"`
+	ExternalReference string                 `json:"external_reference" validate:"omitempty,max=128,printascii"`
+	Metadata          map[string]interface{} `json:"metadata" validate:"max=50"`
+}
+
+type FundsResponse struct {
+	Message       string `json:"message"`
+	TransactionId int64  `json:"transaction_id"`
+}
+
+// TransferLegRequest нога пакетного перевода. Конвертация в пакетах не поддерживается.
+type TransferLegRequest struct {
+	From   string       `json:"from" validate:"required,uuid4"`
+	To     string       `json:"to"    validate:"required,uuid4,nefield=From"`
+	Amount domain.Money `json:"amount" validate:"required,gt=0"`
+}
+
+// SendBatchRequest пакет переводов, проводится атомарно
+type SendBatchRequest struct {
+	Legs []TransferLegRequest `json:"legs" validate:"required,min=1,max=500,dive"`
+}
+
+type SendBatchResponse struct {
+	Message        string  `json:"message"`
+	TransactionIds []int64 `json:"transaction_ids"`
+}
+
+type LegErrorResponse struct {
+	Index int    `json:"index"`
+	Code  string `json:"code"`
+	Limit string `json:"limit,omitempty"` // нарушенный лимит кошелька при коде LIMIT_EXCEEDED
+}
+
+// BatchErrorResponse ответ на отклоненный пакет с причинами по каждой ноге
+type BatchErrorResponse struct {
+	Error   string             `json:"error"`
+	Code    string             `json:"code"`
+	Message string             `json:"message"`
+	Legs    []LegErrorResponse `json:"legs"`
+}
+
+// LimitErrorResponse ответ на перевод, отклоненный лимитом кошелька
+type LimitErrorResponse struct {
+	Error   string `json:"error"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Limit   string `json:"limit"`
+}
+
+// IdempotencyKeyHeader значение заголовка Idempotency-Key
+type IdempotencyKeyHeader struct {
+	Key string `validate:"omitempty,max=255,printascii"`
 }
 
 type GetTransactionByInfoRequest struct {
@@ -13,13 +93,48 @@ type GetTransactionByInfoRequest struct {
 }
 
 type TransactionResponse struct {
-	Id        int64   `json:"id"`
-	From      string  `json:"from"`
-	To        string  `json:"to"`
-	Amount    float64 `json:"amount"`
-	CreatedAt string  `json:"created_at"`
+	Id         int64             `json:"id"`
+	Kind       string            `json:"kind"`
+	From       string            `json:"from"`
+	To         string            `json:"to"`
+	Amount     domain.Money      `json:"amount"`
+	Currency   string            `json:"currency"`
+	Fee        domain.Money      `json:"fee"`
+	CreatedAt  string            `json:"created_at"`
+	ReversalOf int64             `json:"reversal_of,omitempty"`
+	Exchange   *ExchangeResponse `json:"exchange,omitempty"`
+
+	Memo              string                 `json:"memo,omitempty"`
+	ExternalReference string                 `json:"external_reference,omitempty"`
+	Metadata          map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// SearchTransactionsQuery параметры поиска транзакций по внешнему идентификатору
+type SearchTransactionsQuery struct {
+	ExternalReference string `validate:"required,max=128,printascii"`
+	From              string `validate:"omitempty,uuid4"`
+}
+
+type ReverseTransactionResponse struct {
+	Message    string `json:"message"`
+	ReversalId int64  `json:"reversal_id"`
+}
+
+// ListTransactionsQuery параметры страницы истории транзакций, все необязательные
+type ListTransactionsQuery struct {
+	Wallet      string `validate:"omitempty,uuid4"`
+	Role        string `validate:"omitempty,oneof=any sender receiver"`
+	CreatedFrom string `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	CreatedTo   string `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	MinAmount   string `validate:"omitempty,max=32"`
+	MaxAmount   string `validate:"omitempty,max=32"`
+	Order       string `validate:"omitempty,oneof=asc desc"`
+	Count       string `validate:"omitempty,number"`
+	Cursor      string `validate:"omitempty,max=256"`
 }
 
+// TransactionsResponse страница истории транзакций. next_cursor - null на последней странице.
 type TransactionsResponse struct {
 	Transactions []TransactionResponse `json:"transactions"`
+	NextCursor   *string               `json:"next_cursor"`
 }
@@ -1,23 +1,132 @@
 package dto
 
+import "TransactionTest/internal/domain"
+
 type CreateWalletRequest struct {
-	Balance float64 `json:"balance" validate:"required,gte=0"`
+	Balance  domain.Money `json:"balance" validate:"required,gte=0"`
+	Currency string       `json:"currency" validate:"omitempty,len=3,alpha,uppercase"`
 }
 
+// UpdateBalanceRequest ручная корректировка баланса, причина сохраняется в аудите
 type UpdateBalanceRequest struct {
-	Balance float64 `json:"balance" validate:"required,gte=0"`
+	Balance domain.Money `json:"balance" validate:"required,gte=0"`
+	Reason  string       `json:"reason" validate:"required,max=255"`
+}
+
+type BalanceAdjustmentResponse struct {
+	Id              int64        `json:"id"`
+	Address         string       `json:"address"`
+	PreviousBalance domain.Money `json:"previous_balance"`
+	NewBalance      domain.Money `json:"new_balance"`
+	Reason          string       `json:"reason"`
+	EntryId         int64        `json:"entry_id,omitempty"`
+	CreatedAt       string       `json:"created_at"`
+}
+
+// OverdraftRequest кредитная линия кошелька, 0 - без кредита
+type OverdraftRequest struct {
+	OverdraftLimit *domain.Money `json:"overdraft_limit" validate:"required,gte=0"`
 }
 
 type CreateWalletResponse struct {
 	Address string `json:"address"`
 }
 
+// BalanceResponse баланс по журналу (balance) и доступный для списания с учетом резервов и кредитной линии (available)
 type BalanceResponse struct {
-	Balance float64 `json:"balance"`
+	Balance   domain.Money `json:"balance"`
+	Available domain.Money `json:"available"`
+}
+
+// BalanceAtResponse баланс по журналу на момент at
+type BalanceAtResponse struct {
+	Balance domain.Money `json:"balance"`
+	At      string       `json:"at"`
 }
 
 type WalletResponse struct {
-	Address   string  `json:"address"`
-	Balance   float64 `json:"balance"`
-	CreatedAt string  `json:"created_at"`
+	Address         string       `json:"address"`
+	Balance         domain.Money `json:"balance"`
+	Currency        string       `json:"currency"`
+	Status          string       `json:"status"`
+	Treasury        bool         `json:"treasury,omitempty"`
+	OverdraftLimit  domain.Money `json:"overdraft_limit"`
+	CreditUsed      domain.Money `json:"credit_used"`
+	CreditAvailable domain.Money `json:"credit_available"`
+	CreatedAt       string       `json:"created_at"`
+}
+
+// WalletStatusRequest причина смены статуса кошелька (заморозка, разморозка, закрытие)
+type WalletStatusRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+type WalletStatusChangeResponse struct {
+	Id         int64  `json:"id"`
+	Address    string `json:"address"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	Reason     string `json:"reason"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type PostingResponse struct {
+	Id            int64        `json:"id"`
+	EntryId       int64        `json:"entry_id"`
+	Kind          string       `json:"kind"`
+	TransactionId int64        `json:"transaction_id,omitempty"`
+	Currency      string       `json:"currency"`
+	Amount        domain.Money `json:"amount"`
+	CreatedAt     string       `json:"created_at"`
+}
+
+// WalletLimitsRequest лимиты кошелька. Не указанный (или null) лимит берется из конфигурации, 0 - без ограничения.
+type WalletLimitsRequest struct {
+	MaxTransfer     *domain.Money `json:"max_transfer" validate:"omitempty,gte=0"`
+	MaxDailyOutflow *domain.Money `json:"max_daily_outflow" validate:"omitempty,gte=0"`
+	MaxDailyCount   *int          `json:"max_daily_count" validate:"omitempty,gte=0"`
+	MaxBalance      *domain.Money `json:"max_balance" validate:"omitempty,gte=0"`
+}
+
+// WalletLimitsResponse действующие лимиты кошелька. custom - лимиты, заданные для кошелька, остальные взяты из конфигурации.
+type WalletLimitsResponse struct {
+	Address         string       `json:"address"`
+	MaxTransfer     domain.Money `json:"max_transfer"`
+	MaxDailyOutflow domain.Money `json:"max_daily_outflow"`
+	MaxDailyCount   int          `json:"max_daily_count"`
+	MaxBalance      domain.Money `json:"max_balance"`
+	WindowSeconds   int64        `json:"window_seconds"`
+	Custom          []string     `json:"custom"`
+}
+
+// StatementQuery период выписки, обе границы обязательны
+type StatementQuery struct {
+	From string `validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	To   string `validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// StatementLineResponse операция выписки. balance - баланс кошелька после операции.
+type StatementLineResponse struct {
+	PostingId     int64        `json:"posting_id"`
+	Kind          string       `json:"kind"`
+	TransactionId int64        `json:"transaction_id,omitempty"`
+	Counterparty  string       `json:"counterparty,omitempty"`
+	Fee           bool         `json:"fee,omitempty"`
+	Memo          string       `json:"memo,omitempty"`
+	Amount        domain.Money `json:"amount"`
+	Balance       domain.Money `json:"balance"`
+	CreatedAt     string       `json:"created_at"`
+}
+
+// StatementResponse выписка по кошельку за период [from, to)
+type StatementResponse struct {
+	Address        string                  `json:"address"`
+	Currency       string                  `json:"currency"`
+	From           string                  `json:"from"`
+	To             string                  `json:"to"`
+	OpeningBalance domain.Money            `json:"opening_balance"`
+	TotalCredits   domain.Money            `json:"total_credits"`
+	TotalDebits    domain.Money            `json:"total_debits"`
+	ClosingBalance domain.Money            `json:"closing_balance"`
+	Lines          []StatementLineResponse `json:"lines"`
 }
@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"TransactionTest/internal/delivery/dto"
+	"TransactionTest/internal/delivery/validator"
+	"TransactionTest/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// CreateAPIKey обрабатывает HTTP POST запрос для выдачи нового API-ключа.
+// Ключ передается в заголовке X-API-Key или Authorization: Bearer. Права ключа:
+// read - чтение, transfer - переводы, резервы, отложенные и регулярные переводы,
+// admin - все остальное, включая управление ключами (admin включает read и transfer).
+// В базе хранится только хеш, поэтому сам ключ возвращается один раз - в этом ответе.
+//
+// Принимает JSON в теле запроса:
+//
+//	{
+//	  "name": "billing-service",
+//	  "scopes": ["read", "transfer"]
+//	}
+//
+// URL: POST /api/admin/keys
+//
+// Возможные коды ответа:
+//   - 201 Created: ключ выдан
+//   - 400 Bad Request: ошибка валидации или неизвестное право
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "id": 2,
+//	  "name": "billing-service",
+//	  "scopes": ["read", "transfer"],
+//	  "created_at": "2026-10-16T10:00:00Z",
+//	  "key": "tk_5f2b..."
+//	}
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "CreateAPIKey: "
+
+	var req dto.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(
+			ctx,
+			op+"failed to decode JSON",
+			zap.Error(err),
+		)
+		errCode, errMsg := decodeErrorCode(err)
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, errMsg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.String("name", req.Name),
+		zap.Strings("scopes", req.Scopes),
+	)
+
+	if err := validator.ValidateStruct(req); err != nil {
+		h.log.Warn(
+			ctx,
+			op+"validation failed",
+			zap.Any("errors", err),
+		)
+		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, err.Error())
+		return
+	}
+
+	key, plain, svcCode := h.apiKeyService.CreateAPIKey(ctx, req.Name, req.Scopes)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "CreateAPIKey")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"api key created successfully",
+		zap.Int64("api_key_id", key.Id),
+	)
+	h.writeJSON(ctx, w, http.StatusCreated, CreatedAPIKeyResponse(key, plain))
+}
+
+// ListAPIKeys обрабатывает HTTP GET запрос для получения всех API-ключей, включая отозванные,
+// в порядке выдачи. Сами ключи не возвращаются.
+//
+// URL: GET /api/admin/keys
+//
+// Возможные коды ответа:
+//   - 200 OK: список ключей (у отозванных есть revoked_at)
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	[
+//	  {
+//	    "id": 1,
+//	    "name": "bootstrap",
+//	    "scopes": ["admin"],
+//	    "created_at": "2026-10-16T09:00:00Z",
+//	    "revoked_at": "2026-10-16T11:00:00Z"
+//	  }
+//	]
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "ListAPIKeys: "
+
+	h.log.Info(ctx, op+"received request")
+
+	keys, svcCode := h.apiKeyService.ListAPIKeys(ctx)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "ListAPIKeys")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"api keys retrieved successfully",
+		zap.Int("count", len(keys)),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, APIKeysResponse(keys))
+}
+
+// RevokeAPIKey обрабатывает HTTP DELETE запрос для отзыва API-ключа.
+// Запросы с отозванным ключом отклоняются с 401, повторный отзыв не считается ошибкой.
+//
+// Path параметры:
+//   - id: ID ключа (обязательный, положительное число)
+//
+// URL: DELETE /api/admin/keys/2
+//
+// Возможные коды ответа:
+//   - 200 OK: ключ отозван
+//   - 400 Bad Request: неверный ID
+//   - 404 Not Found: ключ не найден
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "message": "API key revoked successfully"
+//	}
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "RevokeAPIKey: "
+
+	id, code, msg := h.parseAndValidateID(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Int64("api_key_id", id),
+	)
+
+	if svcCode := h.apiKeyService.RevokeAPIKey(ctx, id); svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "RevokeAPIKey")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"api key revoked successfully",
+		zap.Int64("api_key_id", id),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, map[string]string{"message": "API key revoked successfully"})
+}
+
+// CreatedAPIKeyResponse переводит выданный ключ в JSON-ответ.
+// Используется и HTTP обработчиком, и командой apikey сервера.
+func CreatedAPIKeyResponse(key *domain.APIKey, plain string) dto.CreatedAPIKeyResponse {
+	return dto.CreatedAPIKeyResponse{
+		APIKeyResponse: apiKeyResponse(key),
+		Key:            plain,
+	}
+}
+
+// APIKeysResponse переводит список ключей в JSON-ответ.
+// Используется и HTTP обработчиком, и командой apikey сервера.
+func APIKeysResponse(keys []domain.APIKey) []dto.APIKeyResponse {
+	response := make([]dto.APIKeyResponse, len(keys))
+	for i := range keys {
+		response[i] = apiKeyResponse(&keys[i])
+	}
+	return response
+}
+
+func apiKeyResponse(key *domain.APIKey) dto.APIKeyResponse {
+	response := dto.APIKeyResponse{
+		Id:        key.Id,
+		Name:      key.Name,
+		Scopes:    make([]string, len(key.Scopes)),
+		CreatedAt: key.CreatedAt.Format(time.RFC3339),
+	}
+	for i, s := range key.Scopes {
+		response.Scopes[i] = string(s)
+	}
+	if key.RevokedAt != nil {
+		response.RevokedAt = key.RevokedAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -1,8 +1,15 @@
 // Package handler предоставляет HTTP обработчики для API транзакций и кошельков.
 //
 // Пакет содержит все HTTP обработчики для работы с:
-// - Транзакциями (отправка денег, получение истории, удаление)
+// - Транзакциями (отправка денег, получение истории, отмена)
 // - Кошельками (создание, получение баланса, обновление, удаление)
+// - Резервами средств (создание, захват, отмена)
+// - Отложенными переводами (создание, список, отмена)
+// - Регулярными переводами (создание, отмена, история запусков)
+// - Сверкой балансов кошельков с журналом
+// - Пополнениями и выводами средств через казначейство
+// - API-ключами (выдача, список, отзыв)
+// - Проверками живости и готовности сервиса
 package handler
 
 import (
@@ -21,13 +28,21 @@ import (
 // Используется для dependency injection в HTTP обработчиках.
 type ITransactionService interface {
 	// SendMoney отправляет деньги с одного кошелька на другой.
-	// Возвращает код ошибки domain.ErrorCode.
-	SendMoney(ctx context.Context, from, to string, amount float64) domain.ErrorCode
+	// Возвращает результат перевода (ID транзакции) и код ошибки domain.ErrorCode.
+	SendMoney(ctx context.Context, req domain.TransferRequest) (*domain.TransferResult, domain.ErrorCode)
+
+	// SendBatch атомарно проводит пакет переводов.
+	// Возвращает ID созданных транзакций либо ошибки по ногам и код ошибки.
+	SendBatch(ctx context.Context, legs []domain.TransferLeg) (*domain.BatchResult, domain.ErrorCode)
 
 	// GetLastTransactions возвращает последние транзакции с ограничением по количеству.
 	// Возвращает слайс транзакций и код ошибки.
 	GetLastTransactions(ctx context.Context, limit int) ([]domain.Transaction, domain.ErrorCode)
 
+	// ListTransactions возвращает страницу истории транзакций по фильтру.
+	// Возвращает страницу с курсором следующей и код ошибки.
+	ListTransactions(ctx context.Context, filter domain.TransactionFilter) (*domain.TransactionPage, domain.ErrorCode)
+
 	// GetTransactionById возвращает транзакцию по её ID.
 	// Возвращает указатель на транзакцию и код ошибки.
 	GetTransactionById(ctx context.Context, id int64) (*domain.Transaction, domain.ErrorCode)
@@ -36,50 +51,208 @@ type ITransactionService interface {
 	// Возвращает указатель на транзакцию и код ошибки.
 	GetTransactionByInfo(ctx context.Context, from, to string, createdAt time.Time) (*domain.Transaction, domain.ErrorCode)
 
-	// RemoveTransaction удаляет транзакцию по её ID.
+	// FindTransactionsByExternalReference ищет транзакции по внешнему идентификатору,
+	// from (необязательный) ограничивает поиск отправителем. Возвращает слайс транзакций и код ошибки.
+	FindTransactionsByExternalReference(ctx context.Context, reference, from string) ([]domain.Transaction, domain.ErrorCode)
+
+	// ReverseTransaction отменяет транзакцию встречным переводом.
+	// Возвращает ID отменяющей транзакции и код ошибки.
+	ReverseTransaction(ctx context.Context, id int64) (*domain.TransferResult, domain.ErrorCode)
+
+	// RemoveTransaction физически удаляет транзакцию по её ID (только в админском режиме).
 	// Возвращает код ошибки.
 	RemoveTransaction(ctx context.Context, id int64) domain.ErrorCode
 }
 
 // IWalletService определяет интерфейс для работы с кошельками.
 type IWalletService interface {
-	// CreateWallet создает новый кошелек с указанным балансом.
-	// Возвращает адрес кошелька и код ошибки.
-	CreateWallet(ctx context.Context, balance float64) (string, domain.ErrorCode)
+	// CreateWallet создает новый кошелек в указанной валюте с указанным балансом.
+	// Пустой код валюты означает валюту по умолчанию. Возвращает адрес кошелька и код ошибки.
+	CreateWallet(ctx context.Context, currency string, balance domain.Money) (string, domain.ErrorCode)
+
+	// GetBalance возвращает баланс кошелька по его адресу: по журналу и доступный с учетом резервов.
+	// Возвращает баланс и код ошибки.
+	GetBalance(ctx context.Context, address string) (domain.Balance, domain.ErrorCode)
 
-	// GetBalance возвращает баланс кошелька по его адресу.
+	// GetBalanceAt возвращает баланс кошелька по журналу на момент at.
 	// Возвращает баланс и код ошибки.
-	GetBalance(ctx context.Context, address string) (float64, domain.ErrorCode)
+	GetBalanceAt(ctx context.Context, address string, at time.Time) (domain.Money, domain.ErrorCode)
 
 	// GetWallet возвращает полную информацию о кошельке по его адресу.
 	// Возвращает указатель на кошелек и код ошибки.
 	GetWallet(ctx context.Context, address string) (*domain.Wallet, domain.ErrorCode)
 
-	// UpdateBalance обновляет баланс кошелька.
+	// UpdateBalance устанавливает баланс кошелька вручную, причина сохраняется в аудите корректировок.
+	// Возвращает запись о корректировке и код ошибки.
+	UpdateBalance(ctx context.Context, address string, newBalance domain.Money, reason string) (*domain.BalanceAdjustment, domain.ErrorCode)
+
+	// GetBalanceAdjustments возвращает последние ручные корректировки баланса кошелька.
+	// Возвращает слайс корректировок и код ошибки.
+	GetBalanceAdjustments(ctx context.Context, address string, limit int) ([]domain.BalanceAdjustment, domain.ErrorCode)
+
+	// SetOverdraftLimit задает кредитную линию кошелька.
 	// Возвращает код ошибки.
-	UpdateBalance(ctx context.Context, address string, newBalance float64) domain.ErrorCode
+	SetOverdraftLimit(ctx context.Context, address string, limit domain.Money) domain.ErrorCode
+
+	// GetWalletEntries возвращает последние проводки журнала по кошельку.
+	// Возвращает слайс проводок и код ошибки.
+	GetWalletEntries(ctx context.Context, address string, limit int) ([]domain.Posting, domain.ErrorCode)
+
+	// GetStatement возвращает выписку по кошельку за период [from, to), согласованную по одному снимку БД.
+	// Возвращает выписку и код ошибки.
+	GetStatement(ctx context.Context, address string, from, to time.Time) (*domain.Statement, domain.ErrorCode)
 
 	// RemoveWallet удаляет кошелек по его адресу.
 	// Возвращает код ошибки.
 	RemoveWallet(ctx context.Context, address string) domain.ErrorCode
+
+	// FreezeWallet замораживает активный кошелек, UnfreezeWallet возвращает его в активное состояние,
+	// CloseWallet закрывает активный кошелек с нулевым балансом. Причина сохраняется в истории статусов.
+	// Возвращают запись о смене статуса и код ошибки.
+	FreezeWallet(ctx context.Context, address, reason string) (*domain.WalletStatusChange, domain.ErrorCode)
+	UnfreezeWallet(ctx context.Context, address, reason string) (*domain.WalletStatusChange, domain.ErrorCode)
+	CloseWallet(ctx context.Context, address, reason string) (*domain.WalletStatusChange, domain.ErrorCode)
+
+	// GetWalletStatusHistory возвращает последние смены статуса кошелька.
+	// Возвращает слайс записей и код ошибки.
+	GetWalletStatusHistory(ctx context.Context, address string, limit int) ([]domain.WalletStatusChange, domain.ErrorCode)
+
+	// GetWalletLimits возвращает лимиты кошелька: заданные для него и действующие с учетом значений по умолчанию.
+	// SetWalletLimits заменяет лимиты кошелька, не заданный лимит возвращается к значению по умолчанию.
+	GetWalletLimits(ctx context.Context, address string) (*domain.WalletLimitSettings, domain.ErrorCode)
+	SetWalletLimits(ctx context.Context, address string, overrides domain.LimitOverrides) (*domain.WalletLimitSettings, domain.ErrorCode)
+}
+
+// IHoldService определяет интерфейс для работы с резервами средств.
+type IHoldService interface {
+	// CreateHold резервирует средства отправителя в пользу получателя.
+	// Возвращает созданный резерв и код ошибки.
+	CreateHold(ctx context.Context, req domain.HoldRequest) (*domain.Hold, domain.ErrorCode)
+
+	// GetHold возвращает резерв по его ID.
+	// Возвращает указатель на резерв и код ошибки.
+	GetHold(ctx context.Context, id int64) (*domain.Hold, domain.ErrorCode)
+
+	// CaptureHold проводит перевод по резерву на сумму amount (0 - вся сумма резерва).
+	// Возвращает ID созданной транзакции и код ошибки.
+	CaptureHold(ctx context.Context, id int64, amount domain.Money) (*domain.TransferResult, domain.ErrorCode)
+
+	// VoidHold отменяет резерв и освобождает средства.
+	// Возвращает код ошибки.
+	VoidHold(ctx context.Context, id int64) domain.ErrorCode
+}
+
+// IScheduledTransferService определяет интерфейс для работы с отложенными переводами.
+type IScheduledTransferService interface {
+	// ScheduleTransfer сохраняет перевод, который будет выполнен не раньше req.ExecuteAt.
+	// Возвращает созданный отложенный перевод и код ошибки.
+	ScheduleTransfer(ctx context.Context, req domain.ScheduledTransferRequest) (*domain.ScheduledTransfer, domain.ErrorCode)
+
+	// GetScheduledTransfers возвращает отложенные переводы в состоянии status (пусто - в любом).
+	// Возвращает слайс переводов и код ошибки.
+	GetScheduledTransfers(ctx context.Context, status domain.ScheduledStatus, limit int) ([]domain.ScheduledTransfer, domain.ErrorCode)
+
+	// CancelScheduledTransfer отменяет перевод, который еще не начал выполняться.
+	// Возвращает код ошибки.
+	CancelScheduledTransfer(ctx context.Context, id int64) domain.ErrorCode
+}
+
+// IMandateService определяет интерфейс для работы с поручениями на регулярные переводы.
+type IMandateService interface {
+	// CreateMandate создает поручение и рассчитывает его первый запуск.
+	// Возвращает созданное поручение и код ошибки.
+	CreateMandate(ctx context.Context, req domain.MandateRequest) (*domain.Mandate, domain.ErrorCode)
+
+	// GetMandate возвращает поручение по его ID.
+	// Возвращает указатель на поручение и код ошибки.
+	GetMandate(ctx context.Context, id int64) (*domain.Mandate, domain.ErrorCode)
+
+	// CancelMandate останавливает активное поручение.
+	// Возвращает код ошибки.
+	CancelMandate(ctx context.Context, id int64) domain.ErrorCode
+
+	// GetMandateRuns возвращает историю попыток поручения, новые первыми.
+	// Возвращает слайс попыток и код ошибки.
+	GetMandateRuns(ctx context.Context, id int64, limit int) ([]domain.MandateRun, domain.ErrorCode)
+}
+
+// IReconciliationService определяет интерфейс сверки балансов кошельков с журналом.
+type IReconciliationService interface {
+	// Reconcile сверяет балансы всех кошельков с журналом, с repair вносит расхождения в журнал.
+	// Возвращает отчет о расхождениях и код ошибки.
+	Reconcile(ctx context.Context, repair bool) (*domain.ReconciliationReport, domain.ErrorCode)
+}
+
+// ITreasuryService определяет интерфейс пополнений и выводов средств через кошелек казначейства.
+type ITreasuryService interface {
+	// Deposit зачисляет средства на кошелек с кошелька казначейства его валюты.
+	// Возвращает результат (ID транзакции) и код ошибки.
+	Deposit(ctx context.Context, req domain.FundsRequest) (*domain.TransferResult, domain.ErrorCode)
+
+	// Withdraw списывает средства с кошелька на кошелек казначейства его валюты.
+	// Возвращает результат (ID транзакции) и код ошибки.
+	Withdraw(ctx context.Context, req domain.FundsRequest) (*domain.TransferResult, domain.ErrorCode)
 }
 
+// IAPIKeyService определяет интерфейс управления API-ключами.
+type IAPIKeyService interface {
+	// CreateAPIKey выдает новый ключ с правами scopes.
+	// Возвращает сохраненный ключ, сам ключ (показывается один раз) и код ошибки.
+	CreateAPIKey(ctx context.Context, name string, scopes []string) (*domain.APIKey, string, domain.ErrorCode)
+
+	// ListAPIKeys возвращает все ключи, включая отозванные.
+	// Возвращает слайс ключей и код ошибки.
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, domain.ErrorCode)
+
+	// RevokeAPIKey отзывает ключ.
+	// Возвращает код ошибки.
+	RevokeAPIKey(ctx context.Context, id int64) domain.ErrorCode
+}
+
+// IHealthService определяет интерфейс проверок готовности сервиса.
+type IHealthService interface {
+	// Readiness выполняет проверки готовности.
+	// Возвращает результат каждой проверки с ее длительностью.
+	Readiness(ctx context.Context) domain.Readiness
+}
+
+// Заголовки, связанные с идемпотентностью запросов
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
 // Handler - HTTP обработчик для API.
-// Содержит зависимости на сервисы транзакций и кошельков, а также логгер.
+// Содержит зависимости на сервисы транзакций, кошельков, резервов, отложенных и регулярных переводов, сверки,
+// казначейства, API-ключей, проверок готовности, а также логгер.
 type Handler struct {
-	transactionService ITransactionService
-	walletService      IWalletService
-	log                logger.Logger
+	transactionService    ITransactionService
+	walletService         IWalletService
+	holdService           IHoldService
+	scheduledService      IScheduledTransferService
+	mandateService        IMandateService
+	reconciliationService IReconciliationService
+	treasuryService       ITreasuryService
+	apiKeyService         IAPIKeyService
+	healthService         IHealthService
+	log                   logger.Logger
 }
 
 // NewHandler создает новый экземпляр HTTP обработчика.
-// Принимает сервисы транзакций и кошельков, а также логгер.
-// Возвращает указатель на Handler.
-func NewHandler(ts ITransactionService, ws IWalletService, l logger.Logger) *Handler {
+// Принимает сервисы транзакций, кошельков, резервов, отложенных и регулярных переводов, сверки, казначейства,
+// API-ключей, проверок готовности, а также логгер. Возвращает указатель на Handler.
+func NewHandler(ts ITransactionService, ws IWalletService, hs IHoldService, ss IScheduledTransferService, ms IMandateService, rs IReconciliationService, trs ITreasuryService, ks IAPIKeyService, hcs IHealthService, l logger.Logger) *Handler {
 	return &Handler{
-		transactionService: ts,
-		walletService:      ws,
-		log:                l,
+		transactionService:    ts,
+		walletService:         ws,
+		holdService:           hs,
+		scheduledService:      ss,
+		mandateService:        ms,
+		reconciliationService: rs,
+		treasuryService:       trs,
+		apiKeyService:         ks,
+		healthService:         hcs,
+		log:                   l,
 	}
 }
 
@@ -140,6 +313,117 @@ func (h *Handler) handleServiceError(ctx context.Context, w http.ResponseWriter,
 	case domain.CodeInvalidTransaction:
 		h.log.Warn(ctx, operation+": invalid transaction")
 		h.writeError(ctx, w, http.StatusBadRequest, code, "Invalid transaction")
+	case domain.CodeInvalidAmount:
+		h.log.Warn(ctx, operation+": invalid amount")
+		h.writeError(ctx, w, http.StatusBadRequest, code, "Amount has more fractional digits than the currency allows")
+	case domain.CodeAmountOverflow:
+		h.log.Warn(ctx, operation+": amount overflow")
+		h.writeError(ctx, w, http.StatusBadRequest, code, "Amount is out of range")
+	case domain.CodeTransactionConflict:
+		h.log.Warn(ctx, operation+": transaction conflict")
+		h.writeError(ctx, w, http.StatusConflict, code, "Concurrent update, please retry")
+	case domain.CodeIdempotencyMismatch:
+		h.log.Warn(ctx, operation+": idempotency key reused")
+		h.writeError(ctx, w, http.StatusUnprocessableEntity, code, "Idempotency key was already used with a different request")
+	case domain.CodeAlreadyReversed:
+		h.log.Warn(ctx, operation+": transaction already reversed")
+		h.writeError(ctx, w, http.StatusConflict, code, "Transaction already reversed")
+	case domain.CodeWalletFrozen:
+		h.log.Warn(ctx, operation+": wallet is frozen")
+		h.writeError(ctx, w, http.StatusUnprocessableEntity, code, "Wallet is frozen")
+	case domain.CodeWalletClosed:
+		h.log.Warn(ctx, operation+": wallet is closed")
+		h.writeError(ctx, w, http.StatusUnprocessableEntity, code, "Wallet is closed")
+	case domain.CodeInvalidWalletStatus:
+		h.log.Warn(ctx, operation+": wallet status transition not allowed")
+		h.writeError(ctx, w, http.StatusConflict, code, "Wallet status transition not allowed")
+	case domain.CodeWalletNotEmpty:
+		h.log.Warn(ctx, operation+": wallet balance is not zero")
+		h.writeError(ctx, w, http.StatusConflict, code, "Wallet balance must be zero to close it")
+	case domain.CodeWalletHasHolds:
+		h.log.Warn(ctx, operation+": wallet has active holds")
+		h.writeError(ctx, w, http.StatusConflict, code, "Capture or void active holds before closing the wallet")
+	case domain.CodeWalletHasHistory:
+		h.log.Warn(ctx, operation+": wallet has history")
+		h.writeError(ctx, w, http.StatusConflict, code, "Wallet has transaction history, close it instead")
+	case domain.CodeLimitExceeded:
+		h.log.Warn(ctx, operation+": wallet limit exceeded")
+		h.writeError(ctx, w, http.StatusUnprocessableEntity, code, "Wallet limit exceeded")
+	case domain.CodeOverdraftInUse:
+		h.log.Warn(ctx, operation+": overdraft limit is below credit used")
+		h.writeError(ctx, w, http.StatusConflict, code, "Overdraft limit is below credit used")
+	case domain.CodeNoTreasury:
+		h.log.Warn(ctx, operation+": treasury wallet is not configured")
+		h.writeError(ctx, w, http.StatusUnprocessableEntity, code, "Treasury wallet is not configured for the wallet currency")
+	case domain.CodeTreasuryWallet:
+		h.log.Warn(ctx, operation+": treasury wallet not allowed")
+		h.writeError(ctx, w, http.StatusUnprocessableEntity, code, "Treasury wallet funds move only through deposits and withdrawals")
+	case domain.CodeDuplicateReference:
+		h.log.Warn(ctx, operation+": external reference already used")
+		h.writeError(ctx, w, http.StatusConflict, code, "External reference already used by sender")
+	case domain.CodeForbidden:
+		h.log.Warn(ctx, operation+": forbidden")
+		h.writeError(ctx, w, http.StatusForbidden, code, "Operation is not allowed")
+	case domain.CodeBatchRejected:
+		h.log.Warn(ctx, operation+": batch rejected")
+		h.writeError(ctx, w, http.StatusUnprocessableEntity, code, "Batch rejected")
+	case domain.CodeUnsupportedCurrency:
+		h.log.Warn(ctx, operation+": unsupported currency")
+		h.writeError(ctx, w, http.StatusBadRequest, code, "Unsupported currency")
+	case domain.CodeCurrencyMismatch:
+		h.log.Warn(ctx, operation+": currency mismatch")
+		h.writeError(ctx, w, http.StatusUnprocessableEntity, code, "Wallets have different currencies")
+	case domain.CodeRateUnavailable:
+		h.log.Warn(ctx, operation+": exchange rate unavailable")
+		h.writeError(ctx, w, http.StatusUnprocessableEntity, code, "Exchange rate is not available for this currency pair")
+	case domain.CodeHoldNotFound:
+		h.log.Warn(ctx, operation+": hold not found")
+		h.writeError(ctx, w, http.StatusNotFound, code, "Hold not found")
+	case domain.CodeHoldNotActive:
+		h.log.Warn(ctx, operation+": hold not active")
+		h.writeError(ctx, w, http.StatusConflict, code, "Hold is already captured, voided or expired")
+	case domain.CodeCaptureExceedsHold:
+		h.log.Warn(ctx, operation+": capture exceeds hold")
+		h.writeError(ctx, w, http.StatusUnprocessableEntity, code, "Capture amount exceeds the held amount")
+	case domain.CodeInvalidHoldTTL:
+		h.log.Warn(ctx, operation+": invalid hold ttl")
+		h.writeError(ctx, w, http.StatusBadRequest, code, "Hold TTL exceeds the allowed maximum")
+	case domain.CodeScheduledNotFound:
+		h.log.Warn(ctx, operation+": scheduled transfer not found")
+		h.writeError(ctx, w, http.StatusNotFound, code, "Scheduled transfer not found")
+	case domain.CodeScheduledNotPending:
+		h.log.Warn(ctx, operation+": scheduled transfer not pending")
+		h.writeError(ctx, w, http.StatusConflict, code, "Scheduled transfer is already running, executed or canceled")
+	case domain.CodeInvalidExecuteAt:
+		h.log.Warn(ctx, operation+": invalid execute_at")
+		h.writeError(ctx, w, http.StatusBadRequest, code, "execute_at must be in the future")
+	case domain.CodeMandateNotFound:
+		h.log.Warn(ctx, operation+": mandate not found")
+		h.writeError(ctx, w, http.StatusNotFound, code, "Mandate not found")
+	case domain.CodeMandateNotActive:
+		h.log.Warn(ctx, operation+": mandate not active")
+		h.writeError(ctx, w, http.StatusConflict, code, "Mandate is already completed or canceled")
+	case domain.CodeInvalidRecurrence:
+		h.log.Warn(ctx, operation+": invalid recurrence")
+		h.writeError(ctx, w, http.StatusBadRequest, code, "Invalid recurrence or no runs before end date")
+	case domain.CodeUnauthorized:
+		h.log.Warn(ctx, operation+": unauthorized")
+		h.writeError(ctx, w, http.StatusUnauthorized, code, "API key required")
+	case domain.CodeAPIKeyNotFound:
+		h.log.Warn(ctx, operation+": api key not found")
+		h.writeError(ctx, w, http.StatusNotFound, code, "API key not found")
+	case domain.CodeInvalidScope:
+		h.log.Warn(ctx, operation+": invalid scope")
+		h.writeError(ctx, w, http.StatusBadRequest, code, "Scopes must be a non-empty list of read, transfer, admin")
+	case domain.CodeInvalidFilter:
+		h.log.Warn(ctx, operation+": invalid filter")
+		h.writeError(ctx, w, http.StatusBadRequest, code, "Invalid transaction filter")
+	case domain.CodeInvalidCursor:
+		h.log.Warn(ctx, operation+": invalid cursor")
+		h.writeError(ctx, w, http.StatusBadRequest, code, "Invalid cursor or cursor does not match the sort order")
+	case domain.CodeInvalidPeriod:
+		h.log.Warn(ctx, operation+": invalid period")
+		h.writeError(ctx, w, http.StatusBadRequest, code, "Period start must be before its end")
 	case domain.CodeInvalidLimit:
 		h.log.Warn(ctx, operation+": invalid limit")
 		h.writeError(ctx, w, http.StatusBadRequest, code, "Invalid limit parameter")
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"TransactionTest/internal/delivery/dto"
+	"TransactionTest/internal/domain"
+)
+
+// Значения поля status в ответах проверок
+const (
+	healthStatusOK       = "ok"
+	healthStatusFail     = "fail"
+	healthStatusReady    = "ready"
+	healthStatusNotReady = "not_ready"
+)
+
+// Healthz обрабатывает HTTP GET запрос проверки живости: процесс запущен и отвечает на запросы.
+// Не обращается к БД, поэтому недоступная база не приводит к перезапуску процесса.
+// API-ключ не требуется.
+//
+// URL: GET /healthz
+//
+// Возможные коды ответа:
+//   - 200 OK: процесс жив
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "status": "ok"
+//	}
+func (h *Handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(r.Context(), w, http.StatusOK, dto.HealthResponse{Status: healthStatusOK})
+}
+
+// Readyz обрабатывает HTTP GET запрос проверки готовности обслуживать запросы.
+// Проверяет доступность БД через пул соединений, совпадение версии схемы с самой новой миграцией
+// и отсутствие dirty-состояния, а также завершенность создания стартовых кошельков.
+// С начала остановки сервера возвращает 503 с единственной проверкой shutdown.
+// API-ключ не требуется.
+//
+// URL: GET /readyz
+//
+// Возможные коды ответа:
+//   - 200 OK: все проверки пройдены
+//   - 503 Service Unavailable: хотя бы одна проверка не пройдена или сервер останавливается
+//
+// Пример ответа:
+//
+//	{
+//	  "status": "not_ready",
+//	  "checks": [
+//	    {"name": "database", "status": "ok", "latency_ms": 0.84, "detail": "ok"},
+//	    {"name": "migrations", "status": "fail", "latency_ms": 0.61, "detail": "database version 20, expected 21"},
+//	    {"name": "seeding", "status": "ok", "latency_ms": 0, "detail": "already_seeded"}
+//	  ]
+//	}
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	readiness := h.healthService.Readiness(r.Context())
+
+	statusCode := http.StatusOK
+	if !readiness.Ready {
+		statusCode = http.StatusServiceUnavailable
+	}
+	h.writeJSON(r.Context(), w, statusCode, readinessResponse(readiness))
+}
+
+// readinessResponse переводит результат проверок готовности в JSON-ответ
+func readinessResponse(readiness domain.Readiness) dto.HealthResponse {
+	response := dto.HealthResponse{
+		Status: healthStatusReady,
+		Checks: make([]dto.HealthCheckResponse, len(readiness.Checks)),
+	}
+	if !readiness.Ready {
+		response.Status = healthStatusNotReady
+	}
+	for i, c := range readiness.Checks {
+		status := healthStatusOK
+		if !c.Healthy {
+			status = healthStatusFail
+		}
+		response.Checks[i] = dto.HealthCheckResponse{
+			Name:      c.Name,
+			Status:    status,
+			LatencyMs: float64(c.Latency) / float64(time.Millisecond),
+			Detail:    c.Detail,
+		}
+	}
+	return response
+}
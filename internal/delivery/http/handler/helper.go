@@ -2,11 +2,14 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"TransactionTest/internal/delivery/dto"
 	"TransactionTest/internal/delivery/validator"
+	"TransactionTest/internal/domain"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
@@ -83,3 +86,139 @@ func (h *Handler) parseAndValidateCount(
 	}
 	return count, 0, ""
 }
+
+// parseAndValidateIdempotencyKey извлекает необязательный заголовок Idempotency-Key и валидирует его.
+// Ключи с префиксами внутренних переводов (scheduled:, mandate:) клиенту недоступны.
+// При ошибке возвращает HTTP‑код и сообщение, чтобы handler мог сразу ответить.
+func (h *Handler) parseAndValidateIdempotencyKey(
+	ctx context.Context,
+	r *http.Request,
+	operation string,
+) (string, int, string) {
+	p := dto.IdempotencyKeyHeader{Key: r.Header.Get(IdempotencyKeyHeader)}
+	if err := validator.ValidateStruct(p); err != nil {
+		h.log.Warn(ctx, operation+"idempotency key validation failed", zap.Error(err))
+		return "", http.StatusBadRequest, err.Error()
+	}
+	if domain.ReservedIdempotencyKey(p.Key) {
+		h.log.Warn(ctx, operation+"reserved idempotency key prefix", zap.String("key", p.Key))
+		return "", http.StatusBadRequest, "idempotency key prefixes scheduled: and mandate: are reserved"
+	}
+	return p.Key, 0, ""
+}
+
+// parseListTransactionsQuery разбирает и валидирует параметры ListTransactions.
+// Согласованность фильтра (роль без кошелька, пустые диапазоны) проверяет сервис.
+func (h *Handler) parseListTransactionsQuery(
+	ctx context.Context,
+	r *http.Request,
+	operation string,
+) (domain.TransactionFilter, domain.ErrorCode, string) {
+	values := r.URL.Query()
+	query := dto.ListTransactionsQuery{
+		Wallet:      values.Get("wallet"),
+		Role:        values.Get("role"),
+		CreatedFrom: values.Get("created_from"),
+		CreatedTo:   values.Get("created_to"),
+		MinAmount:   values.Get("min_amount"),
+		MaxAmount:   values.Get("max_amount"),
+		Order:       values.Get("order"),
+		Count:       values.Get("count"),
+		Cursor:      values.Get("cursor"),
+	}
+	if err := validator.ValidateStruct(query); err != nil {
+		h.log.Warn(ctx, operation+"query validation failed", zap.Error(err))
+		return domain.TransactionFilter{}, domain.CodeInvalidRequestBody, err.Error()
+	}
+
+	filter := domain.TransactionFilter{
+		Wallet: query.Wallet,
+		Role:   domain.WalletRole(query.Role),
+		Order:  domain.SortOrder(query.Order),
+	}
+
+	// created_at хранится без часового пояса в UTC, поэтому границы тоже приводятся к UTC
+	if query.CreatedFrom != "" {
+		t, _ := time.Parse(time.RFC3339, query.CreatedFrom)
+		filter.CreatedFrom = t.UTC()
+	}
+	if query.CreatedTo != "" {
+		t, _ := time.Parse(time.RFC3339, query.CreatedTo)
+		filter.CreatedTo = t.UTC()
+	}
+
+	for _, bound := range []struct {
+		name string
+		raw  string
+		dest *domain.Money
+	}{
+		{"min_amount", query.MinAmount, &filter.MinAmount},
+		{"max_amount", query.MaxAmount, &filter.MaxAmount},
+	} {
+		if bound.raw == "" {
+			continue
+		}
+		amount, err := domain.ParseMoney(bound.raw)
+		if err != nil {
+			h.log.Warn(ctx, operation+"invalid "+bound.name, zap.String(bound.name, bound.raw), zap.Error(err))
+			errCode, _ := decodeErrorCode(err)
+			return domain.TransactionFilter{}, errCode, "Invalid " + bound.name
+		}
+		*bound.dest = amount
+	}
+
+	if query.Count != "" {
+		count, err := strconv.Atoi(query.Count)
+		if err != nil || count <= 0 {
+			h.log.Warn(ctx, operation+"invalid count", zap.String("count", query.Count))
+			return domain.TransactionFilter{}, domain.CodeInvalidRequestBody, "invalid count"
+		}
+		filter.Limit = count
+	}
+
+	if query.Cursor != "" {
+		cursor, err := domain.ParseTransactionCursor(query.Cursor)
+		if err != nil {
+			h.log.Warn(ctx, operation+"invalid cursor", zap.Error(err))
+			return domain.TransactionFilter{}, domain.CodeInvalidCursor, "Invalid cursor"
+		}
+		filter.After = &cursor
+	}
+
+	return filter, domain.CodeOK, ""
+}
+
+// parseStatementPeriod извлекает обязательные границы периода выписки from и to в формате RFC3339.
+// Границы приводятся к UTC, в котором хранятся created_at проводок.
+// При ошибке возвращает HTTP‑код и сообщение, чтобы handler мог сразу ответить.
+func (h *Handler) parseStatementPeriod(
+	ctx context.Context,
+	r *http.Request,
+	operation string,
+) (time.Time, time.Time, int, string) {
+	query := dto.StatementQuery{
+		From: r.URL.Query().Get("from"),
+		To:   r.URL.Query().Get("to"),
+	}
+	if err := validator.ValidateStruct(query); err != nil {
+		h.log.Warn(ctx, operation+"period validation failed", zap.Error(err))
+		return time.Time{}, time.Time{}, http.StatusBadRequest, err.Error()
+	}
+
+	from, _ := time.Parse(time.RFC3339, query.From)
+	to, _ := time.Parse(time.RFC3339, query.To)
+	return from.UTC(), to.UTC(), 0, ""
+}
+
+// decodeErrorCode подбирает код ошибки для неудачного разбора JSON тела запроса.
+// Некорректные денежные суммы отличаем от прочих ошибок, чтобы клиент понимал, что именно не так.
+func decodeErrorCode(err error) (domain.ErrorCode, string) {
+	switch {
+	case errors.Is(err, domain.ErrAmountOverflow):
+		return domain.CodeAmountOverflow, "Amount is out of range"
+	case errors.Is(err, domain.ErrInvalidAmount):
+		return domain.CodeInvalidAmount, err.Error()
+	default:
+		return domain.CodeInvalidRequestBody, "Invalid JSON"
+	}
+}
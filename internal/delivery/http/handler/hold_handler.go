@@ -0,0 +1,324 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"TransactionTest/internal/delivery/dto"
+	"TransactionTest/internal/delivery/validator"
+	"TransactionTest/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// CreateHold обрабатывает HTTP POST запрос для резервирования средств.
+// Резерв уменьшает доступный баланс отправителя, но не баланс по журналу,
+// и автоматически истекает через ttl_seconds (по умолчанию transfers.HoldDefaultTTL).
+//
+// Принимает JSON в теле запроса:
+//
+//	{
+//	  "from": "550e8400-e29b-41d4-a716-446655440000",
+//	  "to": "550e8400-e29b-41d4-a716-446655440001",
+//	  "amount": "25.00",
+//	  "ttl_seconds": 900
+//	}
+//
+// Возможные коды ответа:
+//   - 201 Created: средства зарезервированы
+//   - 400 Bad Request: ошибка валидации, недостаточно доступных средств или слишком большой срок
+//   - 404 Not Found: кошелек не найден
+//   - 409 Conflict: резерв не удалось создать из-за конкурентных изменений
+//   - 422 Unprocessable Entity: кошельки в разных валютах
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "id": 7,
+//	  "from": "550e8400-e29b-41d4-a716-446655440000",
+//	  "to": "550e8400-e29b-41d4-a716-446655440001",
+//	  "amount": "25.00",
+//	  "currency": "USD",
+//	  "status": "active",
+//	  "captured_amount": "0.00",
+//	  "created_at": "2026-10-16T10:00:00Z",
+//	  "expires_at": "2026-10-16T10:15:00Z"
+//	}
+func (h *Handler) CreateHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "CreateHold: "
+
+	var req dto.CreateHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(
+			ctx,
+			op+"failed to decode JSON",
+			zap.Error(err),
+		)
+		errCode, errMsg := decodeErrorCode(err)
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, errMsg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Any("payload", req),
+	)
+
+	if err := validator.ValidateStruct(req); err != nil {
+		h.log.Warn(
+			ctx,
+			op+"validation failed",
+			zap.Any("errors", err),
+		)
+		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, err.Error())
+		return
+	}
+
+	hold, svcCode := h.holdService.CreateHold(ctx, domain.HoldRequest{
+		From:   req.From,
+		To:     req.To,
+		Amount: req.Amount,
+		TTL:    time.Duration(req.TTLSeconds) * time.Second,
+	})
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "CreateHold")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"hold created successfully",
+		zap.Int64("hold_id", hold.Id),
+	)
+	h.writeJSON(ctx, w, http.StatusCreated, holdResponse(hold))
+}
+
+// GetHold обрабатывает HTTP GET запрос для получения резерва.
+//
+// Path параметры:
+//   - id: ID резерва (обязательный, положительное число)
+//
+// URL: GET /api/holds/7
+//
+// Возможные коды ответа:
+//   - 200 OK: резерв найден (формат ответа как у CreateHold)
+//   - 400 Bad Request: неверный ID
+//   - 404 Not Found: резерв не найден
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+func (h *Handler) GetHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "GetHold: "
+
+	id, code, msg := h.parseAndValidateID(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Int64("id", id),
+	)
+
+	hold, svcCode := h.holdService.GetHold(ctx, id)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "GetHold")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"hold retrieved successfully",
+		zap.Int64("id", id),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, holdResponse(hold))
+}
+
+// CaptureHold обрабатывает HTTP POST запрос для захвата резерва.
+// Захват проводит перевод получателю резерва; при частичном захвате остаток освобождается.
+//
+// Path параметры:
+//   - id: ID резерва (обязательный, положительное число)
+//
+// URL: POST /api/holds/7/capture
+//
+// Тело запроса необязательное, без него захватывается вся сумма резерва:
+//
+//	{
+//	  "amount": "10.00"
+//	}
+//
+// Возможные коды ответа:
+//   - 200 OK: резерв захвачен
+//   - 400 Bad Request: ошибка валидации или недостаточно средств
+//   - 404 Not Found: резерв или кошелек не найден
+//   - 409 Conflict: резерв уже захвачен, отменен или истек
+//   - 422 Unprocessable Entity: сумма больше зарезервированной или превышен лимит кошелька (поле limit)
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "message": "Hold captured successfully",
+//	  "transaction_id": 42
+//	}
+func (h *Handler) CaptureHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "CaptureHold: "
+
+	id, code, msg := h.parseAndValidateID(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	var req dto.CaptureHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.log.Error(
+			ctx,
+			op+"failed to decode JSON",
+			zap.Error(err),
+		)
+		errCode, errMsg := decodeErrorCode(err)
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, errMsg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Int64("id", id),
+		zap.Any("payload", req),
+	)
+
+	if err := validator.ValidateStruct(req); err != nil {
+		h.log.Warn(
+			ctx,
+			op+"validation failed",
+			zap.Any("errors", err),
+		)
+		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, err.Error())
+		return
+	}
+
+	result, svcCode := h.holdService.CaptureHold(ctx, id, req.Amount)
+	if svcCode == domain.CodeLimitExceeded && result != nil {
+		h.log.Warn(
+			ctx,
+			op+"wallet limit exceeded",
+			zap.String("limit", string(result.ExceededLimit)),
+		)
+		h.writeJSON(ctx, w, http.StatusUnprocessableEntity, dto.LimitErrorResponse{
+			Error:   http.StatusText(http.StatusUnprocessableEntity),
+			Code:    string(svcCode),
+			Message: "Wallet limit exceeded",
+			Limit:   string(result.ExceededLimit),
+		})
+		return
+	}
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "CaptureHold")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"hold captured successfully",
+		zap.Int64("id", id),
+		zap.Int64("transaction_id", result.TransactionId),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, dto.CaptureHoldResponse{
+		Message:       "Hold captured successfully",
+		TransactionId: result.TransactionId,
+	})
+}
+
+// VoidHold обрабатывает HTTP POST запрос для отмены резерва.
+//
+// Path параметры:
+//   - id: ID резерва (обязательный, положительное число)
+//
+// URL: POST /api/holds/7/void
+//
+// Возможные коды ответа:
+//   - 200 OK: резерв отменен, средства освобождены
+//   - 400 Bad Request: неверный ID
+//   - 404 Not Found: резерв не найден
+//   - 409 Conflict: резерв уже захвачен, отменен или истек
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "message": "Hold voided successfully"
+//	}
+func (h *Handler) VoidHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "VoidHold: "
+
+	id, code, msg := h.parseAndValidateID(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Int64("id", id),
+	)
+
+	if svcCode := h.holdService.VoidHold(ctx, id); svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "VoidHold")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"hold voided successfully",
+		zap.Int64("id", id),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, map[string]string{"message": "Hold voided successfully"})
+}
+
+func holdResponse(hold *domain.Hold) dto.HoldResponse {
+	return dto.HoldResponse{
+		Id:             hold.Id,
+		From:           hold.From,
+		To:             hold.To,
+		Amount:         hold.Amount,
+		Currency:       hold.Currency,
+		Status:         string(hold.Status),
+		CapturedAmount: hold.CapturedAmount,
+		TransactionId:  hold.TransactionId,
+		CreatedAt:      hold.CreatedAt.Format(time.RFC3339),
+		ExpiresAt:      hold.ExpiresAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,364 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"TransactionTest/internal/delivery/dto"
+	"TransactionTest/internal/delivery/validator"
+	"TransactionTest/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// CreateMandate обрабатывает HTTP POST запрос для создания поручения на регулярный перевод.
+// Каждый запуск выполняется фоновой задачей так же, как POST /api/send (с проверкой баланса и комиссией).
+// Неудачный запуск повторяется по политике transfers.MandateMaxAttempts / transfers.MandateRetryDelay.
+//
+// Принимает JSON в теле запроса (start_at по умолчанию - текущий момент, end_at и max_runs необязательные):
+//
+//	{
+//	  "from": "550e8400-e29b-41d4-a716-446655440000",
+//	  "to": "550e8400-e29b-41d4-a716-446655440001",
+//	  "amount": "50.00",
+//	  "recurrence": {"frequency": "weekly", "interval": 1},
+//	  "start_at": "2026-10-19T09:00:00Z",
+//	  "end_at": "2027-01-01T00:00:00Z",
+//	  "max_runs": 10
+//	}
+//
+// Вместо frequency daily/weekly/monthly можно задать cron-выражение в UTC:
+// "recurrence": {"frequency": "cron", "cron": "0 9 * * 1"}.
+//
+// Возможные коды ответа:
+//   - 201 Created: поручение создано
+//   - 400 Bad Request: ошибка валидации или неверное расписание (в том числе без запусков до end_at)
+//   - 404 Not Found: кошелек не найден
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "id": 5,
+//	  "from": "550e8400-e29b-41d4-a716-446655440000",
+//	  "to": "550e8400-e29b-41d4-a716-446655440001",
+//	  "amount": "50.00",
+//	  "convert": false,
+//	  "recurrence": {"frequency": "weekly", "interval": 1},
+//	  "start_at": "2026-10-19T09:00:00Z",
+//	  "end_at": "2027-01-01T00:00:00Z",
+//	  "max_runs": 10,
+//	  "status": "active",
+//	  "runs_count": 0,
+//	  "next_run_at": "2026-10-19T09:00:00Z",
+//	  "created_at": "2026-10-16T10:00:00Z"
+//	}
+func (h *Handler) CreateMandate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "CreateMandate: "
+
+	var req dto.CreateMandateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(
+			ctx,
+			op+"failed to decode JSON",
+			zap.Error(err),
+		)
+		errCode, errMsg := decodeErrorCode(err)
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, errMsg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Any("payload", req),
+	)
+
+	if err := validator.ValidateStruct(req); err != nil {
+		h.log.Warn(
+			ctx,
+			op+"validation failed",
+			zap.Any("errors", err),
+		)
+		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, err.Error())
+		return
+	}
+
+	mandateReq := domain.MandateRequest{
+		From:    req.From,
+		To:      req.To,
+		Amount:  req.Amount,
+		Convert: req.Convert,
+		Recurrence: domain.Recurrence{
+			Frequency: domain.Frequency(req.Recurrence.Frequency),
+			Interval:  req.Recurrence.Interval,
+			Cron:      req.Recurrence.Cron,
+		},
+		MaxRuns: req.MaxRuns,
+	}
+	if mandateReq.Recurrence.Frequency != domain.FrequencyCron && mandateReq.Recurrence.Interval == 0 {
+		mandateReq.Recurrence.Interval = 1
+	}
+	if req.StartAt != "" {
+		startAt, err := time.Parse(time.RFC3339, req.StartAt)
+		if err != nil {
+			h.log.Warn(ctx, op+"invalid start_at format", zap.String("startAt", req.StartAt), zap.Error(err))
+			h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, "Invalid start_at format")
+			return
+		}
+		mandateReq.StartAt = startAt
+	}
+	if req.EndAt != "" {
+		endAt, err := time.Parse(time.RFC3339, req.EndAt)
+		if err != nil {
+			h.log.Warn(ctx, op+"invalid end_at format", zap.String("endAt", req.EndAt), zap.Error(err))
+			h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, "Invalid end_at format")
+			return
+		}
+		mandateReq.EndAt = &endAt
+	}
+
+	mandate, svcCode := h.mandateService.CreateMandate(ctx, mandateReq)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "CreateMandate")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"mandate created successfully",
+		zap.Int64("mandate_id", mandate.Id),
+	)
+	h.writeJSON(ctx, w, http.StatusCreated, mandateResponse(mandate))
+}
+
+// GetMandate обрабатывает HTTP GET запрос для получения поручения.
+//
+// Path параметры:
+//   - id: ID поручения (обязательный, положительное число)
+//
+// URL: GET /api/mandates/5
+//
+// Возможные коды ответа:
+//   - 200 OK: поручение найдено (формат ответа как у CreateMandate)
+//   - 400 Bad Request: неверный ID
+//   - 404 Not Found: поручение не найдено
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+func (h *Handler) GetMandate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "GetMandate: "
+
+	id, code, msg := h.parseAndValidateID(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Int64("id", id),
+	)
+
+	mandate, svcCode := h.mandateService.GetMandate(ctx, id)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "GetMandate")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"mandate retrieved successfully",
+		zap.Int64("id", id),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, mandateResponse(mandate))
+}
+
+// CancelMandate обрабатывает HTTP POST запрос для отмены поручения.
+// Уже начатая попытка доводится до конца, новых запусков не будет.
+//
+// Path параметры:
+//   - id: ID поручения (обязательный, положительное число)
+//
+// URL: POST /api/mandates/5/cancel
+//
+// Возможные коды ответа:
+//   - 200 OK: поручение отменено
+//   - 400 Bad Request: неверный ID
+//   - 404 Not Found: поручение не найдено
+//   - 409 Conflict: поручение уже завершено или отменено
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "message": "Mandate canceled successfully"
+//	}
+func (h *Handler) CancelMandate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "CancelMandate: "
+
+	id, code, msg := h.parseAndValidateID(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Int64("id", id),
+	)
+
+	if svcCode := h.mandateService.CancelMandate(ctx, id); svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "CancelMandate")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"mandate canceled successfully",
+		zap.Int64("id", id),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, map[string]string{"message": "Mandate canceled successfully"})
+}
+
+// GetMandateRuns обрабатывает HTTP GET запрос для получения истории попыток поручения, новые первыми.
+// Неудачная попытка, после которой будет повтор, имеет статус retrying; последняя неудачная - failed.
+//
+// Path параметры:
+//   - id: ID поручения (обязательный, положительное число)
+//
+// Query параметры:
+//   - count: количество попыток (обязательный, положительное число)
+//
+// URL: GET /api/mandates/5/runs?count=10
+//
+// Возможные коды ответа:
+//   - 200 OK: история попыток
+//   - 400 Bad Request: неверный ID или count
+//   - 404 Not Found: поручение не найдено
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	[
+//	  {
+//	    "id": 12,
+//	    "occurrence": 2,
+//	    "attempt": 2,
+//	    "scheduled_at": "2026-10-26T09:00:00Z",
+//	    "status": "succeeded",
+//	    "transaction_id": 57,
+//	    "executed_at": "2026-10-26T10:00:03Z"
+//	  },
+//	  {
+//	    "id": 11,
+//	    "occurrence": 2,
+//	    "attempt": 1,
+//	    "scheduled_at": "2026-10-26T09:00:00Z",
+//	    "status": "retrying",
+//	    "failure_code": "INSUFFICIENT_FUNDS",
+//	    "executed_at": "2026-10-26T09:00:02Z"
+//	  }
+//	]
+func (h *Handler) GetMandateRuns(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "GetMandateRuns: "
+
+	id, code, msg := h.parseAndValidateID(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	count, code, msg := h.parseAndValidateCount(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Int64("id", id),
+		zap.Int("count", count),
+	)
+
+	runs, svcCode := h.mandateService.GetMandateRuns(ctx, id, count)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "GetMandateRuns")
+		return
+	}
+
+	response := make([]dto.MandateRunResponse, len(runs))
+	for i, run := range runs {
+		response[i] = dto.MandateRunResponse{
+			Id:            run.Id,
+			Occurrence:    run.Occurrence,
+			Attempt:       run.Attempt,
+			ScheduledAt:   run.ScheduledAt.Format(time.RFC3339),
+			Status:        string(run.Status),
+			TransactionId: run.TransactionId,
+			FailureCode:   string(run.FailureCode),
+			ExecutedAt:    run.ExecutedAt.Format(time.RFC3339),
+		}
+	}
+
+	h.log.Info(
+		ctx,
+		op+"mandate runs retrieved successfully",
+		zap.Int64("id", id),
+		zap.Int("count", len(runs)),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, response)
+}
+
+func mandateResponse(m *domain.Mandate) dto.MandateResponse {
+	response := dto.MandateResponse{
+		Id:      m.Id,
+		From:    m.From,
+		To:      m.To,
+		Amount:  m.Amount,
+		Convert: m.Convert,
+		Recurrence: dto.RecurrenceResponse{
+			Frequency: string(m.Recurrence.Frequency),
+			Interval:  m.Recurrence.Interval,
+			Cron:      m.Recurrence.Cron,
+		},
+		StartAt:   m.StartAt.Format(time.RFC3339),
+		MaxRuns:   m.MaxRuns,
+		Status:    string(m.Status),
+		RunsCount: m.RunsCount,
+		CreatedAt: m.CreatedAt.Format(time.RFC3339),
+	}
+	if m.EndAt != nil {
+		response.EndAt = m.EndAt.Format(time.RFC3339)
+	}
+	if m.NextRunAt != nil {
+		response.NextRunAt = m.NextRunAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"TransactionTest/internal/delivery/dto"
+	"TransactionTest/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// Reconcile обрабатывает HTTP POST запрос для сверки балансов кошельков с журналом проводок.
+// Для каждого кошелька ожидаемый баланс - сумма его проводок (начальный баланс, переводы, корректировки),
+// он сравнивается с wallets.balance. В режиме исправления расхождение вносится в журнал
+// корректирующей записью (kind = correction), сам баланс кошелька не меняется.
+//
+// Query параметры:
+//   - repair: true - записать корректирующие записи (необязательный, по умолчанию false)
+//
+// URL: POST /api/admin/reconcile?repair=true
+//
+// Возможные коды ответа:
+//   - 200 OK: сверка выполнена, расхождения в поле drifts
+//   - 400 Bad Request: неверный repair
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "started_at": "2026-10-16T10:00:00Z",
+//	  "finished_at": "2026-10-16T10:00:01Z",
+//	  "repair": true,
+//	  "checked": 120,
+//	  "repaired": 1,
+//	  "drifts": [
+//	    {
+//	      "address": "550e8400-e29b-41d4-a716-446655440000",
+//	      "currency": "USD",
+//	      "balance": "150.00",
+//	      "expected": "100.00",
+//	      "drift": "50.00",
+//	      "repaired": true
+//	    }
+//	  ]
+//	}
+func (h *Handler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "Reconcile: "
+
+	var repair bool
+	switch r.URL.Query().Get("repair") {
+	case "", "false":
+	case "true":
+		repair = true
+	default:
+		h.log.Warn(ctx, op+"invalid repair", zap.String("repair", r.URL.Query().Get("repair")))
+		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, "repair must be true or false")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Bool("repair", repair),
+	)
+
+	report, svcCode := h.reconciliationService.Reconcile(ctx, repair)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "Reconcile")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"reconciliation finished",
+		zap.Int("checked", report.Checked),
+		zap.Int("drifts", len(report.Drifts)),
+		zap.Int("repaired", report.Repaired()),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, ReconciliationReportResponse(report))
+}
+
+// ReconciliationReportResponse переводит отчет сверки в JSON-ответ.
+// Используется и HTTP обработчиком, и командой reconcile сервера.
+func ReconciliationReportResponse(report *domain.ReconciliationReport) dto.ReconciliationReportResponse {
+	response := dto.ReconciliationReportResponse{
+		StartedAt:  report.StartedAt.Format(time.RFC3339),
+		FinishedAt: report.FinishedAt.Format(time.RFC3339),
+		Repair:     report.Repair,
+		Checked:    report.Checked,
+		Repaired:   report.Repaired(),
+		Drifts:     make([]dto.WalletDriftResponse, 0, len(report.Drifts)),
+	}
+	for _, d := range report.Drifts {
+		response.Drifts = append(response.Drifts, dto.WalletDriftResponse{
+			Address:  d.Address,
+			Currency: d.Currency,
+			Balance:  d.Stored,
+			Expected: d.Expected,
+			Drift:    d.Drift(),
+			Repaired: d.Repaired,
+		})
+	}
+	return response
+}
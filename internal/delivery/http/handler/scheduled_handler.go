@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"TransactionTest/internal/delivery/dto"
+	"TransactionTest/internal/delivery/validator"
+	"TransactionTest/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// ScheduleTransfer обрабатывает HTTP POST запрос для создания отложенного перевода.
+// Перевод выполняется фоновой задачей не раньше execute_at так же, как POST /api/send;
+// баланс и валюты проверяются в момент выполнения, результат виден в списке отложенных переводов.
+//
+// Принимает JSON в теле запроса:
+//
+//	{
+//	  "from": "550e8400-e29b-41d4-a716-446655440000",
+//	  "to": "550e8400-e29b-41d4-a716-446655440001",
+//	  "amount": "1500.00",
+//	  "convert": false,
+//	  "execute_at": "2026-11-01T09:00:00Z"
+//	}
+//
+// Возможные коды ответа:
+//   - 201 Created: перевод запланирован
+//   - 400 Bad Request: ошибка валидации или execute_at не в будущем
+//   - 404 Not Found: кошелек не найден
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "id": 3,
+//	  "from": "550e8400-e29b-41d4-a716-446655440000",
+//	  "to": "550e8400-e29b-41d4-a716-446655440001",
+//	  "amount": "1500.00",
+//	  "convert": false,
+//	  "execute_at": "2026-11-01T09:00:00Z",
+//	  "status": "pending",
+//	  "created_at": "2026-10-16T10:00:00Z"
+//	}
+func (h *Handler) ScheduleTransfer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "ScheduleTransfer: "
+
+	var req dto.CreateScheduledTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(
+			ctx,
+			op+"failed to decode JSON",
+			zap.Error(err),
+		)
+		errCode, errMsg := decodeErrorCode(err)
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, errMsg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Any("payload", req),
+	)
+
+	if err := validator.ValidateStruct(req); err != nil {
+		h.log.Warn(
+			ctx,
+			op+"validation failed",
+			zap.Any("errors", err),
+		)
+		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, err.Error())
+		return
+	}
+
+	executeAt, err := time.Parse(time.RFC3339, req.ExecuteAt)
+	if err != nil {
+		h.log.Warn(
+			ctx,
+			op+"invalid execute_at format",
+			zap.String("executeAt", req.ExecuteAt),
+			zap.Error(err),
+		)
+		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, "Invalid execute_at format")
+		return
+	}
+
+	scheduled, svcCode := h.scheduledService.ScheduleTransfer(ctx, domain.ScheduledTransferRequest{
+		From:      req.From,
+		To:        req.To,
+		Amount:    req.Amount,
+		Convert:   req.Convert,
+		ExecuteAt: executeAt,
+	})
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "ScheduleTransfer")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"transfer scheduled successfully",
+		zap.Int64("scheduled_id", scheduled.Id),
+	)
+	h.writeJSON(ctx, w, http.StatusCreated, scheduledTransferResponse(scheduled))
+}
+
+// GetScheduledTransfers обрабатывает HTTP GET запрос для получения отложенных переводов
+// по возрастанию времени выполнения.
+//
+// Query параметры:
+//   - count: количество переводов (обязательный, положительное число)
+//   - status: фильтр по состоянию (необязательный): pending, running, succeeded, failed, canceled
+//
+// URL: GET /api/transfers/scheduled?count=10&status=failed
+//
+// Возможные коды ответа:
+//   - 200 OK: список переводов (формат элемента как у ScheduleTransfer;
+//     у выполненных есть executed_at и transaction_id или failure_code)
+//   - 400 Bad Request: неверный count или status
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+func (h *Handler) GetScheduledTransfers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "GetScheduledTransfers: "
+
+	count, code, msg := h.parseAndValidateCount(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	query := dto.ScheduledStatusQuery{Status: r.URL.Query().Get("status")}
+	if err := validator.ValidateStruct(query); err != nil {
+		h.log.Warn(
+			ctx,
+			op+"query validation failed",
+			zap.Error(err),
+		)
+		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, err.Error())
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Int("count", count),
+		zap.String("status", query.Status),
+	)
+
+	transfers, svcCode := h.scheduledService.GetScheduledTransfers(ctx, domain.ScheduledStatus(query.Status), count)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "GetScheduledTransfers")
+		return
+	}
+
+	response := make([]dto.ScheduledTransferResponse, len(transfers))
+	for i := range transfers {
+		response[i] = scheduledTransferResponse(&transfers[i])
+	}
+
+	h.log.Info(
+		ctx,
+		op+"scheduled transfers retrieved successfully",
+		zap.Int("count", len(transfers)),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, response)
+}
+
+// CancelScheduledTransfer обрабатывает HTTP POST запрос для отмены отложенного перевода.
+// Отменить можно только перевод, который еще не начал выполняться.
+//
+// Path параметры:
+//   - id: ID отложенного перевода (обязательный, положительное число)
+//
+// URL: POST /api/transfers/scheduled/3/cancel
+//
+// Возможные коды ответа:
+//   - 200 OK: перевод отменен
+//   - 400 Bad Request: неверный ID
+//   - 404 Not Found: перевод не найден
+//   - 409 Conflict: перевод уже выполняется, выполнен или отменен
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "message": "Scheduled transfer canceled successfully"
+//	}
+func (h *Handler) CancelScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "CancelScheduledTransfer: "
+
+	id, code, msg := h.parseAndValidateID(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Int64("id", id),
+	)
+
+	if svcCode := h.scheduledService.CancelScheduledTransfer(ctx, id); svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "CancelScheduledTransfer")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"scheduled transfer canceled successfully",
+		zap.Int64("id", id),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, map[string]string{"message": "Scheduled transfer canceled successfully"})
+}
+
+func scheduledTransferResponse(s *domain.ScheduledTransfer) dto.ScheduledTransferResponse {
+	response := dto.ScheduledTransferResponse{
+		Id:            s.Id,
+		From:          s.From,
+		To:            s.To,
+		Amount:        s.Amount,
+		Convert:       s.Convert,
+		ExecuteAt:     s.ExecuteAt.Format(time.RFC3339),
+		Status:        string(s.Status),
+		TransactionId: s.TransactionId,
+		FailureCode:   string(s.FailureCode),
+		CreatedAt:     s.CreatedAt.Format(time.RFC3339),
+	}
+	if s.ExecutedAt != nil {
+		response.ExecutedAt = s.ExecutedAt.Format(time.RFC3339)
+	}
+	return response
+}
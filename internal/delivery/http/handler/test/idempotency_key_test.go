@@ -0,0 +1,59 @@
+package test
+
+import (
+	"TransactionTest/internal/delivery/http/handler"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// stubTransactionService запоминает ключ идемпотентности переводов, остальные методы в тестах не вызываются
+type stubTransactionService struct {
+	handler.ITransactionService
+	keys []string
+}
+
+func (s *stubTransactionService) SendMoney(ctx context.Context, req domain.TransferRequest) (*domain.TransferResult, domain.ErrorCode) {
+	s.keys = append(s.keys, req.IdempotencyKey)
+	return &domain.TransferResult{TransactionId: 1}, domain.CodeOK
+}
+
+func sendWithKey(ts handler.ITransactionService, key string) *httptest.ResponseRecorder {
+	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(zap.FatalLevel)
+	log, _ := logger.New(&cfg)
+	h := handler.NewHandler(ts, nil, nil, nil, nil, nil, nil, nil, nil, log)
+
+	body := `{"from": "0b7b6c5e-8c7e-4d6a-9a51-3f2a1c9e0d11", "to": "6f1d2c3b-4a5e-4f60-8b7a-9c0d1e2f3a44", "amount": "10"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/send", strings.NewReader(body))
+	req.Header.Set(handler.IdempotencyKeyHeader, key)
+	rec := httptest.NewRecorder()
+	h.SendMoney(rec, req)
+	return rec
+}
+
+func TestHandler_SendMoney_ReservedIdempotencyKey(t *testing.T) {
+	for _, key := range []string{"scheduled:7", "mandate:3:2"} {
+		ts := &stubTransactionService{}
+		rec := sendWithKey(ts, key)
+
+		// Ключи внутренних переводов клиенту недоступны, перевод не выполняется
+		assert.Equal(t, http.StatusBadRequest, rec.Code, key)
+		assert.Contains(t, rec.Body.String(), string(domain.CodeInvalidRequestBody))
+		assert.Empty(t, ts.keys)
+	}
+}
+
+func TestHandler_SendMoney_ClientIdempotencyKey(t *testing.T) {
+	ts := &stubTransactionService{}
+	rec := sendWithKey(ts, "order-42:scheduled")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"order-42:scheduled"}, ts.keys)
+}
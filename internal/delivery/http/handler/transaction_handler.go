@@ -19,19 +19,62 @@ import (
 //	{
 //	  "from": "uuid4-адрес-отправителя",
 //	  "to": "uuid4-адрес-получателя",
-//	  "amount": 100.50
+//	  "amount": "100.50",
+//	  "convert": false,
+//	  "memo": "Оплата заказа 1001",
+//	  "external_reference": "order-1001",
+//	  "metadata": {"order_id": 1001, "channel": "web"}
+//	}
+//
+// memo (до 255 символов), external_reference (до 128 печатных ASCII символов) и metadata (JSON-объект,
+// до 50 ключей) необязательные и возвращаются вместе с транзакцией. external_reference уникален
+// в пределах отправителя: повторный перевод с тем же значением отклоняется с 409 DUPLICATE_EXTERNAL_REFERENCE.
+//
+// Сумма передается строкой (число тоже принимается) и может содержать не более двух знаков после запятой.
+// Если кошельки в разных валютах, перевод проводится только с "convert": true: сумма списывается
+// в валюте отправителя, а получателю зачисляется сконвертированная по текущему курсу (за вычетом спреда).
+//
+// Необязательный заголовок Idempotency-Key (до 255 печатных ASCII символов) защищает от повторного
+// списания при ретраях клиента: повтор с тем же ключом и телом возвращает исходный ответ
+// с заголовком Idempotent-Replayed: true.
+//
+// Комиссия по тарифу валюты отправителя (секция fees конфигурации) списывается сверх amount
+// и возвращается в поле fee; если средств не хватает на сумму вместе с комиссией - INSUFFICIENT_FUNDS.
+//
+// Перевод проверяется лимитами кошельков (свои лимиты кошелька или секция limits конфигурации):
+// у отправителя - сумма перевода, сумма и число исходящих переводов за скользящее окно, у получателя -
+// баланс после зачисления. Нарушенный лимит возвращается в поле limit ответа 422 LIMIT_EXCEEDED:
+//
+//	{
+//	  "error": "Unprocessable Entity",
+//	  "code": "LIMIT_EXCEEDED",
+//	  "message": "Wallet limit exceeded",
+//	  "limit": "max_daily_outflow"
 //	}
 //
 // Возможные коды ответа:
-//   - 200 OK: деньги успешно отправлены
+//   - 200 OK: деньги успешно отправлены (или возвращен результат по ключу идемпотентности)
 //   - 400 Bad Request: ошибка валидации или недостаточно средств
 //   - 404 Not Found: кошелек не найден
+//   - 409 Conflict: перевод не удалось провести из-за конкурентных изменений (повторы исчерпаны)
+//     или external_reference уже использован отправителем
+//   - 422 Unprocessable Entity: ключ идемпотентности уже использован с другим телом запроса,
+//     кошельки в разных валютах без convert, нет курса для пары валют, кошелек заморожен или закрыт
+//     либо превышен лимит кошелька
 //   - 500 Internal Server Error: внутренняя ошибка сервера
 //
-// Пример успешного ответа:
+// Пример успешного ответа (fee только при ненулевой комиссии, exchange только для перевода с конвертацией):
 //
 //	{
-//	  "message": "Money sent successfully"
+//	  "message": "Money sent successfully",
+//	  "transaction_id": 42,
+//	  "fee": "1.00",
+//	  "exchange": {
+//	    "to_currency": "EUR",
+//	    "to_amount": "91.54",
+//	    "rate": "0.9154",
+//	    "rate_at": "2026-10-01T00:00:00Z"
+//	  }
 //	}
 func (h *Handler) SendMoney(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -44,7 +87,8 @@ func (h *Handler) SendMoney(w http.ResponseWriter, r *http.Request) {
 			op+"failed to decode JSON",
 			zap.Error(err),
 		)
-		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, "Invalid JSON")
+		errCode, errMsg := decodeErrorCode(err)
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, errMsg)
 		return
 	}
 
@@ -64,7 +108,38 @@ func (h *Handler) SendMoney(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	svcCode := h.transactionService.SendMoney(ctx, req.From, req.To, req.Amount)
+	idempotencyKey, code, msg := h.parseAndValidateIdempotencyKey(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	result, svcCode := h.transactionService.SendMoney(ctx, domain.TransferRequest{
+		From:           req.From,
+		To:             req.To,
+		Amount:         req.Amount,
+		Convert:        req.Convert,
+		IdempotencyKey: idempotencyKey,
+		Details: domain.TransferDetails{
+			Memo:              req.Memo,
+			ExternalReference: req.ExternalReference,
+			Metadata:          req.Metadata,
+		},
+	})
+	if svcCode == domain.CodeLimitExceeded && result != nil {
+		h.log.Warn(
+			ctx,
+			op+"wallet limit exceeded",
+			zap.String("limit", string(result.ExceededLimit)),
+		)
+		h.writeJSON(ctx, w, http.StatusUnprocessableEntity, dto.LimitErrorResponse{
+			Error:   http.StatusText(http.StatusUnprocessableEntity),
+			Code:    string(svcCode),
+			Message: "Wallet limit exceeded",
+			Limit:   string(result.ExceededLimit),
+		})
+		return
+	}
 	if svcCode != domain.CodeOK {
 		h.log.Warn(
 			ctx,
@@ -78,14 +153,168 @@ func (h *Handler) SendMoney(w http.ResponseWriter, r *http.Request) {
 	h.log.Info(
 		ctx,
 		op+"transaction completed successfully",
+		zap.Int64("transaction_id", result.TransactionId),
 		zap.String("from", req.From),
 		zap.String("to", req.To),
-		zap.Float64("amount", req.Amount),
+		zap.Stringer("amount", req.Amount),
+		zap.Bool("replayed", result.Replayed),
 	)
-	h.writeJSON(ctx, w, http.StatusOK, map[string]string{"message": "Money sent successfully"})
+	if result.Replayed {
+		w.Header().Set(IdempotencyReplayedHeader, "true")
+	}
+	h.writeJSON(ctx, w, http.StatusOK, dto.SendMoneyResponse{
+		Message:       "Money sent successfully",
+		TransactionId: result.TransactionId,
+		Fee:           result.Fee,
+		Exchange:      exchangeResponse(result.Exchange),
+	})
+}
+
+// transactionResponse транзакция для ответа
+func transactionResponse(t *domain.Transaction) dto.TransactionResponse {
+	return dto.TransactionResponse{
+		Id:                t.Id,
+		Kind:              string(t.Kind),
+		From:              t.From,
+		To:                t.To,
+		Amount:            t.Amount,
+		Currency:          t.Currency,
+		Fee:               t.Fee,
+		CreatedAt:         t.CreatedAt.Format(time.RFC3339),
+		ReversalOf:        t.ReversalOf,
+		Exchange:          exchangeResponse(t.Exchange),
+		Memo:              t.Details.Memo,
+		ExternalReference: t.Details.ExternalReference,
+		Metadata:          t.Details.Metadata,
+	}
+}
+
+// exchangeResponse детали конвертации для ответа, nil для перевода в одной валюте
+func exchangeResponse(e *domain.Exchange) *dto.ExchangeResponse {
+	if e == nil {
+		return nil
+	}
+	return &dto.ExchangeResponse{
+		ToCurrency: e.ToCurrency,
+		ToAmount:   e.ToAmount,
+		Rate:       e.Rate,
+		RateAt:     e.RateAt.Format(time.RFC3339),
+	}
+}
+
+// SendBatch обрабатывает HTTP POST запрос для пакетного перевода.
+// Все ноги проводятся в одной транзакции БД: либо проходят все, либо ни одна.
+//
+// Принимает JSON в теле запроса (от 1 до 500 ног):
+//
+//	{
+//	  "legs": [
+//	    {"from": "uuid4-отправителя", "to": "uuid4-получателя", "amount": "10.00"},
+//	    {"from": "uuid4-отправителя", "to": "uuid4-получателя-2", "amount": "5.50"}
+//	  ]
+//	}
+//
+// Возможные коды ответа:
+//   - 200 OK: все переводы проведены
+//   - 400 Bad Request: ошибка валидации запроса
+//   - 409 Conflict: пакет не удалось провести из-за конкурентных изменений (повторы исчерпаны)
+//   - 422 Unprocessable Entity: пакет отклонен, в поле legs причины по каждой ноге (при LIMIT_EXCEEDED - нарушенный лимит в поле limit)
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "message": "Batch sent successfully",
+//	  "transaction_ids": [42, 43]
+//	}
+//
+// Пример отклоненного пакета:
+//
+//	{
+//	  "error": "Unprocessable Entity",
+//	  "code": "BATCH_REJECTED",
+//	  "message": "Batch rejected",
+//	  "legs": [{"index": 1, "code": "INSUFFICIENT_FUNDS"}]
+//	}
+func (h *Handler) SendBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "SendBatch: "
+
+	var req dto.SendBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(
+			ctx,
+			op+"failed to decode JSON",
+			zap.Error(err),
+		)
+		errCode, errMsg := decodeErrorCode(err)
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, errMsg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Int("legs", len(req.Legs)),
+	)
+
+	if err := validator.ValidateStruct(req); err != nil {
+		h.log.Warn(
+			ctx,
+			op+"validation failed",
+			zap.Any("errors", err),
+		)
+		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, err.Error())
+		return
+	}
+
+	legs := make([]domain.TransferLeg, len(req.Legs))
+	for i, leg := range req.Legs {
+		legs[i] = domain.TransferLeg{From: leg.From, To: leg.To, Amount: leg.Amount}
+	}
+
+	result, svcCode := h.transactionService.SendBatch(ctx, legs)
+	if svcCode == domain.CodeBatchRejected && result != nil {
+		h.log.Warn(
+			ctx,
+			op+"batch rejected",
+			zap.Int("rejected_legs", len(result.LegErrors)),
+		)
+		response := dto.BatchErrorResponse{
+			Error:   http.StatusText(http.StatusUnprocessableEntity),
+			Code:    string(svcCode),
+			Message: "Batch rejected",
+			Legs:    make([]dto.LegErrorResponse, len(result.LegErrors)),
+		}
+		for i, legErr := range result.LegErrors {
+			response.Legs[i] = dto.LegErrorResponse{Index: legErr.Index, Code: string(legErr.Code), Limit: string(legErr.Limit)}
+		}
+		h.writeJSON(ctx, w, http.StatusUnprocessableEntity, response)
+		return
+	}
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "SendBatch")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"batch completed successfully",
+		zap.Int64s("transaction_ids", result.TransactionIds),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, dto.SendBatchResponse{
+		Message:        "Batch sent successfully",
+		TransactionIds: result.TransactionIds,
+	})
 }
 
 // GetLastTransactions обрабатывает HTTP GET запрос для получения последних транзакций.
+// Вызывается только для запроса с единственным параметром count, листать дальше можно через ListTransactions.
 //
 // Query параметры:
 //   - count: количество транзакций (обязательный, от 1 до 1000)
@@ -104,7 +333,8 @@ func (h *Handler) SendMoney(w http.ResponseWriter, r *http.Request) {
 //	    "id": 1,
 //	    "from": "uuid-отправителя",
 //	    "to": "uuid-получателя",
-//	    "amount": 100.50,
+//	    "amount": "100.50",
+//	    "currency": "USD",
 //	    "created_at": "2023-01-01T12:00:00Z"
 //	  }
 //	]
@@ -137,13 +367,7 @@ func (h *Handler) GetLastTransactions(w http.ResponseWriter, r *http.Request) {
 
 	response := make([]dto.TransactionResponse, len(transactions))
 	for i, t := range transactions {
-		response[i] = dto.TransactionResponse{
-			Id:        t.Id,
-			From:      t.From,
-			To:        t.To,
-			Amount:    t.Amount,
-			CreatedAt: t.CreatedAt.Format(time.RFC3339),
-		}
+		response[i] = transactionResponse(&t)
 	}
 
 	h.log.Info(
@@ -154,6 +378,95 @@ func (h *Handler) GetLastTransactions(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(ctx, w, http.StatusOK, response)
 }
 
+// ListTransactions обрабатывает HTTP GET запрос для постраничного получения истории транзакций с фильтрами.
+// Страницы выбираются по курсору (keyset): следующая страница запрашивается с cursor из next_cursor
+// и теми же фильтрами, поэтому новые переводы не сдвигают уже полученные страницы.
+// Запрос только с ?count=N обрабатывает GetLastTransactions.
+//
+// Query параметры (все необязательные):
+//   - wallet: адрес кошелька
+//   - role: роль кошелька - sender (исходящие), receiver (входящие) или any (по умолчанию)
+//   - created_from, created_to: границы времени создания в RFC3339, created_from включительно, created_to нет
+//   - min_amount, max_amount: границы суммы в валюте отправителя, включительно
+//   - order: desc (по умолчанию, сначала новые) или asc
+//   - count: размер страницы, от 1 до 1000, по умолчанию 50
+//   - cursor: next_cursor из предыдущей страницы
+//
+// URL: GET /api/transactions?wallet=uuid-кошелька&role=sender&created_from=2025-03-01T00:00:00Z&count=2
+//
+// Возможные коды ответа:
+//   - 200 OK: страница получена
+//   - 400 Bad Request: неверный параметр, несогласованный фильтр или курсор
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "transactions": [
+//	    {
+//	      "id": 12,
+//	      "kind": "transfer",
+//	      "from": "uuid-кошелька",
+//	      "to": "uuid-получателя",
+//	      "amount": "100.50",
+//	      "currency": "USD",
+//	      "fee": "0.00",
+//	      "created_at": "2025-03-02T12:00:00Z"
+//	    }
+//	  ],
+//	  "next_cursor": "djF8ZGVzY3wxNzQwOTE2ODAwMDAwMDAwMDAwfDEy"
+//	}
+func (h *Handler) ListTransactions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "ListTransactions: "
+
+	filter, errCode, msg := h.parseListTransactionsQuery(ctx, r, op)
+	if errCode != domain.CodeOK {
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, msg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.String("wallet", filter.Wallet),
+		zap.String("role", string(filter.Role)),
+		zap.String("order", string(filter.Order)),
+		zap.Int("count", filter.Limit),
+		zap.Bool("has_cursor", filter.After != nil),
+	)
+
+	page, svcCode := h.transactionService.ListTransactions(ctx, filter)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "ListTransactions")
+		return
+	}
+
+	response := dto.TransactionsResponse{
+		Transactions: make([]dto.TransactionResponse, len(page.Transactions)),
+	}
+	for i := range page.Transactions {
+		response.Transactions[i] = transactionResponse(&page.Transactions[i])
+	}
+	if page.Next != nil {
+		next := page.Next.Encode()
+		response.NextCursor = &next
+	}
+
+	h.log.Info(
+		ctx,
+		op+"transactions retrieved successfully",
+		zap.Int("count", len(page.Transactions)),
+		zap.Bool("has_next", page.Next != nil),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, response)
+}
+
 // GetTransactionById обрабатывает HTTP GET запрос для получения транзакции по ID.
 //
 // Path параметры:
@@ -173,7 +486,8 @@ func (h *Handler) GetLastTransactions(w http.ResponseWriter, r *http.Request) {
 //	  "id": 123,
 //	  "from": "uuid-отправителя",
 //	  "to": "uuid-получателя",
-//	  "amount": 100.50,
+//	  "amount": "100.50",
+//	  "currency": "USD",
 //	  "created_at": "2023-01-01T12:00:00Z"
 //	}
 func (h *Handler) GetTransactionById(w http.ResponseWriter, r *http.Request) {
@@ -203,13 +517,7 @@ func (h *Handler) GetTransactionById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := dto.TransactionResponse{
-		Id:        transaction.Id,
-		From:      transaction.From,
-		To:        transaction.To,
-		Amount:    transaction.Amount,
-		CreatedAt: transaction.CreatedAt.Format(time.RFC3339),
-	}
+	response := transactionResponse(transaction)
 
 	h.log.Info(
 		ctx,
@@ -241,7 +549,8 @@ func (h *Handler) GetTransactionById(w http.ResponseWriter, r *http.Request) {
 //	  "id": 123,
 //	  "from": "uuid-отправителя",
 //	  "to": "uuid-получателя",
-//	  "amount": 100.50,
+//	  "amount": "100.50",
+//	  "currency": "USD",
 //	  "created_at": "2023-01-01T12:00:00Z"
 //	}
 func (h *Handler) GetTransactionByInfo(w http.ResponseWriter, r *http.Request) {
@@ -255,7 +564,8 @@ func (h *Handler) GetTransactionByInfo(w http.ResponseWriter, r *http.Request) {
 			op+"failed to decode JSON",
 			zap.Error(err),
 		)
-		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, "Invalid JSON")
+		errCode, errMsg := decodeErrorCode(err)
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, errMsg)
 		return
 	}
 
@@ -290,13 +600,7 @@ func (h *Handler) GetTransactionByInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := dto.TransactionResponse{
-		Id:        transaction.Id,
-		From:      transaction.From,
-		To:        transaction.To,
-		Amount:    transaction.Amount,
-		CreatedAt: transaction.CreatedAt.Format(time.RFC3339),
-	}
+	response := transactionResponse(transaction)
 
 	h.log.Info(
 		ctx,
@@ -308,7 +612,90 @@ func (h *Handler) GetTransactionByInfo(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(ctx, w, http.StatusOK, response)
 }
 
-// RemoveTransaction обрабатывает HTTP DELETE запрос для удаления транзакции.
+// SearchTransactions обрабатывает HTTP GET запрос для поиска транзакций по внешнему идентификатору.
+// Идентификатор уникален только в пределах отправителя, поэтому без from может найтись несколько транзакций.
+//
+// Query параметры:
+//   - external_reference: внешний идентификатор (обязательный)
+//   - from: адрес отправителя (необязательный, UUID)
+//
+// URL: GET /api/transactions/search?external_reference=order-1001&from=uuid-отправителя
+//
+// Возможные коды ответа:
+//   - 200 OK: найденные транзакции, старые первыми (пустой список, если ничего не найдено)
+//   - 400 Bad Request: неверные параметры
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	[
+//	  {
+//	    "id": 42,
+//	    "from": "uuid-отправителя",
+//	    "to": "uuid-получателя",
+//	    "amount": "100.50",
+//	    "currency": "USD",
+//	    "fee": "0.00",
+//	    "created_at": "2026-10-16T12:00:00Z",
+//	    "memo": "Оплата заказа 1001",
+//	    "external_reference": "order-1001",
+//	    "metadata": {"order_id": 1001, "channel": "web"}
+//	  }
+//	]
+func (h *Handler) SearchTransactions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "SearchTransactions: "
+
+	query := dto.SearchTransactionsQuery{
+		ExternalReference: r.URL.Query().Get("external_reference"),
+		From:              r.URL.Query().Get("from"),
+	}
+	if err := validator.ValidateStruct(query); err != nil {
+		h.log.Warn(
+			ctx,
+			op+"query validation failed",
+			zap.Error(err),
+		)
+		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, err.Error())
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.String("external_reference", query.ExternalReference),
+		zap.String("from", query.From),
+	)
+
+	transactions, svcCode := h.transactionService.FindTransactionsByExternalReference(ctx, query.ExternalReference, query.From)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "SearchTransactions")
+		return
+	}
+
+	response := make([]dto.TransactionResponse, len(transactions))
+	for i, t := range transactions {
+		response[i] = transactionResponse(&t)
+	}
+
+	h.log.Info(
+		ctx,
+		op+"transactions found",
+		zap.String("external_reference", query.ExternalReference),
+		zap.Int("count", len(transactions)),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, response)
+}
+
+// ReverseTransaction обрабатывает HTTP DELETE запрос для отмены транзакции.
+// Транзакция не удаляется: создается встречный перевод, связанный с исходным через reversal_of,
+// а балансы обоих кошельков восстанавливаются атомарно.
+// Комиссия исходного перевода не возвращается: отправитель получает обратно только сумму перевода.
 //
 // Path параметры:
 //   - id: ID транзакции (обязательный, положительное число)
@@ -316,8 +703,70 @@ func (h *Handler) GetTransactionByInfo(w http.ResponseWriter, r *http.Request) {
 // URL: DELETE /api/transaction/123
 //
 // Возможные коды ответа:
+//   - 200 OK: транзакция успешно отменена
+//   - 400 Bad Request: неверный ID, отмена отмены или у получателя недостаточно средств
+//   - 404 Not Found: транзакция или кошелек не найдены
+//   - 409 Conflict: транзакция уже отменена
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "message": "Transaction reversed successfully",
+//	  "reversal_id": 124
+//	}
+func (h *Handler) ReverseTransaction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "ReverseTransaction: "
+
+	id, code, msg := h.parseAndValidateID(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.Int64("id", id),
+	)
+
+	result, srvCode := h.transactionService.ReverseTransaction(ctx, id)
+	if srvCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(srvCode)),
+		)
+		h.handleServiceError(ctx, w, srvCode, "ReverseTransaction")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"transaction reversed successfully",
+		zap.Int64("id", id),
+		zap.Int64("reversal_id", result.TransactionId),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, dto.ReverseTransactionResponse{
+		Message:    "Transaction reversed successfully",
+		ReversalId: result.TransactionId,
+	})
+}
+
+// RemoveTransaction обрабатывает HTTP DELETE запрос для физического удаления транзакции.
+// Балансы при этом не меняются, поэтому операция доступна только в админском режиме
+// (transfers.AdminHardDelete в конфигурации).
+//
+// Path параметры:
+//   - id: ID транзакции (обязательный, положительное число)
+//
+// URL: DELETE /api/admin/transaction/123
+//
+// Возможные коды ответа:
 //   - 200 OK: транзакция успешно удалена
 //   - 400 Bad Request: неверный ID
+//   - 403 Forbidden: админский режим выключен
 //   - 404 Not Found: транзакция не найдена
 //   - 500 Internal Server Error: внутренняя ошибка сервера
 //
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"TransactionTest/internal/delivery/dto"
+	"TransactionTest/internal/delivery/validator"
+	"TransactionTest/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// Deposit обрабатывает HTTP POST запрос для пополнения кошелька.
+// Средства переводятся с кошелька казначейства валюты кошелька (секция treasury конфигурации)
+// и записываются транзакцией с типом deposit. Баланс казначейства может уходить в минус.
+//
+// Path параметры:
+//   - address: адрес кошелька (UUID, обязательный)
+//
+// Принимает JSON в теле запроса (memo, external_reference и metadata необязательные, как в SendMoney):
+//
+//	{
+//	  "amount": "100.00",
+//	  "memo": "Пополнение картой",
+//	  "external_reference": "psp-8841"
+//	}
+//
+// URL: POST /api/wallet/550e8400-e29b-41d4-a716-446655440000/deposit
+//
+// Возможные коды ответа:
+//   - 200 OK: кошелек пополнен
+//   - 400 Bad Request: ошибка валидации
+//   - 404 Not Found: кошелек не найден
+//   - 409 Conflict: конкурентные изменения (повторы исчерпаны) или external_reference уже использован
+//   - 422 Unprocessable Entity: для валюты кошелька не настроено казначейство, кошелек заморожен,
+//     закрыт или сам является казначейством либо превышен лимит баланса (ответ как у SendMoney)
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "message": "Deposit completed successfully",
+//	  "transaction_id": 42
+//	}
+func (h *Handler) Deposit(w http.ResponseWriter, r *http.Request) {
+	h.moveFunds(w, r, "Deposit", "Deposit completed successfully", h.treasuryService.Deposit)
+}
+
+// Withdraw обрабатывает HTTP POST запрос для вывода средств с кошелька.
+// Средства переводятся на кошелек казначейства валюты кошелька и записываются транзакцией
+// с типом withdrawal. Вывод проверяется доступным балансом и лимитами исходящих переводов.
+// Тело запроса и формат ответа как у Deposit.
+//
+// URL: POST /api/wallet/550e8400-e29b-41d4-a716-446655440000/withdraw
+//
+// Возможные коды ответа:
+//   - 200 OK: средства выведены
+//   - 400 Bad Request: ошибка валидации или недостаточно средств
+//   - 404 Not Found: кошелек не найден
+//   - 409 Conflict: конкурентные изменения (повторы исчерпаны) или external_reference уже использован
+//   - 422 Unprocessable Entity: для валюты кошелька не настроено казначейство, кошелек заморожен,
+//     закрыт или сам является казначейством либо превышен лимит кошелька
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+func (h *Handler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	h.moveFunds(w, r, "Withdraw", "Withdrawal completed successfully", h.treasuryService.Withdraw)
+}
+
+// moveFunds общая часть обработчиков пополнения и вывода
+func (h *Handler) moveFunds(
+	w http.ResponseWriter,
+	r *http.Request,
+	operation, message string,
+	move func(ctx context.Context, req domain.FundsRequest) (*domain.TransferResult, domain.ErrorCode),
+) {
+	ctx := r.Context()
+	op := operation + ": "
+
+	address, code, msg := h.parseAndValidateAddress(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	var req dto.FundsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(
+			ctx,
+			op+"failed to decode JSON",
+			zap.Error(err),
+		)
+		errCode, errMsg := decodeErrorCode(err)
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, errMsg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.String("address", address),
+		zap.Any("payload", req),
+	)
+
+	if err := validator.ValidateStruct(req); err != nil {
+		h.log.Warn(
+			ctx,
+			op+"validation failed",
+			zap.Any("errors", err),
+		)
+		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, err.Error())
+		return
+	}
+
+	result, svcCode := move(ctx, domain.FundsRequest{
+		Address: address,
+		Amount:  req.Amount,
+		Details: domain.TransferDetails{
+			Memo:              req.Memo,
+			ExternalReference: req.ExternalReference,
+			Metadata:          req.Metadata,
+		},
+	})
+	if svcCode == domain.CodeLimitExceeded && result != nil {
+		h.log.Warn(
+			ctx,
+			op+"wallet limit exceeded",
+			zap.String("limit", string(result.ExceededLimit)),
+		)
+		h.writeJSON(ctx, w, http.StatusUnprocessableEntity, dto.LimitErrorResponse{
+			Error:   http.StatusText(http.StatusUnprocessableEntity),
+			Code:    string(svcCode),
+			Message: "Wallet limit exceeded",
+			Limit:   string(result.ExceededLimit),
+		})
+		return
+	}
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, operation)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"completed successfully",
+		zap.Int64("transaction_id", result.TransactionId),
+		zap.String("address", address),
+		zap.Stringer("amount", req.Amount),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, dto.FundsResponse{
+		Message:       message,
+		TransactionId: result.TransactionId,
+	})
+}
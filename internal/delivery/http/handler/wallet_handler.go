@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -17,13 +18,20 @@ import (
 // Принимает JSON в теле запроса:
 //
 //	{
-//	  "balance": 100.50
+//	  "balance": "100.50",
+//	  "currency": "EUR"
 //	}
 //
+// Поле currency необязательное: без него кошелек открывается в валюте по умолчанию (currencies.Default).
+// Ненулевой balance зачисляется пополнением (транзакция deposit) с кошелька казначейства валюты.
+//
 // Возможные коды ответа:
 //   - 201 Created: кошелек успешно создан
-//   - 400 Bad Request: ошибка валидации (отрицательный баланс)
+//   - 400 Bad Request: ошибка валидации (отрицательный баланс, неподдерживаемая валюта,
+//     лишние знаки после запятой для валюты)
 //   - 409 Conflict: кошелек уже существует (невозможно)
+//   - 422 Unprocessable Entity: для валюты нет казначейства, казначейство заморожено
+//     или закрыто, баланс больше лимита max_balance
 //   - 500 Internal Server Error: внутренняя ошибка сервера
 //
 // Пример успешного ответа:
@@ -42,7 +50,8 @@ func (h *Handler) CreateWallet(w http.ResponseWriter, r *http.Request) {
 			op+"failed to decode JSON",
 			zap.Error(err),
 		)
-		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, "Invalid JSON")
+		errCode, errMsg := decodeErrorCode(err)
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, errMsg)
 		return
 	}
 
@@ -62,7 +71,7 @@ func (h *Handler) CreateWallet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	address, svcCode := h.walletService.CreateWallet(ctx, req.Balance)
+	address, svcCode := h.walletService.CreateWallet(ctx, req.Currency, req.Balance)
 	if svcCode != domain.CodeOK {
 		h.log.Warn(
 			ctx,
@@ -77,7 +86,8 @@ func (h *Handler) CreateWallet(w http.ResponseWriter, r *http.Request) {
 		ctx,
 		op+"wallet created successfully",
 		zap.String("address", address),
-		zap.Float64("balance", req.Balance),
+		zap.Stringer("balance", req.Balance),
+		zap.String("currency", req.Currency),
 	)
 	h.writeJSON(ctx, w, http.StatusCreated, dto.CreateWalletResponse{Address: address})
 }
@@ -87,18 +97,32 @@ func (h *Handler) CreateWallet(w http.ResponseWriter, r *http.Request) {
 // Path параметры:
 //   - address: адрес кошелька (UUID4, обязательный)
 //
+// Query параметры:
+//   - at: момент времени в формате RFC3339 (необязательный), баланс на этот момент по истории проводок
+//
 // URL: GET /api/wallet/uuid4/balance
+// URL: GET /api/wallet/uuid4/balance?at=2025-03-03T00:00:00Z
 //
 // Возможные коды ответа:
 //   - 200 OK: баланс успешно получен
-//   - 400 Bad Request: неверный адрес
+//   - 400 Bad Request: неверный адрес или at
 //   - 404 Not Found: кошелек не найден
 //   - 500 Internal Server Error: внутренняя ошибка сервера
 //
+// balance - баланс по журналу, available - доступно для списания: за вычетом активных резервов плюс кредитная линия.
+//
 // Пример успешного ответа:
 //
 //	{
-//	  "balance": 100.50
+//	  "balance": "100.50",
+//	  "available": "80.50"
+//	}
+//
+// Пример ответа с at (резервы на прошлый момент не восстанавливаются, поэтому available нет):
+//
+//	{
+//	  "balance": "42.00",
+//	  "at": "2025-03-03T00:00:00Z"
 //	}
 func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -110,6 +134,22 @@ func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if rawAt := r.URL.Query().Get("at"); rawAt != "" {
+		at, err := time.Parse(time.RFC3339, rawAt)
+		if err != nil {
+			h.log.Warn(
+				ctx,
+				op+"invalid at format",
+				zap.String("at", rawAt),
+				zap.Error(err),
+			)
+			h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, "Invalid at format")
+			return
+		}
+		h.getBalanceAt(ctx, w, address, at)
+		return
+	}
+
 	h.log.Info(
 		ctx,
 		op+"received request",
@@ -131,12 +171,47 @@ func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
 		ctx,
 		op+"balance retrieved successfully",
 		zap.String("address", address),
-		zap.Float64("balance", balance),
+		zap.Stringer("balance", balance.Total),
+		zap.Stringer("available", balance.Available),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, dto.BalanceResponse{Balance: balance.Total, Available: balance.Available})
+}
+
+// getBalanceAt отвечает на GetBalance с параметром at
+func (h *Handler) getBalanceAt(ctx context.Context, w http.ResponseWriter, address string, at time.Time) {
+	const op = "GetBalance: "
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.String("address", address),
+		zap.Time("at", at),
+	)
+
+	balance, svcCode := h.walletService.GetBalanceAt(ctx, address, at)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "GetBalance")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"historical balance retrieved successfully",
+		zap.String("address", address),
+		zap.Time("at", at),
+		zap.Stringer("balance", balance),
 	)
-	h.writeJSON(ctx, w, http.StatusOK, dto.BalanceResponse{Balance: balance})
+	h.writeJSON(ctx, w, http.StatusOK, dto.BalanceAtResponse{Balance: balance, At: at.Format(time.RFC3339)})
 }
 
 // GetWallet обрабатывает HTTP GET запрос для получения полной информации о кошельке.
+// У кошелька с кредитной линией баланс может быть отрицательным: credit_used - задолженность,
+// credit_available - неиспользованная часть overdraft_limit.
 //
 // Path параметры:
 //   - address: адрес кошелька (UUID, обязательный)
@@ -153,7 +228,12 @@ func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
 //
 //	{
 //	  "address": "550e8400-e29b-41d4-a716-446655440000",
-//	  "balance": 100.50,
+//	  "balance": "100.50",
+//	  "currency": "USD",
+//	  "status": "active",
+//	  "overdraft_limit": "0.00",
+//	  "credit_used": "0.00",
+//	  "credit_available": "0.00",
 //	  "created_at": "2023-01-01T12:00:00Z"
 //	}
 func (h *Handler) GetWallet(w http.ResponseWriter, r *http.Request) {
@@ -184,9 +264,15 @@ func (h *Handler) GetWallet(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := dto.WalletResponse{
-		Address:   wallet.Address,
-		Balance:   wallet.Balance,
-		CreatedAt: wallet.CreatedAt.Format(time.RFC3339),
+		Address:         wallet.Address,
+		Balance:         wallet.Balance,
+		Currency:        wallet.Currency,
+		Status:          string(wallet.Status),
+		Treasury:        wallet.Treasury,
+		OverdraftLimit:  wallet.OverdraftLimit,
+		CreditUsed:      wallet.CreditUsed(),
+		CreditAvailable: wallet.CreditAvailable(),
+		CreatedAt:       wallet.CreatedAt.Format(time.RFC3339),
 	}
 
 	h.log.Info(
@@ -197,7 +283,10 @@ func (h *Handler) GetWallet(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(ctx, w, http.StatusOK, response)
 }
 
-// UpdateBalance обрабатывает HTTP PUT запрос для обновления баланса кошелька.
+// UpdateBalance обрабатывает HTTP PUT запрос для ручной корректировки баланса кошелька.
+// Разница с текущим балансом проводится корректирующей записью журнала, а корректировка
+// с прежним и новым балансом и причиной сохраняется в аудите (GET /api/wallet/{address}/adjustments).
+// Для движения денег используйте пополнения и выводы (POST /api/wallet/{address}/deposit, /withdraw).
 //
 // Path параметры:
 //   - address: адрес кошелька (UUID, обязательный)
@@ -205,21 +294,29 @@ func (h *Handler) GetWallet(w http.ResponseWriter, r *http.Request) {
 // Принимает JSON в теле запроса:
 //
 //	{
-//	  "balance": 200.75
+//	  "balance": "200.75",
+//	  "reason": "Исправление ошибки оператора"
 //	}
 //
 // URL: PUT /api/wallet/550e8400-e29b-41d4-a716-446655440000/balance
 //
 // Возможные коды ответа:
 //   - 200 OK: баланс успешно обновлен
-//   - 400 Bad Request: ошибка валидации или отрицательный баланс
+//   - 400 Bad Request: ошибка валидации, нет причины или отрицательный баланс
 //   - 404 Not Found: кошелек не найден
+//   - 422 Unprocessable Entity: кошелек закрыт или это кошелек казначейства
 //   - 500 Internal Server Error: внутренняя ошибка сервера
 //
-// Пример успешного ответа:
+// Пример успешного ответа (entry_id отсутствует, если баланс не изменился):
 //
 //	{
-//	  "message": "Balance updated successfully"
+//	  "id": 3,
+//	  "address": "550e8400-e29b-41d4-a716-446655440000",
+//	  "previous_balance": "150.00",
+//	  "new_balance": "200.75",
+//	  "reason": "Исправление ошибки оператора",
+//	  "entry_id": 12,
+//	  "created_at": "2026-10-01T12:00:00Z"
 //	}
 func (h *Handler) UpdateBalance(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -238,7 +335,8 @@ func (h *Handler) UpdateBalance(w http.ResponseWriter, r *http.Request) {
 			op+"failed to decode JSON",
 			zap.Error(err),
 		)
-		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, "Invalid JSON")
+		errCode, errMsg := decodeErrorCode(err)
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, errMsg)
 		return
 	}
 
@@ -259,7 +357,7 @@ func (h *Handler) UpdateBalance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	svcCode := h.walletService.UpdateBalance(ctx, address, req.Balance)
+	adjustment, svcCode := h.walletService.UpdateBalance(ctx, address, req.Balance, req.Reason)
 	if svcCode != domain.CodeOK {
 		h.log.Warn(
 			ctx,
@@ -274,9 +372,254 @@ func (h *Handler) UpdateBalance(w http.ResponseWriter, r *http.Request) {
 		ctx,
 		op+"balance updated successfully",
 		zap.String("address", address),
-		zap.Float64("new_balance", req.Balance),
+		zap.Stringer("new_balance", req.Balance),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, balanceAdjustmentResponse(adjustment))
+}
+
+// GetBalanceAdjustments обрабатывает HTTP GET запрос для получения ручных корректировок баланса кошелька, новые первыми.
+//
+// Path параметры:
+//   - address: адрес кошелька (UUID, обязательный)
+//
+// Query параметры:
+//   - count: количество записей (обязательный, положительное число)
+//
+// URL: GET /api/wallet/550e8400-e29b-41d4-a716-446655440000/adjustments?count=10
+//
+// Возможные коды ответа:
+//   - 200 OK: корректировки получены (формат записи как в ответе UpdateBalance)
+//   - 400 Bad Request: неверный адрес или count
+//   - 404 Not Found: кошелек не найден
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+func (h *Handler) GetBalanceAdjustments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "GetBalanceAdjustments: "
+
+	address, code, msg := h.parseAndValidateAddress(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	count, code, msg := h.parseAndValidateCount(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.String("address", address),
+		zap.Int("count", count),
+	)
+
+	adjustments, svcCode := h.walletService.GetBalanceAdjustments(ctx, address, count)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "GetBalanceAdjustments")
+		return
+	}
+
+	response := make([]dto.BalanceAdjustmentResponse, len(adjustments))
+	for i, a := range adjustments {
+		response[i] = balanceAdjustmentResponse(&a)
+	}
+
+	h.log.Info(
+		ctx,
+		op+"balance adjustments retrieved successfully",
+		zap.String("address", address),
+		zap.Int("count", len(adjustments)),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, response)
+}
+
+func balanceAdjustmentResponse(a *domain.BalanceAdjustment) dto.BalanceAdjustmentResponse {
+	return dto.BalanceAdjustmentResponse{
+		Id:              a.Id,
+		Address:         a.Address,
+		PreviousBalance: a.Previous,
+		NewBalance:      a.New,
+		Reason:          a.Reason,
+		EntryId:         a.EntryId,
+		CreatedAt:       a.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// SetOverdraftLimit обрабатывает HTTP PUT запрос для изменения кредитной линии кошелька.
+// С кредитной линией переводы могут уводить баланс до -overdraft_limit; 0 отключает кредит.
+//
+// Path параметры:
+//   - address: адрес кошелька (UUID, обязательный)
+//
+// Принимает JSON в теле запроса:
+//
+//	{
+//	  "overdraft_limit": "500.00"
+//	}
+//
+// URL: PUT /api/wallet/550e8400-e29b-41d4-a716-446655440000/overdraft
+//
+// Возможные коды ответа:
+//   - 200 OK: кредитная линия изменена
+//   - 400 Bad Request: ошибка валидации или отрицательный лимит
+//   - 404 Not Found: кошелек не найден
+//   - 409 Conflict: лимит меньше текущей задолженности
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "message": "Overdraft limit updated successfully"
+//	}
+func (h *Handler) SetOverdraftLimit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "SetOverdraftLimit: "
+
+	address, code, msg := h.parseAndValidateAddress(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	var req dto.OverdraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(
+			ctx,
+			op+"failed to decode JSON",
+			zap.Error(err),
+		)
+		errCode, errMsg := decodeErrorCode(err)
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, errMsg)
+		return
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		h.log.Warn(
+			ctx,
+			op+"request validation failed",
+			zap.Any("errors", err),
+		)
+		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, err.Error())
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.String("address", address),
+		zap.Stringer("overdraft_limit", *req.OverdraftLimit),
+	)
+
+	svcCode := h.walletService.SetOverdraftLimit(ctx, address, *req.OverdraftLimit)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "SetOverdraftLimit")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"overdraft limit updated successfully",
+		zap.String("address", address),
+		zap.Stringer("overdraft_limit", *req.OverdraftLimit),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, map[string]string{"message": "Overdraft limit updated successfully"})
+}
+
+// GetWalletEntries обрабатывает HTTP GET запрос для получения проводок журнала по кошельку.
+// Положительная сумма - зачисление на кошелек, отрицательная - списание.
+//
+// Path параметры:
+//   - address: адрес кошелька (UUID, обязательный)
+//
+// Query параметры:
+//   - count: количество проводок (обязательный, положительное число)
+//
+// URL: GET /api/wallet/550e8400-e29b-41d4-a716-446655440000/entries?count=10
+//
+// Возможные коды ответа:
+//   - 200 OK: проводки получены
+//   - 400 Bad Request: неверный адрес или count
+//   - 404 Not Found: кошелек не найден
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	[
+//	  {
+//	    "id": 42,
+//	    "entry_id": 21,
+//	    "kind": "transfer",
+//	    "transaction_id": 7,
+//	    "amount": "-50.00",
+//	    "created_at": "2023-01-01T12:00:00Z"
+//	  }
+//	]
+func (h *Handler) GetWalletEntries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "GetWalletEntries: "
+
+	address, code, msg := h.parseAndValidateAddress(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	count, code, msg := h.parseAndValidateCount(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.String("address", address),
+		zap.Int("count", count),
+	)
+
+	postings, svcCode := h.walletService.GetWalletEntries(ctx, address, count)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "GetWalletEntries")
+		return
+	}
+
+	response := make([]dto.PostingResponse, len(postings))
+	for i, p := range postings {
+		response[i] = dto.PostingResponse{
+			Id:            p.Id,
+			EntryId:       p.EntryId,
+			Kind:          string(p.Kind),
+			TransactionId: p.TransactionId,
+			Currency:      p.Currency,
+			Amount:        p.Amount,
+			CreatedAt:     p.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	h.log.Info(
+		ctx,
+		op+"entries retrieved successfully",
+		zap.String("address", address),
+		zap.Int("count", len(postings)),
 	)
-	h.writeJSON(ctx, w, http.StatusOK, map[string]string{"message": "Balance updated successfully"})
+	h.writeJSON(ctx, w, http.StatusOK, response)
 }
 
 // RemoveWallet обрабатывает HTTP DELETE запрос для удаления кошелька.
@@ -286,10 +629,14 @@ func (h *Handler) UpdateBalance(w http.ResponseWriter, r *http.Request) {
 //
 // URL: DELETE /api/wallet/550e8400-e29b-41d4-a716-446655440000
 //
+// Удалить можно только кошелек без истории: без переводов и без проводок в журнале, в том числе
+// проводки начального баланса. Такой кошелек нужно закрыть (POST /api/wallet/{address}/close).
+//
 // Возможные коды ответа:
 //   - 200 OK: кошелек успешно удален
 //   - 400 Bad Request: неверный адрес
 //   - 404 Not Found: кошелек не найден
+//   - 409 Conflict: у кошелька есть история переводов или проводки в журнале
 //   - 500 Internal Server Error: внутренняя ошибка сервера
 //
 // Пример успешного ответа:
@@ -331,3 +678,482 @@ func (h *Handler) RemoveWallet(w http.ResponseWriter, r *http.Request) {
 	)
 	h.writeJSON(ctx, w, http.StatusOK, map[string]string{"message": "Wallet removed successfully"})
 }
+
+// FreezeWallet обрабатывает HTTP POST запрос для заморозки кошелька.
+// Замороженный кошелек не может отправлять и получать переводы (WALLET_FROZEN), пока его не разморозят.
+//
+// Path параметры:
+//   - address: адрес кошелька (UUID, обязательный)
+//
+// Принимает JSON в теле запроса (причина обязательна и сохраняется в истории статусов):
+//
+//	{
+//	  "reason": "Подозрительная активность"
+//	}
+//
+// URL: POST /api/wallet/550e8400-e29b-41d4-a716-446655440000/freeze
+//
+// Возможные коды ответа:
+//   - 200 OK: кошелек заморожен
+//   - 400 Bad Request: неверный адрес или не указана причина
+//   - 404 Not Found: кошелек не найден
+//   - 409 Conflict: кошелек не активен
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "id": 3,
+//	  "address": "550e8400-e29b-41d4-a716-446655440000",
+//	  "from_status": "active",
+//	  "to_status": "frozen",
+//	  "reason": "Подозрительная активность",
+//	  "created_at": "2026-10-16T12:00:00Z"
+//	}
+func (h *Handler) FreezeWallet(w http.ResponseWriter, r *http.Request) {
+	h.changeWalletStatus(w, r, "FreezeWallet", h.walletService.FreezeWallet)
+}
+
+// UnfreezeWallet обрабатывает HTTP POST запрос для разморозки кошелька.
+// Тело запроса и формат ответа как у FreezeWallet.
+//
+// URL: POST /api/wallet/550e8400-e29b-41d4-a716-446655440000/unfreeze
+//
+// Возможные коды ответа:
+//   - 200 OK: кошелек снова активен
+//   - 400 Bad Request: неверный адрес или не указана причина
+//   - 404 Not Found: кошелек не найден
+//   - 409 Conflict: кошелек не заморожен
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+func (h *Handler) UnfreezeWallet(w http.ResponseWriter, r *http.Request) {
+	h.changeWalletStatus(w, r, "UnfreezeWallet", h.walletService.UnfreezeWallet)
+}
+
+// CloseWallet обрабатывает HTTP POST запрос для закрытия кошелька.
+// Закрыть можно только активный кошелек с нулевым балансом, закрытие необратимо.
+// Тело запроса и формат ответа как у FreezeWallet.
+//
+// URL: POST /api/wallet/550e8400-e29b-41d4-a716-446655440000/close
+//
+// Возможные коды ответа:
+//   - 200 OK: кошелек закрыт
+//   - 400 Bad Request: неверный адрес или не указана причина
+//   - 404 Not Found: кошелек не найден
+//   - 409 Conflict: кошелек не активен, его баланс не нулевой или у него есть активные резервы
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+func (h *Handler) CloseWallet(w http.ResponseWriter, r *http.Request) {
+	h.changeWalletStatus(w, r, "CloseWallet", h.walletService.CloseWallet)
+}
+
+// changeWalletStatus общая часть обработчиков смены статуса кошелька
+func (h *Handler) changeWalletStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	operation string,
+	change func(ctx context.Context, address, reason string) (*domain.WalletStatusChange, domain.ErrorCode),
+) {
+	ctx := r.Context()
+	op := operation + ": "
+
+	address, code, msg := h.parseAndValidateAddress(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	var req dto.WalletStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(
+			ctx,
+			op+"failed to decode JSON",
+			zap.Error(err),
+		)
+		errCode, errMsg := decodeErrorCode(err)
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, errMsg)
+		return
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		h.log.Warn(
+			ctx,
+			op+"validation failed",
+			zap.Any("errors", err),
+		)
+		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, err.Error())
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.String("address", address),
+		zap.String("reason", req.Reason),
+	)
+
+	statusChange, svcCode := change(ctx, address, req.Reason)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, operation)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"wallet status changed successfully",
+		zap.String("address", address),
+		zap.String("status", string(statusChange.ToStatus)),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, walletStatusChangeResponse(statusChange))
+}
+
+// GetWalletStatusHistory обрабатывает HTTP GET запрос для получения истории статусов кошелька, новые первыми.
+//
+// Path параметры:
+//   - address: адрес кошелька (UUID, обязательный)
+//
+// Query параметры:
+//   - count: количество записей (обязательный, положительное число)
+//
+// URL: GET /api/wallet/550e8400-e29b-41d4-a716-446655440000/status-history?count=10
+//
+// Возможные коды ответа:
+//   - 200 OK: история получена (формат записи как в ответе FreezeWallet)
+//   - 400 Bad Request: неверный адрес или count
+//   - 404 Not Found: кошелек не найден
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+func (h *Handler) GetWalletStatusHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "GetWalletStatusHistory: "
+
+	address, code, msg := h.parseAndValidateAddress(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	count, code, msg := h.parseAndValidateCount(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.String("address", address),
+		zap.Int("count", count),
+	)
+
+	changes, svcCode := h.walletService.GetWalletStatusHistory(ctx, address, count)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "GetWalletStatusHistory")
+		return
+	}
+
+	response := make([]dto.WalletStatusChangeResponse, len(changes))
+	for i, c := range changes {
+		response[i] = walletStatusChangeResponse(&c)
+	}
+
+	h.log.Info(
+		ctx,
+		op+"status history retrieved successfully",
+		zap.String("address", address),
+		zap.Int("count", len(changes)),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, response)
+}
+
+func walletStatusChangeResponse(c *domain.WalletStatusChange) dto.WalletStatusChangeResponse {
+	return dto.WalletStatusChangeResponse{
+		Id:         c.Id,
+		Address:    c.Address,
+		FromStatus: string(c.FromStatus),
+		ToStatus:   string(c.ToStatus),
+		Reason:     c.Reason,
+		CreatedAt:  c.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// GetWalletLimits обрабатывает HTTP GET запрос для получения лимитов кошелька.
+// Возвращаются действующие лимиты: заданные для кошелька (перечислены в custom), остальные - из конфигурации.
+// 0 означает отсутствие ограничения.
+//
+// Path параметры:
+//   - address: адрес кошелька (UUID, обязательный)
+//
+// URL: GET /api/wallet/550e8400-e29b-41d4-a716-446655440000/limits
+//
+// Возможные коды ответа:
+//   - 200 OK: лимиты получены
+//   - 400 Bad Request: неверный адрес
+//   - 404 Not Found: кошелек не найден
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "address": "550e8400-e29b-41d4-a716-446655440000",
+//	  "max_transfer": "1000.00",
+//	  "max_daily_outflow": "5000.00",
+//	  "max_daily_count": 20,
+//	  "max_balance": "0.00",
+//	  "window_seconds": 86400,
+//	  "custom": ["max_transfer"]
+//	}
+func (h *Handler) GetWalletLimits(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "GetWalletLimits: "
+
+	address, code, msg := h.parseAndValidateAddress(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.String("address", address),
+	)
+
+	settings, svcCode := h.walletService.GetWalletLimits(ctx, address)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "GetWalletLimits")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"wallet limits retrieved successfully",
+		zap.String("address", address),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, walletLimitsResponse(settings))
+}
+
+// SetWalletLimits обрабатывает HTTP PUT запрос для изменения лимитов кошелька.
+// Запрос заменяет все лимиты кошелька: не указанный (или null) лимит возвращается к значению из конфигурации,
+// 0 снимает ограничение.
+//
+// Path параметры:
+//   - address: адрес кошелька (UUID, обязательный)
+//
+// Принимает JSON в теле запроса:
+//
+//	{
+//	  "max_transfer": "1000.00",
+//	  "max_daily_outflow": "5000.00",
+//	  "max_daily_count": 20,
+//	  "max_balance": null
+//	}
+//
+// URL: PUT /api/wallet/550e8400-e29b-41d4-a716-446655440000/limits
+//
+// Возможные коды ответа:
+//   - 200 OK: лимиты изменены, ответ как у GetWalletLimits
+//   - 400 Bad Request: ошибка валидации или отрицательный лимит
+//   - 404 Not Found: кошелек не найден
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+func (h *Handler) SetWalletLimits(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "SetWalletLimits: "
+
+	address, code, msg := h.parseAndValidateAddress(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	var req dto.WalletLimitsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error(
+			ctx,
+			op+"failed to decode JSON",
+			zap.Error(err),
+		)
+		errCode, errMsg := decodeErrorCode(err)
+		h.writeError(ctx, w, http.StatusBadRequest, errCode, errMsg)
+		return
+	}
+
+	if err := validator.ValidateStruct(req); err != nil {
+		h.log.Warn(
+			ctx,
+			op+"validation failed",
+			zap.Any("errors", err),
+		)
+		h.writeError(ctx, w, http.StatusBadRequest, domain.CodeInvalidRequestBody, err.Error())
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.String("address", address),
+		zap.Any("payload", req),
+	)
+
+	settings, svcCode := h.walletService.SetWalletLimits(ctx, address, domain.LimitOverrides{
+		MaxTransfer:     req.MaxTransfer,
+		MaxDailyOutflow: req.MaxDailyOutflow,
+		MaxDailyCount:   req.MaxDailyCount,
+		MaxBalance:      req.MaxBalance,
+	})
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "SetWalletLimits")
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"wallet limits updated successfully",
+		zap.String("address", address),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, walletLimitsResponse(settings))
+}
+
+func walletLimitsResponse(s *domain.WalletLimitSettings) dto.WalletLimitsResponse {
+	kinds := s.Overrides.Kinds()
+	custom := make([]string, len(kinds))
+	for i, kind := range kinds {
+		custom[i] = string(kind)
+	}
+	return dto.WalletLimitsResponse{
+		Address:         s.Address,
+		MaxTransfer:     s.Effective.MaxTransfer,
+		MaxDailyOutflow: s.Effective.MaxDailyOutflow,
+		MaxDailyCount:   s.Effective.MaxDailyCount,
+		MaxBalance:      s.Effective.MaxBalance,
+		WindowSeconds:   int64(s.Window.Seconds()),
+		Custom:          custom,
+	}
+}
+
+// GetWalletStatement обрабатывает HTTP GET запрос для получения выписки по кошельку за период.
+// Выписка содержит баланс на начало периода, все зачисления и списания с балансом после каждого,
+// обороты и баланс на конец периода. Все значения читаются из одного снимка БД.
+// Комиссия перевода - отдельная операция с fee = true.
+//
+// Path параметры:
+//   - address: адрес кошелька (UUID, обязательный)
+//
+// Query параметры:
+//   - from: начало периода включительно (RFC3339, обязательный)
+//   - to: конец периода не включительно (RFC3339, обязательный)
+//
+// URL: GET /api/wallet/550e8400-e29b-41d4-a716-446655440000/statement?from=2025-03-01T00:00:00Z&to=2025-04-01T00:00:00Z
+//
+// Возможные коды ответа:
+//   - 200 OK: выписка получена
+//   - 400 Bad Request: неверный адрес, формат from или to, либо from не раньше to
+//   - 404 Not Found: кошелек не найден
+//   - 500 Internal Server Error: внутренняя ошибка сервера
+//
+// Пример успешного ответа:
+//
+//	{
+//	  "address": "550e8400-e29b-41d4-a716-446655440000",
+//	  "currency": "USD",
+//	  "from": "2025-03-01T00:00:00Z",
+//	  "to": "2025-04-01T00:00:00Z",
+//	  "opening_balance": "100.00",
+//	  "total_credits": "25.00",
+//	  "total_debits": "51.00",
+//	  "closing_balance": "74.00",
+//	  "lines": [
+//	    {"posting_id": 42, "kind": "transfer", "transaction_id": 7, "counterparty": "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+//	     "amount": "-50.00", "balance": "50.00", "created_at": "2025-03-02T12:00:00Z"},
+//	    {"posting_id": 44, "kind": "transfer", "transaction_id": 7, "counterparty": "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+//	     "fee": true, "amount": "-1.00", "balance": "49.00", "created_at": "2025-03-02T12:00:00Z"},
+//	    {"posting_id": 57, "kind": "deposit", "transaction_id": 9, "amount": "25.00", "balance": "74.00",
+//	     "created_at": "2025-03-10T08:30:00Z"}
+//	  ]
+//	}
+func (h *Handler) GetWalletStatement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	const op = "GetWalletStatement: "
+
+	address, code, msg := h.parseAndValidateAddress(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	from, to, code, msg := h.parseStatementPeriod(ctx, r, op)
+	if code != 0 {
+		h.writeError(ctx, w, code, domain.CodeInvalidRequestBody, msg)
+		return
+	}
+
+	h.log.Info(
+		ctx,
+		op+"received request",
+		zap.String("address", address),
+		zap.Time("from", from),
+		zap.Time("to", to),
+	)
+
+	statement, svcCode := h.walletService.GetStatement(ctx, address, from, to)
+	if svcCode != domain.CodeOK {
+		h.log.Warn(
+			ctx,
+			op+"service returned error",
+			zap.String("error_code", string(svcCode)),
+		)
+		h.handleServiceError(ctx, w, svcCode, "GetWalletStatement")
+		return
+	}
+
+	response := dto.StatementResponse{
+		Address:        statement.Address,
+		Currency:       statement.Currency,
+		From:           statement.From.Format(time.RFC3339),
+		To:             statement.To.Format(time.RFC3339),
+		OpeningBalance: statement.OpeningBalance,
+		TotalCredits:   statement.TotalCredits,
+		TotalDebits:    statement.TotalDebits,
+		ClosingBalance: statement.ClosingBalance,
+		Lines:          make([]dto.StatementLineResponse, len(statement.Lines)),
+	}
+	for i, l := range statement.Lines {
+		response.Lines[i] = dto.StatementLineResponse{
+			PostingId:     l.PostingId,
+			Kind:          string(l.Kind),
+			TransactionId: l.TransactionId,
+			Counterparty:  l.Counterparty,
+			Fee:           l.Fee,
+			Memo:          l.Memo,
+			Amount:        l.Amount,
+			Balance:       l.Balance,
+			CreatedAt:     l.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	h.log.Info(
+		ctx,
+		op+"statement retrieved successfully",
+		zap.String("address", address),
+		zap.Int("count", len(statement.Lines)),
+		zap.Stringer("closing_balance", statement.ClosingBalance),
+	)
+	h.writeJSON(ctx, w, http.StatusOK, response)
+}
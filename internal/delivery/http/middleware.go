@@ -2,28 +2,180 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	httpBase "net/http"
+	"regexp"
+	"strings"
 	"time"
 
+	"TransactionTest/internal/delivery/http/handler"
 	"TransactionTest/internal/domain"
 	"TransactionTest/internal/logger"
 
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
 
-// RequestIDMiddleware добавляет уникальный RequestID в контекст запроса
+// Заголовки корреляции запросов
+const (
+	RequestIDHeader   = "X-Request-ID"
+	TraceParentHeader = "traceparent"
+)
+
+// requestIDPattern допустимый X-Request-ID клиента: UUID или другой токен без пробелов и спецсимволов
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestIDMiddleware берет ID запроса из X-Request-ID клиента или, если заголовка нет
+// либо он некорректен, создает новый. ID добавляется в контекст запроса и возвращается в X-Request-ID ответа.
 func RequestIDMiddleware(next httpBase.Handler) httpBase.Handler {
 	return httpBase.HandlerFunc(func(w httpBase.ResponseWriter, r *httpBase.Request) {
-		requestID := uuid.New().String()
-		ctx := context.WithValue(r.Context(), logger.RequestID, requestID)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		requestID := r.Header.Get(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(logger.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// TraceContextMiddleware принимает W3C traceparent клиента, без него или с некорректным заголовком
+// начинает новую трассу. Trace context добавляется в контекст запроса и возвращается в traceparent ответа.
+func TraceContextMiddleware(next httpBase.Handler) httpBase.Handler {
+	return httpBase.HandlerFunc(func(w httpBase.ResponseWriter, r *httpBase.Request) {
+		trace, err := logger.ParseTraceParent(r.Header.Get(TraceParentHeader))
+		if err != nil {
+			trace = logger.NewTrace()
+		}
+		w.Header().Set(TraceParentHeader, trace.TraceParent())
+		next.ServeHTTP(w, r.WithContext(logger.WithTrace(r.Context(), trace)))
 	})
 }
 
-// LoggingMiddleware логирует информацию о запросах
-func LoggingMiddleware(log logger.Logger) func(httpBase.Handler) httpBase.Handler {
+// APIKeyHeader заголовок с API-ключом. Ключ также принимается в Authorization: Bearer <key>.
+const APIKeyHeader = "X-API-Key"
+
+// Authenticator проверяет API-ключ запроса, реализуется service.APIKeyService
+type Authenticator interface {
+	Authenticate(ctx context.Context, key string) (*domain.APIKey, domain.ErrorCode)
+}
+
+type contextKey string
+
+// apiKeyContextKey ключ контекста, под которым AuthMiddleware сохраняет найденный API-ключ
+const apiKeyContextKey contextKey = "APIKey"
+
+// APIKeyFromContext возвращает API-ключ запроса или nil, если запрос без ключа
+func APIKeyFromContext(ctx context.Context) *domain.APIKey {
+	key, _ := ctx.Value(apiKeyContextKey).(*domain.APIKey)
+	return key
+}
+
+// AuthMiddleware проверяет API-ключ из заголовка и добавляет его в контекст запроса, а его ID - в логи.
+// Запрос без ключа пропускается дальше: отклонить его решает RequireScope маршрута.
+// Неизвестный или отозванный ключ отклоняется с 401. С auth = nil аутентификация выключена.
+func AuthMiddleware(auth Authenticator, log logger.Logger) func(httpBase.Handler) httpBase.Handler {
+	return func(next httpBase.Handler) httpBase.Handler {
+		return httpBase.HandlerFunc(func(w httpBase.ResponseWriter, r *httpBase.Request) {
+			plain := apiKeyFromRequest(r)
+			if auth == nil || plain == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, code := auth.Authenticate(r.Context(), plain)
+			switch code {
+			case domain.CodeOK:
+			case domain.CodeUnauthorized:
+				log.Warn(r.Context(), "Invalid API key",
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.String("remote_addr", r.RemoteAddr),
+				)
+				writeUnauthorized(w, "Invalid or revoked API key")
+				return
+			default:
+				log.Error(r.Context(), "API key authentication failed", zap.String("error_code", string(code)))
+				writeMiddlewareError(w, httpBase.StatusInternalServerError, domain.CodeInternal, "Internal server error")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), apiKeyContextKey, key)
+			ctx = logger.WithAPIKeyID(ctx, key.Id)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope пропускает к обработчику только запросы с ключом, у которого есть право scope.
+// Без ключа возвращает 401, с ключом без нужного права - 403.
+func RequireScope(scope domain.Scope, log logger.Logger) func(httpBase.HandlerFunc) httpBase.HandlerFunc {
+	return func(next httpBase.HandlerFunc) httpBase.HandlerFunc {
+		return func(w httpBase.ResponseWriter, r *httpBase.Request) {
+			key := APIKeyFromContext(r.Context())
+			if key == nil {
+				log.Warn(r.Context(), "API key required",
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+				)
+				writeUnauthorized(w, "API key required")
+				return
+			}
+			if !key.Allows(scope) {
+				log.Warn(r.Context(), "API key scope missing",
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.String("scope", string(scope)),
+				)
+				writeMiddlewareError(w, httpBase.StatusForbidden, domain.CodeForbidden, fmt.Sprintf("API key lacks the %q scope", scope))
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// apiKeyFromRequest достает ключ из X-API-Key или Authorization: Bearer
+func apiKeyFromRequest(r *httpBase.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if found && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+func writeUnauthorized(w httpBase.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	writeMiddlewareError(w, httpBase.StatusUnauthorized, domain.CodeUnauthorized, message)
+}
+
+// writeMiddlewareError отвечает ошибкой в том же формате, что и обработчики
+func writeMiddlewareError(w httpBase.ResponseWriter, statusCode int, code domain.ErrorCode, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(handler.ErrorResponse{
+		Error:   httpBase.StatusText(statusCode),
+		Code:    string(code),
+		Message: message,
+	})
+}
+
+// HTTPMetrics учитывает обработанные запросы, реализуется metrics.Metrics
+type HTTPMetrics interface {
+	ObserveRequest(route, method string, status int, duration time.Duration)
+}
+
+// unmatchedRoute метка маршрута для запросов, не подошедших ни к одному маршруту (404, 405)
+const unmatchedRoute = "unmatched"
+
+// LoggingMiddleware логирует информацию о запросах и учитывает их в метриках (m может быть nil).
+// В метрики попадает шаблон маршрута, а не путь с адресами и ID; запросы без маршрута
+// учитываются под одной меткой unmatched, чтобы произвольные пути не плодили ряды.
+func LoggingMiddleware(log logger.Logger, m HTTPMetrics) func(httpBase.Handler) httpBase.Handler {
 	return func(next httpBase.Handler) httpBase.Handler {
 		return httpBase.HandlerFunc(func(w httpBase.ResponseWriter, r *httpBase.Request) {
 			start := time.Now()
@@ -35,6 +187,16 @@ func LoggingMiddleware(log logger.Logger) func(httpBase.Handler) httpBase.Handle
 
 			duration := time.Since(start)
 
+			if m != nil {
+				route := unmatchedRoute
+				if current := mux.CurrentRoute(r); current != nil {
+					if template, err := current.GetPathTemplate(); err == nil {
+						route = template
+					}
+				}
+				m.ObserveRequest(route, r.Method, wrapped.statusCode, duration)
+			}
+
 			log.Info(r.Context(), "HTTP Request",
 				zap.String("method", r.Method),
 				zap.String("path", r.URL.Path),
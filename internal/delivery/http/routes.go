@@ -1,6 +1,7 @@
 package http
 
 import (
+	"TransactionTest/internal/domain"
 	"TransactionTest/internal/logger"
 	"github.com/gorilla/mux"
 	httpBase "net/http"
@@ -8,45 +9,171 @@ import (
 
 type IHanlder interface {
 	SendMoney(w httpBase.ResponseWriter, r *httpBase.Request)
+	SendBatch(w httpBase.ResponseWriter, r *httpBase.Request)
 	GetLastTransactions(w httpBase.ResponseWriter, r *httpBase.Request)
+	ListTransactions(w httpBase.ResponseWriter, r *httpBase.Request)
 	GetBalance(w httpBase.ResponseWriter, r *httpBase.Request)
 
 	GetTransactionById(w httpBase.ResponseWriter, r *httpBase.Request)
+	ReverseTransaction(w httpBase.ResponseWriter, r *httpBase.Request)
 	RemoveTransaction(w httpBase.ResponseWriter, r *httpBase.Request)
 	GetTransactionByInfo(w httpBase.ResponseWriter, r *httpBase.Request)
+	SearchTransactions(w httpBase.ResponseWriter, r *httpBase.Request)
 
 	CreateWallet(w httpBase.ResponseWriter, r *httpBase.Request)
 	GetWallet(w httpBase.ResponseWriter, r *httpBase.Request)
 	RemoveWallet(w httpBase.ResponseWriter, r *httpBase.Request)
 	UpdateBalance(w httpBase.ResponseWriter, r *httpBase.Request)
+	GetWalletEntries(w httpBase.ResponseWriter, r *httpBase.Request)
+	GetWalletStatement(w httpBase.ResponseWriter, r *httpBase.Request)
+	FreezeWallet(w httpBase.ResponseWriter, r *httpBase.Request)
+	UnfreezeWallet(w httpBase.ResponseWriter, r *httpBase.Request)
+	CloseWallet(w httpBase.ResponseWriter, r *httpBase.Request)
+	GetWalletStatusHistory(w httpBase.ResponseWriter, r *httpBase.Request)
+	GetWalletLimits(w httpBase.ResponseWriter, r *httpBase.Request)
+	SetWalletLimits(w httpBase.ResponseWriter, r *httpBase.Request)
+	SetOverdraftLimit(w httpBase.ResponseWriter, r *httpBase.Request)
+	GetBalanceAdjustments(w httpBase.ResponseWriter, r *httpBase.Request)
+
+	Deposit(w httpBase.ResponseWriter, r *httpBase.Request)
+	Withdraw(w httpBase.ResponseWriter, r *httpBase.Request)
+
+	CreateHold(w httpBase.ResponseWriter, r *httpBase.Request)
+	GetHold(w httpBase.ResponseWriter, r *httpBase.Request)
+	CaptureHold(w httpBase.ResponseWriter, r *httpBase.Request)
+	VoidHold(w httpBase.ResponseWriter, r *httpBase.Request)
+
+	ScheduleTransfer(w httpBase.ResponseWriter, r *httpBase.Request)
+	GetScheduledTransfers(w httpBase.ResponseWriter, r *httpBase.Request)
+	CancelScheduledTransfer(w httpBase.ResponseWriter, r *httpBase.Request)
+
+	CreateMandate(w httpBase.ResponseWriter, r *httpBase.Request)
+	GetMandate(w httpBase.ResponseWriter, r *httpBase.Request)
+	CancelMandate(w httpBase.ResponseWriter, r *httpBase.Request)
+	GetMandateRuns(w httpBase.ResponseWriter, r *httpBase.Request)
+
+	Reconcile(w httpBase.ResponseWriter, r *httpBase.Request)
+
+	CreateAPIKey(w httpBase.ResponseWriter, r *httpBase.Request)
+	ListAPIKeys(w httpBase.ResponseWriter, r *httpBase.Request)
+	RevokeAPIKey(w httpBase.ResponseWriter, r *httpBase.Request)
+
+	Healthz(w httpBase.ResponseWriter, r *httpBase.Request)
+	Readyz(w httpBase.ResponseWriter, r *httpBase.Request)
 }
 
-func NewRouter(h IHanlder, log logger.Logger) *mux.Router {
+// NewRouter регистрирует маршруты API. Каждый маршрут объявляет право API-ключа, которое он требует;
+// с auth = nil аутентификация выключена и права не проверяются. m может быть nil, тогда запросы не учитываются в метриках.
+func NewRouter(h IHanlder, auth Authenticator, m HTTPMetrics, log logger.Logger) *mux.Router {
 	r := mux.NewRouter()
 
-	// Добавляем middleware
+	// Добавляем middleware. Аутентификация раньше логирования, чтобы ID ключа попал в строку HTTP Request
 	r.Use(RequestIDMiddleware)
-	r.Use(LoggingMiddleware(log))
+	r.Use(TraceContextMiddleware)
+	r.Use(AuthMiddleware(auth, log))
+	r.Use(LoggingMiddleware(log, m))
 	r.Use(RecoveryMiddleware(log))
 
+	// mux применяет middleware только к найденным маршрутам, поэтому ответы 404 и 405
+	// оборачиваются отдельно, иначе такие запросы не попадут ни в лог, ни в метрики
+	unmatched := func(next httpBase.Handler) httpBase.Handler {
+		return RequestIDMiddleware(TraceContextMiddleware(LoggingMiddleware(log, m)(next)))
+	}
+	r.NotFoundHandler = unmatched(httpBase.NotFoundHandler())
+	r.MethodNotAllowedHandler = unmatched(httpBase.HandlerFunc(func(w httpBase.ResponseWriter, _ *httpBase.Request) {
+		w.WriteHeader(httpBase.StatusMethodNotAllowed)
+	}))
+
+	scope := func(s domain.Scope, next httpBase.HandlerFunc) httpBase.HandlerFunc {
+		if auth == nil {
+			return next
+		}
+		return RequireScope(s, log)(next)
+	}
+	read := func(next httpBase.HandlerFunc) httpBase.HandlerFunc { return scope(domain.ScopeRead, next) }
+	transfer := func(next httpBase.HandlerFunc) httpBase.HandlerFunc { return scope(domain.ScopeTransfer, next) }
+	admin := func(next httpBase.HandlerFunc) httpBase.HandlerFunc { return scope(domain.ScopeAdmin, next) }
+
+	// Пробы оркестратора, API-ключ не требуется
+	r.HandleFunc("/healthz", h.Healthz).Methods(httpBase.MethodGet)
+	r.HandleFunc("/readyz", h.Readyz).Methods(httpBase.MethodGet)
+
 	api := r.PathPrefix("/api").Subrouter()
 
 	// Пути указанные в ТЗ
-	api.HandleFunc("/send", h.SendMoney).Methods(httpBase.MethodPost)
-	api.HandleFunc("/transactions", h.GetLastTransactions).Methods(httpBase.MethodGet).Queries("count", "{count}")
-	api.HandleFunc("/wallet/{address}/balance", h.GetBalance).Methods(httpBase.MethodGet)
+	api.HandleFunc("/send", transfer(h.SendMoney)).Methods(httpBase.MethodPost)
+	api.HandleFunc("/transactions", read(h.GetLastTransactions)).Methods(httpBase.MethodGet).Queries("count", "{count}").MatcherFunc(onlyCountQuery)
+	api.HandleFunc("/wallet/{address}/balance", read(h.GetBalance)).Methods(httpBase.MethodGet)
 
 	// Дополнительные пути
-	api.HandleFunc("/transaction/{id}", h.GetTransactionById).Methods(httpBase.MethodGet)
-	api.HandleFunc("/transaction/{id}", h.RemoveTransaction).Methods(httpBase.MethodDelete)
-	api.HandleFunc("/transaction/{from}/{to}/{createdAt}", h.GetTransactionByInfo).Methods(httpBase.MethodGet)
+	api.HandleFunc("/send/batch", transfer(h.SendBatch)).Methods(httpBase.MethodPost)
+	api.HandleFunc("/transaction/{id}", read(h.GetTransactionById)).Methods(httpBase.MethodGet)
+	api.HandleFunc("/transaction/{id}", transfer(h.ReverseTransaction)).Methods(httpBase.MethodDelete)
+	api.HandleFunc("/transaction/{from}/{to}/{createdAt}", read(h.GetTransactionByInfo)).Methods(httpBase.MethodGet)
+	api.HandleFunc("/transactions/search", read(h.SearchTransactions)).Methods(httpBase.MethodGet)
+	// Постраничная история с фильтрами: любой запрос к /transactions, кроме запроса из ТЗ с одним count
+	api.HandleFunc("/transactions", read(h.ListTransactions)).Methods(httpBase.MethodGet)
+
+	// Ожидает на вход - { "balance": x.x }. Начальный баланс выпускает деньги с казначейства, как пополнение,
+	// поэтому создание кошелька требует того же права admin
+	api.HandleFunc("/wallet/create", admin(h.CreateWallet)).Methods(httpBase.MethodPost)
 
-	// Ожидает на вход - { "balance": x.x }
-	api.HandleFunc("/wallet/create", h.CreateWallet).Methods(httpBase.MethodPost)
+	api.HandleFunc("/wallet/{address}", read(h.GetWallet)).Methods(httpBase.MethodGet)
+	api.HandleFunc("/wallet/{address}", admin(h.RemoveWallet)).Methods(httpBase.MethodDelete)
+	api.HandleFunc("/wallet/{address}/balance", admin(h.UpdateBalance)).Methods(httpBase.MethodPut)
+	api.HandleFunc("/wallet/{address}/adjustments", read(h.GetBalanceAdjustments)).Methods(httpBase.MethodGet).Queries("count", "{count}")
+	api.HandleFunc("/wallet/{address}/overdraft", admin(h.SetOverdraftLimit)).Methods(httpBase.MethodPut)
+	api.HandleFunc("/wallet/{address}/entries", read(h.GetWalletEntries)).Methods(httpBase.MethodGet).Queries("count", "{count}")
+	api.HandleFunc("/wallet/{address}/statement", read(h.GetWalletStatement)).Methods(httpBase.MethodGet)
 
-	api.HandleFunc("/wallet/{address}", h.GetWallet).Methods(httpBase.MethodGet)
-	api.HandleFunc("/wallet/{address}", h.RemoveWallet).Methods(httpBase.MethodDelete)
-	api.HandleFunc("/wallet/{address}/balance", h.UpdateBalance).Methods(httpBase.MethodPut)
+	// Жизненный цикл кошелька: заморозка, разморозка и закрытие с указанием причины
+	api.HandleFunc("/wallet/{address}/freeze", admin(h.FreezeWallet)).Methods(httpBase.MethodPost)
+	api.HandleFunc("/wallet/{address}/unfreeze", admin(h.UnfreezeWallet)).Methods(httpBase.MethodPost)
+	api.HandleFunc("/wallet/{address}/close", admin(h.CloseWallet)).Methods(httpBase.MethodPost)
+	api.HandleFunc("/wallet/{address}/status-history", read(h.GetWalletStatusHistory)).Methods(httpBase.MethodGet).Queries("count", "{count}")
+
+	// Пополнение и вывод средств через кошелек казначейства
+	api.HandleFunc("/wallet/{address}/deposit", admin(h.Deposit)).Methods(httpBase.MethodPost)
+	api.HandleFunc("/wallet/{address}/withdraw", admin(h.Withdraw)).Methods(httpBase.MethodPost)
+
+	// Лимиты кошелька
+	api.HandleFunc("/wallet/{address}/limits", read(h.GetWalletLimits)).Methods(httpBase.MethodGet)
+	api.HandleFunc("/wallet/{address}/limits", admin(h.SetWalletLimits)).Methods(httpBase.MethodPut)
+
+	// Резервы средств: захват проводит перевод, отмена освобождает средства
+	api.HandleFunc("/holds", transfer(h.CreateHold)).Methods(httpBase.MethodPost)
+	api.HandleFunc("/holds/{id}", read(h.GetHold)).Methods(httpBase.MethodGet)
+	api.HandleFunc("/holds/{id}/capture", transfer(h.CaptureHold)).Methods(httpBase.MethodPost)
+	api.HandleFunc("/holds/{id}/void", transfer(h.VoidHold)).Methods(httpBase.MethodPost)
+
+	// Отложенные переводы выполняются фоновой задачей
+	api.HandleFunc("/transfers/scheduled", transfer(h.ScheduleTransfer)).Methods(httpBase.MethodPost)
+	api.HandleFunc("/transfers/scheduled", read(h.GetScheduledTransfers)).Methods(httpBase.MethodGet).Queries("count", "{count}")
+	api.HandleFunc("/transfers/scheduled/{id}/cancel", transfer(h.CancelScheduledTransfer)).Methods(httpBase.MethodPost)
+
+	// Регулярные переводы (поручения)
+	api.HandleFunc("/mandates", transfer(h.CreateMandate)).Methods(httpBase.MethodPost)
+	api.HandleFunc("/mandates/{id}", read(h.GetMandate)).Methods(httpBase.MethodGet)
+	api.HandleFunc("/mandates/{id}/cancel", transfer(h.CancelMandate)).Methods(httpBase.MethodPost)
+	api.HandleFunc("/mandates/{id}/runs", read(h.GetMandateRuns)).Methods(httpBase.MethodGet).Queries("count", "{count}")
+
+	// Физическое удаление, доступно только в админском режиме
+	api.HandleFunc("/admin/transaction/{id}", admin(h.RemoveTransaction)).Methods(httpBase.MethodDelete)
+
+	// Сверка балансов кошельков с журналом
+	api.HandleFunc("/admin/reconcile", admin(h.Reconcile)).Methods(httpBase.MethodPost)
+
+	// API-ключи: сам ключ возвращается только при создании
+	api.HandleFunc("/admin/keys", admin(h.CreateAPIKey)).Methods(httpBase.MethodPost)
+	api.HandleFunc("/admin/keys", admin(h.ListAPIKeys)).Methods(httpBase.MethodGet)
+	api.HandleFunc("/admin/keys/{id}", admin(h.RevokeAPIKey)).Methods(httpBase.MethodDelete)
 
 	return r
 }
+
+// onlyCountQuery выбирает запрос из ТЗ GET /api/transactions?count=N, ответ на который - массив последних транзакций.
+// С любым другим параметром запрос уходит в постраничную историю ListTransactions.
+func onlyCountQuery(r *httpBase.Request, _ *mux.RouteMatch) bool {
+	query := r.URL.Query()
+	return len(query) == 1 && query.Has("count")
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	httpCust "TransactionTest/internal/delivery/http"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	httpBase "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// stubHandler отвечает только на создание кошелька, остальные обработчики в тестах не вызываются
+type stubHandler struct {
+	httpCust.IHanlder
+	created int
+}
+
+func (h *stubHandler) CreateWallet(w httpBase.ResponseWriter, r *httpBase.Request) {
+	h.created++
+	w.WriteHeader(httpBase.StatusCreated)
+}
+
+// stubAuthenticator выдает ключ с правами, заданными значением заголовка
+type stubAuthenticator struct{}
+
+func (stubAuthenticator) Authenticate(ctx context.Context, key string) (*domain.APIKey, domain.ErrorCode) {
+	return &domain.APIKey{Id: 1, Name: key, Scopes: []domain.Scope{domain.Scope(key)}}, domain.CodeOK
+}
+
+func newTestRouter(h httpCust.IHanlder) httpBase.Handler {
+	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(zap.FatalLevel)
+	log, _ := logger.New(&cfg)
+	return httpCust.NewRouter(h, stubAuthenticator{}, nil, log)
+}
+
+func createWallet(router httpBase.Handler, scope domain.Scope) int {
+	req := httptest.NewRequest(httpBase.MethodPost, "/api/wallet/create", strings.NewReader(`{"balance": "100"}`))
+	req.Header.Set("X-API-Key", string(scope))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestRouter_CreateWallet_TransferScopeForbidden(t *testing.T) {
+	h := &stubHandler{}
+	router := newTestRouter(h)
+
+	// Начальный баланс выпускает деньги, ключ с правом transfer кошелек создать не может
+	assert.Equal(t, httpBase.StatusForbidden, createWallet(router, domain.ScopeTransfer))
+	assert.Equal(t, 0, h.created)
+}
+
+func TestRouter_CreateWallet_AdminScope(t *testing.T) {
+	h := &stubHandler{}
+	router := newTestRouter(h)
+
+	assert.Equal(t, httpBase.StatusCreated, createWallet(router, domain.ScopeAdmin))
+	assert.Equal(t, 1, h.created)
+}
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"TransactionTest/internal/domain"
+
 	"github.com/go-playground/validator/v10"
 )
 
@@ -60,6 +62,8 @@ func getValidationMessage(field, tag, param string) string {
 		return fmt.Sprintf("%s must be a valid email address", field)
 	case "url":
 		return fmt.Sprintf("%s must be a valid URL", field)
+	case "printascii":
+		return fmt.Sprintf("%s must contain only printable ASCII characters", field)
 	case "oneof":
 		return fmt.Sprintf("%s must be one of: %s", field, param)
 	default:
@@ -73,12 +77,12 @@ func ValidateUUID(uuid string) bool {
 }
 
 // ValidateAmount проверяет сумму транзакции
-func ValidateAmount(amount float64) error {
+func ValidateAmount(amount domain.Money) error {
 	return validate.Var(amount, "gt=0")
 }
 
 // ValidateBalance проверяет баланс кошелька
-func ValidateBalance(balance float64) error {
+func ValidateBalance(balance domain.Money) error {
 	return validate.Var(balance, "gte=0")
 }
 
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scope право API-ключа. Каждый маршрут требует одно право, ScopeAdmin включает все остальные.
+type Scope string
+
+const (
+	ScopeRead     Scope = "read"     // чтение кошельков, транзакций и отчетов
+	ScopeTransfer Scope = "transfer" // переводы, резервы, отложенные и регулярные переводы
+	ScopeAdmin    Scope = "admin"    // создание кошельков, изменение балансов и лимитов, статусы кошельков, управление ключами
+)
+
+// apiKeyPrefix префикс выдаваемых ключей, по нему ключ легко узнать в логах и конфигурации
+const apiKeyPrefix = "tk_"
+
+// ParseScopes проверяет права и убирает повторы, сохраняя порядок.
+// Пустой список недопустим: ключ без прав ничего не может вызвать.
+func ParseScopes(values []string) ([]Scope, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidScope)
+	}
+	scopes := make([]Scope, 0, len(values))
+	seen := make(map[Scope]bool, len(values))
+	for _, v := range values {
+		scope := Scope(strings.TrimSpace(v))
+		switch scope {
+		case ScopeRead, ScopeTransfer, ScopeAdmin:
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrInvalidScope, v)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// APIKey ключ доступа к API. В базе хранится только хеш ключа, сам ключ показывается один раз при создании.
+type APIKey struct {
+	Id        int64
+	Name      string
+	Scopes    []Scope
+	CreatedAt time.Time
+	RevokedAt *time.Time // nil, пока ключ действует
+}
+
+// Revoked сообщает, отозван ли ключ
+func (k APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// Allows сообщает, разрешает ли ключ действие с правом scope
+func (k APIKey) Allows(scope Scope) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateAPIKey создает новый случайный ключ
+func GenerateAPIKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return apiKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+// HashAPIKey возвращает хеш ключа для хранения и поиска в базе.
+// Ключи случайные и длинные, поэтому соль и медленный хеш не нужны.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Currency валюта по ISO 4217 и число знаков после запятой в ее суммах.
+// Decimals не может превышать MoneyScale: суммы хранятся в DECIMAL(18, 2).
+type Currency struct {
+	Code     string
+	Decimals int
+}
+
+// Allows проверяет, что сумма не точнее, чем допускает валюта (например, 0.50 для JPY недопустимо)
+func (c Currency) Allows(m Money) bool {
+	return m%c.step() == 0
+}
+
+// Floor округляет сумму вниз до минимальной единицы валюты
+func (c Currency) Floor(m Money) Money {
+	step := c.step()
+	return m - m%step
+}
+
+// Ceil округляет сумму вверх до минимальной единицы валюты
+func (c Currency) Ceil(m Money) Money {
+	if rem := m % c.step(); rem > 0 {
+		return m - rem + c.step()
+	}
+	return m
+}
+
+func (c Currency) step() Money {
+	step := Money(1)
+	for i := c.Decimals; i < MoneyScale; i++ {
+		step *= 10
+	}
+	return step
+}
+
+// CurrencyRegistry справочник допустимых валют
+type CurrencyRegistry struct {
+	defaultCode string
+	byCode      map[string]Currency
+}
+
+// NewCurrencyRegistry собирает справочник валют. Код валюты по умолчанию обязан быть в списке.
+func NewCurrencyRegistry(defaultCode string, currencies []Currency) (*CurrencyRegistry, error) {
+	r := &CurrencyRegistry{
+		defaultCode: strings.ToUpper(defaultCode),
+		byCode:      make(map[string]Currency, len(currencies)),
+	}
+	for _, c := range currencies {
+		c.Code = strings.ToUpper(c.Code)
+		if !isCurrencyCode(c.Code) {
+			return nil, fmt.Errorf("%w: invalid code %q", ErrUnsupportedCurrency, c.Code)
+		}
+		if c.Decimals < 0 || c.Decimals > MoneyScale {
+			return nil, fmt.Errorf("%w: %s must have from 0 to %d decimals", ErrUnsupportedCurrency, c.Code, MoneyScale)
+		}
+		r.byCode[c.Code] = c
+	}
+	if _, ok := r.byCode[r.defaultCode]; !ok {
+		return nil, fmt.Errorf("%w: default currency %q is not in the list", ErrUnsupportedCurrency, defaultCode)
+	}
+	return r, nil
+}
+
+// Lookup возвращает валюту по коду. Пустой код означает валюту по умолчанию.
+func (r *CurrencyRegistry) Lookup(code string) (Currency, error) {
+	if code == "" {
+		code = r.defaultCode
+	}
+	c, ok := r.byCode[strings.ToUpper(code)]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, code)
+	}
+	return c, nil
+}
+
+// Default возвращает валюту по умолчанию
+func (r *CurrencyRegistry) Default() Currency {
+	return r.byCode[r.defaultCode]
+}
+
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
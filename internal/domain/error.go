@@ -17,6 +17,13 @@ var (
 	ErrNegativeBalance     = errors.New("negative balance not allowed")
 	ErrWalletAlreadyExists = errors.New("wallet address already exists")
 	ErrInsufficientFunds   = errors.New("insufficient funds")
+	ErrWalletFrozen        = errors.New("wallet is frozen")
+	ErrWalletClosed        = errors.New("wallet is closed")
+	ErrWalletStatus        = errors.New("wallet status transition not allowed")
+	ErrWalletNotEmpty      = errors.New("wallet balance is not zero")
+	ErrWalletHasHistory    = errors.New("wallet has transaction history")
+	ErrOverdraftInUse      = errors.New("overdraft limit is below credit used")
+	ErrNoTreasury          = errors.New("no treasury wallet for currency")
 )
 
 // Ошибки транзакций
@@ -25,6 +32,43 @@ var (
 	ErrInvalidTransaction  = errors.New("invalid transaction")
 	ErrTransactionConflict = errors.New("transaction conflict")
 	ErrSelfTransfer        = errors.New("cannot transfer to self")
+	ErrAlreadyReversed     = errors.New("transaction already reversed")
+	ErrDuplicateReference  = errors.New("external reference already used by sender")
+	ErrInvalidFilter       = errors.New("invalid transaction filter")
+	ErrInvalidCursor       = errors.New("invalid cursor")
+)
+
+// Ошибки резервов
+var (
+	ErrHoldNotActive = errors.New("hold is not active")
+)
+
+// Ошибки отложенных и регулярных переводов
+var (
+	ErrScheduledNotPending = errors.New("scheduled transfer is not pending")
+	ErrMandateNotActive    = errors.New("mandate is not active")
+	ErrInvalidRecurrence   = errors.New("invalid recurrence")
+)
+
+// Ошибки журнала проводок
+var (
+	ErrUnbalancedEntry = errors.New("journal entry is not balanced")
+)
+
+// Ошибки API-ключей
+var (
+	ErrInvalidScope = errors.New("invalid api key scope")
+)
+
+// Ошибки денежных сумм
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+	ErrInvalidRate         = errors.New("invalid exchange rate")
+	ErrRateNotFound        = errors.New("exchange rate not found")
+	ErrInvalidAmount       = errors.New("invalid amount")
+	ErrAmountOverflow      = errors.New("amount out of range")
+	ErrInvalidFeeSchedule  = errors.New("invalid fee schedule")
 )
 
 // коды ошибок слоя бизнесс логики
@@ -42,4 +86,41 @@ const (
 	CodeNegativeAmount      ErrorCode = "NEGATIVE_AMOUNT"
 	CodeInvalidTransaction  ErrorCode = "INVALID_TRANSACTION"
 	CodeInvalidRequestBody  ErrorCode = "INVALID_REQUEST_BODY"
+	CodeInvalidAmount       ErrorCode = "INVALID_AMOUNT"
+	CodeAmountOverflow      ErrorCode = "AMOUNT_OVERFLOW"
+	CodeTransactionConflict ErrorCode = "TRANSACTION_CONFLICT"
+	CodeIdempotencyMismatch ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeAlreadyReversed     ErrorCode = "TRANSACTION_ALREADY_REVERSED"
+	CodeForbidden           ErrorCode = "FORBIDDEN"
+	CodeBatchRejected       ErrorCode = "BATCH_REJECTED"
+	CodeUnsupportedCurrency ErrorCode = "UNSUPPORTED_CURRENCY"
+	CodeCurrencyMismatch    ErrorCode = "CURRENCY_MISMATCH"
+	CodeRateUnavailable     ErrorCode = "EXCHANGE_RATE_UNAVAILABLE"
+	CodeHoldNotFound        ErrorCode = "HOLD_NOT_FOUND"
+	CodeHoldNotActive       ErrorCode = "HOLD_NOT_ACTIVE"
+	CodeCaptureExceedsHold  ErrorCode = "CAPTURE_EXCEEDS_HOLD"
+	CodeInvalidHoldTTL      ErrorCode = "INVALID_HOLD_TTL"
+	CodeScheduledNotFound   ErrorCode = "SCHEDULED_TRANSFER_NOT_FOUND"
+	CodeScheduledNotPending ErrorCode = "SCHEDULED_TRANSFER_NOT_PENDING"
+	CodeInvalidExecuteAt    ErrorCode = "INVALID_EXECUTE_AT"
+	CodeMandateNotFound     ErrorCode = "MANDATE_NOT_FOUND"
+	CodeMandateNotActive    ErrorCode = "MANDATE_NOT_ACTIVE"
+	CodeInvalidRecurrence   ErrorCode = "INVALID_RECURRENCE"
+	CodeDuplicateReference  ErrorCode = "DUPLICATE_EXTERNAL_REFERENCE"
+	CodeWalletFrozen        ErrorCode = "WALLET_FROZEN"
+	CodeWalletClosed        ErrorCode = "WALLET_CLOSED"
+	CodeInvalidWalletStatus ErrorCode = "INVALID_WALLET_STATUS_TRANSITION"
+	CodeWalletNotEmpty      ErrorCode = "WALLET_NOT_EMPTY"
+	CodeWalletHasHolds      ErrorCode = "WALLET_HAS_ACTIVE_HOLDS"
+	CodeWalletHasHistory    ErrorCode = "WALLET_HAS_HISTORY"
+	CodeLimitExceeded       ErrorCode = "LIMIT_EXCEEDED"
+	CodeOverdraftInUse      ErrorCode = "OVERDRAFT_IN_USE"
+	CodeNoTreasury          ErrorCode = "TREASURY_NOT_CONFIGURED"
+	CodeTreasuryWallet      ErrorCode = "TREASURY_WALLET"
+	CodeUnauthorized        ErrorCode = "UNAUTHORIZED"
+	CodeAPIKeyNotFound      ErrorCode = "API_KEY_NOT_FOUND"
+	CodeInvalidScope        ErrorCode = "INVALID_SCOPE"
+	CodeInvalidFilter       ErrorCode = "INVALID_FILTER"
+	CodeInvalidCursor       ErrorCode = "INVALID_CURSOR"
+	CodeInvalidPeriod       ErrorCode = "INVALID_PERIOD"
 )
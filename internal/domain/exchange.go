@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate курс обмена с фиксированной точкой: сколько единиц валюты назначения дают за единицу исходной.
+// Хранится целым числом с RateScale знаками после запятой, что соответствует колонке DECIMAL(20, 8).
+type Rate int64
+
+const (
+	// RateScale количество знаков после запятой в курсе
+	RateScale = 8
+
+	rateFactor = 100000000
+	// spreadBase база для спреда в базисных пунктах (1 б.п. = 0.01%)
+	spreadBase = 10000
+)
+
+// ExchangeRate курс пары валют на момент Timestamp
+type ExchangeRate struct {
+	From      string
+	To        string
+	Rate      Rate
+	Timestamp time.Time
+}
+
+// Exchange детали конвертации в переводе между кошельками в разных валютах
+type Exchange struct {
+	ToCurrency string // валюта получателя
+	ToAmount   Money  // сумма, зачисленная получателю
+	Rate       Rate   // примененный курс с учетом спреда
+	RateAt     time.Time
+}
+
+// ParseRate разбирает положительный десятичный курс ("0.92", "91.5")
+func ParseRate(s string) (Rate, error) {
+	raw := strings.TrimSpace(s)
+	intPart, fracPart, _ := strings.Cut(raw, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRate, raw)
+	}
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRate, raw)
+	}
+
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > RateScale {
+		return 0, fmt.Errorf("%w: %q has more than %d fractional digits", ErrInvalidRate, raw, RateScale)
+	}
+	fracPart += strings.Repeat("0", RateScale-len(fracPart))
+
+	intPart = strings.TrimLeft(intPart, "0")
+	// DECIMAL(20, 8) вмещает не более 12 цифр в целой части
+	if len(intPart) > 20-RateScale {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRate, raw)
+	}
+
+	units, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil || units <= 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRate, raw)
+	}
+	return Rate(units), nil
+}
+
+// String возвращает курс в виде десятичной строки без лишних нулей
+func (r Rate) String() string {
+	s := fmt.Sprintf("%d.%08d", int64(r)/rateFactor, int64(r)%rateFactor)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// Convert переводит сумму по курсу. Результат округляется вниз до минимальной единицы Money.
+func (r Rate) Convert(m Money) (Money, error) {
+	converted := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(int64(r)))
+	converted.Quo(converted, big.NewInt(rateFactor))
+	if !converted.IsInt64() {
+		return 0, ErrAmountOverflow
+	}
+	result := Money(converted.Int64())
+	if err := result.Check(); err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+// WithSpread уменьшает курс на спред в базисных пунктах: клиент получает чуть меньше рыночного курса
+func (r Rate) WithSpread(bps int) Rate {
+	adjusted := new(big.Int).Mul(big.NewInt(int64(r)), big.NewInt(int64(spreadBase-bps)))
+	return Rate(adjusted.Quo(adjusted, big.NewInt(spreadBase)).Int64())
+}
+
+// Inverse возвращает обратный курс (1 / r), округленный вниз
+func (r Rate) Inverse() Rate {
+	if r <= 0 {
+		return 0
+	}
+	return Rate(int64(rateFactor) * rateFactor / int64(r))
+}
+
+// MarshalJSON сериализует курс строкой, как и Money
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(r.String())), nil
+}
+
+// Scan реализует sql.Scanner. Драйвер отдает DECIMAL в текстовом виде.
+func (r *Rate) Scan(src interface{}) error {
+	var (
+		parsed Rate
+		err    error
+	)
+	switch v := src.(type) {
+	case string:
+		parsed, err = parseStoredRate(v)
+	case []byte:
+		parsed, err = parseStoredRate(string(v))
+	case nil:
+		// Колонка rate пустая у переводов в одной валюте
+		parsed = 0
+	default:
+		return fmt.Errorf("%w: cannot scan %T into Rate", ErrInvalidRate, src)
+	}
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// parseStoredRate как ParseRate, но допускает нулевой курс, которым в запросах заменяется NULL
+func parseStoredRate(s string) (Rate, error) {
+	if strings.Trim(s, "0.") == "" {
+		return 0, nil
+	}
+	return ParseRate(s)
+}
+
+// Value реализует driver.Valuer
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
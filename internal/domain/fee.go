@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// FeeTier ступень тарифа: применяется к суммам перевода не больше UpTo. UpTo = 0 - без верхней границы.
+type FeeTier struct {
+	UpTo       Money
+	Flat       Money
+	PercentBps int // процент в базисных пунктах (100 = 1%)
+}
+
+// FeeSchedule тариф комиссии за переводы в одной валюте.
+// Комиссия = Flat + PercentBps от суммы; если заданы ступени, Flat и PercentBps берутся из первой подходящей.
+// Результат округляется вверх до минимальной единицы валюты и ограничивается Min и Max (Max = 0 - без ограничения).
+type FeeSchedule struct {
+	Currency   string
+	Collector  string // кошелек, на который зачисляется комиссия
+	Flat       Money
+	PercentBps int
+	Tiers      []FeeTier
+	Min        Money
+	Max        Money
+}
+
+// Calculate считает комиссию за перевод amount
+func (s FeeSchedule) Calculate(currency Currency, amount Money) (Money, error) {
+	flat, bps := s.Flat, s.PercentBps
+	for _, tier := range s.Tiers {
+		if tier.UpTo == 0 || amount <= tier.UpTo {
+			flat, bps = tier.Flat, tier.PercentBps
+			break
+		}
+	}
+
+	// Процент округляется вверх: amount * bps / 10000
+	percent := new(big.Int).Mul(big.NewInt(int64(amount)), big.NewInt(int64(bps)))
+	percent.Add(percent, big.NewInt(spreadBase-1))
+	percent.Quo(percent, big.NewInt(spreadBase))
+	percent.Add(percent, big.NewInt(int64(flat)))
+	if !percent.IsInt64() {
+		return 0, ErrAmountOverflow
+	}
+
+	fee := currency.Ceil(Money(percent.Int64()))
+	if fee < s.Min {
+		fee = s.Min
+	}
+	if s.Max > 0 && fee > s.Max {
+		fee = s.Max
+	}
+	if err := fee.Check(); err != nil {
+		return 0, err
+	}
+	return fee, nil
+}
+
+// validate проверяет тариф при загрузке конфигурации
+func (s FeeSchedule) validate(currency Currency) error {
+	if s.Collector == "" {
+		return fmt.Errorf("%w: %s has no collector wallet", ErrInvalidFeeSchedule, s.Currency)
+	}
+	if s.Min < 0 || s.Max < 0 || (s.Max > 0 && s.Max < s.Min) {
+		return fmt.Errorf("%w: %s has invalid min/max", ErrInvalidFeeSchedule, s.Currency)
+	}
+	amounts := []Money{s.Flat, s.Min, s.Max}
+	rates := []int{s.PercentBps}
+	for i, tier := range s.Tiers {
+		last := i == len(s.Tiers)-1
+		if (tier.UpTo == 0 && !last) || tier.UpTo < 0 || (i > 0 && tier.UpTo != 0 && tier.UpTo <= s.Tiers[i-1].UpTo) {
+			return fmt.Errorf("%w: %s tiers must be in ascending order of UpTo, only the last may be unbounded", ErrInvalidFeeSchedule, s.Currency)
+		}
+		amounts = append(amounts, tier.Flat)
+		rates = append(rates, tier.PercentBps)
+	}
+	for _, m := range amounts {
+		if m < 0 || !currency.Allows(m) {
+			return fmt.Errorf("%w: %s amount %v is not valid for the currency", ErrInvalidFeeSchedule, s.Currency, m)
+		}
+	}
+	for _, bps := range rates {
+		if bps < 0 || bps > spreadBase {
+			return fmt.Errorf("%w: %s percent must be from 0 to %d bps", ErrInvalidFeeSchedule, s.Currency, spreadBase)
+		}
+	}
+	return nil
+}
+
+// FeeSchedules тарифы комиссий по валютам. Переводы в валютах без тарифа проходят без комиссии.
+type FeeSchedules struct {
+	byCurrency map[string]FeeSchedule
+	currencies *CurrencyRegistry
+}
+
+// NewFeeSchedules проверяет тарифы и собирает их в справочник. Валюта каждого тарифа обязана быть в currencies.
+func NewFeeSchedules(schedules []FeeSchedule, currencies *CurrencyRegistry) (*FeeSchedules, error) {
+	f := &FeeSchedules{
+		byCurrency: make(map[string]FeeSchedule, len(schedules)),
+		currencies: currencies,
+	}
+	for _, s := range schedules {
+		s.Currency = strings.ToUpper(s.Currency)
+		currency, err := currencies.Lookup(s.Currency)
+		if err != nil || s.Currency == "" {
+			return nil, fmt.Errorf("%w: unknown currency %q", ErrInvalidFeeSchedule, s.Currency)
+		}
+		if _, ok := f.byCurrency[s.Currency]; ok {
+			return nil, fmt.Errorf("%w: duplicate schedule for %s", ErrInvalidFeeSchedule, s.Currency)
+		}
+		if err := s.validate(currency); err != nil {
+			return nil, err
+		}
+		f.byCurrency[s.Currency] = s
+	}
+	return f, nil
+}
+
+// Fee возвращает комиссию за перевод amount в валюте currency и кошелек, на который она зачисляется.
+// Если тарифа нет, комиссия нулевая.
+func (f *FeeSchedules) Fee(currency string, amount Money) (Money, string, error) {
+	if f == nil {
+		return 0, "", nil
+	}
+	s, ok := f.byCurrency[currency]
+	if !ok {
+		return 0, "", nil
+	}
+	c, err := f.currencies.Lookup(currency)
+	if err != nil {
+		return 0, "", err
+	}
+	fee, err := s.Calculate(c, amount)
+	if err != nil {
+		return 0, "", err
+	}
+	return fee, s.Collector, nil
+}
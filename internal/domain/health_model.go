@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+)
+
+// SeedingStatus результат создания стартовых кошельков при запуске
+type SeedingStatus string
+
+const (
+	SeedingPending       SeedingStatus = "pending"
+	SeedingDisabled      SeedingStatus = "disabled"
+	SeedingAlreadySeeded SeedingStatus = "already_seeded"
+	SeedingSeeded        SeedingStatus = "seeded"
+	SeedingFailed        SeedingStatus = "failed"
+)
+
+// SeedingStatuses все состояния создания стартовых кошельков
+var SeedingStatuses = []SeedingStatus{SeedingPending, SeedingDisabled, SeedingAlreadySeeded, SeedingSeeded, SeedingFailed}
+
+// Completed сообщает, что создание кошельков завершено и не мешает обслуживать запросы
+func (s SeedingStatus) Completed() bool {
+	return s == SeedingDisabled || s == SeedingAlreadySeeded || s == SeedingSeeded
+}
+
+// Имена проверок готовности
+const (
+	HealthCheckShutdown   = "shutdown"
+	HealthCheckDatabase   = "database"
+	HealthCheckMigrations = "migrations"
+	HealthCheckSeeding    = "seeding"
+)
+
+// HealthCheck результат одной проверки готовности
+type HealthCheck struct {
+	Name    string
+	Healthy bool
+	Latency time.Duration
+	Detail  string // состояние или причина неудачи
+}
+
+// Readiness результат проверки готовности сервиса обслуживать запросы
+type Readiness struct {
+	Ready  bool
+	Checks []HealthCheck
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+)
+
+// HoldStatus состояние резерва средств
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "active"
+	HoldCaptured HoldStatus = "captured"
+	HoldVoided   HoldStatus = "voided"
+	HoldExpired  HoldStatus = "expired"
+)
+
+// Hold резерв средств на кошельке отправителя в пользу получателя.
+// Активный резерв уменьшает доступный баланс, но не баланс по журналу.
+type Hold struct {
+	Id             int64
+	From           string
+	To             string
+	Amount         Money
+	Currency       string
+	Status         HoldStatus
+	CapturedAmount Money // сумма, списанная при захвате
+	TransactionId  int64 // перевод, созданный при захвате, 0 до захвата
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+}
+
+// IsActive сообщает, что резерв еще держит средства.
+// Резерв с прошедшим сроком читается из БД как expired, даже если фоновая задача еще не пометила его.
+func (h Hold) IsActive() bool {
+	return h.Status == HoldActive
+}
+
+// HoldRequest параметры нового резерва
+type HoldRequest struct {
+	From   string
+	To     string
+	Amount Money
+	TTL    time.Duration // 0 - срок по умолчанию из конфигурации
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Префиксы ключей идемпотентности, которые сервис выдает сам отложенным и регулярным переводам
+const (
+	scheduledKeyPrefix = "scheduled:"
+	mandateKeyPrefix   = "mandate:"
+)
+
+// IdempotencyRecord сохраненный ключ идемпотентности и результат запроса, выполненного с ним
+type IdempotencyRecord struct {
+	Key           string
+	RequestHash   string
+	TransactionId int64
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+}
+
+// ReservedIdempotencyKey сообщает, что ключ занят под внутренние переводы. Клиентские и внутренние ключи
+// хранятся вместе, и клиент, заранее занявший ключ запуска, подменил бы его перевод своим.
+func ReservedIdempotencyKey(key string) bool {
+	return strings.HasPrefix(key, scheduledKeyPrefix) || strings.HasPrefix(key, mandateKeyPrefix)
+}
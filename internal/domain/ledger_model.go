@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// EntryKind тип записи журнала
+type EntryKind string
+
+const (
+	EntryTransfer   EntryKind = "transfer"
+	EntryOpening    EntryKind = "opening"
+	EntryAdjustment EntryKind = "adjustment"
+	EntryReversal   EntryKind = "reversal"
+	EntryDeposit    EntryKind = "deposit"
+	EntryWithdrawal EntryKind = "withdrawal"
+	// EntryCorrection запись сверки: вносит в журнал расхождение с сохраненным балансом, баланс кошелька не меняет
+	EntryCorrection EntryKind = "correction"
+)
+
+// Системные счета. Не являются кошельками и не имеют баланса в wallets.
+const (
+	systemAccountPrefix = "system:"
+
+	// AccountEquity счет-источник для начальных балансов и ручных корректировок
+	AccountEquity = systemAccountPrefix + "equity"
+	// AccountFX счет обменных операций: принимает исходную валюту и выдает валюту получателя
+	AccountFX = systemAccountPrefix + "fx"
+)
+
+// IsSystemAccount сообщает, что счет системный, а не адрес кошелька
+func IsSystemAccount(account string) bool {
+	return strings.HasPrefix(account, systemAccountPrefix)
+}
+
+// Posting проводка по одному счету. Amount > 0 - кредит (баланс растет), Amount < 0 - дебет.
+type Posting struct {
+	Id            int64
+	EntryId       int64
+	Kind          EntryKind
+	TransactionId int64 // 0, если запись не связана с транзакцией
+	Account       string
+	Currency      string
+	Amount        Money
+	CreatedAt     time.Time
+}
+
+// JournalEntry запись журнала - набор проводок, в сумме дающих ноль по каждой валюте
+type JournalEntry struct {
+	Kind          EntryKind
+	TransactionId int64
+	Postings      []Posting
+}
+
+// Validate проверяет, что запись содержит хотя бы две ненулевые проводки и сбалансирована по каждой валюте
+func (e JournalEntry) Validate() error {
+	if len(e.Postings) < 2 {
+		return ErrUnbalancedEntry
+	}
+	sums := make(map[string]Money, 1)
+	for _, p := range e.Postings {
+		if p.Amount == 0 || p.Account == "" || p.Currency == "" {
+			return ErrUnbalancedEntry
+		}
+		sums[p.Currency] += p.Amount
+	}
+	for _, sum := range sums {
+		if sum != 0 {
+			return ErrUnbalancedEntry
+		}
+	}
+	return nil
+}
+
+// NewTransferEntry запись журнала для перевода amount в валюте currency со счета from на счет to
+func NewTransferEntry(kind EntryKind, transactionId int64, from, to, currency string, amount Money) JournalEntry {
+	return JournalEntry{
+		Kind:          kind,
+		TransactionId: transactionId,
+		Postings: []Posting{
+			{Account: from, Currency: currency, Amount: -amount},
+			{Account: to, Currency: currency, Amount: amount},
+		},
+	}
+}
+
+// NewExchangeEntry запись журнала для перевода с конвертацией: amount в валюте currency списывается
+// с from на счет обмена, а exchange.ToAmount в валюте получателя зачисляется со счета обмена на to.
+// Каждая валюта сбалансирована отдельно.
+func NewExchangeEntry(kind EntryKind, transactionId int64, from, to, currency string, amount Money, exchange Exchange) JournalEntry {
+	return JournalEntry{
+		Kind:          kind,
+		TransactionId: transactionId,
+		Postings: []Posting{
+			{Account: from, Currency: currency, Amount: -amount},
+			{Account: AccountFX, Currency: currency, Amount: amount},
+			{Account: AccountFX, Currency: exchange.ToCurrency, Amount: -exchange.ToAmount},
+			{Account: to, Currency: exchange.ToCurrency, Amount: exchange.ToAmount},
+		},
+	}
+}
+
+// WithFee добавляет к записи списание комиссии fee с плательщика payer на кошелек collector
+func (e JournalEntry) WithFee(payer, collector, currency string, fee Money) JournalEntry {
+	if fee == 0 {
+		return e
+	}
+	e.Postings = append(e.Postings,
+		Posting{Account: payer, Currency: currency, Amount: -fee},
+		Posting{Account: collector, Currency: currency, Amount: fee},
+	)
+	return e
+}
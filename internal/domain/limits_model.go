@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// LimitKind вид лимита кошелька, возвращается клиенту вместе с LIMIT_EXCEEDED
+type LimitKind string
+
+const (
+	LimitMaxTransfer     LimitKind = "max_transfer"      // сумма одного перевода
+	LimitMaxDailyOutflow LimitKind = "max_daily_outflow" // сумма исходящих переводов за окно
+	LimitMaxDailyCount   LimitKind = "max_daily_count"   // число исходящих переводов за окно
+	LimitMaxBalance      LimitKind = "max_balance"       // баланс получателя после зачисления
+)
+
+// WalletLimits лимиты кошелька в его валюте. Нулевое значение - без ограничения.
+type WalletLimits struct {
+	MaxTransfer     Money
+	MaxDailyOutflow Money
+	MaxDailyCount   int
+	MaxBalance      Money
+}
+
+// Validate проверяет, что лимиты не отрицательные и помещаются в DECIMAL(18, 2)
+func (l WalletLimits) Validate() error {
+	for _, m := range []Money{l.MaxTransfer, l.MaxDailyOutflow, l.MaxBalance} {
+		if m < 0 {
+			return fmt.Errorf("%w: limit %v must not be negative", ErrInvalidInput, m)
+		}
+		if err := m.Check(); err != nil {
+			return err
+		}
+	}
+	if l.MaxDailyCount < 0 {
+		return fmt.Errorf("%w: daily count %d must not be negative", ErrInvalidInput, l.MaxDailyCount)
+	}
+	return nil
+}
+
+// CheckOutgoing проверяет исходящий перевод amount с комиссией fee, если за окно уже ушло used.
+// Комиссия учитывается в сумме за окно, но не в лимите одного перевода.
+// Возвращает нарушенный лимит или пустую строку.
+func (l WalletLimits) CheckOutgoing(amount, fee Money, used Outflow) LimitKind {
+	switch {
+	case l.MaxTransfer > 0 && amount > l.MaxTransfer:
+		return LimitMaxTransfer
+	case l.MaxDailyCount > 0 && used.Count+1 > l.MaxDailyCount:
+		return LimitMaxDailyCount
+	case l.MaxDailyOutflow > 0 && used.Amount+amount+fee > l.MaxDailyOutflow:
+		return LimitMaxDailyOutflow
+	}
+	return ""
+}
+
+// CheckIncoming проверяет, что баланс после зачисления amount не превысит MaxBalance
+func (l WalletLimits) CheckIncoming(balance, amount Money) LimitKind {
+	if l.MaxBalance > 0 && balance+amount > l.MaxBalance {
+		return LimitMaxBalance
+	}
+	return ""
+}
+
+// LimitOverrides лимиты, заданные для конкретного кошелька. nil - действует значение по умолчанию из конфигурации.
+type LimitOverrides struct {
+	MaxTransfer     *Money
+	MaxDailyOutflow *Money
+	MaxDailyCount   *int
+	MaxBalance      *Money
+}
+
+// Apply накладывает заданные для кошелька лимиты на значения по умолчанию
+func (o LimitOverrides) Apply(defaults WalletLimits) WalletLimits {
+	limits := defaults
+	if o.MaxTransfer != nil {
+		limits.MaxTransfer = *o.MaxTransfer
+	}
+	if o.MaxDailyOutflow != nil {
+		limits.MaxDailyOutflow = *o.MaxDailyOutflow
+	}
+	if o.MaxDailyCount != nil {
+		limits.MaxDailyCount = *o.MaxDailyCount
+	}
+	if o.MaxBalance != nil {
+		limits.MaxBalance = *o.MaxBalance
+	}
+	return limits
+}
+
+// Kinds возвращает виды лимитов, заданных для кошелька
+func (o LimitOverrides) Kinds() []LimitKind {
+	kinds := make([]LimitKind, 0, 4)
+	if o.MaxTransfer != nil {
+		kinds = append(kinds, LimitMaxTransfer)
+	}
+	if o.MaxDailyOutflow != nil {
+		kinds = append(kinds, LimitMaxDailyOutflow)
+	}
+	if o.MaxDailyCount != nil {
+		kinds = append(kinds, LimitMaxDailyCount)
+	}
+	if o.MaxBalance != nil {
+		kinds = append(kinds, LimitMaxBalance)
+	}
+	return kinds
+}
+
+// LimitPolicy лимиты по умолчанию и скользящее окно для дневных лимитов
+type LimitPolicy struct {
+	Defaults WalletLimits
+	Window   time.Duration
+}
+
+// Outflow исходящие переводы кошелька за окно: сумма с комиссиями и число переводов
+type Outflow struct {
+	Amount Money
+	Count  int
+}
+
+// WalletLimitSettings лимиты кошелька: заданные для него и действующие с учетом значений по умолчанию
+type WalletLimitSettings struct {
+	Address   string
+	Overrides LimitOverrides
+	Effective WalletLimits
+	Window    time.Duration
+}
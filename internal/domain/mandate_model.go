@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// MandateStatus состояние регулярного перевода
+type MandateStatus string
+
+const (
+	MandateActive    MandateStatus = "active"
+	MandateCompleted MandateStatus = "completed" // исчерпан лимит запусков или наступила дата окончания
+	MandateCanceled  MandateStatus = "canceled"
+)
+
+// MandateRunStatus результат одной попытки регулярного перевода
+type MandateRunStatus string
+
+const (
+	MandateRunSucceeded MandateRunStatus = "succeeded"
+	MandateRunRetrying  MandateRunStatus = "retrying" // попытка не удалась, будет повтор
+	MandateRunFailed    MandateRunStatus = "failed"   // попытки исчерпаны, запуск пропущен
+)
+
+// Mandate поручение на регулярный перевод ("50 с A на B каждый понедельник до даты X").
+// Каждое срабатывание расписания - запуск (Occurrence), у запуска может быть несколько попыток (Attempt).
+type Mandate struct {
+	Id         int64
+	From       string
+	To         string
+	Amount     Money
+	Convert    bool
+	Recurrence Recurrence
+	StartAt    time.Time
+	EndAt      *time.Time // nil - без даты окончания
+	MaxRuns    int        // 0 - без ограничения числа запусков
+	Status     MandateStatus
+	RunsCount  int // завершенных запусков (успешных и неудачных)
+	Occurrence int // номер текущего запуска, с 1
+	Attempt    int // номер попытки текущего запуска, с 1
+	// ScheduledAt время текущего запуска по расписанию, NextRunAt - когда выполнить следующую попытку.
+	// Они расходятся, пока запуск повторяется после неудачи. У завершенного поручения NextRunAt = nil.
+	ScheduledAt time.Time
+	NextRunAt   *time.Time
+	CreatedAt   time.Time
+}
+
+// MandateRequest параметры нового регулярного перевода
+type MandateRequest struct {
+	From       string
+	To         string
+	Amount     Money
+	Convert    bool
+	Recurrence Recurrence
+	StartAt    time.Time // нулевое - с текущего момента
+	EndAt      *time.Time
+	MaxRuns    int
+}
+
+// NewMandate создает активное поручение с первым запуском не раньше now
+func NewMandate(req MandateRequest, now time.Time) (Mandate, error) {
+	if err := req.Recurrence.Validate(); err != nil {
+		return Mandate{}, err
+	}
+	if req.MaxRuns < 0 {
+		return Mandate{}, fmt.Errorf("%w: max runs must not be negative", ErrInvalidRecurrence)
+	}
+	start := req.StartAt
+	if start.IsZero() {
+		start = now
+	}
+	start = start.UTC()
+
+	m := Mandate{
+		From:       req.From,
+		To:         req.To,
+		Amount:     req.Amount,
+		Convert:    req.Convert,
+		Recurrence: req.Recurrence,
+		StartAt:    start,
+		MaxRuns:    req.MaxRuns,
+		Status:     MandateActive,
+		Occurrence: 1,
+		Attempt:    1,
+	}
+	if req.EndAt != nil {
+		end := req.EndAt.UTC()
+		m.EndAt = &end
+	}
+
+	// Срабатывания в прошлом не догоняем
+	after := start.Add(-time.Nanosecond)
+	if now.After(after) {
+		after = now.Add(-time.Nanosecond)
+	}
+	first := req.Recurrence.Next(start, after)
+	if first.IsZero() || m.pastEnd(first) {
+		return Mandate{}, fmt.Errorf("%w: schedule has no runs before end date", ErrInvalidRecurrence)
+	}
+	m.ScheduledAt = first
+	m.NextRunAt = &first
+	return m, nil
+}
+
+// TransferRequest возвращает запрос перевода для текущей попытки.
+// Ключ идемпотентности привязан только к запуску: если перевод прошел, а вызывающий код получил ошибку,
+// следующая попытка того же запуска вернет уже проведенный перевод вместо второго списания.
+// Неудачная попытка ключ не сохраняет, поэтому повтор после нее выполняется заново.
+func (m Mandate) TransferRequest() TransferRequest {
+	return TransferRequest{
+		From:           m.From,
+		To:             m.To,
+		Amount:         m.Amount,
+		Convert:        m.Convert,
+		IdempotencyKey: fmt.Sprintf(mandateKeyPrefix+"%d:%d", m.Id, m.Occurrence),
+	}
+}
+
+// Advance завершает текущий запуск и переходит к следующему срабатыванию расписания.
+// Если лимит запусков исчерпан или следующее срабатывание позже даты окончания, поручение завершается.
+func (m *Mandate) Advance() {
+	m.RunsCount++
+	m.Occurrence++
+	m.Attempt = 1
+
+	next := m.Recurrence.Next(m.StartAt, m.ScheduledAt)
+	if (m.MaxRuns > 0 && m.RunsCount >= m.MaxRuns) || next.IsZero() || m.pastEnd(next) {
+		m.Status = MandateCompleted
+		m.NextRunAt = nil
+		return
+	}
+	m.ScheduledAt = next
+	m.NextRunAt = &next
+}
+
+// Retry откладывает следующую попытку текущего запуска до at
+func (m *Mandate) Retry(at time.Time) {
+	m.Attempt++
+	at = at.UTC()
+	m.NextRunAt = &at
+}
+
+func (m Mandate) pastEnd(t time.Time) bool {
+	return m.EndAt != nil && t.After(*m.EndAt)
+}
+
+// MandateRun попытка выполнения регулярного перевода
+type MandateRun struct {
+	Id            int64
+	MandateId     int64
+	Occurrence    int
+	Attempt       int
+	ScheduledAt   time.Time
+	Status        MandateRunStatus
+	TransactionId int64     // созданный перевод, 0 для неудачной попытки
+	FailureCode   ErrorCode // причина неудачи
+	ExecutedAt    time.Time
+}
@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Money денежная сумма с фиксированной точкой.
+// Хранится целым числом минимальных единиц (сотых долей), что соответствует колонкам DECIMAL(18, 2).
+// В JSON и в БД передается строкой вида "100.50", поэтому никаких потерь точности на float64 нет.
+type Money int64
+
+const (
+	// MoneyScale количество знаков после запятой
+	MoneyScale = 2
+	// MaxMoney максимальное по модулю значение, помещающееся в DECIMAL(18, 2)
+	MaxMoney Money = 999999999999999999
+
+	moneyFactor = 100
+)
+
+// ParseMoney разбирает десятичную строку в Money.
+// Отклоняет суммы с более чем двумя значащими знаками после запятой (ErrInvalidAmount)
+// и суммы, которые не помещаются в DECIMAL(18, 2) (ErrAmountOverflow).
+func ParseMoney(s string) (Money, error) {
+	raw := strings.TrimSpace(s)
+	str := raw
+	if str == "" {
+		return 0, fmt.Errorf("%w: empty value", ErrInvalidAmount)
+	}
+
+	negative := false
+	switch str[0] {
+	case '-':
+		negative = true
+		str = str[1:]
+	case '+':
+		str = str[1:]
+	}
+
+	intPart, fracPart, hasDot := strings.Cut(str, ".")
+	if intPart == "" && (!hasDot || fracPart == "") {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, raw)
+	}
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, raw)
+	}
+
+	// Нули в конце дробной части точность не меняют ("1.500" == "1.50")
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > MoneyScale {
+		return 0, fmt.Errorf("%w: %q has more than %d fractional digits", ErrInvalidAmount, raw, MoneyScale)
+	}
+	fracPart += strings.Repeat("0", MoneyScale-len(fracPart))
+
+	intPart = strings.TrimLeft(intPart, "0")
+	// DECIMAL(18, 2) вмещает не более 16 цифр в целой части
+	if len(intPart) > 18-MoneyScale {
+		return 0, fmt.Errorf("%w: %q", ErrAmountOverflow, raw)
+	}
+
+	units, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, raw)
+	}
+	if negative {
+		units = -units
+	}
+
+	return Money(units), nil
+}
+
+// MustParseMoney как ParseMoney, но паникует при ошибке. Предназначена для констант и тестов.
+func MustParseMoney(s string) Money {
+	m, err := ParseMoney(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String возвращает сумму в виде десятичной строки с двумя знаками после запятой
+func (m Money) String() string {
+	sign := ""
+	units := int64(m)
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, units/moneyFactor, units%moneyFactor)
+}
+
+// Check проверяет, что сумма помещается в DECIMAL(18, 2)
+func (m Money) Check() error {
+	if m > MaxMoney || m < -MaxMoney {
+		return ErrAmountOverflow
+	}
+	return nil
+}
+
+// MarshalJSON сериализует сумму строкой, чтобы клиенты не теряли точность
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(m.String())), nil
+}
+
+// UnmarshalJSON принимает как строку ("100.50"), так и число (100.50).
+// Число разбирается по его текстовому представлению, без промежуточного float64.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidAmount, s)
+		}
+		s = unquoted
+	}
+
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Scan реализует sql.Scanner. Драйвер отдает DECIMAL в текстовом виде.
+func (m *Money) Scan(src interface{}) error {
+	var (
+		parsed Money
+		err    error
+	)
+	switch v := src.(type) {
+	case string:
+		parsed, err = ParseMoney(v)
+	case []byte:
+		parsed, err = ParseMoney(string(v))
+	case int64:
+		if v > int64(MaxMoney/moneyFactor) || v < -int64(MaxMoney/moneyFactor) {
+			return ErrAmountOverflow
+		}
+		parsed = Money(v * moneyFactor)
+	case nil:
+		return fmt.Errorf("%w: cannot scan NULL into Money", ErrInvalidAmount)
+	default:
+		return fmt.Errorf("%w: cannot scan %T into Money", ErrInvalidAmount, src)
+	}
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value реализует driver.Valuer
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"time"
+)
+
+// BalanceCheck сохраненный баланс кошелька и баланс, который дает журнал проводок
+type BalanceCheck struct {
+	Address  string
+	Currency string
+	Stored   Money // wallets.balance
+	Expected Money // сумма проводок по счету кошелька
+}
+
+// Drift расхождение сохраненного баланса с журналом, > 0 - в кошельке больше, чем объясняет история
+func (c BalanceCheck) Drift() Money {
+	return c.Stored - c.Expected
+}
+
+// CorrectionEntry запись журнала, которая признает расхождение: зачисляет drift на кошелек со счета капитала.
+// Проводится без изменения wallets.balance, после нее журнал сходится с сохраненным балансом.
+func (c BalanceCheck) CorrectionEntry() JournalEntry {
+	return NewTransferEntry(EntryCorrection, 0, AccountEquity, c.Address, c.Currency, c.Drift())
+}
+
+// WalletDrift кошелек с расхождением в отчете сверки
+type WalletDrift struct {
+	BalanceCheck
+	Repaired bool // записана корректирующая запись
+}
+
+// ReconciliationReport отчет сверки балансов кошельков с журналом
+type ReconciliationReport struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Repair     bool // сверка в режиме исправления
+	Checked    int  // проверено кошельков
+	Drifts     []WalletDrift
+}
+
+// Repaired число исправленных кошельков
+func (r ReconciliationReport) Repaired() int {
+	repaired := 0
+	for _, d := range r.Drifts {
+		if d.Repaired {
+			repaired++
+		}
+	}
+	return repaired
+}
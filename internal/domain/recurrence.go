@@ -0,0 +1,237 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency периодичность регулярного перевода
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "daily"
+	FrequencyWeekly  Frequency = "weekly"
+	FrequencyMonthly Frequency = "monthly"
+	FrequencyCron    Frequency = "cron"
+)
+
+// cronHorizon насколько далеко ищется следующее срабатывание cron-выражения.
+// Выражение вроде "0 0 30 2 *" не срабатывает никогда, и поиск не должен быть бесконечным.
+const cronHorizon = 5 * 366 * 24 * time.Hour
+
+// Recurrence расписание регулярного перевода: каждые Interval дней/недель/месяцев
+// от момента начала либо по cron-выражению из пяти полей (минута, час, день месяца, месяц, день недели).
+// Все вычисления ведутся в UTC.
+type Recurrence struct {
+	Frequency Frequency
+	Interval  int    // для daily/weekly/monthly, не меньше 1
+	Cron      string // для cron
+}
+
+// Validate проверяет расписание
+func (r Recurrence) Validate() error {
+	switch r.Frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+		if r.Interval < 1 {
+			return fmt.Errorf("%w: interval must be at least 1", ErrInvalidRecurrence)
+		}
+		if r.Cron != "" {
+			return fmt.Errorf("%w: cron is allowed only with frequency cron", ErrInvalidRecurrence)
+		}
+	case FrequencyCron:
+		if _, err := ParseCron(r.Cron); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: unknown frequency %q", ErrInvalidRecurrence, r.Frequency)
+	}
+	return nil
+}
+
+// Next возвращает первое срабатывание расписания строго после after и не раньше start.
+// Срабатывания daily/weekly/monthly отсчитываются от start, поэтому не накапливают сдвиг;
+// месячное срабатывание на 31-е число в коротком месяце переносится на последний день месяца.
+// Нулевое время означает, что срабатываний больше нет.
+func (r Recurrence) Next(start, after time.Time) time.Time {
+	start = start.UTC()
+	after = after.UTC()
+	if after.Before(start) {
+		after = start.Add(-time.Nanosecond)
+	}
+
+	if r.Frequency == FrequencyCron {
+		schedule, err := ParseCron(r.Cron)
+		if err != nil {
+			return time.Time{}
+		}
+		return schedule.Next(after)
+	}
+
+	// Оценка номера срабатывания снизу, дальше досчитываем по одному
+	var n int
+	switch r.Frequency {
+	case FrequencyDaily:
+		n = int(after.Sub(start) / (24 * time.Hour * time.Duration(r.Interval)))
+	case FrequencyWeekly:
+		n = int(after.Sub(start) / (7 * 24 * time.Hour * time.Duration(r.Interval)))
+	case FrequencyMonthly:
+		months := (after.Year()-start.Year())*12 + int(after.Month()-start.Month())
+		n = months / r.Interval
+	default:
+		return time.Time{}
+	}
+	if n > 0 {
+		n--
+	}
+	for {
+		occurrence := r.occurrence(start, n)
+		if occurrence.After(after) {
+			return occurrence
+		}
+		n++
+	}
+}
+
+// occurrence возвращает n-е (с нуля) срабатывание календарного расписания
+func (r Recurrence) occurrence(start time.Time, n int) time.Time {
+	switch r.Frequency {
+	case FrequencyDaily:
+		return start.AddDate(0, 0, n*r.Interval)
+	case FrequencyWeekly:
+		return start.AddDate(0, 0, 7*n*r.Interval)
+	default:
+		return addMonthsClamped(start, n*r.Interval)
+	}
+}
+
+// addMonthsClamped прибавляет месяцы, не перескакивая в следующий месяц (31 января + 1 = 28/29 февраля)
+func addMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	first = first.AddDate(0, months, 0)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// CronSchedule разобранное cron-выражение: множества допустимых значений каждого поля
+type CronSchedule struct {
+	minutes, hours, days, months, weekdays uint64
+	// По правилам cron, если ограничены и день месяца, и день недели, достаточно совпадения любого из них
+	anyDay, anyWeekday bool
+}
+
+// ParseCron разбирает cron-выражение из пяти полей.
+// Поддерживаются *, числа, диапазоны a-b, шаг */n и a-b/n и списки через запятую.
+// День недели 0-7, где и 0, и 7 - воскресенье.
+func ParseCron(expr string) (CronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return CronSchedule{}, fmt.Errorf("%w: cron %q must have 5 fields", ErrInvalidRecurrence, expr)
+	}
+
+	var s CronSchedule
+	var err error
+	if s.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return CronSchedule{}, fmt.Errorf("%w: cron %q minute: %v", ErrInvalidRecurrence, expr, err)
+	}
+	if s.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return CronSchedule{}, fmt.Errorf("%w: cron %q hour: %v", ErrInvalidRecurrence, expr, err)
+	}
+	if s.days, err = parseCronField(fields[2], 1, 31); err != nil {
+		return CronSchedule{}, fmt.Errorf("%w: cron %q day of month: %v", ErrInvalidRecurrence, expr, err)
+	}
+	if s.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return CronSchedule{}, fmt.Errorf("%w: cron %q month: %v", ErrInvalidRecurrence, expr, err)
+	}
+	if s.weekdays, err = parseCronField(fields[4], 0, 7); err != nil {
+		return CronSchedule{}, fmt.Errorf("%w: cron %q day of week: %v", ErrInvalidRecurrence, expr, err)
+	}
+	if s.weekdays&(1<<7) != 0 {
+		s.weekdays |= 1
+	}
+	s.anyDay = strings.HasPrefix(fields[2], "*")
+	s.anyWeekday = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			loPart, hiPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(loPart); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiPart); err != nil {
+					return 0, fmt.Errorf("invalid value %q", part)
+				}
+			} else if hasStep {
+				// "5/15" означает с 5 до конца диапазона с шагом 15
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("value %q out of range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// Next возвращает первое срабатывание строго после after (с точностью до минуты) либо нулевое время
+func (s CronSchedule) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronHorizon)
+
+	for t.Before(limit) {
+		if s.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s CronSchedule) dayMatches(t time.Time) bool {
+	day := s.days&(1<<uint(t.Day())) != 0
+	weekday := s.weekdays&(1<<uint(t.Weekday())) != 0
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
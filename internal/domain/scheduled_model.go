@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScheduledStatus состояние отложенного перевода
+type ScheduledStatus string
+
+const (
+	ScheduledPending   ScheduledStatus = "pending"
+	ScheduledRunning   ScheduledStatus = "running"
+	ScheduledSucceeded ScheduledStatus = "succeeded"
+	ScheduledFailed    ScheduledStatus = "failed"
+	ScheduledCanceled  ScheduledStatus = "canceled"
+)
+
+// ScheduledTransfer перевод, который фоновая задача выполнит не раньше ExecuteAt
+type ScheduledTransfer struct {
+	Id            int64
+	From          string
+	To            string
+	Amount        Money
+	Convert       bool
+	ExecuteAt     time.Time
+	Status        ScheduledStatus
+	TransactionId int64     // созданный перевод, 0 до успешного выполнения
+	FailureCode   ErrorCode // причина неудачи, пусто для остальных состояний
+	CreatedAt     time.Time
+	ExecutedAt    *time.Time // nil до выполнения
+}
+
+// TransferRequest возвращает запрос перевода для выполнения.
+// Ключ идемпотентности привязан к ID, поэтому повторное выполнение после сбоя не создаст второй перевод.
+func (s ScheduledTransfer) TransferRequest() TransferRequest {
+	return TransferRequest{
+		From:           s.From,
+		To:             s.To,
+		Amount:         s.Amount,
+		Convert:        s.Convert,
+		IdempotencyKey: fmt.Sprintf(scheduledKeyPrefix+"%d", s.Id),
+	}
+}
+
+// ScheduledTransferRequest параметры нового отложенного перевода
+type ScheduledTransferRequest struct {
+	From      string
+	To        string
+	Amount    Money
+	Convert   bool
+	ExecuteAt time.Time
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"time"
+)
+
+// StatementLine операция выписки - одна проводка журнала по кошельку
+type StatementLine struct {
+	PostingId     int64
+	Kind          EntryKind
+	TransactionId int64  // 0 для записей без транзакции: открытие счета, корректировки, сверка
+	Counterparty  string // другая сторона транзакции, пусто для записей без транзакции
+	Fee           bool   // проводка комиссии перевода
+	Memo          string
+	Amount        Money // > 0 - кредит, < 0 - дебет
+	Balance       Money // баланс кошелька после проводки
+	CreatedAt     time.Time
+}
+
+// Statement выписка по кошельку за период [From, To).
+// ClosingBalance = OpeningBalance + TotalCredits - TotalDebits.
+type Statement struct {
+	Address        string
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance Money // баланс до первой проводки периода
+	TotalCredits   Money
+	TotalDebits    Money // по модулю
+	ClosingBalance Money
+	Lines          []StatementLine
+}
+
+// NewStatement собирает выписку из баланса на начало периода и проводок периода в хронологическом порядке:
+// проставляет баланс после каждой проводки, обороты и баланс на конец периода
+func NewStatement(address, currency string, from, to time.Time, opening Money, lines []StatementLine) *Statement {
+	s := &Statement{
+		Address:        address,
+		Currency:       currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		ClosingBalance: opening,
+		Lines:          lines,
+	}
+	for i := range s.Lines {
+		amount := s.Lines[i].Amount
+		if amount > 0 {
+			s.TotalCredits += amount
+		} else {
+			s.TotalDebits -= amount
+		}
+		s.ClosingBalance += amount
+		s.Lines[i].Balance = s.ClosingBalance
+	}
+	return s
+}
@@ -0,0 +1,46 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := domain.ParseScopes([]string{"read", " transfer", "read"})
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Scope{domain.ScopeRead, domain.ScopeTransfer}, scopes)
+
+	_, err = domain.ParseScopes([]string{"read", "write"})
+	assert.True(t, errors.Is(err, domain.ErrInvalidScope))
+
+	_, err = domain.ParseScopes(nil)
+	assert.True(t, errors.Is(err, domain.ErrInvalidScope))
+}
+
+func TestAPIKey_Allows(t *testing.T) {
+	reader := domain.APIKey{Scopes: []domain.Scope{domain.ScopeRead}}
+	assert.True(t, reader.Allows(domain.ScopeRead))
+	assert.False(t, reader.Allows(domain.ScopeTransfer))
+	assert.False(t, reader.Allows(domain.ScopeAdmin))
+
+	// admin включает остальные права
+	admin := domain.APIKey{Scopes: []domain.Scope{domain.ScopeAdmin}}
+	assert.True(t, admin.Allows(domain.ScopeRead))
+	assert.True(t, admin.Allows(domain.ScopeTransfer))
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	first, err := domain.GenerateAPIKey()
+	assert.NoError(t, err)
+	second, err := domain.GenerateAPIKey()
+	assert.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, "tk_"))
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, domain.HashAPIKey(first), domain.HashAPIKey(first))
+	assert.NotEqual(t, domain.HashAPIKey(first), domain.HashAPIKey(second))
+	assert.NotContains(t, domain.HashAPIKey(first), first)
+}
@@ -0,0 +1,48 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCurrency_Allows(t *testing.T) {
+	usd := domain.Currency{Code: "USD", Decimals: 2}
+	jpy := domain.Currency{Code: "JPY", Decimals: 0}
+
+	assert.True(t, usd.Allows(domain.MustParseMoney("10.55")))
+	assert.True(t, jpy.Allows(domain.MustParseMoney("10")))
+	assert.False(t, jpy.Allows(domain.MustParseMoney("10.50")))
+	assert.False(t, jpy.Allows(domain.MustParseMoney("0.01")))
+}
+
+func TestCurrencyRegistry_Lookup(t *testing.T) {
+	r, err := domain.NewCurrencyRegistry("usd", []domain.Currency{
+		{Code: "USD", Decimals: 2},
+		{Code: "jpy", Decimals: 0},
+	})
+	assert.NoError(t, err)
+
+	c, err := r.Lookup("")
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", c.Code)
+
+	c, err = r.Lookup("JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, c.Decimals)
+
+	_, err = r.Lookup("EUR")
+	assert.True(t, errors.Is(err, domain.ErrUnsupportedCurrency))
+}
+
+func TestCurrencyRegistry_InvalidConfig(t *testing.T) {
+	_, err := domain.NewCurrencyRegistry("EUR", []domain.Currency{{Code: "USD", Decimals: 2}})
+	assert.True(t, errors.Is(err, domain.ErrUnsupportedCurrency), "default must be in the list")
+
+	_, err = domain.NewCurrencyRegistry("USD", []domain.Currency{{Code: "US", Decimals: 2}})
+	assert.True(t, errors.Is(err, domain.ErrUnsupportedCurrency), "code must have three letters")
+
+	_, err = domain.NewCurrencyRegistry("USD", []domain.Currency{{Code: "USD", Decimals: 3}})
+	assert.True(t, errors.Is(err, domain.ErrUnsupportedCurrency), "decimals above MoneyScale")
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	rate, err := domain.ParseRate("0.92")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Rate(92000000), rate)
+	assert.Equal(t, "0.92", rate.String())
+
+	rate, err = domain.ParseRate("149.8")
+	assert.NoError(t, err)
+	assert.Equal(t, "149.8", rate.String())
+
+	for _, s := range []string{"", "0", "-1", "abc", "0.000000001", "1e5"} {
+		_, err := domain.ParseRate(s)
+		assert.True(t, errors.Is(err, domain.ErrInvalidRate), s)
+	}
+}
+
+func TestRate_Convert(t *testing.T) {
+	rate, _ := domain.ParseRate("0.9154")
+	converted, err := rate.Convert(domain.MustParseMoney("100"))
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MustParseMoney("91.54"), converted)
+
+	// Округление вниз до сотых
+	converted, err = rate.Convert(domain.MustParseMoney("0.01"))
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Money(0), converted)
+
+	big, _ := domain.ParseRate("1000")
+	_, err = big.Convert(domain.MaxMoney)
+	assert.True(t, errors.Is(err, domain.ErrAmountOverflow))
+}
+
+func TestRate_WithSpreadAndInverse(t *testing.T) {
+	rate, _ := domain.ParseRate("0.92")
+	assert.Equal(t, "0.9154", rate.WithSpread(50).String())
+	assert.Equal(t, rate, rate.WithSpread(0))
+
+	two, _ := domain.ParseRate("2")
+	assert.Equal(t, "0.5", two.Inverse().String())
+}
+
+func TestTransaction_ReversedExchange(t *testing.T) {
+	rate, _ := domain.ParseRate("0.9154")
+	rateAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	original := domain.Transaction{
+		Id: 1, From: "from", To: "to", Amount: domain.MustParseMoney("100"), Currency: "USD",
+		Exchange: &domain.Exchange{ToCurrency: "EUR", ToAmount: domain.MustParseMoney("91.54"), Rate: rate, RateAt: rateAt},
+	}
+
+	reversal := original.Reversed()
+	assert.Equal(t, "to", reversal.From)
+	assert.Equal(t, "from", reversal.To)
+	assert.Equal(t, domain.MustParseMoney("91.54"), reversal.Amount)
+	assert.Equal(t, "EUR", reversal.Currency)
+	assert.Equal(t, "USD", reversal.Exchange.ToCurrency)
+	assert.Equal(t, domain.MustParseMoney("100"), reversal.Exchange.ToAmount)
+
+	entry := reversal.Entry(domain.EntryReversal)
+	assert.NoError(t, entry.Validate())
+	assert.Len(t, entry.Postings, 4)
+}
+
+func TestNewExchangeEntry(t *testing.T) {
+	exchange := domain.Exchange{ToCurrency: "EUR", ToAmount: domain.MustParseMoney("91.54")}
+	entry := domain.NewExchangeEntry(domain.EntryTransfer, 1, "from", "to", "USD", domain.MustParseMoney("100"), exchange)
+	assert.NoError(t, entry.Validate())
+	assert.Equal(t, []domain.Posting{
+		{Account: "from", Currency: "USD", Amount: -domain.MustParseMoney("100")},
+		{Account: domain.AccountFX, Currency: "USD", Amount: domain.MustParseMoney("100")},
+		{Account: domain.AccountFX, Currency: "EUR", Amount: -domain.MustParseMoney("91.54")},
+		{Account: "to", Currency: "EUR", Amount: domain.MustParseMoney("91.54")},
+	}, entry.Postings)
+}
+
+func TestTransferRequest_FingerprintConvert(t *testing.T) {
+	plain := domain.TransferRequest{From: "a", To: "b", Amount: 100}
+	converted := plain
+	converted.Convert = true
+	assert.NotEqual(t, plain.Fingerprint(), converted.Fingerprint())
+}
+
+func TestTransferRequest_FingerprintDetails(t *testing.T) {
+	plain := domain.TransferRequest{From: "a", To: "b", Amount: 100}
+	withDetails := plain
+	withDetails.Details = domain.TransferDetails{ExternalReference: "order-1", Metadata: map[string]interface{}{"a": 1, "b": 2}}
+	assert.NotEqual(t, plain.Fingerprint(), withDetails.Fingerprint())
+
+	// Порядок ключей metadata не влияет на хэш
+	reordered := plain
+	reordered.Details = domain.TransferDetails{ExternalReference: "order-1", Metadata: map[string]interface{}{"b": 2, "a": 1}}
+	assert.Equal(t, withDetails.Fingerprint(), reordered.Fingerprint())
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func feeCurrencies(t *testing.T) *domain.CurrencyRegistry {
+	currencies, err := domain.NewCurrencyRegistry("USD", []domain.Currency{{Code: "USD", Decimals: 2}, {Code: "JPY", Decimals: 0}})
+	assert.NoError(t, err)
+	return currencies
+}
+
+func TestFeeSchedule_Calculate(t *testing.T) {
+	usd := domain.Currency{Code: "USD", Decimals: 2}
+	m := domain.MustParseMoney
+
+	flat := domain.FeeSchedule{Flat: m("0.30")}
+	fee, err := flat.Calculate(usd, m("1000"))
+	assert.NoError(t, err)
+	assert.Equal(t, m("0.30"), fee)
+
+	// 1.5% + 0.30, процент округляется вверх до цента
+	percent := domain.FeeSchedule{Flat: m("0.30"), PercentBps: 150}
+	fee, _ = percent.Calculate(usd, m("10.01"))
+	assert.Equal(t, m("0.46"), fee)
+
+	capped := domain.FeeSchedule{PercentBps: 100, Min: m("0.50"), Max: m("20")}
+	fee, _ = capped.Calculate(usd, m("10"))
+	assert.Equal(t, m("0.50"), fee)
+	fee, _ = capped.Calculate(usd, m("100"))
+	assert.Equal(t, m("1"), fee)
+	fee, _ = capped.Calculate(usd, m("5000"))
+	assert.Equal(t, m("20"), fee)
+
+	tiered := domain.FeeSchedule{Tiers: []domain.FeeTier{
+		{UpTo: m("100"), Flat: m("0.50")},
+		{UpTo: m("1000"), PercentBps: 50},
+		{PercentBps: 25},
+	}}
+	fee, _ = tiered.Calculate(usd, m("100"))
+	assert.Equal(t, m("0.50"), fee)
+	fee, _ = tiered.Calculate(usd, m("200"))
+	assert.Equal(t, m("1"), fee)
+	fee, _ = tiered.Calculate(usd, m("2000"))
+	assert.Equal(t, m("5"), fee)
+}
+
+func TestFeeSchedule_CalculateRoundsToCurrency(t *testing.T) {
+	jpy := domain.Currency{Code: "JPY", Decimals: 0}
+	fee, err := domain.FeeSchedule{PercentBps: 100}.Calculate(jpy, domain.MustParseMoney("150"))
+	assert.NoError(t, err)
+	// 1.50 JPY округляется вверх до целых иен
+	assert.Equal(t, domain.MustParseMoney("2"), fee)
+}
+
+func TestFeeSchedules_Fee(t *testing.T) {
+	fees, err := domain.NewFeeSchedules([]domain.FeeSchedule{
+		{Currency: "usd", Collector: "fees", Flat: domain.MustParseMoney("1")},
+	}, feeCurrencies(t))
+	assert.NoError(t, err)
+
+	fee, collector, err := fees.Fee("USD", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MustParseMoney("1"), fee)
+	assert.Equal(t, "fees", collector)
+
+	fee, collector, err = fees.Fee("JPY", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Money(0), fee)
+	assert.Equal(t, "", collector)
+
+	var none *domain.FeeSchedules
+	fee, _, err = none.Fee("USD", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Money(0), fee)
+}
+
+func TestNewFeeSchedules_Invalid(t *testing.T) {
+	m := domain.MustParseMoney
+	cases := map[string]domain.FeeSchedule{
+		"unknown currency": {Currency: "GBP", Collector: "fees"},
+		"no collector":     {Currency: "USD"},
+		"min above max":    {Currency: "USD", Collector: "fees", Min: m("5"), Max: m("1")},
+		"percent too big":  {Currency: "USD", Collector: "fees", PercentBps: 10001},
+		"fraction for JPY": {Currency: "JPY", Collector: "fees", Flat: m("0.50")},
+		"unbounded tier first": {Currency: "USD", Collector: "fees", Tiers: []domain.FeeTier{
+			{Flat: m("1")}, {UpTo: m("100"), Flat: m("2")},
+		}},
+		"tiers not ascending": {Currency: "USD", Collector: "fees", Tiers: []domain.FeeTier{
+			{UpTo: m("100")}, {UpTo: m("50")},
+		}},
+	}
+	for name, schedule := range cases {
+		_, err := domain.NewFeeSchedules([]domain.FeeSchedule{schedule}, feeCurrencies(t))
+		assert.True(t, errors.Is(err, domain.ErrInvalidFeeSchedule), name)
+	}
+
+	_, err := domain.NewFeeSchedules([]domain.FeeSchedule{
+		{Currency: "USD", Collector: "a"}, {Currency: "USD", Collector: "b"},
+	}, feeCurrencies(t))
+	assert.True(t, errors.Is(err, domain.ErrInvalidFeeSchedule))
+}
+
+func TestTransaction_ReversedKeepsFee(t *testing.T) {
+	original := domain.Transaction{Id: 1, From: "from", To: "to", Amount: domain.MustParseMoney("10"), Currency: "USD", Fee: domain.MustParseMoney("1")}
+
+	reversal := original.Reversed()
+	assert.Zero(t, reversal.Fee)
+	assert.Equal(t, domain.MustParseMoney("10"), reversal.Amount)
+	assert.Equal(t, []domain.Posting{
+		{Account: "to", Currency: "USD", Amount: -domain.MustParseMoney("10")},
+		{Account: "from", Currency: "USD", Amount: domain.MustParseMoney("10")},
+	}, reversal.Entry(domain.EntryReversal).Postings)
+}
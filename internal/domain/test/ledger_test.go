@@ -0,0 +1,27 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestJournalEntry_Validate(t *testing.T) {
+	assert.NoError(t, domain.NewTransferEntry(domain.EntryTransfer, 1, "from", "to", "USD", 10).Validate())
+
+	cases := map[string]domain.JournalEntry{
+		"single posting": {Postings: []domain.Posting{{Account: "a", Amount: 10}}},
+		"unbalanced":     {Postings: []domain.Posting{{Account: "a", Amount: -10}, {Account: "b", Amount: 9}}},
+		"zero posting":   {Postings: []domain.Posting{{Account: "a", Amount: 0}, {Account: "b", Amount: 0}}},
+		"empty account":  {Postings: []domain.Posting{{Account: "", Amount: -10}, {Account: "b", Amount: 10}}},
+	}
+	for name, entry := range cases {
+		assert.True(t, errors.Is(entry.Validate(), domain.ErrUnbalancedEntry), name)
+	}
+}
+
+func TestIsSystemAccount(t *testing.T) {
+	assert.True(t, domain.IsSystemAccount(domain.AccountEquity))
+	assert.False(t, domain.IsSystemAccount("550e8400-e29b-41d4-a716-446655440000"))
+}
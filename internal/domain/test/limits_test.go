@@ -0,0 +1,51 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWalletLimits_CheckOutgoing(t *testing.T) {
+	m := domain.MustParseMoney
+	limits := domain.WalletLimits{MaxTransfer: m("100"), MaxDailyOutflow: m("250"), MaxDailyCount: 3}
+
+	assert.Equal(t, domain.LimitKind(""), limits.CheckOutgoing(m("100"), m("1"), domain.Outflow{Amount: m("100"), Count: 1}))
+	assert.Equal(t, domain.LimitMaxTransfer, limits.CheckOutgoing(m("100.01"), 0, domain.Outflow{}))
+	assert.Equal(t, domain.LimitMaxDailyCount, limits.CheckOutgoing(m("1"), 0, domain.Outflow{Amount: m("10"), Count: 3}))
+	// Комиссия входит в дневную сумму
+	assert.Equal(t, domain.LimitMaxDailyOutflow, limits.CheckOutgoing(m("50"), m("0.01"), domain.Outflow{Amount: m("200"), Count: 1}))
+
+	// Нулевые лимиты не ограничивают
+	assert.Equal(t, domain.LimitKind(""), domain.WalletLimits{}.CheckOutgoing(m("1000000"), 0, domain.Outflow{Amount: m("1000000"), Count: 1000}))
+}
+
+func TestWalletLimits_CheckIncoming(t *testing.T) {
+	m := domain.MustParseMoney
+	limits := domain.WalletLimits{MaxBalance: m("500")}
+
+	assert.Equal(t, domain.LimitKind(""), limits.CheckIncoming(m("400"), m("100")))
+	assert.Equal(t, domain.LimitMaxBalance, limits.CheckIncoming(m("400"), m("100.01")))
+	assert.Equal(t, domain.LimitKind(""), domain.WalletLimits{}.CheckIncoming(m("400"), m("100.01")))
+}
+
+func TestLimitOverrides_Apply(t *testing.T) {
+	m := domain.MustParseMoney
+	defaults := domain.WalletLimits{MaxTransfer: m("100"), MaxDailyOutflow: m("250"), MaxDailyCount: 3, MaxBalance: m("1000")}
+
+	noLimit := domain.Money(0)
+	count := 10
+	overrides := domain.LimitOverrides{MaxTransfer: &noLimit, MaxDailyCount: &count}
+
+	assert.Equal(t, domain.WalletLimits{MaxTransfer: 0, MaxDailyOutflow: m("250"), MaxDailyCount: 10, MaxBalance: m("1000")}, overrides.Apply(defaults))
+	assert.Equal(t, []domain.LimitKind{domain.LimitMaxTransfer, domain.LimitMaxDailyCount}, overrides.Kinds())
+	assert.Equal(t, defaults, domain.LimitOverrides{}.Apply(defaults))
+}
+
+func TestWalletLimits_Validate(t *testing.T) {
+	assert.NoError(t, domain.WalletLimits{MaxTransfer: 100}.Validate())
+	assert.True(t, errors.Is(domain.WalletLimits{MaxBalance: -1}.Validate(), domain.ErrInvalidInput))
+	assert.True(t, errors.Is(domain.WalletLimits{MaxDailyCount: -1}.Validate(), domain.ErrInvalidInput))
+	assert.True(t, errors.Is(domain.WalletLimits{MaxTransfer: domain.MaxMoney + 1}.Validate(), domain.ErrAmountOverflow))
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMoney_Parse_Success(t *testing.T) {
+	cases := map[string]domain.Money{
+		"0":                   0,
+		"100":                 10000,
+		"100.5":               10050,
+		"100.50":              10050,
+		"0.01":                1,
+		".99":                 99,
+		"-12.34":              -1234,
+		"1.500":               150,
+		"9999999999999999.99": domain.MaxMoney,
+	}
+	for in, want := range cases {
+		got, err := domain.ParseMoney(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+}
+
+func TestMoney_Parse_TooManyFractionalDigits(t *testing.T) {
+	_, err := domain.ParseMoney("0.001")
+	assert.True(t, errors.Is(err, domain.ErrInvalidAmount))
+}
+
+func TestMoney_Parse_Overflow(t *testing.T) {
+	_, err := domain.ParseMoney("10000000000000000")
+	assert.True(t, errors.Is(err, domain.ErrAmountOverflow))
+}
+
+func TestMoney_Parse_Invalid(t *testing.T) {
+	for _, in := range []string{"", ".", "abc", "1e5", "1,5", "--1"} {
+		_, err := domain.ParseMoney(in)
+		assert.True(t, errors.Is(err, domain.ErrInvalidAmount), in)
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, "100.50", domain.Money(10050).String())
+	assert.Equal(t, "0.05", domain.Money(5).String())
+	assert.Equal(t, "-1.20", domain.Money(-120).String())
+}
+
+func TestMoney_JSON(t *testing.T) {
+	var v struct {
+		Amount domain.Money `json:"amount"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":"0.10"}`), &v))
+	assert.Equal(t, domain.Money(10), v.Amount)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":0.3}`), &v))
+	assert.Equal(t, domain.Money(30), v.Amount)
+
+	err := json.Unmarshal([]byte(`{"amount":1.005}`), &v)
+	assert.True(t, errors.Is(err, domain.ErrInvalidAmount))
+
+	out, err := json.Marshal(v)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"0.30"}`, string(out))
+}
+
+func TestMoney_Scan(t *testing.T) {
+	var m domain.Money
+	assert.NoError(t, m.Scan("123.45"))
+	assert.Equal(t, domain.Money(12345), m)
+
+	assert.NoError(t, m.Scan([]byte("0.10")))
+	assert.Equal(t, domain.Money(10), m)
+
+	assert.NoError(t, m.Scan(int64(7)))
+	assert.Equal(t, domain.Money(700), m)
+
+	assert.Error(t, m.Scan(nil))
+	assert.Error(t, m.Scan(1.5))
+}
+
+func TestMoney_Check(t *testing.T) {
+	assert.NoError(t, domain.MaxMoney.Check())
+	assert.True(t, errors.Is((domain.MaxMoney+1).Check(), domain.ErrAmountOverflow))
+}
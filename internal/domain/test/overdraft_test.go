@@ -0,0 +1,21 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWallet_Overdraft(t *testing.T) {
+	m := domain.MustParseMoney
+
+	w := domain.Wallet{Balance: m("30"), Held: m("10"), OverdraftLimit: m("100")}
+	assert.Equal(t, m("120"), w.Available())
+	assert.Equal(t, domain.Money(0), w.CreditUsed())
+	assert.Equal(t, m("100"), w.CreditAvailable())
+
+	w = domain.Wallet{Balance: m("-40"), OverdraftLimit: m("100")}
+	assert.Equal(t, m("60"), w.Available())
+	assert.Equal(t, m("40"), w.CreditUsed())
+	assert.Equal(t, m("60"), w.CreditAvailable())
+}
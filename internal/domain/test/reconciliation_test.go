@@ -0,0 +1,31 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBalanceCheck_CorrectionEntry(t *testing.T) {
+	// Сохраненный баланс меньше журнала - корректировка списывает разницу с кошелька
+	check := domain.BalanceCheck{Address: "addr", Currency: "EUR", Stored: 70, Expected: 100}
+	assert.Equal(t, domain.Money(-30), check.Drift())
+
+	entry := check.CorrectionEntry()
+	assert.NoError(t, entry.Validate())
+	assert.Equal(t, domain.EntryCorrection, entry.Kind)
+
+	var walletSum domain.Money
+	for _, p := range entry.Postings {
+		if p.Account == "addr" {
+			walletSum += p.Amount
+		}
+	}
+	// После корректировки журнал сходится с сохраненным балансом
+	assert.Equal(t, check.Stored, check.Expected+walletSum)
+}
+
+func TestReconciliationReport_Repaired(t *testing.T) {
+	report := domain.ReconciliationReport{Drifts: []domain.WalletDrift{{Repaired: true}, {}, {Repaired: true}}}
+	assert.Equal(t, 2, report.Repaired())
+}
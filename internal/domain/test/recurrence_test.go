@@ -0,0 +1,137 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day, hour, min int) time.Time {
+	return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+}
+
+func TestRecurrence_Validate(t *testing.T) {
+	assert.NoError(t, domain.Recurrence{Frequency: domain.FrequencyDaily, Interval: 1}.Validate())
+	assert.NoError(t, domain.Recurrence{Frequency: domain.FrequencyCron, Cron: "0 9 * * 1-5"}.Validate())
+
+	invalid := []domain.Recurrence{
+		{Frequency: domain.FrequencyWeekly},
+		{Frequency: domain.FrequencyMonthly, Interval: 1, Cron: "0 9 * * *"},
+		{Frequency: domain.FrequencyCron, Cron: "0 9 * *"},
+		{Frequency: domain.FrequencyCron, Cron: "60 9 * * *"},
+		{Frequency: domain.FrequencyCron, Cron: "*/0 * * * *"},
+		{Frequency: "yearly", Interval: 1},
+	}
+	for _, r := range invalid {
+		assert.True(t, errors.Is(r.Validate(), domain.ErrInvalidRecurrence), "%+v", r)
+	}
+}
+
+func TestRecurrence_Next_Calendar(t *testing.T) {
+	start := date(2026, 10, 5, 9, 0)
+
+	daily := domain.Recurrence{Frequency: domain.FrequencyDaily, Interval: 2}
+	assert.Equal(t, start, daily.Next(start, start.Add(-time.Hour)))
+	assert.Equal(t, date(2026, 10, 7, 9, 0), daily.Next(start, start))
+	assert.Equal(t, date(2026, 10, 11, 9, 0), daily.Next(start, date(2026, 10, 9, 9, 0)))
+
+	weekly := domain.Recurrence{Frequency: domain.FrequencyWeekly, Interval: 1}
+	assert.Equal(t, date(2026, 10, 19, 9, 0), weekly.Next(start, date(2026, 10, 16, 0, 0)))
+
+	// 31 января + месяц = 28 февраля, но март снова 31-го: сдвиг не накапливается
+	endOfMonth := date(2026, 1, 31, 12, 0)
+	monthly := domain.Recurrence{Frequency: domain.FrequencyMonthly, Interval: 1}
+	assert.Equal(t, date(2026, 2, 28, 12, 0), monthly.Next(endOfMonth, endOfMonth))
+	assert.Equal(t, date(2026, 3, 31, 12, 0), monthly.Next(endOfMonth, date(2026, 2, 28, 12, 0)))
+
+	quarterly := domain.Recurrence{Frequency: domain.FrequencyMonthly, Interval: 3}
+	assert.Equal(t, date(2027, 1, 31, 12, 0), quarterly.Next(endOfMonth, date(2026, 11, 1, 0, 0)))
+}
+
+func TestRecurrence_Next_Cron(t *testing.T) {
+	// По будням в 09:30
+	weekdays := domain.Recurrence{Frequency: domain.FrequencyCron, Cron: "30 9 * * 1-5"}
+	friday := date(2026, 10, 16, 10, 0)
+	assert.Equal(t, date(2026, 10, 19, 9, 30), weekdays.Next(time.Time{}, friday))
+
+	// Каждые 15 минут
+	quarter := domain.Recurrence{Frequency: domain.FrequencyCron, Cron: "*/15 * * * *"}
+	assert.Equal(t, date(2026, 10, 16, 10, 15), quarter.Next(time.Time{}, friday))
+
+	// 1-го числа или по воскресеньям (7 = 0): достаточно совпадения любого из дней
+	either := domain.Recurrence{Frequency: domain.FrequencyCron, Cron: "0 0 1 * 7"}
+	assert.Equal(t, date(2026, 10, 18, 0, 0), either.Next(time.Time{}, friday))
+	assert.Equal(t, date(2026, 11, 1, 0, 0), either.Next(time.Time{}, date(2026, 10, 25, 0, 0)))
+
+	// Срабатывания не раньше начала
+	assert.Equal(t, date(2026, 11, 2, 9, 30), weekdays.Next(date(2026, 11, 1, 0, 0), friday))
+
+	// 30 февраля не наступает никогда
+	never := domain.Recurrence{Frequency: domain.FrequencyCron, Cron: "0 0 30 2 *"}
+	assert.True(t, never.Next(time.Time{}, friday).IsZero())
+}
+
+func TestNewMandate(t *testing.T) {
+	now := date(2026, 10, 16, 10, 0)
+	weekly := domain.Recurrence{Frequency: domain.FrequencyWeekly, Interval: 1}
+
+	// Начало в прошлом: пропущенные срабатывания не догоняются
+	m, err := domain.NewMandate(domain.MandateRequest{From: "a", To: "b", Amount: 100, Recurrence: weekly, StartAt: date(2026, 10, 5, 9, 0)}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MandateActive, m.Status)
+	assert.Equal(t, date(2026, 10, 19, 9, 0), m.ScheduledAt)
+	assert.Equal(t, date(2026, 10, 19, 9, 0), *m.NextRunAt)
+	assert.Equal(t, 1, m.Occurrence)
+	assert.Equal(t, 1, m.Attempt)
+
+	// Без начала - первый запуск сразу
+	m, err = domain.NewMandate(domain.MandateRequest{From: "a", To: "b", Amount: 100, Recurrence: weekly}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, now, m.ScheduledAt)
+
+	end := date(2026, 10, 18, 0, 0)
+	_, err = domain.NewMandate(domain.MandateRequest{From: "a", To: "b", Amount: 100, Recurrence: weekly, StartAt: date(2026, 10, 5, 9, 0), EndAt: &end}, now)
+	assert.True(t, errors.Is(err, domain.ErrInvalidRecurrence))
+
+	_, err = domain.NewMandate(domain.MandateRequest{From: "a", To: "b", Amount: 100, Recurrence: weekly, MaxRuns: -1}, now)
+	assert.True(t, errors.Is(err, domain.ErrInvalidRecurrence))
+}
+
+func TestMandate_Advance(t *testing.T) {
+	now := date(2026, 10, 16, 10, 0)
+	daily := domain.Recurrence{Frequency: domain.FrequencyDaily, Interval: 1}
+
+	m, err := domain.NewMandate(domain.MandateRequest{From: "a", To: "b", Amount: 100, Recurrence: daily, StartAt: now, MaxRuns: 2}, now)
+	assert.NoError(t, err)
+	m.Id = 7
+	assert.Equal(t, "mandate:7:1", m.TransferRequest().IdempotencyKey)
+
+	m.Retry(now.Add(time.Hour))
+	assert.Equal(t, 2, m.Attempt)
+	assert.Equal(t, now, m.ScheduledAt)
+	assert.Equal(t, now.Add(time.Hour), *m.NextRunAt)
+	// Попытки одного запуска используют общий ключ, чтобы повтор не списал средства второй раз
+	assert.Equal(t, "mandate:7:1", m.TransferRequest().IdempotencyKey)
+
+	// Следующий запуск считается от расписания, а не от времени повтора
+	m.Advance()
+	assert.Equal(t, 1, m.RunsCount)
+	assert.Equal(t, 2, m.Occurrence)
+	assert.Equal(t, "mandate:7:2", m.TransferRequest().IdempotencyKey)
+	assert.Equal(t, 1, m.Attempt)
+	assert.Equal(t, date(2026, 10, 17, 10, 0), *m.NextRunAt)
+
+	m.Advance()
+	assert.Equal(t, domain.MandateCompleted, m.Status)
+	assert.Nil(t, m.NextRunAt)
+
+	end := date(2026, 10, 17, 12, 0)
+	m, _ = domain.NewMandate(domain.MandateRequest{From: "a", To: "b", Amount: 100, Recurrence: daily, StartAt: now, EndAt: &end}, now)
+	m.Advance()
+	assert.Equal(t, domain.MandateActive, m.Status)
+	m.Advance()
+	assert.Equal(t, domain.MandateCompleted, m.Status)
+	assert.Equal(t, 2, m.RunsCount)
+}
@@ -0,0 +1,38 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewStatement_RunningBalance(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	lines := []domain.StatementLine{
+		{PostingId: 1, Amount: domain.MustParseMoney("-50")},
+		{PostingId: 2, Amount: domain.MustParseMoney("-1"), Fee: true},
+		{PostingId: 3, Amount: domain.MustParseMoney("25")},
+	}
+
+	s := domain.NewStatement("addr", "USD", from, to, domain.MustParseMoney("100"), lines)
+
+	assert.Equal(t, domain.MustParseMoney("100"), s.OpeningBalance)
+	assert.Equal(t, domain.MustParseMoney("25"), s.TotalCredits)
+	assert.Equal(t, domain.MustParseMoney("51"), s.TotalDebits)
+	assert.Equal(t, domain.MustParseMoney("74"), s.ClosingBalance)
+	assert.Equal(t, domain.MustParseMoney("50"), s.Lines[0].Balance)
+	assert.Equal(t, domain.MustParseMoney("49"), s.Lines[1].Balance)
+	assert.Equal(t, domain.MustParseMoney("74"), s.Lines[2].Balance)
+	assert.Equal(t, s.OpeningBalance+s.TotalCredits-s.TotalDebits, s.ClosingBalance)
+}
+
+func TestNewStatement_NoLines(t *testing.T) {
+	s := domain.NewStatement("addr", "USD", time.Time{}, time.Now(), domain.MustParseMoney("-10"), nil)
+
+	assert.Equal(t, domain.MustParseMoney("-10"), s.ClosingBalance)
+	assert.Zero(t, s.TotalCredits)
+	assert.Zero(t, s.TotalDebits)
+	assert.Empty(t, s.Lines)
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"encoding/base64"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTransactionCursor_RoundTrip(t *testing.T) {
+	cursor := domain.TransactionCursor{
+		Order:     domain.SortAsc,
+		CreatedAt: time.Date(2025, 3, 3, 12, 30, 0, 123456000, time.UTC),
+		Id:        42,
+	}
+	parsed, err := domain.ParseTransactionCursor(cursor.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, parsed)
+}
+
+func TestParseTransactionCursor_Invalid(t *testing.T) {
+	for _, raw := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("v2|desc|1|1")),
+		base64.RawURLEncoding.EncodeToString([]byte("v1|sideways|1|1")),
+		base64.RawURLEncoding.EncodeToString([]byte("v1|desc|x|1")),
+		base64.RawURLEncoding.EncodeToString([]byte("v1|desc|1|0")),
+	} {
+		_, err := domain.ParseTransactionCursor(raw)
+		assert.True(t, errors.Is(err, domain.ErrInvalidCursor), raw)
+	}
+}
+
+func TestTransactionFilter_Normalize(t *testing.T) {
+	f := domain.TransactionFilter{}.Normalize()
+	assert.Equal(t, domain.SortDesc, f.Order)
+	assert.Equal(t, domain.WalletRoleAny, f.Role)
+	assert.Equal(t, domain.DefaultTransactionPageSize, f.Limit)
+	assert.NoError(t, f.Validate())
+}
+
+func TestTransactionFilter_Validate(t *testing.T) {
+	at := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	valid := domain.TransactionFilter{}.Normalize()
+
+	cases := []struct {
+		name   string
+		modify func(f *domain.TransactionFilter)
+		err    error
+	}{
+		{"role without wallet", func(f *domain.TransactionFilter) { f.Role = domain.WalletRoleSender }, domain.ErrInvalidFilter},
+		{"limit too large", func(f *domain.TransactionFilter) { f.Limit = domain.MaxTransactionPageSize + 1 }, domain.ErrInvalidFilter},
+		{"min above max", func(f *domain.TransactionFilter) { f.MinAmount, f.MaxAmount = 200, 100 }, domain.ErrInvalidFilter},
+		{"empty range", func(f *domain.TransactionFilter) { f.CreatedFrom, f.CreatedTo = at, at }, domain.ErrInvalidFilter},
+		{"cursor of other order", func(f *domain.TransactionFilter) {
+			f.After = &domain.TransactionCursor{Order: domain.SortAsc, CreatedAt: at, Id: 1}
+		}, domain.ErrInvalidCursor},
+	}
+	for _, c := range cases {
+		f := valid
+		c.modify(&f)
+		assert.True(t, errors.Is(f.Validate(), c.err), c.name)
+	}
+
+	f := valid
+	f.MinAmount = 100
+	assert.NoError(t, f.Validate(), "min without max")
+}
@@ -0,0 +1,68 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func treasuryCurrencies(t *testing.T) *domain.CurrencyRegistry {
+	r, err := domain.NewCurrencyRegistry("USD", []domain.Currency{
+		{Code: "USD", Decimals: 2},
+		{Code: "EUR", Decimals: 2},
+	})
+	assert.NoError(t, err)
+	return r
+}
+
+func TestTreasury_Wallet(t *testing.T) {
+	treasury, err := domain.NewTreasury([]domain.TreasuryWallet{
+		{Currency: "usd", Address: "t-usd"},
+		{Currency: "EUR", Address: "t-eur"},
+	}, treasuryCurrencies(t))
+	assert.NoError(t, err)
+
+	address, ok := treasury.Wallet("USD")
+	assert.True(t, ok)
+	assert.Equal(t, "t-usd", address)
+
+	_, ok = treasury.Wallet("JPY")
+	assert.False(t, ok)
+
+	assert.Equal(t, []domain.TreasuryWallet{
+		{Currency: "EUR", Address: "t-eur"},
+		{Currency: "USD", Address: "t-usd"},
+	}, treasury.Wallets())
+
+	// Без секции treasury пополнения и выводы недоступны
+	var empty *domain.Treasury
+	_, ok = empty.Wallet("USD")
+	assert.False(t, ok)
+}
+
+func TestTreasury_InvalidConfig(t *testing.T) {
+	cases := [][]domain.TreasuryWallet{
+		{{Currency: "", Address: "t"}},
+		{{Currency: "JPY", Address: "t"}},
+		{{Currency: "USD", Address: ""}},
+		{{Currency: "USD", Address: "a"}, {Currency: "usd", Address: "b"}},
+		{{Currency: "USD", Address: "a"}, {Currency: "EUR", Address: "a"}},
+	}
+	for _, wallets := range cases {
+		_, err := domain.NewTreasury(wallets, treasuryCurrencies(t))
+		assert.True(t, errors.Is(err, domain.ErrInvalidInput), "%v", wallets)
+	}
+}
+
+func TestWallet_CanSpend(t *testing.T) {
+	m := domain.MustParseMoney
+
+	w := domain.Wallet{Balance: m("10")}
+	assert.True(t, w.CanSpend(m("10")))
+	assert.False(t, w.CanSpend(m("10.01")))
+
+	// Казначейство может уходить в минус без ограничений
+	w = domain.Wallet{Balance: m("-1000"), Treasury: true}
+	assert.True(t, w.CanSpend(m("500")))
+}
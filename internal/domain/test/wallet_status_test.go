@@ -0,0 +1,25 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWalletStatus_CanTransitionTo(t *testing.T) {
+	cases := []struct {
+		from, to domain.WalletStatus
+		allowed  bool
+	}{
+		{domain.WalletActive, domain.WalletFrozen, true},
+		{domain.WalletActive, domain.WalletClosed, true},
+		{domain.WalletFrozen, domain.WalletActive, true},
+		{domain.WalletFrozen, domain.WalletClosed, false},
+		{domain.WalletClosed, domain.WalletActive, false},
+		{domain.WalletClosed, domain.WalletFrozen, false},
+		{domain.WalletActive, domain.WalletActive, false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.allowed, c.from.CanTransitionTo(c.to), "%s -> %s", c.from, c.to)
+	}
+}
@@ -9,12 +9,20 @@ type TxExecutor interface {
 	Rollback(ctx context.Context) error
 	Exec(ctx context.Context, sql string, arguments ...interface{}) (CommandTag, error)
 	QueryRow(ctx context.Context, sql string, args ...interface{}) Row
+	Query(ctx context.Context, sql string, args ...interface{}) (Rows, error)
 }
 
 type Row interface {
 	Scan(dest ...interface{}) error
 }
 
+type Rows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Close()
+	Err() error
+}
+
 type CommandTag interface {
 	RowsAffected() int64
 }
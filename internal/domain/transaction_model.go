@@ -1,13 +1,148 @@
 package domain
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
+// TransactionKind тип транзакции
+type TransactionKind string
+
+const (
+	KindTransfer   TransactionKind = "transfer"   // перевод между кошельками
+	KindDeposit    TransactionKind = "deposit"    // пополнение с кошелька казначейства
+	KindWithdrawal TransactionKind = "withdrawal" // вывод на кошелек казначейства
+)
+
 type Transaction struct {
-	Id        int64
-	From      string
-	To        string
-	Amount    float64
-	CreatedAt time.Time
+	Id         int64
+	Kind       TransactionKind
+	From       string
+	To         string
+	Amount     Money
+	Currency   string
+	CreatedAt  time.Time
+	ReversalOf int64     // id отмененной транзакции, 0 для обычного перевода
+	Exchange   *Exchange // детали конвертации, nil для перевода в одной валюте
+	Fee        Money     // комиссия, списанная с отправителя сверх Amount
+	Details    TransferDetails
+}
+
+// TransferDetails необязательные данные клиента, которые хранятся вместе с переводом
+type TransferDetails struct {
+	Memo string
+	// ExternalReference идентификатор перевода во внешней системе (например, номер заказа).
+	// Уникален в пределах кошелька отправителя.
+	ExternalReference string
+	// Metadata произвольные данные клиента, хранятся как JSON
+	Metadata map[string]interface{}
+}
+
+// IsZero сообщает, что данные не заданы
+func (d TransferDetails) IsZero() bool {
+	return d.Memo == "" && d.ExternalReference == "" && len(d.Metadata) == 0
+}
+
+// IsReversal сообщает, что транзакция является отменой другой транзакции
+func (t Transaction) IsReversal() bool {
+	return t.ReversalOf != 0
+}
+
+// Reversed возвращает встречную транзакцию, отменяющую t. Отмена сохраняет тип исходной транзакции.
+// Для перевода с конвертацией получатель возвращает зачисленную сумму, а отправитель получает исходную.
+// Комиссия исходного перевода при отмене не возвращается: она остается на кошельке-сборщике,
+// а у отмены комиссия нулевая, поэтому ее запись журнала содержит только возврат суммы перевода.
+func (t Transaction) Reversed() Transaction {
+	reversal := Transaction{
+		Kind:       t.Kind,
+		From:       t.To,
+		To:         t.From,
+		Amount:     t.Amount,
+		Currency:   t.Currency,
+		ReversalOf: t.Id,
+	}
+	if t.Exchange != nil {
+		reversal.Amount = t.Exchange.ToAmount
+		reversal.Currency = t.Exchange.ToCurrency
+		reversal.Exchange = &Exchange{
+			ToCurrency: t.Currency,
+			ToAmount:   t.Amount,
+			Rate:       t.Exchange.Rate.Inverse(),
+			RateAt:     t.Exchange.RateAt,
+		}
+	}
+	return reversal
+}
+
+// Entry возвращает запись журнала, проводящую транзакцию
+func (t Transaction) Entry(kind EntryKind) JournalEntry {
+	if t.Exchange != nil {
+		return NewExchangeEntry(kind, t.Id, t.From, t.To, t.Currency, t.Amount, *t.Exchange)
+	}
+	return NewTransferEntry(kind, t.Id, t.From, t.To, t.Currency, t.Amount)
+}
+
+// TransferRequest параметры перевода между кошельками
+type TransferRequest struct {
+	From   string
+	To     string
+	Amount Money
+	// Convert разрешает перевод между кошельками в разных валютах по текущему курсу
+	Convert bool
+	// IdempotencyKey необязательный ключ, по которому повтор запроса вернет исходный результат
+	IdempotencyKey string
+	Details        TransferDetails
+}
+
+// Fingerprint возвращает хэш содержимого запроса (без ключа идемпотентности).
+// По нему отличаем повтор того же запроса от переиспользования ключа с другими данными.
+func (r TransferRequest) Fingerprint() string {
+	payload := fmt.Sprintf("%s|%s|%s", r.From, r.To, r.Amount)
+	if r.Convert {
+		// Флаг добавляется только при конвертации, чтобы не менять хэши уже сохраненных ключей
+		payload += "|convert"
+	}
+	if !r.Details.IsZero() {
+		// json.Marshal сортирует ключи metadata, поэтому хэш не зависит от их порядка в запросе
+		details, _ := json.Marshal(r.Details)
+		payload += "|" + string(details)
+	}
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])
+}
+
+// TransferResult итог проведенного перевода
+type TransferResult struct {
+	TransactionId int64
+	// Exchange детали конвертации, если перевод был между валютами
+	Exchange *Exchange
+	// Fee комиссия, списанная с отправителя
+	Fee Money
+	// Replayed выставляется, когда результат взят из ранее выполненного запроса с тем же ключом идемпотентности
+	Replayed bool
+	// ExceededLimit нарушенный лимит, заполняется только вместе с кодом CodeLimitExceeded
+	ExceededLimit LimitKind
+}
+
+// TransferLeg одна нога пакетного перевода
+type TransferLeg struct {
+	From   string
+	To     string
+	Amount Money
+}
+
+// LegError причина, по которой нога пакета не может быть проведена
+type LegError struct {
+	Index int // индекс ноги в исходном запросе
+	Code  ErrorCode
+	Limit LimitKind // нарушенный лимит кошелька при CodeLimitExceeded
+}
+
+// BatchResult итог пакетного перевода: либо ID всех созданных транзакций, либо ошибки по ногам
+type BatchResult struct {
+	TransactionIds []int64
+	LegErrors      []LegError
 }
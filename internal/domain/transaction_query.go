@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WalletRole роль кошелька в фильтре истории транзакций
+type WalletRole string
+
+const (
+	WalletRoleAny      WalletRole = "any"      // отправитель или получатель
+	WalletRoleSender   WalletRole = "sender"   // только исходящие
+	WalletRoleReceiver WalletRole = "receiver" // только входящие
+)
+
+// SortOrder порядок истории транзакций по времени создания
+type SortOrder string
+
+const (
+	SortDesc SortOrder = "desc" // сначала новые
+	SortAsc  SortOrder = "asc"  // сначала старые
+)
+
+// Ограничения размера страницы истории транзакций
+const (
+	DefaultTransactionPageSize = 50
+	MaxTransactionPageSize     = 1000
+)
+
+// transactionCursorVersion версия формата курсора, чтобы старые курсоры отклонялись при смене формата
+const transactionCursorVersion = "v1"
+
+// TransactionCursor позиция в истории транзакций: последняя транзакция предыдущей страницы.
+// Транзакции упорядочены по (created_at, id), id различает транзакции с одинаковым временем.
+type TransactionCursor struct {
+	Order     SortOrder
+	CreatedAt time.Time
+	Id        int64
+}
+
+// Encode возвращает непрозрачное для клиента представление курсора
+func (c TransactionCursor) Encode() string {
+	raw := fmt.Sprintf("%s|%s|%d|%d", transactionCursorVersion, c.Order, c.CreatedAt.UnixNano(), c.Id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseTransactionCursor разбирает курсор, полученный из Encode. Некорректный курсор - ErrInvalidCursor.
+func ParseTransactionCursor(s string) (TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return TransactionCursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 4 || parts[0] != transactionCursorVersion {
+		return TransactionCursor{}, fmt.Errorf("%w: unknown format", ErrInvalidCursor)
+	}
+
+	order := SortOrder(parts[1])
+	if order != SortDesc && order != SortAsc {
+		return TransactionCursor{}, fmt.Errorf("%w: unknown order %q", ErrInvalidCursor, parts[1])
+	}
+	nanos, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return TransactionCursor{}, fmt.Errorf("%w: invalid time", ErrInvalidCursor)
+	}
+	id, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil || id <= 0 {
+		return TransactionCursor{}, fmt.Errorf("%w: invalid id", ErrInvalidCursor)
+	}
+
+	// created_at хранится без часового пояса и читается в UTC
+	return TransactionCursor{Order: order, CreatedAt: time.Unix(0, nanos).UTC(), Id: id}, nil
+}
+
+// TransactionFilter параметры выборки истории транзакций. Нулевые значения границ означают отсутствие ограничения.
+type TransactionFilter struct {
+	Wallet      string
+	Role        WalletRole // учитывается только вместе с Wallet
+	CreatedFrom time.Time  // включительно
+	CreatedTo   time.Time  // не включительно
+	MinAmount   Money      // включительно, в валюте отправителя
+	MaxAmount   Money      // включительно, в валюте отправителя
+	Order       SortOrder
+	After       *TransactionCursor // nil - первая страница
+	Limit       int
+}
+
+// Normalize подставляет значения по умолчанию: порядок от новых к старым, любая роль кошелька, размер страницы
+func (f TransactionFilter) Normalize() TransactionFilter {
+	if f.Order == "" {
+		f.Order = SortDesc
+	}
+	if f.Role == "" {
+		f.Role = WalletRoleAny
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultTransactionPageSize
+	}
+	return f
+}
+
+// Validate проверяет согласованность фильтра. Ошибка курсора - ErrInvalidCursor, остальные - ErrInvalidFilter.
+func (f TransactionFilter) Validate() error {
+	switch {
+	case f.Order != SortDesc && f.Order != SortAsc:
+		return fmt.Errorf("%w: unknown order %q", ErrInvalidFilter, f.Order)
+	case f.Role != WalletRoleAny && f.Role != WalletRoleSender && f.Role != WalletRoleReceiver:
+		return fmt.Errorf("%w: unknown wallet role %q", ErrInvalidFilter, f.Role)
+	case f.Role != WalletRoleAny && f.Wallet == "":
+		return fmt.Errorf("%w: wallet role %q requires a wallet", ErrInvalidFilter, f.Role)
+	case f.Limit <= 0 || f.Limit > MaxTransactionPageSize:
+		return fmt.Errorf("%w: count must be between 1 and %d", ErrInvalidFilter, MaxTransactionPageSize)
+	case f.MinAmount < 0 || f.MaxAmount < 0:
+		return fmt.Errorf("%w: amount bounds must not be negative", ErrInvalidFilter)
+	case f.MaxAmount > 0 && f.MinAmount > f.MaxAmount:
+		return fmt.Errorf("%w: min amount is greater than max amount", ErrInvalidFilter)
+	case !f.CreatedFrom.IsZero() && !f.CreatedTo.IsZero() && !f.CreatedFrom.Before(f.CreatedTo):
+		return fmt.Errorf("%w: created_at range is empty", ErrInvalidFilter)
+	case f.After != nil && f.After.Order != f.Order:
+		return fmt.Errorf("%w: cursor was issued for order %q", ErrInvalidCursor, f.After.Order)
+	}
+	return nil
+}
+
+// TransactionPage страница истории транзакций. Next - курсор следующей страницы, nil на последней.
+type TransactionPage struct {
+	Transactions []Transaction
+	Next         *TransactionCursor
+}
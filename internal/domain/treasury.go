@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TreasuryWallet кошелек казначейства в одной валюте
+type TreasuryWallet struct {
+	Currency string
+	Address  string
+}
+
+// Treasury кошельки казначейства по валютам. Пополнение кошелька - перевод с кошелька казначейства,
+// вывод - перевод на него; баланс казначейства может уходить в минус на сумму выпущенных в систему денег.
+type Treasury struct {
+	byCurrency map[string]string
+}
+
+// NewTreasury проверяет кошельки казначейства и собирает их в справочник.
+// Валюта каждого кошелька обязана быть в currencies, на валюту - не больше одного кошелька.
+func NewTreasury(wallets []TreasuryWallet, currencies *CurrencyRegistry) (*Treasury, error) {
+	t := &Treasury{byCurrency: make(map[string]string, len(wallets))}
+	addresses := make(map[string]bool, len(wallets))
+	for _, w := range wallets {
+		currency := strings.ToUpper(w.Currency)
+		if _, err := currencies.Lookup(currency); err != nil || currency == "" {
+			return nil, fmt.Errorf("%w: treasury wallet in unknown currency %q", ErrInvalidInput, w.Currency)
+		}
+		if w.Address == "" {
+			return nil, fmt.Errorf("%w: treasury wallet for %s has no address", ErrInvalidInput, currency)
+		}
+		if _, ok := t.byCurrency[currency]; ok {
+			return nil, fmt.Errorf("%w: duplicate treasury wallet for %s", ErrInvalidInput, currency)
+		}
+		if addresses[w.Address] {
+			return nil, fmt.Errorf("%w: treasury wallet %s is used for several currencies", ErrInvalidInput, w.Address)
+		}
+		t.byCurrency[currency] = w.Address
+		addresses[w.Address] = true
+	}
+	return t, nil
+}
+
+// Wallet возвращает адрес кошелька казначейства в валюте currency
+func (t *Treasury) Wallet(currency string) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	address, ok := t.byCurrency[currency]
+	return address, ok
+}
+
+// Wallets возвращает все кошельки казначейства в порядке кодов валют
+func (t *Treasury) Wallets() []TreasuryWallet {
+	if t == nil {
+		return nil
+	}
+	wallets := make([]TreasuryWallet, 0, len(t.byCurrency))
+	for currency, address := range t.byCurrency {
+		wallets = append(wallets, TreasuryWallet{Currency: currency, Address: address})
+	}
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i].Currency < wallets[j].Currency })
+	return wallets
+}
+
+// FundsRequest пополнение или вывод средств кошелька через казначейство
+type FundsRequest struct {
+	Address string
+	Amount  Money
+	Details TransferDetails
+}
@@ -5,7 +5,85 @@ import (
 )
 
 type Wallet struct {
+	Address        string
+	Balance        Money // баланс по журналу, с кредитной линией может быть отрицательным
+	Held           Money // сумма активных резервов, заполняется при блокировке кошелька
+	OverdraftLimit Money // кредитная линия: баланс не опускается ниже -OverdraftLimit
+	Currency       string
+	Status         WalletStatus
+	Treasury       bool // кошелек казначейства: баланс может быть отрицательным без ограничения
+	CreatedAt      time.Time
+}
+
+// WalletStatus состояние кошелька
+type WalletStatus string
+
+const (
+	WalletActive WalletStatus = "active"
+	WalletFrozen WalletStatus = "frozen" // временно заблокирован, можно разморозить
+	WalletClosed WalletStatus = "closed" // закрыт окончательно
+)
+
+// CanTransitionTo сообщает, разрешен ли переход: active -> frozen -> active и active -> closed
+func (s WalletStatus) CanTransitionTo(next WalletStatus) bool {
+	switch s {
+	case WalletActive:
+		return next == WalletFrozen || next == WalletClosed
+	case WalletFrozen:
+		return next == WalletActive
+	default:
+		return false
+	}
+}
+
+// WalletStatusChange запись о смене статуса кошелька с указанной причиной
+type WalletStatusChange struct {
+	Id         int64
+	Address    string
+	FromStatus WalletStatus
+	ToStatus   WalletStatus
+	Reason     string
+	CreatedAt  time.Time
+}
+
+// Available возвращает сумму, доступную для списания: баланс по журналу за вычетом резервов
+// плюс кредитная линия
+func (w Wallet) Available() Money {
+	return w.Balance - w.Held + w.OverdraftLimit
+}
+
+// CanSpend сообщает, хватает ли доступных средств на списание amount.
+// Баланс кошелька казначейства не ограничен снизу.
+func (w Wallet) CanSpend(amount Money) bool {
+	return w.Treasury || w.Available() >= amount
+}
+
+// CreditUsed возвращает задолженность по кредитной линии
+func (w Wallet) CreditUsed() Money {
+	if w.Balance < 0 {
+		return -w.Balance
+	}
+	return 0
+}
+
+// CreditAvailable возвращает неиспользованную часть кредитной линии
+func (w Wallet) CreditAvailable() Money {
+	return w.OverdraftLimit - w.CreditUsed()
+}
+
+// Balance баланс кошелька: по журналу и доступный с учетом активных резервов и кредитной линии
+type Balance struct {
+	Total     Money
+	Available Money
+}
+
+// BalanceAdjustment запись аудита ручного изменения баланса (PUT /wallet/{address}/balance)
+type BalanceAdjustment struct {
+	Id        int64
 	Address   string
-	Balance   float64
+	Previous  Money
+	New       Money
+	Reason    string
+	EntryId   int64 // запись журнала adjustment
 	CreatedAt time.Time
 }
@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// contextKey ключи значений запроса в контексте. Неэкспортируемый тип не пересекается с ключами других пакетов.
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	traceKey
+	apiKeyIDKey
+)
+
+// Имена полей, под которыми значения из контекста попадают в каждую строку лога
+const (
+	RequestIDField = "RequestID"
+	TraceIDField   = "TraceID"
+	SpanIDField    = "SpanID"
+	APIKeyIDField  = "APIKeyID"
+)
+
+// WithRequestID добавляет в контекст ID запроса
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext возвращает ID запроса или пустую строку
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithAPIKeyID добавляет в контекст ID API-ключа, которым аутентифицирован запрос
+func WithAPIKeyID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, apiKeyIDKey, id)
+}
+
+// APIKeyIDFromContext возвращает ID API-ключа запроса, ok = false для запроса без ключа
+func APIKeyIDFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(apiKeyIDKey).(int64)
+	return id, ok
+}
+
+// Trace W3C trace context запроса из заголовка traceparent: 00-<trace-id>-<span-id>-<flags>.
+// SpanID - span вызывающей стороны, родительский для обработки запроса.
+type Trace struct {
+	Version string
+	TraceID string
+	SpanID  string
+	Flags   string
+}
+
+// TraceParent возвращает значение заголовка traceparent
+func (t Trace) TraceParent() string {
+	return t.Version + "-" + t.TraceID + "-" + t.SpanID + "-" + t.Flags
+}
+
+// ParseTraceParent разбирает и проверяет заголовок traceparent по W3C Trace Context.
+// Поля версии 00 должны быть в нижнем регистре, нулевые trace-id и span-id недопустимы.
+// Для будущих версий лишние поля после flags игнорируются.
+func ParseTraceParent(header string) (Trace, error) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return Trace{}, fmt.Errorf("traceparent %q: expected version-traceid-spanid-flags", header)
+	}
+	t := Trace{Version: parts[0], TraceID: parts[1], SpanID: parts[2], Flags: parts[3]}
+	switch {
+	case !isLowerHex(t.Version, 2) || t.Version == "ff":
+		return Trace{}, fmt.Errorf("traceparent %q: invalid version", header)
+	case t.Version == "00" && len(parts) != 4:
+		return Trace{}, fmt.Errorf("traceparent %q: version 00 has exactly four fields", header)
+	case !isLowerHex(t.TraceID, 32) || isZeros(t.TraceID):
+		return Trace{}, fmt.Errorf("traceparent %q: invalid trace-id", header)
+	case !isLowerHex(t.SpanID, 16) || isZeros(t.SpanID):
+		return Trace{}, fmt.Errorf("traceparent %q: invalid parent-id", header)
+	case !isLowerHex(t.Flags, 2):
+		return Trace{}, fmt.Errorf("traceparent %q: invalid trace-flags", header)
+	}
+	return t, nil
+}
+
+// NewTrace начинает новую трассу для запроса без корректного traceparent
+func NewTrace() Trace {
+	return Trace{Version: "00", TraceID: randomHex(16), SpanID: randomHex(8), Flags: "00"}
+}
+
+// WithTrace добавляет в контекст trace context запроса
+func WithTrace(ctx context.Context, t Trace) context.Context {
+	return context.WithValue(ctx, traceKey, t)
+}
+
+// TraceFromContext возвращает trace context запроса, ok = false, если его нет
+func TraceFromContext(ctx context.Context) (Trace, bool) {
+	t, ok := ctx.Value(traceKey).(Trace)
+	return t, ok
+}
+
+func isLowerHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isZeros(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	// crypto/rand.Read не возвращает ошибку на поддерживаемых платформах
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -10,7 +10,6 @@ import (
 
 const (
 	LoggerKey = "logger"
-	RequestID = "RequestID"
 )
 
 type Logger struct {
@@ -30,38 +29,44 @@ func New(cfgLog *zap.Config) (Logger, error) {
 	return Logger{l: logger}, nil
 }
 
-// addRequestID достает из контекста RequestID и добавляет его в поля
-func (l *Logger) addRequestID(ctx context.Context, fields []zap.Field) []zap.Field {
-	if ctx.Value(RequestID) != nil {
-		fields = append(fields, zap.String(RequestID, ctx.Value(RequestID).(string)))
+// addContextFields достает из контекста ID запроса, trace context и ID API-ключа и добавляет их в поля
+func (l *Logger) addContextFields(ctx context.Context, fields []zap.Field) []zap.Field {
+	if id := RequestIDFromContext(ctx); id != "" {
+		fields = append(fields, zap.String(RequestIDField, id))
+	}
+	if t, ok := TraceFromContext(ctx); ok {
+		fields = append(fields, zap.String(TraceIDField, t.TraceID), zap.String(SpanIDField, t.SpanID))
+	}
+	if id, ok := APIKeyIDFromContext(ctx); ok {
+		fields = append(fields, zap.Int64(APIKeyIDField, id))
 	}
 	return fields
 }
 
 func (l *Logger) Info(ctx context.Context, msg string, fields ...zap.Field) {
-	l.l.Info(msg, l.addRequestID(ctx, fields)...)
+	l.l.Info(msg, l.addContextFields(ctx, fields)...)
 }
 
 func (l *Logger) Debug(ctx context.Context, msg string, fields ...zap.Field) {
-	l.l.Debug(msg, l.addRequestID(ctx, fields)...)
+	l.l.Debug(msg, l.addContextFields(ctx, fields)...)
 }
 
 func (l *Logger) Warn(ctx context.Context, msg string, fields ...zap.Field) {
-	l.l.Warn(msg, l.addRequestID(ctx, fields)...)
+	l.l.Warn(msg, l.addContextFields(ctx, fields)...)
 }
 
 func (l *Logger) Error(ctx context.Context, msg string, fields ...zap.Field) {
-	l.l.Error(msg, l.addRequestID(ctx, fields)...)
+	l.l.Error(msg, l.addContextFields(ctx, fields)...)
 }
 
 func (l *Logger) DPanic(ctx context.Context, msg string, fields ...zap.Field) {
-	l.l.DPanic(msg, l.addRequestID(ctx, fields)...)
+	l.l.DPanic(msg, l.addContextFields(ctx, fields)...)
 }
 
 func (l *Logger) Panic(ctx context.Context, msg string, fields ...zap.Field) {
-	l.l.Panic(msg, l.addRequestID(ctx, fields)...)
+	l.l.Panic(msg, l.addContextFields(ctx, fields)...)
 }
 
 func (l *Logger) Fatal(ctx context.Context, msg string, fields ...zap.Field) {
-	l.l.Fatal(msg, l.addRequestID(ctx, fields)...)
+	l.l.Fatal(msg, l.addContextFields(ctx, fields)...)
 }
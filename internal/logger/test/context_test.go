@@ -0,0 +1,62 @@
+package test
+
+import (
+	"TransactionTest/internal/logger"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	trace, err := logger.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", trace.SpanID)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", trace.TraceParent())
+
+	// Будущая версия может добавить поля после flags
+	_, err = logger.ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	assert.NoError(t, err)
+}
+
+func TestParseTraceParent_Invalid(t *testing.T) {
+	for _, header := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",       // нет flags
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",    // запрещенная версия
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",    // верхний регистр
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",    // нулевой trace-id
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",    // нулевой parent-id
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-xx", // лишнее поле в версии 00
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",     // короткий trace-id
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0g",    // не hex
+	} {
+		_, err := logger.ParseTraceParent(header)
+		assert.Error(t, err, header)
+	}
+}
+
+func TestNewTrace(t *testing.T) {
+	trace := logger.NewTrace()
+	parsed, err := logger.ParseTraceParent(trace.TraceParent())
+	assert.NoError(t, err)
+	assert.Equal(t, trace, parsed)
+	assert.NotEqual(t, trace.TraceID, logger.NewTrace().TraceID)
+}
+
+func TestContextValues(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", logger.RequestIDFromContext(ctx))
+	_, ok := logger.APIKeyIDFromContext(ctx)
+	assert.False(t, ok)
+
+	// Строковый ключ другого пакета не пересекается с типизированным ключом
+	ctx = context.WithValue(ctx, "RequestID", "raw")
+	assert.Equal(t, "", logger.RequestIDFromContext(ctx))
+
+	ctx = logger.WithAPIKeyID(logger.WithRequestID(ctx, "req-1"), 7)
+	assert.Equal(t, "req-1", logger.RequestIDFromContext(ctx))
+	id, ok := logger.APIKeyIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), id)
+}
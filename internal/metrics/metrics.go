@@ -0,0 +1,136 @@
+// Package metrics собирает метрики сервиса и отдает их в текстовом формате Prometheus.
+//
+// Метрики:
+// - http_requests_total, http_request_duration_seconds - запросы по шаблону маршрута, методу и статусу
+// - transfers_total, transfer_amount_total - переводы SendMoney по коду результата domain.ErrorCode
+// - pgxpool_* - состояние пула соединений с БД
+// - seeding_status, migration_version, migration_dirty - состояние запуска
+package metrics
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"TransactionTest/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// OutcomeOK значение метки outcome для успешного перевода (domain.CodeOK - пустая строка)
+const OutcomeOK = "OK"
+
+// Metrics метрики сервиса в собственном реестре
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests   *prometheus.CounterVec
+	httpDuration   *prometheus.HistogramVec
+	transfers      *prometheus.CounterVec
+	transferAmount *prometheus.CounterVec
+
+	seeding          *prometheus.GaugeVec
+	migrationVersion prometheus.Gauge
+	migrationDirty   prometheus.Gauge
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by route template, method and status code.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by route template, method and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		transfers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "transfers_total",
+			Help: "SendMoney calls by outcome (OK or error code).",
+		}, []string{"outcome"}),
+		transferAmount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "transfer_amount_total",
+			Help: "Requested SendMoney amounts by outcome, in wallet currency units without conversion.",
+		}, []string{"outcome"}),
+		seeding: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "seeding_status",
+			Help: "Wallet seeding result at startup, 1 for the current status.",
+		}, []string{"status"}),
+		migrationVersion: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "migration_version",
+			Help: "Database migration version after startup.",
+		}),
+		migrationDirty: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "migration_dirty",
+			Help: "1 if the last migration failed and the database is in a dirty state.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.transfers,
+		m.transferAmount,
+		m.seeding,
+		m.migrationVersion,
+		m.migrationDirty,
+	)
+	m.SetSeedingStatus(domain.SeedingPending)
+	return m
+}
+
+// Handler отдает метрики в текстовом формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest учитывает HTTP запрос. route - шаблон маршрута, а не путь, чтобы число рядов не росло с адресами.
+func (m *Metrics) ObserveRequest(route, method string, status int, duration time.Duration) {
+	labels := prometheus.Labels{"route": route, "method": method, "status": strconv.Itoa(status)}
+	m.httpRequests.With(labels).Inc()
+	m.httpDuration.With(labels).Observe(duration.Seconds())
+}
+
+// ObserveTransfer учитывает перевод с кодом результата code и запрошенной суммой amount
+func (m *Metrics) ObserveTransfer(code domain.ErrorCode, amount domain.Money) {
+	outcome := string(code)
+	if code == domain.CodeOK {
+		outcome = OutcomeOK
+	}
+	m.transfers.WithLabelValues(outcome).Inc()
+	if amount > 0 {
+		m.transferAmount.WithLabelValues(outcome).Add(float64(amount) / math.Pow10(domain.MoneyScale))
+	}
+}
+
+// SetSeedingStatus отмечает текущее состояние создания стартовых кошельков.
+// Остальные состояния обнуляются, чтобы у метрики всегда был полный набор рядов.
+func (m *Metrics) SetSeedingStatus(status domain.SeedingStatus) {
+	for _, s := range domain.SeedingStatuses {
+		m.seeding.WithLabelValues(string(s)).Set(0)
+	}
+	m.seeding.WithLabelValues(string(status)).Set(1)
+}
+
+// SetMigrationStatus отмечает версию схемы и признак незавершенной миграции
+func (m *Metrics) SetMigrationStatus(version uint, dirty bool) {
+	m.migrationVersion.Set(float64(version))
+	if dirty {
+		m.migrationDirty.Set(1)
+	} else {
+		m.migrationDirty.Set(0)
+	}
+}
+
+// RegisterPool добавляет метрики пула соединений. Значения читаются из pool.Stat() при каждом сборе.
+func (m *Metrics) RegisterPool(pool *pgxpool.Pool) error {
+	return m.registry.Register(newPoolCollector(pool))
+}
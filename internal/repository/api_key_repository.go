@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"TransactionTest/internal/domain"
+)
+
+type APIKeyRepository struct {
+	db IDB
+}
+
+func NewAPIKeyRepository(db IDB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// apiKeyColumns колонки ключа в порядке, который ожидает scanAPIKey
+const apiKeyColumns = `id, name, scopes, created_at, revoked_at`
+
+func scanAPIKey(row Row, k *domain.APIKey) error {
+	var scopes []string
+	if err := row.Scan(
+		&k.Id,
+		&k.Name,
+		&scopes,
+		&k.CreatedAt,
+		&k.RevokedAt,
+	); err != nil {
+		return err
+	}
+	k.Scopes = make([]domain.Scope, len(scopes))
+	for i, s := range scopes {
+		k.Scopes[i] = domain.Scope(s)
+	}
+	return nil
+}
+
+// CreateAPIKey сохраняет ключ по его хешу. Совпадение хеша с существующим ключом возвращает domain.ErrConflict.
+func (kr *APIKeyRepository) CreateAPIKey(ctx context.Context, name, keyHash string, scopes []domain.Scope) (*domain.APIKey, error) {
+	query := `INSERT INTO api_keys (name, key_hash, scopes)
+              VALUES ($1, $2, $3)
+              RETURNING ` + apiKeyColumns
+
+	values := make([]string, len(scopes))
+	for i, s := range scopes {
+		values[i] = string(s)
+	}
+
+	var k domain.APIKey
+
+	err := scanAPIKey(kr.db.QueryRow(ctx, query, name, keyHash, values), &k)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok {
+			if dbErr.SQLState() == ErrCodeUniqueViolation {
+				return nil, fmt.Errorf("%w: api key hash already exists", domain.ErrConflict)
+			}
+			if dbErr.SQLState() == ErrCodeCheckViolation {
+				return nil, fmt.Errorf("%w: %v", domain.ErrInvalidScope, scopes)
+			}
+		}
+		return nil, fmt.Errorf("%w: failed to create api key: %w", domain.ErrInternal, err)
+	}
+
+	return &k, nil
+}
+
+// GetAPIKeyByHash ищет ключ, в том числе отозванный, по хешу
+func (kr *APIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + `
+              FROM api_keys WHERE key_hash = $1`
+
+	var k domain.APIKey
+
+	err := scanAPIKey(kr.db.QueryRow(ctx, query, keyHash), &k)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: failed to find api key: %w", domain.ErrInternal, err)
+	}
+
+	return &k, nil
+}
+
+// ListAPIKeys возвращает все ключи, включая отозванные, в порядке создания
+func (kr *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + `
+              FROM api_keys
+              ORDER BY id`
+
+	rows, err := kr.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list api keys: %w", domain.ErrInternal, err)
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+
+	for rows.Next() {
+		var k domain.APIKey
+		if err := scanAPIKey(rows, &k); err != nil {
+			return nil, fmt.Errorf("%w: failed to scan api key: %w", domain.ErrInternal, err)
+		}
+		keys = append(keys, k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: error while fetching rows: %w", domain.ErrInternal, err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey отзывает ключ. Повторный отзыв не меняет время первого.
+func (kr *APIKeyRepository) RevokeAPIKey(ctx context.Context, id int64) error {
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now())
+              WHERE id = $1`
+
+	result, err := kr.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("%w: failed to revoke api key %v: %w", domain.ErrInternal, id, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"TransactionTest/internal/domain"
+)
+
+type HoldRepository struct {
+	db IDB
+}
+
+func NewHoldRepository(db IDB) *HoldRepository {
+	return &HoldRepository{db: db}
+}
+
+// holdColumns колонки резерва в порядке, который ожидает scanHold.
+// Активный резерв с прошедшим сроком читается как expired, не дожидаясь фоновой задачи.
+const holdColumns = `id, from_wallet, to_wallet, amount, currency,
+              CASE WHEN status = 'active' AND expires_at <= now() THEN 'expired' ELSE status END,
+              captured_amount, COALESCE(transaction_id, 0), created_at, expires_at`
+
+func scanHold(row Row, h *domain.Hold) error {
+	return row.Scan(
+		&h.Id,
+		&h.From,
+		&h.To,
+		&h.Amount,
+		&h.Currency,
+		&h.Status,
+		&h.CapturedAmount,
+		&h.TransactionId,
+		&h.CreatedAt,
+		&h.ExpiresAt,
+	)
+}
+
+func (hr *HoldRepository) BeginTX(ctx context.Context) (domain.TxExecutor, error) {
+	tx, err := hr.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	return &txAdapter{tx: tx}, nil
+}
+
+// CreateHoldTx создает активный резерв на срок ttl. Кошелек отправителя должен быть заблокирован вызывающим кодом.
+func (hr *HoldRepository) CreateHoldTx(ctx context.Context, tx domain.TxExecutor, from, to, currency string, amount domain.Money, ttl time.Duration) (*domain.Hold, error) {
+	query := `INSERT INTO holds (from_wallet, to_wallet, amount, currency, expires_at)
+              VALUES ($1, $2, $3, $4, now() + make_interval(secs => $5))
+              RETURNING ` + holdColumns
+
+	var h domain.Hold
+
+	err := scanHold(tx.QueryRow(ctx, query, from, to, amount, currency, ttl.Seconds()), &h)
+	if err != nil {
+		if isTxConflict(err) {
+			return nil, fmt.Errorf("%w: failed to create hold: %w", domain.ErrTransactionConflict, err)
+		}
+		if dbErr, ok := err.(DBError); ok {
+			if dbErr.SQLState() == ErrCodeForeignKeyViolation {
+				return nil, fmt.Errorf("%w: wallet %s or %s", domain.ErrNotFound, from, to)
+			}
+			if dbErr.SQLState() == ErrCodeNumericOutOfRange {
+				return nil, fmt.Errorf("%w: amount %v", domain.ErrAmountOverflow, amount)
+			}
+		}
+		return nil, fmt.Errorf("%w: failed to create hold: %w", domain.ErrInternal, err)
+	}
+
+	return &h, nil
+}
+
+func (hr *HoldRepository) GetHold(ctx context.Context, id int64) (*domain.Hold, error) {
+	query := `SELECT ` + holdColumns + `
+              FROM holds WHERE id = $1`
+
+	var h domain.Hold
+
+	err := scanHold(hr.db.QueryRow(ctx, query, id), &h)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: failed to find hold %v: %w", domain.ErrInternal, id, err)
+	}
+
+	return &h, nil
+}
+
+// GetHoldForUpdateTx читает резерв и блокирует его строку до конца транзакции БД
+func (hr *HoldRepository) GetHoldForUpdateTx(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Hold, error) {
+	query := `SELECT ` + holdColumns + `
+              FROM holds WHERE id = $1 FOR UPDATE`
+
+	var h domain.Hold
+
+	err := scanHold(tx.QueryRow(ctx, query, id), &h)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return nil, domain.ErrNotFound
+		}
+		if isTxConflict(err) {
+			return nil, fmt.Errorf("%w: failed to lock hold %v: %w", domain.ErrTransactionConflict, id, err)
+		}
+		return nil, fmt.Errorf("%w: failed to lock hold %v: %w", domain.ErrInternal, id, err)
+	}
+
+	return &h, nil
+}
+
+// CloseHoldTx переводит активный резерв в конечное состояние status.
+// Для захвата передаются списанная сумма и созданный перевод, для отмены - нули.
+func (hr *HoldRepository) CloseHoldTx(ctx context.Context, tx domain.TxExecutor, id int64, status domain.HoldStatus, capturedAmount domain.Money, transactionId int64) error {
+	query := `UPDATE holds SET status = $1, captured_amount = $2, transaction_id = NULLIF($3, 0)
+              WHERE id = $4 AND status = 'active' AND expires_at > now()`
+
+	result, err := tx.Exec(ctx, query, status, capturedAmount, transactionId, id)
+	if err != nil {
+		if isTxConflict(err) {
+			return fmt.Errorf("%w: failed to close hold %v: %w", domain.ErrTransactionConflict, id, err)
+		}
+		return fmt.Errorf("%w: failed to close hold %v: %w", domain.ErrInternal, id, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrHoldNotActive
+	}
+
+	return nil
+}
+
+// ExpireHolds помечает истекшими активные резервы, срок которых прошел.
+// На доступный баланс это не влияет: истекший резерв перестает учитываться сразу по expires_at.
+func (hr *HoldRepository) ExpireHolds(ctx context.Context) (int64, error) {
+	query := `UPDATE holds SET status = 'expired' WHERE status = 'active' AND expires_at <= now()`
+
+	result, err := hr.db.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to expire holds: %w", domain.ErrInternal, err)
+	}
+
+	return result.RowsAffected(), nil
+}
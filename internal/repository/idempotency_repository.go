@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"TransactionTest/internal/domain"
+)
+
+type IdempotencyRepository struct {
+	db IDB
+}
+
+func NewIdempotencyRepository(db IDB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// ReserveKeyTx занимает ключ идемпотентности в рамках транзакции.
+// Если ключ свободен или срок его хранения истек, возвращает nil — запрос нужно выполнить.
+// Если ключ уже занят, возвращает сохраненную запись. Конкурентный запрос с тем же ключом
+// ждет на уникальном индексе, пока первая транзакция не завершится.
+func (ir *IdempotencyRepository) ReserveKeyTx(ctx context.Context, tx domain.TxExecutor, key, requestHash string, ttl time.Duration) (*domain.IdempotencyRecord, error) {
+	query := `INSERT INTO idempotency_keys (key, request_hash, expires_at)
+              VALUES ($1, $2, now() + make_interval(secs => $3))
+              ON CONFLICT (key) DO UPDATE
+              SET request_hash = EXCLUDED.request_hash,
+                  transaction_id = NULL,
+                  created_at = now(),
+                  expires_at = EXCLUDED.expires_at
+              WHERE idempotency_keys.expires_at <= now()
+              RETURNING key`
+
+	var reserved string
+
+	err := tx.QueryRow(ctx, query, key, requestHash, ttl.Seconds()).Scan(&reserved)
+	if err == nil {
+		return nil, nil
+	}
+	if dbErr, ok := err.(DBError); !ok || dbErr.SQLState() != "no_rows" {
+		if isTxConflict(err) {
+			return nil, fmt.Errorf("%w: failed to reserve idempotency key: %w", domain.ErrTransactionConflict, err)
+		}
+		return nil, fmt.Errorf("%w: failed to reserve idempotency key: %w", domain.ErrInternal, err)
+	}
+
+	query = `SELECT key, request_hash, COALESCE(transaction_id, 0), created_at, expires_at
+             FROM idempotency_keys WHERE key = $1`
+
+	var r domain.IdempotencyRecord
+
+	err = tx.QueryRow(ctx, query, key).Scan(
+		&r.Key,
+		&r.RequestHash,
+		&r.TransactionId,
+		&r.CreatedAt,
+		&r.ExpiresAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read idempotency key: %w", domain.ErrInternal, err)
+	}
+
+	return &r, nil
+}
+
+// CompleteKeyTx привязывает к ключу идентификатор созданной транзакции
+func (ir *IdempotencyRepository) CompleteKeyTx(ctx context.Context, tx domain.TxExecutor, key string, transactionId int64) error {
+	query := `UPDATE idempotency_keys SET transaction_id = $1 WHERE key = $2`
+
+	result, err := tx.Exec(ctx, query, transactionId, key)
+	if err != nil {
+		if isTxConflict(err) {
+			return fmt.Errorf("%w: failed to complete idempotency key: %w", domain.ErrTransactionConflict, err)
+		}
+		return fmt.Errorf("%w: failed to complete idempotency key: %w", domain.ErrInternal, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// RemoveExpiredKeys удаляет ключи с истекшим сроком хранения и возвращает их количество
+func (ir *IdempotencyRepository) RemoveExpiredKeys(ctx context.Context) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at <= now()`
+
+	result, err := ir.db.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to remove expired idempotency keys: %w", domain.ErrInternal, err)
+	}
+
+	return result.RowsAffected(), nil
+}
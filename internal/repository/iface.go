@@ -47,11 +47,18 @@ const (
 	ErrCodeUniqueViolation     = "23505"
 	ErrCodeCheckViolation      = "23514"
 	ErrCodeForeignKeyViolation = "23503"
+	ErrCodeNumericOutOfRange   = "22003"
+	ErrCodeSerializationFail   = "40001"
+	ErrCodeDeadlockDetected    = "40P01"
+	ErrCodeUndefinedTable      = "42P01"
 )
 
 // Имена constraint-ов
 const (
 	ConstraintBalanceNonNegative = "chk_balance_nonnegative"
+	ConstraintBalanceOverdraft   = "chk_balance_within_overdraft"
 	ConstraintAmountPositive     = "chk_amount_positive"
 	ConstraintNoSelfTransfer     = "chk_no_self_transfer"
+	ConstraintUniqueReversal     = "uq_transactions_reversal_of"
+	ConstraintUniqueExternalRef  = "uq_transactions_external_reference"
 )
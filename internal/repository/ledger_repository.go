@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"TransactionTest/internal/domain"
+)
+
+type LedgerRepository struct {
+	db IDB
+}
+
+func NewLedgerRepository(db IDB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// PostEntryTx записывает сбалансированную запись журнала и применяет ее проводки к балансам кошельков.
+// wallets.balance - проекция журнала, поэтому вне этого метода балансы не меняются.
+// Строки кошельков должны быть заблокированы вызывающим кодом, если важен порядок блокировок.
+func (lr *LedgerRepository) PostEntryTx(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+	return lr.writeEntryTx(ctx, tx, entry, true)
+}
+
+// RecordEntryTx записывает сбалансированную запись журнала, не меняя балансы кошельков.
+// Используется только сверкой, чтобы внести в журнал уже существующее расхождение с wallets.balance.
+func (lr *LedgerRepository) RecordEntryTx(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+	return lr.writeEntryTx(ctx, tx, entry, false)
+}
+
+func (lr *LedgerRepository) writeEntryTx(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry, apply bool) (int64, error) {
+	if err := entry.Validate(); err != nil {
+		return 0, err
+	}
+
+	query := `INSERT INTO journal_entries (kind, transaction_id) 
+              VALUES ($1, NULLIF($2, 0)) RETURNING id`
+
+	var entryId int64
+
+	err := tx.QueryRow(ctx, query, entry.Kind, entry.TransactionId).Scan(&entryId)
+	if err != nil {
+		if isTxConflict(err) {
+			return 0, fmt.Errorf("%w: failed to create journal entry: %w", domain.ErrTransactionConflict, err)
+		}
+		return 0, fmt.Errorf("%w: failed to create journal entry: %w", domain.ErrInternal, err)
+	}
+
+	for _, p := range entry.Postings {
+		if err := lr.insertPostingTx(ctx, tx, entryId, p); err != nil {
+			return 0, err
+		}
+		if !apply || domain.IsSystemAccount(p.Account) {
+			continue
+		}
+		if err := lr.applyPostingTx(ctx, tx, p); err != nil {
+			return 0, err
+		}
+	}
+
+	return entryId, nil
+}
+
+func (lr *LedgerRepository) insertPostingTx(ctx context.Context, tx domain.TxExecutor, entryId int64, p domain.Posting) error {
+	query := `INSERT INTO postings (entry_id, account, currency, amount) VALUES ($1, $2, $3, $4)`
+
+	_, err := tx.Exec(ctx, query, entryId, p.Account, p.Currency, p.Amount)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == ErrCodeNumericOutOfRange {
+			return fmt.Errorf("%w: posting %v to %v", domain.ErrAmountOverflow, p.Amount, p.Account)
+		}
+		if isTxConflict(err) {
+			return fmt.Errorf("%w: failed to create posting: %w", domain.ErrTransactionConflict, err)
+		}
+		return fmt.Errorf("%w: failed to create posting: %w", domain.ErrInternal, err)
+	}
+
+	return nil
+}
+
+func (lr *LedgerRepository) applyPostingTx(ctx context.Context, tx domain.TxExecutor, p domain.Posting) error {
+	// Проводка в чужой валюте не должна попасть в баланс кошелька
+	query := `UPDATE wallets SET balance = balance + $1 WHERE address = $2 AND currency = $3`
+
+	result, err := tx.Exec(ctx, query, p.Amount, p.Account, p.Currency)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok {
+			if isBalanceViolation(dbErr) {
+				return domain.ErrInsufficientFunds
+			}
+			if dbErr.SQLState() == ErrCodeNumericOutOfRange {
+				return fmt.Errorf("%w: balance of %v", domain.ErrAmountOverflow, p.Account)
+			}
+		}
+		if isTxConflict(err) {
+			return fmt.Errorf("%w: failed to apply posting to wallet %v: %w", domain.ErrTransactionConflict, p.Account, err)
+		}
+		return fmt.Errorf("%w: failed to apply posting to wallet %v: %w", domain.ErrInternal, p.Account, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: wallet %v in %v", domain.ErrNotFound, p.Account, p.Currency)
+	}
+
+	return nil
+}
+
+// GetAccountPostings возвращает последние проводки по счету (адресу кошелька или системному счету)
+func (lr *LedgerRepository) GetAccountPostings(ctx context.Context, account string, limit int) ([]domain.Posting, error) {
+	query := `SELECT p.id, p.entry_id, e.kind, COALESCE(e.transaction_id, 0), p.account, p.currency, p.amount, p.created_at
+              FROM postings p
+              JOIN journal_entries e ON e.id = p.entry_id
+              WHERE p.account = $1
+              ORDER BY p.created_at DESC, p.id DESC
+              LIMIT $2`
+
+	rows, err := lr.db.Query(ctx, query, account, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get postings of %v: %w", domain.ErrInternal, account, err)
+	}
+	defer rows.Close()
+
+	postings := make([]domain.Posting, 0, limit)
+
+	for rows.Next() {
+		var p domain.Posting
+
+		if err := rows.Scan(&p.Id, &p.EntryId, &p.Kind, &p.TransactionId, &p.Account, &p.Currency, &p.Amount, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%w: failed to scan posting: %w", domain.ErrInternal, err)
+		}
+
+		postings = append(postings, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: error while fetching rows: %w", domain.ErrInternal, err)
+	}
+
+	return postings, nil
+}
+
+// GetBalanceAt возвращает баланс кошелька на момент at: последний снимок не позже at
+// плюс проводки между снимком и at. Без снимка суммируется вся история счета.
+func (lr *LedgerRepository) GetBalanceAt(ctx context.Context, address string, at time.Time) (domain.Money, error) {
+	query := `SELECT COALESCE(s.balance, 0) + COALESCE((
+                  SELECT SUM(p.amount) FROM postings p
+                  WHERE p.account = w.address
+                    AND p.created_at > COALESCE(s.as_of, '-infinity'::timestamp)
+                    AND p.created_at <= $2
+              ), 0)
+              FROM wallets w
+              LEFT JOIN LATERAL (
+                  SELECT as_of, balance FROM balance_snapshots
+                  WHERE account = w.address AND as_of <= $2
+                  ORDER BY as_of DESC
+                  LIMIT 1
+              ) s ON true
+              WHERE w.address = $1`
+
+	var balance domain.Money
+
+	err := lr.db.QueryRow(ctx, query, address, at.UTC()).Scan(&balance)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return 0, domain.ErrNotFound
+		}
+		return 0, fmt.Errorf("%w: failed to get balance of %v at %v: %w", domain.ErrInternal, address, at, err)
+	}
+
+	return balance, nil
+}
+
+// CreateBalanceSnapshots снимает балансы кошельков на момент now() - lag и возвращает число снимков.
+// Снимок создается только для счетов с проводками после их предыдущего снимка.
+// lag защищает от транзакций, которые еще не закоммичены: их проводки получают created_at
+// по началу транзакции и не должны оказаться раньше уже снятого снимка.
+func (lr *LedgerRepository) CreateBalanceSnapshots(ctx context.Context, lag time.Duration) (int64, error) {
+	query := `WITH cutoff AS (
+                  SELECT now()::timestamp - make_interval(secs => $1) AS at
+              ),
+              last AS (
+                  SELECT DISTINCT ON (account) account, as_of, balance
+                  FROM balance_snapshots
+                  ORDER BY account, as_of DESC
+              )
+              INSERT INTO balance_snapshots (account, as_of, balance)
+              SELECT p.account, c.at, COALESCE(l.balance, 0) + SUM(p.amount)
+              FROM postings p
+              CROSS JOIN cutoff c
+              LEFT JOIN last l ON l.account = p.account
+              WHERE p.account NOT LIKE 'system:%'
+                AND p.created_at > COALESCE(l.as_of, '-infinity'::timestamp)
+                AND p.created_at <= c.at
+              GROUP BY p.account, c.at, l.balance
+              ON CONFLICT (account, as_of) DO NOTHING`
+
+	result, err := lr.db.Exec(ctx, query, lag.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to create balance snapshots: %w", domain.ErrInternal, err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// balanceCheckQuery сохраненный баланс кошелька и сумма проводок по его счету
+const balanceCheckQuery = `SELECT w.address, w.currency, w.balance,
+                     COALESCE((SELECT SUM(p.amount) FROM postings p WHERE p.account = w.address), 0)
+              FROM wallets w`
+
+// GetBalanceChecks сравнивает сохраненные балансы всех кошельков с журналом.
+// Запрос читает кошельки и проводки из одного снимка БД, поэтому параллельные переводы не дают ложных расхождений.
+func (lr *LedgerRepository) GetBalanceChecks(ctx context.Context) ([]domain.BalanceCheck, error) {
+	query := balanceCheckQuery + `
+              ORDER BY w.address`
+
+	rows, err := lr.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get balance checks: %w", domain.ErrInternal, err)
+	}
+	defer rows.Close()
+
+	var checks []domain.BalanceCheck
+
+	for rows.Next() {
+		var c domain.BalanceCheck
+
+		if err := rows.Scan(&c.Address, &c.Currency, &c.Stored, &c.Expected); err != nil {
+			return nil, fmt.Errorf("%w: failed to scan balance check: %w", domain.ErrInternal, err)
+		}
+
+		checks = append(checks, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: error while fetching rows: %w", domain.ErrInternal, err)
+	}
+
+	return checks, nil
+}
+
+// GetBalanceCheckForUpdateTx сравнивает баланс одного кошелька с журналом и блокирует строку кошелька
+// до конца транзакции, чтобы расхождение не изменилось до записи корректировки.
+// Кошелек блокируется отдельным запросом: в READ COMMITTED запрос с FOR UPDATE, дождавшийся блокировки,
+// перечитывает баланс после перевода, закоммиченного за время ожидания, а сумму проводок берет
+// из снимка на начало запроса, и такой перевод выглядел бы расхождением. Баланс и проводки
+// читаются следующим запросом из нового снимка, уже после блокировки.
+func (lr *LedgerRepository) GetBalanceCheckForUpdateTx(ctx context.Context, tx domain.TxExecutor, address string) (*domain.BalanceCheck, error) {
+	lockQuery := `SELECT address FROM wallets WHERE address = $1 FOR UPDATE`
+
+	var locked string
+
+	if err := tx.QueryRow(ctx, lockQuery, address).Scan(&locked); err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return nil, domain.ErrNotFound
+		}
+		if isTxConflict(err) {
+			return nil, fmt.Errorf("%w: failed to lock wallet %v: %w", domain.ErrTransactionConflict, address, err)
+		}
+		return nil, fmt.Errorf("%w: failed to lock wallet %v: %w", domain.ErrInternal, address, err)
+	}
+
+	query := balanceCheckQuery + `
+              WHERE w.address = $1`
+
+	var c domain.BalanceCheck
+
+	err := tx.QueryRow(ctx, query, address).Scan(&c.Address, &c.Currency, &c.Stored, &c.Expected)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return nil, domain.ErrNotFound
+		}
+		if isTxConflict(err) {
+			return nil, fmt.Errorf("%w: failed to check balance of %v: %w", domain.ErrTransactionConflict, address, err)
+		}
+		return nil, fmt.Errorf("%w: failed to check balance of %v: %w", domain.ErrInternal, address, err)
+	}
+
+	return &c, nil
+}
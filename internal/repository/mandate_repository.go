@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"TransactionTest/internal/domain"
+)
+
+type MandateRepository struct {
+	db IDB
+}
+
+func NewMandateRepository(db IDB) *MandateRepository {
+	return &MandateRepository{db: db}
+}
+
+// mandateColumns колонки поручения в порядке, который ожидает scanMandate
+const mandateColumns = `id, from_wallet, to_wallet, amount, allow_convert, frequency, repeat_interval, cron,
+              start_at, end_at, max_runs, status, runs_count, occurrence, attempt, scheduled_at, next_run_at, created_at`
+
+func scanMandate(row Row, m *domain.Mandate) error {
+	return row.Scan(
+		&m.Id,
+		&m.From,
+		&m.To,
+		&m.Amount,
+		&m.Convert,
+		&m.Recurrence.Frequency,
+		&m.Recurrence.Interval,
+		&m.Recurrence.Cron,
+		&m.StartAt,
+		&m.EndAt,
+		&m.MaxRuns,
+		&m.Status,
+		&m.RunsCount,
+		&m.Occurrence,
+		&m.Attempt,
+		&m.ScheduledAt,
+		&m.NextRunAt,
+		&m.CreatedAt,
+	)
+}
+
+// CreateMandate сохраняет поручение с уже рассчитанным первым запуском
+func (mr *MandateRepository) CreateMandate(ctx context.Context, m domain.Mandate) (*domain.Mandate, error) {
+	query := `INSERT INTO mandates (from_wallet, to_wallet, amount, allow_convert, frequency, repeat_interval, cron,
+                  start_at, end_at, max_runs, scheduled_at, next_run_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+              RETURNING ` + mandateColumns
+
+	var created domain.Mandate
+
+	err := scanMandate(mr.db.QueryRow(ctx, query,
+		m.From, m.To, m.Amount, m.Convert,
+		m.Recurrence.Frequency, m.Recurrence.Interval, m.Recurrence.Cron,
+		m.StartAt, m.EndAt, m.MaxRuns, m.ScheduledAt, m.NextRunAt,
+	), &created)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok {
+			if dbErr.SQLState() == ErrCodeForeignKeyViolation {
+				return nil, fmt.Errorf("%w: wallet %s or %s", domain.ErrNotFound, m.From, m.To)
+			}
+			if dbErr.SQLState() == ErrCodeNumericOutOfRange {
+				return nil, fmt.Errorf("%w: amount %v", domain.ErrAmountOverflow, m.Amount)
+			}
+		}
+		return nil, fmt.Errorf("%w: failed to create mandate: %w", domain.ErrInternal, err)
+	}
+
+	return &created, nil
+}
+
+func (mr *MandateRepository) GetMandate(ctx context.Context, id int64) (*domain.Mandate, error) {
+	query := `SELECT ` + mandateColumns + `
+              FROM mandates WHERE id = $1`
+
+	var m domain.Mandate
+
+	err := scanMandate(mr.db.QueryRow(ctx, query, id), &m)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: failed to find mandate %v: %w", domain.ErrInternal, id, err)
+	}
+
+	return &m, nil
+}
+
+// CancelMandate отменяет активное поручение. Уже начатая попытка доводится до конца,
+// но расписание после нее не продолжается.
+func (mr *MandateRepository) CancelMandate(ctx context.Context, id int64) error {
+	query := `UPDATE mandates SET status = 'canceled', next_run_at = NULL
+              WHERE id = $1 AND status = 'active'`
+
+	result, err := mr.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("%w: failed to cancel mandate %v: %w", domain.ErrInternal, id, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		if _, err := mr.GetMandate(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrMandateNotActive
+	}
+
+	return nil
+}
+
+// ClaimDueMandate забирает на выполнение одно поручение с наступившей попыткой и блокирует его на lease.
+// Если процесс упал во время выполнения, после lease поручение будет забрано повторно.
+// Если забирать нечего, возвращает domain.ErrNotFound.
+func (mr *MandateRepository) ClaimDueMandate(ctx context.Context, lease time.Duration) (*domain.Mandate, error) {
+	query := `UPDATE mandates SET locked_until = now() + make_interval(secs => $1)
+              WHERE id = (
+                  SELECT id FROM mandates
+                  WHERE status = 'active' AND next_run_at <= now()
+                    AND (locked_until IS NULL OR locked_until <= now())
+                  ORDER BY next_run_at, id
+                  LIMIT 1
+                  FOR UPDATE SKIP LOCKED
+              )
+              RETURNING ` + mandateColumns
+
+	var m domain.Mandate
+
+	err := scanMandate(mr.db.QueryRow(ctx, query, lease.Seconds()), &m)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: failed to claim mandate: %w", domain.ErrInternal, err)
+	}
+
+	return &m, nil
+}
+
+// FinishMandateRun одним запросом записывает попытку в историю и сохраняет новое состояние расписания m.
+// Отмененное за время попытки поручение остается отмененным.
+// Повторная запись той же попытки возвращает domain.ErrConflict.
+func (mr *MandateRepository) FinishMandateRun(ctx context.Context, run domain.MandateRun, m domain.Mandate) error {
+	query := `WITH run AS (
+                  INSERT INTO mandate_runs (mandate_id, occurrence, attempt, scheduled_at, status, transaction_id, failure_code)
+                  VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), $7)
+              )
+              UPDATE mandates
+              SET status = $8, runs_count = $9, occurrence = $10, attempt = $11,
+                  scheduled_at = $12, next_run_at = $13, locked_until = NULL
+              WHERE id = $1 AND status = 'active'`
+
+	_, err := mr.db.Exec(ctx, query,
+		run.MandateId, run.Occurrence, run.Attempt, run.ScheduledAt, run.Status, run.TransactionId, run.FailureCode,
+		m.Status, m.RunsCount, m.Occurrence, m.Attempt, m.ScheduledAt, m.NextRunAt,
+	)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == ErrCodeUniqueViolation {
+			return fmt.Errorf("%w: mandate %v run %v attempt %v already recorded", domain.ErrConflict, run.MandateId, run.Occurrence, run.Attempt)
+		}
+		return fmt.Errorf("%w: failed to record mandate %v run: %w", domain.ErrInternal, run.MandateId, err)
+	}
+
+	return nil
+}
+
+// GetMandateRuns возвращает последние попытки поручения, новые первыми
+func (mr *MandateRepository) GetMandateRuns(ctx context.Context, mandateId int64, limit int) ([]domain.MandateRun, error) {
+	query := `SELECT id, mandate_id, occurrence, attempt, scheduled_at, status,
+                     COALESCE(transaction_id, 0), failure_code, executed_at
+              FROM mandate_runs
+              WHERE mandate_id = $1
+              ORDER BY id DESC
+              LIMIT $2`
+
+	rows, err := mr.db.Query(ctx, query, mandateId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get mandate runs: %w", domain.ErrInternal, err)
+	}
+	defer rows.Close()
+
+	runs := make([]domain.MandateRun, 0, limit)
+
+	for rows.Next() {
+		var r domain.MandateRun
+
+		err := rows.Scan(
+			&r.Id,
+			&r.MandateId,
+			&r.Occurrence,
+			&r.Attempt,
+			&r.ScheduledAt,
+			&r.Status,
+			&r.TransactionId,
+			&r.FailureCode,
+			&r.ExecutedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to scan mandate run: %w", domain.ErrInternal, err)
+		}
+
+		runs = append(runs, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: error while fetching rows: %w", domain.ErrInternal, err)
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"TransactionTest/internal/domain"
+)
+
+type ScheduledTransferRepository struct {
+	db IDB
+}
+
+func NewScheduledTransferRepository(db IDB) *ScheduledTransferRepository {
+	return &ScheduledTransferRepository{db: db}
+}
+
+// scheduledColumns колонки отложенного перевода в порядке, который ожидает scanScheduledTransfer
+const scheduledColumns = `id, from_wallet, to_wallet, amount, allow_convert, execute_at, status,
+              COALESCE(transaction_id, 0), failure_code, created_at, executed_at`
+
+func scanScheduledTransfer(row Row, s *domain.ScheduledTransfer) error {
+	return row.Scan(
+		&s.Id,
+		&s.From,
+		&s.To,
+		&s.Amount,
+		&s.Convert,
+		&s.ExecuteAt,
+		&s.Status,
+		&s.TransactionId,
+		&s.FailureCode,
+		&s.CreatedAt,
+		&s.ExecutedAt,
+	)
+}
+
+func (sr *ScheduledTransferRepository) CreateScheduledTransfer(ctx context.Context, req domain.ScheduledTransferRequest) (*domain.ScheduledTransfer, error) {
+	query := `INSERT INTO scheduled_transfers (from_wallet, to_wallet, amount, allow_convert, execute_at)
+              VALUES ($1, $2, $3, $4, $5)
+              RETURNING ` + scheduledColumns
+
+	var s domain.ScheduledTransfer
+
+	err := scanScheduledTransfer(sr.db.QueryRow(ctx, query, req.From, req.To, req.Amount, req.Convert, req.ExecuteAt.UTC()), &s)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok {
+			if dbErr.SQLState() == ErrCodeForeignKeyViolation {
+				return nil, fmt.Errorf("%w: wallet %s or %s", domain.ErrNotFound, req.From, req.To)
+			}
+			if dbErr.SQLState() == ErrCodeNumericOutOfRange {
+				return nil, fmt.Errorf("%w: amount %v", domain.ErrAmountOverflow, req.Amount)
+			}
+		}
+		return nil, fmt.Errorf("%w: failed to create scheduled transfer: %w", domain.ErrInternal, err)
+	}
+
+	return &s, nil
+}
+
+func (sr *ScheduledTransferRepository) GetScheduledTransfer(ctx context.Context, id int64) (*domain.ScheduledTransfer, error) {
+	query := `SELECT ` + scheduledColumns + `
+              FROM scheduled_transfers WHERE id = $1`
+
+	var s domain.ScheduledTransfer
+
+	err := scanScheduledTransfer(sr.db.QueryRow(ctx, query, id), &s)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: failed to find scheduled transfer %v: %w", domain.ErrInternal, id, err)
+	}
+
+	return &s, nil
+}
+
+// GetScheduledTransfers возвращает отложенные переводы по возрастанию времени выполнения.
+// Пустой status означает переводы в любом состоянии.
+func (sr *ScheduledTransferRepository) GetScheduledTransfers(ctx context.Context, status domain.ScheduledStatus, limit int) ([]domain.ScheduledTransfer, error) {
+	query := `SELECT ` + scheduledColumns + `
+              FROM scheduled_transfers
+              WHERE $1 = '' OR status = $1
+              ORDER BY execute_at, id
+              LIMIT $2`
+
+	rows, err := sr.db.Query(ctx, query, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get scheduled transfers: %w", domain.ErrInternal, err)
+	}
+	defer rows.Close()
+
+	transfers := make([]domain.ScheduledTransfer, 0, limit)
+
+	for rows.Next() {
+		var s domain.ScheduledTransfer
+
+		if err := scanScheduledTransfer(rows, &s); err != nil {
+			return nil, fmt.Errorf("%w: failed to scan scheduled transfer: %w", domain.ErrInternal, err)
+		}
+
+		transfers = append(transfers, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: error while fetching rows: %w", domain.ErrInternal, err)
+	}
+
+	return transfers, nil
+}
+
+// CancelScheduledTransfer отменяет перевод, который еще не начал выполняться
+func (sr *ScheduledTransferRepository) CancelScheduledTransfer(ctx context.Context, id int64) error {
+	query := `UPDATE scheduled_transfers SET status = 'canceled'
+              WHERE id = $1 AND status = 'pending'`
+
+	result, err := sr.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("%w: failed to cancel scheduled transfer %v: %w", domain.ErrInternal, id, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		// Отличаем отсутствующий перевод от уже выполненного или отмененного
+		if _, err := sr.GetScheduledTransfer(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrScheduledNotPending
+	}
+
+	return nil
+}
+
+// ClaimDueTransfer забирает на выполнение один наступивший перевод и переводит его в running.
+// Перевод, который завис в running дольше lease (процесс упал во время выполнения), забирается повторно.
+// SKIP LOCKED позволяет нескольким экземплярам сервиса разбирать очередь без блокировок друг друга.
+// Если забирать нечего, возвращает domain.ErrNotFound.
+func (sr *ScheduledTransferRepository) ClaimDueTransfer(ctx context.Context, lease time.Duration) (*domain.ScheduledTransfer, error) {
+	query := `UPDATE scheduled_transfers SET status = 'running', started_at = now()
+              WHERE id = (
+                  SELECT id FROM scheduled_transfers
+                  WHERE (status = 'pending' AND execute_at <= now())
+                     OR (status = 'running' AND started_at <= now() - make_interval(secs => $1))
+                  ORDER BY execute_at, id
+                  LIMIT 1
+                  FOR UPDATE SKIP LOCKED
+              )
+              RETURNING ` + scheduledColumns
+
+	var s domain.ScheduledTransfer
+
+	err := scanScheduledTransfer(sr.db.QueryRow(ctx, query, lease.Seconds()), &s)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: failed to claim scheduled transfer: %w", domain.ErrInternal, err)
+	}
+
+	return &s, nil
+}
+
+// FinishScheduledTransfer записывает результат выполнения перевода, забранного ClaimDueTransfer.
+// Для успешного перевода передается созданная транзакция, для неудачного - код ошибки.
+func (sr *ScheduledTransferRepository) FinishScheduledTransfer(ctx context.Context, id int64, status domain.ScheduledStatus, transactionId int64, failureCode domain.ErrorCode) error {
+	query := `UPDATE scheduled_transfers
+              SET status = $1, transaction_id = NULLIF($2, 0), failure_code = $3, executed_at = now()
+              WHERE id = $4 AND status = 'running'`
+
+	result, err := sr.db.Exec(ctx, query, status, transactionId, failureCode, id)
+	if err != nil {
+		return fmt.Errorf("%w: failed to finish scheduled transfer %v: %w", domain.ErrInternal, id, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"TransactionTest/internal/domain"
+)
+
+// SchemaRepository состояние подключения к БД и схемы для проверок готовности
+type SchemaRepository struct {
+	db IDB
+}
+
+func NewSchemaRepository(db IDB) *SchemaRepository {
+	return &SchemaRepository{db: db}
+}
+
+// Ping проверяет, что из пула можно получить соединение и выполнить запрос
+func (sr *SchemaRepository) Ping(ctx context.Context) error {
+	var one int
+	if err := sr.db.QueryRow(ctx, `SELECT 1`).Scan(&one); err != nil {
+		return fmt.Errorf("%w: failed to ping database: %w", domain.ErrInternal, err)
+	}
+	return nil
+}
+
+// MigrationVersion возвращает версию схемы из таблицы golang-migrate и признак незавершенной миграции.
+// Если миграции еще не запускались, возвращает domain.ErrNotFound.
+func (sr *SchemaRepository) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	query := `SELECT version, dirty FROM schema_migrations LIMIT 1`
+
+	var (
+		version int64
+		dirty   bool
+	)
+	if err := sr.db.QueryRow(ctx, query).Scan(&version, &dirty); err != nil {
+		if dbErr, ok := err.(DBError); ok {
+			switch dbErr.SQLState() {
+			case "no_rows", ErrCodeUndefinedTable:
+				return 0, false, domain.ErrNotFound
+			}
+		}
+		return 0, false, fmt.Errorf("%w: failed to read migration version: %w", domain.ErrInternal, err)
+	}
+	return uint(version), dirty, nil
+}
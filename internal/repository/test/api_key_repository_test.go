@@ -0,0 +1,61 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAPIKeyRepository_CreateAPIKey_Success(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			gotArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				assert.Len(t, dest, 5)
+				*dest[0].(*int64) = 2
+				*dest[1].(*string) = "billing"
+				*dest[2].(*[]string) = []string{"read", "transfer"}
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewAPIKeyRepository(mockDB)
+	key, err := repo.CreateAPIKey(ctx, "billing", "hash", []domain.Scope{domain.ScopeRead, domain.ScopeTransfer})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), key.Id)
+	assert.Equal(t, []domain.Scope{domain.ScopeRead, domain.ScopeTransfer}, key.Scopes)
+	assert.Nil(t, key.RevokedAt)
+	assert.Equal(t, []interface{}{"billing", "hash", []string{"read", "transfer"}}, gotArgs)
+}
+
+func TestAPIKeyRepository_GetAPIKeyByHash_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: "no_rows"}
+			}}
+		},
+	}
+	repo := repository.NewAPIKeyRepository(mockDB)
+	key, err := repo.GetAPIKeyByHash(ctx, "hash")
+	assert.Nil(t, key)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestAPIKeyRepository_RevokeAPIKey_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, arguments ...interface{}) (repository.CommandTag, error) {
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 0 }}, nil
+		},
+	}
+	repo := repository.NewAPIKeyRepository(mockDB)
+	err := repo.RevokeAPIKey(ctx, 9)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
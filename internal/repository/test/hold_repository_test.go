@@ -0,0 +1,102 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestHoldRepository_CreateHoldTx_Success(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			gotArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				assert.Len(t, dest, 10)
+				*dest[0].(*int64) = 7
+				*dest[5].(*domain.HoldStatus) = domain.HoldActive
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewHoldRepository(nil)
+	hold, err := repo.CreateHoldTx(ctx, *mockTx, "from", "to", "USD", domain.MustParseMoney("25"), 15*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), hold.Id)
+	assert.True(t, hold.IsActive())
+	assert.Equal(t, []interface{}{"from", "to", domain.MustParseMoney("25"), "USD", float64(900)}, gotArgs)
+}
+
+func TestHoldRepository_CreateHoldTx_WalletNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: repository.ErrCodeForeignKeyViolation}
+			}}
+		},
+	}
+	repo := repository.NewHoldRepository(nil)
+	_, err := repo.CreateHoldTx(ctx, *mockTx, "from", "to", "USD", 10, time.Minute)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestHoldRepository_GetHold_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: "no_rows"}
+			}}
+		},
+	}
+	repo := repository.NewHoldRepository(mockDB)
+	hold, err := repo.GetHold(ctx, 7)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+	assert.Nil(t, hold)
+}
+
+func TestHoldRepository_CloseHoldTx_Success(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	mockTx := &MockTx{
+		ExecFunc: func(ctx context.Context, sql string, arguments ...interface{}) (repository.CommandTag, error) {
+			gotArgs = arguments
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 1 }}, nil
+		},
+	}
+	repo := repository.NewHoldRepository(nil)
+	err := repo.CloseHoldTx(ctx, *mockTx, 7, domain.HoldCaptured, domain.MustParseMoney("10"), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{domain.HoldCaptured, domain.MustParseMoney("10"), int64(3), int64(7)}, gotArgs)
+}
+
+func TestHoldRepository_CloseHoldTx_NotActive(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		ExecFunc: func(ctx context.Context, sql string, arguments ...interface{}) (repository.CommandTag, error) {
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 0 }}, nil
+		},
+	}
+	repo := repository.NewHoldRepository(nil)
+	err := repo.CloseHoldTx(ctx, *mockTx, 7, domain.HoldVoided, 0, 0)
+	assert.True(t, errors.Is(err, domain.ErrHoldNotActive))
+}
+
+func TestHoldRepository_ExpireHolds(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, arguments ...interface{}) (repository.CommandTag, error) {
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 4 }}, nil
+		},
+	}
+	repo := repository.NewHoldRepository(mockDB)
+	expired, err := repo.ExpireHolds(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), expired)
+}
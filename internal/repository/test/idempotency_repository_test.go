@@ -0,0 +1,92 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyRepository_ReserveKeyTx_NewKey(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*string) = "k1"
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewIdempotencyRepository(nil)
+	record, err := repo.ReserveKeyTx(ctx, *mockTx, "k1", "hash", time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+}
+
+func TestIdempotencyRepository_ReserveKeyTx_ExistingKey(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			calls++
+			if calls == 1 {
+				return &MockRow{ScanFunc: func(dest ...interface{}) error {
+					return &mockDBError{sqlState: "no_rows"}
+				}}
+			}
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*string) = "k1"
+				*dest[1].(*string) = "hash"
+				*dest[2].(*int64) = 42
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewIdempotencyRepository(nil)
+	record, err := repo.ReserveKeyTx(ctx, *mockTx, "k1", "hash", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), record.TransactionId)
+	assert.Equal(t, "hash", record.RequestHash)
+}
+
+func TestIdempotencyRepository_ReserveKeyTx_InternalError(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return errors.New("fail")
+			}}
+		},
+	}
+	repo := repository.NewIdempotencyRepository(nil)
+	_, err := repo.ReserveKeyTx(ctx, *mockTx, "k1", "hash", time.Hour)
+	assert.True(t, errors.Is(err, domain.ErrInternal))
+}
+
+func TestIdempotencyRepository_CompleteKeyTx_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 0 }}, nil
+		},
+	}
+	repo := repository.NewIdempotencyRepository(nil)
+	err := repo.CompleteKeyTx(ctx, *mockTx, "k1", 42)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestIdempotencyRepository_RemoveExpiredKeys(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 5 }}, nil
+		},
+	}
+	repo := repository.NewIdempotencyRepository(mockDB)
+	removed, err := repo.RemoveExpiredKeys(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), removed)
+}
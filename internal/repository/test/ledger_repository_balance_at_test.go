@@ -0,0 +1,75 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLedgerRepository_GetBalanceAt_Success(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2025, 3, 3, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	var gotArgs []interface{}
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			gotArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*domain.Money) = domain.MustParseMoney("42")
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewLedgerRepository(mockDB)
+	balance, err := repo.GetBalanceAt(ctx, "addr", at)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MustParseMoney("42"), balance)
+	// Время передается в UTC, как хранятся created_at проводок
+	assert.Equal(t, []interface{}{"addr", at.UTC()}, gotArgs)
+	assert.Equal(t, time.UTC, gotArgs[1].(time.Time).Location())
+}
+
+func TestLedgerRepository_GetBalanceAt_WalletNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: "no_rows"}
+			}}
+		},
+	}
+	repo := repository.NewLedgerRepository(mockDB)
+	_, err := repo.GetBalanceAt(ctx, "addr", time.Now())
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestLedgerRepository_CreateBalanceSnapshots(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+			gotArgs = args
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 3 }}, nil
+		},
+	}
+	repo := repository.NewLedgerRepository(mockDB)
+	created, err := repo.CreateBalanceSnapshots(ctx, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), created)
+	assert.Equal(t, []interface{}{float64(60)}, gotArgs)
+}
+
+func TestLedgerRepository_CreateBalanceSnapshots_Error(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+			return nil, errors.New("db down")
+		},
+	}
+	repo := repository.NewLedgerRepository(mockDB)
+	_, err := repo.CreateBalanceSnapshots(ctx, time.Minute)
+	assert.True(t, errors.Is(err, domain.ErrInternal))
+}
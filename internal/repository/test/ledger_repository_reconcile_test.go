@@ -0,0 +1,93 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestLedgerRepository_RecordEntryTx_DoesNotTouchWallets(t *testing.T) {
+	ctx := context.Background()
+	mockTx, statements := newLedgerTx(nil)
+	repo := repository.NewLedgerRepository(nil)
+	check := domain.BalanceCheck{Address: "addr", Currency: "USD", Stored: 150, Expected: 100}
+	id, err := repo.RecordEntryTx(ctx, *mockTx, check.CorrectionEntry())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), id)
+	assert.Len(t, *statements, 2)
+	for _, s := range *statements {
+		assert.False(t, strings.Contains(s, "UPDATE wallets"))
+	}
+}
+
+func TestLedgerRepository_GetBalanceCheckForUpdateTx_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockTx := MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: "no_rows"}
+			}}
+		},
+	}
+	repo := repository.NewLedgerRepository(nil)
+	_, err := repo.GetBalanceCheckForUpdateTx(ctx, mockTx, "addr")
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+// balanceCheckTx эмулирует кошелек addr с балансом и суммой проводок.
+// Запрос блокировки дожидается перевода transfer, закоммиченного другой транзакцией:
+// после него меняются и баланс, и проводки.
+func balanceCheckTx(t *testing.T, stored, postings, transfer domain.Money) (MockTx, *[]string) {
+	var statements []string
+	mockTx := MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			statements = append(statements, sql)
+			if strings.Contains(sql, "FOR UPDATE") {
+				assert.NotContains(t, sql, "postings")
+				stored += transfer
+				postings += transfer
+				return &MockRow{ScanFunc: func(dest ...interface{}) error {
+					*dest[0].(*string) = "addr"
+					return nil
+				}}
+			}
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*string) = "addr"
+				*dest[1].(*string) = "USD"
+				*dest[2].(*domain.Money) = stored
+				*dest[3].(*domain.Money) = postings
+				return nil
+			}}
+		},
+	}
+	return mockTx, &statements
+}
+
+func TestLedgerRepository_GetBalanceCheckForUpdateTx_Success(t *testing.T) {
+	ctx := context.Background()
+	mockTx, statements := balanceCheckTx(t, 150, 100, 0)
+	repo := repository.NewLedgerRepository(nil)
+	check, err := repo.GetBalanceCheckForUpdateTx(ctx, mockTx, "addr")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Money(50), check.Drift())
+	assert.Len(t, *statements, 2)
+}
+
+func TestLedgerRepository_GetBalanceCheckForUpdateTx_TransferDuringLock(t *testing.T) {
+	ctx := context.Background()
+	// Расхождения нет, пока сверка ждет блокировку, проходит перевод на 30
+	mockTx, statements := balanceCheckTx(t, 100, 100, 30)
+	repo := repository.NewLedgerRepository(nil)
+	check, err := repo.GetBalanceCheckForUpdateTx(ctx, mockTx, "addr")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Money(130), check.Stored)
+	assert.Equal(t, domain.Money(0), check.Drift())
+	// Блокировка первым запросом, баланс и проводки - вторым, без FOR UPDATE
+	assert.Len(t, *statements, 2)
+	assert.Contains(t, (*statements)[0], "FOR UPDATE")
+	assert.NotContains(t, (*statements)[1], "FOR UPDATE")
+}
@@ -0,0 +1,178 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newLedgerTx(execErr func(sql string, args []interface{}) error) (*MockTx, *[]string) {
+	var statements []string
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*int64) = 10
+				return nil
+			}}
+		},
+		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+			statements = append(statements, sql)
+			if execErr != nil {
+				if err := execErr(sql, args); err != nil {
+					return nil, err
+				}
+			}
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 1 }}, nil
+		},
+	}
+	return mockTx, &statements
+}
+
+func TestLedgerRepository_PostEntryTx_Success(t *testing.T) {
+	ctx := context.Background()
+	mockTx, statements := newLedgerTx(nil)
+	repo := repository.NewLedgerRepository(nil)
+	entry := domain.NewTransferEntry(domain.EntryOpening, 0, domain.AccountEquity, "addr", "USD", 100)
+	id, err := repo.PostEntryTx(ctx, *mockTx, entry)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), id)
+	// Две проводки и одно обновление баланса: системный счет в wallets не проецируется
+	updates := 0
+	for _, s := range *statements {
+		if strings.Contains(s, "UPDATE wallets") {
+			updates++
+		}
+	}
+	assert.Len(t, *statements, 3)
+	assert.Equal(t, 1, updates)
+}
+
+func TestLedgerRepository_PostEntryTx_Unbalanced(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewLedgerRepository(nil)
+	entry := domain.JournalEntry{
+		Kind: domain.EntryTransfer,
+		Postings: []domain.Posting{
+			{Account: "from", Amount: -10},
+			{Account: "to", Amount: 9},
+		},
+	}
+	_, err := repo.PostEntryTx(ctx, MockTx{}, entry)
+	assert.True(t, errors.Is(err, domain.ErrUnbalancedEntry))
+}
+
+func TestLedgerRepository_PostEntryTx_InsufficientFunds(t *testing.T) {
+	ctx := context.Background()
+	mockTx, _ := newLedgerTx(func(sql string, args []interface{}) error {
+		if strings.Contains(sql, "UPDATE wallets") && args[1] == "from" {
+			return &mockDBError{sqlState: repository.ErrCodeCheckViolation, constraint: repository.ConstraintBalanceNonNegative}
+		}
+		return nil
+	})
+	repo := repository.NewLedgerRepository(nil)
+	_, err := repo.PostEntryTx(ctx, *mockTx, domain.NewTransferEntry(domain.EntryTransfer, 1, "from", "to", "USD", 10))
+	assert.True(t, errors.Is(err, domain.ErrInsufficientFunds))
+}
+
+func TestLedgerRepository_PostEntryTx_WalletNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockTx, _ := newLedgerTx(nil)
+	mockTx.ExecFunc = func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+		if strings.Contains(sql, "UPDATE wallets") {
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 0 }}, nil
+		}
+		return &MockCommandTag{RowsAffectedFunc: func() int64 { return 1 }}, nil
+	}
+	repo := repository.NewLedgerRepository(nil)
+	_, err := repo.PostEntryTx(ctx, *mockTx, domain.NewTransferEntry(domain.EntryTransfer, 1, "from", "to", "USD", 10))
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestLedgerRepository_PostEntryTx_Overflow(t *testing.T) {
+	ctx := context.Background()
+	mockTx, _ := newLedgerTx(func(sql string, args []interface{}) error {
+		if strings.Contains(sql, "UPDATE wallets") && args[1] == "to" {
+			return &mockDBError{sqlState: repository.ErrCodeNumericOutOfRange}
+		}
+		return nil
+	})
+	repo := repository.NewLedgerRepository(nil)
+	_, err := repo.PostEntryTx(ctx, *mockTx, domain.NewTransferEntry(domain.EntryTransfer, 1, "from", "to", "USD", 10))
+	assert.True(t, errors.Is(err, domain.ErrAmountOverflow))
+}
+
+func TestLedgerRepository_PostEntryTx_Deadlock(t *testing.T) {
+	ctx := context.Background()
+	mockTx, _ := newLedgerTx(func(sql string, args []interface{}) error {
+		return &mockDBError{sqlState: repository.ErrCodeDeadlockDetected}
+	})
+	repo := repository.NewLedgerRepository(nil)
+	_, err := repo.PostEntryTx(ctx, *mockTx, domain.NewTransferEntry(domain.EntryTransfer, 1, "from", "to", "USD", 10))
+	assert.True(t, errors.Is(err, domain.ErrTransactionConflict))
+}
+
+func TestLedgerRepository_GetAccountPostings_Success(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	calls := 0
+	mockDB := &MockDB{
+		QueryFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.Rows, error) {
+			return &MockRows{
+				NextFunc: func() bool {
+					calls++
+					return calls <= 2
+				},
+				ScanFunc: func(dest ...interface{}) error {
+					*dest[0].(*int64) = int64(calls)
+					*dest[1].(*int64) = 5
+					*dest[2].(*domain.EntryKind) = domain.EntryTransfer
+					*dest[3].(*int64) = 7
+					*dest[4].(*string) = "addr"
+					*dest[5].(*string) = "EUR"
+					*dest[6].(*domain.Money) = -10
+					*dest[7].(*time.Time) = now
+					return nil
+				},
+				CloseFunc: func() {},
+				ErrFunc:   func() error { return nil },
+			}, nil
+		},
+	}
+	repo := repository.NewLedgerRepository(mockDB)
+	postings, err := repo.GetAccountPostings(ctx, "addr", 10)
+	assert.NoError(t, err)
+	assert.Len(t, postings, 2)
+	assert.Equal(t, int64(7), postings[0].TransactionId)
+	assert.Equal(t, domain.Money(-10), postings[1].Amount)
+	assert.Equal(t, "EUR", postings[1].Currency)
+}
+
+func TestLedgerRepository_GetAccountPostings_QueryError(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.Rows, error) {
+			return nil, errors.New("fail")
+		},
+	}
+	repo := repository.NewLedgerRepository(mockDB)
+	_, err := repo.GetAccountPostings(ctx, "addr", 10)
+	assert.True(t, errors.Is(err, domain.ErrInternal))
+}
+
+func TestLedgerRepository_PostEntryTx_OverdraftExceeded(t *testing.T) {
+	ctx := context.Background()
+	mockTx, _ := newLedgerTx(func(sql string, args []interface{}) error {
+		if strings.Contains(sql, "UPDATE wallets") && args[1] == "from" {
+			return &mockDBError{sqlState: repository.ErrCodeCheckViolation, constraint: repository.ConstraintBalanceOverdraft}
+		}
+		return nil
+	})
+	repo := repository.NewLedgerRepository(nil)
+	_, err := repo.PostEntryTx(ctx, *mockTx, domain.NewTransferEntry(domain.EntryTransfer, 1, "from", "to", "USD", 10))
+	assert.True(t, errors.Is(err, domain.ErrInsufficientFunds))
+}
@@ -0,0 +1,153 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMandateRepository_CreateMandate_Success(t *testing.T) {
+	ctx := context.Background()
+	startAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	var gotArgs []interface{}
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			gotArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				assert.Len(t, dest, 18)
+				*dest[0].(*int64) = 5
+				*dest[11].(*domain.MandateStatus) = domain.MandateActive
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewMandateRepository(mockDB)
+	mandate, err := repo.CreateMandate(ctx, domain.Mandate{
+		From: "from", To: "to", Amount: 100,
+		Recurrence:  domain.Recurrence{Frequency: domain.FrequencyWeekly, Interval: 1},
+		StartAt:     startAt,
+		MaxRuns:     4,
+		ScheduledAt: startAt,
+		NextRunAt:   &startAt,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), mandate.Id)
+	assert.Equal(t, domain.MandateActive, mandate.Status)
+	assert.Len(t, gotArgs, 12)
+	assert.Equal(t, domain.FrequencyWeekly, gotArgs[4])
+}
+
+func TestMandateRepository_CreateMandate_WalletNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: repository.ErrCodeForeignKeyViolation}
+			}}
+		},
+	}
+	repo := repository.NewMandateRepository(mockDB)
+	_, err := repo.CreateMandate(ctx, domain.Mandate{From: "from", To: "to", Amount: 10})
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestMandateRepository_CancelMandate_NotActive(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, arguments ...interface{}) (repository.CommandTag, error) {
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 0 }}, nil
+		},
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[11].(*domain.MandateStatus) = domain.MandateCompleted
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewMandateRepository(mockDB)
+	err := repo.CancelMandate(ctx, 5)
+	assert.True(t, errors.Is(err, domain.ErrMandateNotActive))
+}
+
+func TestMandateRepository_ClaimDueMandate_Empty(t *testing.T) {
+	ctx := context.Background()
+	var query string
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			query = sql
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: "no_rows"}
+			}}
+		},
+	}
+	repo := repository.NewMandateRepository(mockDB)
+	_, err := repo.ClaimDueMandate(ctx, time.Minute)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+	assert.Contains(t, query, "SKIP LOCKED")
+}
+
+func TestMandateRepository_FinishMandateRun(t *testing.T) {
+	ctx := context.Background()
+	scheduledAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	nextRunAt := scheduledAt.Add(time.Hour)
+	var gotArgs []interface{}
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, arguments ...interface{}) (repository.CommandTag, error) {
+			gotArgs = arguments
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 1 }}, nil
+		},
+	}
+	repo := repository.NewMandateRepository(mockDB)
+	run := domain.MandateRun{MandateId: 5, Occurrence: 2, Attempt: 1, ScheduledAt: scheduledAt,
+		Status: domain.MandateRunRetrying, FailureCode: domain.CodeInsufficientFunds}
+	state := domain.Mandate{Id: 5, Status: domain.MandateActive, RunsCount: 1, Occurrence: 2, Attempt: 2,
+		ScheduledAt: scheduledAt, NextRunAt: &nextRunAt}
+	err := repo.FinishMandateRun(ctx, run, state)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		int64(5), 2, 1, scheduledAt, domain.MandateRunRetrying, int64(0), domain.CodeInsufficientFunds,
+		domain.MandateActive, 1, 2, 2, scheduledAt, &nextRunAt,
+	}, gotArgs)
+}
+
+func TestMandateRepository_FinishMandateRun_AlreadyRecorded(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, arguments ...interface{}) (repository.CommandTag, error) {
+			return nil, &mockDBError{sqlState: repository.ErrCodeUniqueViolation}
+		},
+	}
+	repo := repository.NewMandateRepository(mockDB)
+	err := repo.FinishMandateRun(ctx, domain.MandateRun{MandateId: 5, Occurrence: 1, Attempt: 1}, domain.Mandate{Id: 5})
+	assert.True(t, errors.Is(err, domain.ErrConflict))
+}
+
+func TestMandateRepository_GetMandateRuns(t *testing.T) {
+	ctx := context.Background()
+	ids := []int64{12, 11}
+	i := -1
+	mockDB := &MockDB{
+		QueryFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.Rows, error) {
+			assert.Equal(t, []interface{}{int64(5), 10}, args)
+			return &MockRows{
+				NextFunc: func() bool { i++; return i < len(ids) },
+				ScanFunc: func(dest ...interface{}) error {
+					*dest[0].(*int64) = ids[i]
+					*dest[1].(*int64) = 5
+					return nil
+				},
+				CloseFunc: func() {},
+				ErrFunc:   func() error { return nil },
+			}, nil
+		},
+	}
+	repo := repository.NewMandateRepository(mockDB)
+	runs, err := repo.GetMandateRuns(ctx, 5, 10)
+	assert.NoError(t, err)
+	assert.Len(t, runs, 2)
+	assert.Equal(t, int64(12), runs[0].Id)
+}
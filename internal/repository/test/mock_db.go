@@ -31,7 +31,7 @@ type MockTx struct {
 	RollbackFunc func(ctx context.Context) error
 	ExecFunc     func(ctx context.Context, sql string, arguments ...interface{}) (repository.CommandTag, error)
 	QueryRowFunc func(ctx context.Context, sql string, args ...interface{}) repository.Row
-	QueryFunc    func(ctx context.Context, sql string, args ...interface{}) (repository.Rows, error)
+	QueryFunc    func(ctx context.Context, sql string, args ...interface{}) (domain.Rows, error)
 }
 
 func (m MockTx) Commit(ctx context.Context) error   { return m.CommitFunc(ctx) }
@@ -42,7 +42,7 @@ func (m MockTx) Exec(ctx context.Context, sql string, arguments ...interface{})
 func (m MockTx) QueryRow(ctx context.Context, sql string, args ...interface{}) domain.Row {
 	return m.QueryRowFunc(ctx, sql, args...)
 }
-func (m MockTx) Query(ctx context.Context, sql string, args ...interface{}) (repository.Rows, error) {
+func (m MockTx) Query(ctx context.Context, sql string, args ...interface{}) (domain.Rows, error) {
 	return m.QueryFunc(ctx, sql, args...)
 }
 
@@ -0,0 +1,137 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestScheduledTransferRepository_CreateScheduledTransfer_Success(t *testing.T) {
+	ctx := context.Background()
+	executeAt := time.Date(2026, 11, 1, 9, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	var gotArgs []interface{}
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			gotArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				assert.Len(t, dest, 11)
+				*dest[0].(*int64) = 3
+				*dest[6].(*domain.ScheduledStatus) = domain.ScheduledPending
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewScheduledTransferRepository(mockDB)
+	scheduled, err := repo.CreateScheduledTransfer(ctx, domain.ScheduledTransferRequest{
+		From: "from", To: "to", Amount: domain.MustParseMoney("1500"), Convert: true, ExecuteAt: executeAt,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), scheduled.Id)
+	assert.Equal(t, domain.ScheduledPending, scheduled.Status)
+	assert.Equal(t, []interface{}{"from", "to", domain.MustParseMoney("1500"), true, executeAt.UTC()}, gotArgs)
+}
+
+func TestScheduledTransferRepository_CreateScheduledTransfer_WalletNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: repository.ErrCodeForeignKeyViolation}
+			}}
+		},
+	}
+	repo := repository.NewScheduledTransferRepository(mockDB)
+	_, err := repo.CreateScheduledTransfer(ctx, domain.ScheduledTransferRequest{From: "from", To: "to", Amount: 10})
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestScheduledTransferRepository_CancelScheduledTransfer_NotPending(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, arguments ...interface{}) (repository.CommandTag, error) {
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 0 }}, nil
+		},
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[6].(*domain.ScheduledStatus) = domain.ScheduledSucceeded
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewScheduledTransferRepository(mockDB)
+	err := repo.CancelScheduledTransfer(ctx, 3)
+	assert.True(t, errors.Is(err, domain.ErrScheduledNotPending))
+}
+
+func TestScheduledTransferRepository_CancelScheduledTransfer_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, arguments ...interface{}) (repository.CommandTag, error) {
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 0 }}, nil
+		},
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: "no_rows"}
+			}}
+		},
+	}
+	repo := repository.NewScheduledTransferRepository(mockDB)
+	err := repo.CancelScheduledTransfer(ctx, 3)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestScheduledTransferRepository_ClaimDueTransfer(t *testing.T) {
+	ctx := context.Background()
+	var query string
+	var gotArgs []interface{}
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			query = sql
+			gotArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*int64) = 3
+				*dest[6].(*domain.ScheduledStatus) = domain.ScheduledRunning
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewScheduledTransferRepository(mockDB)
+	scheduled, err := repo.ClaimDueTransfer(ctx, 5*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), scheduled.Id)
+	assert.Equal(t, []interface{}{float64(300)}, gotArgs)
+	assert.Contains(t, query, "SKIP LOCKED")
+}
+
+func TestScheduledTransferRepository_ClaimDueTransfer_Empty(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: "no_rows"}
+			}}
+		},
+	}
+	repo := repository.NewScheduledTransferRepository(mockDB)
+	_, err := repo.ClaimDueTransfer(ctx, time.Minute)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestScheduledTransferRepository_FinishScheduledTransfer(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, arguments ...interface{}) (repository.CommandTag, error) {
+			gotArgs = arguments
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 1 }}, nil
+		},
+	}
+	repo := repository.NewScheduledTransferRepository(mockDB)
+	err := repo.FinishScheduledTransfer(ctx, 3, domain.ScheduledFailed, 0, domain.CodeInsufficientFunds)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{domain.ScheduledFailed, int64(0), domain.CodeInsufficientFunds, int64(3)}, gotArgs)
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSchemaRepository_MigrationVersion_Success(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*int64) = 21
+				*dest[1].(*bool) = true
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewSchemaRepository(mockDB)
+	version, dirty, err := repo.MigrationVersion(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(21), version)
+	assert.True(t, dirty)
+}
+
+func TestSchemaRepository_MigrationVersion_NoTable(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: repository.ErrCodeUndefinedTable}
+			}}
+		},
+	}
+	repo := repository.NewSchemaRepository(mockDB)
+	_, _, err := repo.MigrationVersion(ctx)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestSchemaRepository_Ping_Error(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return errors.New("connection refused")
+			}}
+		},
+	}
+	repo := repository.NewSchemaRepository(mockDB)
+	err := repo.Ping(ctx)
+	assert.True(t, errors.Is(err, domain.ErrInternal))
+}
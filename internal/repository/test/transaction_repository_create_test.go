@@ -101,7 +101,7 @@ func TestTransactionRepository_CreateTransactionTx_Success(t *testing.T) {
 		},
 	}
 	repo := repository.NewTransactionRepository(nil)
-	id, err := repo.CreateTransactionTx(ctx, mockTx, "from", "to", 10)
+	id, err := repo.CreateTransactionTx(ctx, mockTx, "from", "to", 10, 0, domain.TransferDetails{})
 	assert.NoError(t, err)
 	assert.Equal(t, int64(42), id)
 }
@@ -116,7 +116,7 @@ func TestTransactionRepository_CreateTransactionTx_NegativeAmount(t *testing.T)
 		},
 	}
 	repo := repository.NewTransactionRepository(nil)
-	_, err := repo.CreateTransactionTx(ctx, *mockTx, "from", "to", -10)
+	_, err := repo.CreateTransactionTx(ctx, *mockTx, "from", "to", -10, 0, domain.TransferDetails{})
 	assert.True(t, errors.Is(err, domain.ErrNegativeAmount))
 }
 
@@ -130,7 +130,7 @@ func TestTransactionRepository_CreateTransactionTx_SelfTransfer(t *testing.T) {
 		},
 	}
 	repo := repository.NewTransactionRepository(nil)
-	_, err := repo.CreateTransactionTx(ctx, *mockTx, "from", "from", 10)
+	_, err := repo.CreateTransactionTx(ctx, *mockTx, "from", "from", 10, 0, domain.TransferDetails{})
 	assert.True(t, errors.Is(err, domain.ErrSelfTransfer))
 }
 
@@ -144,7 +144,7 @@ func TestTransactionRepository_CreateTransactionTx_FKViolation(t *testing.T) {
 		},
 	}
 	repo := repository.NewTransactionRepository(nil)
-	_, err := repo.CreateTransactionTx(ctx, *mockTx, "from", "to", 10)
+	_, err := repo.CreateTransactionTx(ctx, *mockTx, "from", "to", 10, 0, domain.TransferDetails{})
 	assert.True(t, errors.Is(err, domain.ErrNotFound))
 }
 
@@ -158,6 +158,52 @@ func TestTransactionRepository_CreateTransactionTx_InternalError(t *testing.T) {
 		},
 	}
 	repo := repository.NewTransactionRepository(nil)
-	_, err := repo.CreateTransactionTx(ctx, *mockTx, "from", "to", 10)
+	_, err := repo.CreateTransactionTx(ctx, *mockTx, "from", "to", 10, 0, domain.TransferDetails{})
 	assert.True(t, errors.Is(err, domain.ErrInternal))
 }
+
+func TestTransactionRepository_CreateTransactionTx_Overflow(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: repository.ErrCodeNumericOutOfRange}
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	_, err := repo.CreateTransactionTx(ctx, *mockTx, "from", "to", domain.MaxMoney, 0, domain.TransferDetails{})
+	assert.True(t, errors.Is(err, domain.ErrAmountOverflow))
+}
+
+func TestTransactionRepository_CreateTransactionTx_SerializationFailure(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: repository.ErrCodeSerializationFail}
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	_, err := repo.CreateTransactionTx(ctx, *mockTx, "from", "to", 10, 0, domain.TransferDetails{})
+	assert.True(t, errors.Is(err, domain.ErrTransactionConflict))
+}
+
+func TestTransactionRepository_CreateTransactionTx_Fee(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			gotArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*int64) = 42
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	_, err := repo.CreateTransactionTx(ctx, *mockTx, "from", "to", 1000, 15, domain.TransferDetails{})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"from", "to", domain.Money(1000), domain.Money(15), "", "", []byte(nil)}, gotArgs)
+}
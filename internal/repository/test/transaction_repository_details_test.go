@@ -0,0 +1,84 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTransactionRepository_CreateTransactionTx_Details(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			gotArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*int64) = 42
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	details := domain.TransferDetails{
+		Memo:              "Оплата заказа",
+		ExternalReference: "order-1001",
+		Metadata:          map[string]interface{}{"order": "1001", "items": 3},
+	}
+	_, err := repo.CreateTransactionTx(ctx, *mockTx, "from", "to", 1000, 0, details)
+	assert.NoError(t, err)
+	assert.Equal(t, "Оплата заказа", gotArgs[4])
+	assert.Equal(t, "order-1001", gotArgs[5])
+	assert.JSONEq(t, `{"order": "1001", "items": 3}`, string(gotArgs[6].([]byte)))
+}
+
+func TestTransactionRepository_CreateTransactionTx_DuplicateReference(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: repository.ErrCodeUniqueViolation, constraint: repository.ConstraintUniqueExternalRef}
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	_, err := repo.CreateTransactionTx(ctx, *mockTx, "from", "to", 1000, 0, domain.TransferDetails{ExternalReference: "order-1001"})
+	assert.True(t, errors.Is(err, domain.ErrDuplicateReference))
+}
+
+func TestTransactionRepository_GetTransactionsByExternalReference(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	i := -1
+	mockDB := &MockDB{
+		QueryFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.Rows, error) {
+			gotArgs = args
+			return &MockRows{
+				NextFunc: func() bool { i++; return i < 1 },
+				ScanFunc: func(dest ...interface{}) error {
+					assert.Len(t, dest, 16)
+					*dest[0].(*int64) = 42
+					*dest[12].(*string) = "Оплата заказа"
+					*dest[13].(*string) = "order-1001"
+					*dest[14].(*[]byte) = []byte(`{"order": "1001"}`)
+					*dest[15].(*domain.TransactionKind) = domain.KindTransfer
+					return nil
+				},
+				CloseFunc: func() {},
+				ErrFunc:   func() error { return nil },
+			}, nil
+		},
+	}
+	repo := repository.NewTransactionRepository(mockDB)
+	transactions, err := repo.GetTransactionsByExternalReference(ctx, "order-1001", "from")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"order-1001", "from"}, gotArgs)
+	assert.Len(t, transactions, 1)
+	assert.Equal(t, domain.TransferDetails{
+		Memo:              "Оплата заказа",
+		ExternalReference: "order-1001",
+		Metadata:          map[string]interface{}{"order": "1001"},
+	}, transactions[0].Details)
+}
@@ -0,0 +1,101 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func testExchange() domain.Exchange {
+	rate, _ := domain.ParseRate("0.9154")
+	return domain.Exchange{
+		ToCurrency: "EUR",
+		ToAmount:   domain.MustParseMoney("91.54"),
+		Rate:       rate,
+		RateAt:     time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestTransactionRepository_CreateExchangeTx_Success(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			gotArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*int64) = 5
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	exchange := testExchange()
+	id, err := repo.CreateExchangeTx(ctx, *mockTx, "from", "to", domain.MustParseMoney("100"), domain.MustParseMoney("1.50"), exchange, domain.TransferDetails{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), id)
+	assert.Equal(t, []interface{}{"from", "to", domain.MustParseMoney("100"), domain.MustParseMoney("1.50"), "EUR", exchange.ToAmount, exchange.Rate, exchange.RateAt, "", "", []byte(nil)}, gotArgs)
+}
+
+func TestTransactionRepository_CreateExchangeTx_WalletNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: "no_rows"}
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	_, err := repo.CreateExchangeTx(ctx, *mockTx, "from", "to", 100, 0, testExchange(), domain.TransferDetails{})
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestTransactionRepository_GetTransactionById_Exchange(t *testing.T) {
+	ctx := context.Background()
+	exchange := testExchange()
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*int64) = 1
+				*dest[6].(*string) = "USD"
+				*dest[7].(*string) = exchange.ToCurrency
+				*dest[8].(*domain.Money) = exchange.ToAmount
+				*dest[9].(*domain.Rate) = exchange.Rate
+				*dest[10].(*time.Time) = exchange.RateAt
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(mockDB)
+	tr, err := repo.GetTransactionById(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, &exchange, tr.Exchange)
+}
+
+func TestTransactionRepository_CreateReversalTx_Exchange(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			gotArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*int64) = 43
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	exchange := testExchange()
+	original := &domain.Transaction{Id: 42, From: "from", To: "to", Amount: domain.MustParseMoney("100"), Currency: "USD", Exchange: &exchange}
+	_, err := repo.CreateReversalTx(ctx, *mockTx, original)
+	assert.NoError(t, err)
+	// Получатель возвращает зачисленные EUR, отправитель получает исходные USD
+	assert.Equal(t, []interface{}{
+		"to", "from", exchange.ToAmount, "EUR", int64(42), domain.KindTransfer,
+		"USD", domain.MustParseMoney("100"), exchange.Rate.Inverse(), exchange.RateAt,
+	}, gotArgs)
+}
@@ -18,7 +18,7 @@ func TestTransactionRepository_GetTransactionById_Success(t *testing.T) {
 				*dest[0].(*int64) = 1
 				*dest[1].(*string) = "from"
 				*dest[2].(*string) = "to"
-				*dest[3].(*float64) = 10
+				*dest[3].(*domain.Money) = domain.MustParseMoney("10")
 				*dest[4].(*time.Time) = time.Now()
 				return nil
 			}}
@@ -69,7 +69,7 @@ func TestTransactionRepository_GetTransactionByInfo_Success(t *testing.T) {
 				*dest[0].(*int64) = 1
 				*dest[1].(*string) = "from"
 				*dest[2].(*string) = "to"
-				*dest[3].(*float64) = 10
+				*dest[3].(*domain.Money) = domain.MustParseMoney("10")
 				*dest[4].(*time.Time) = time.Now()
 				return nil
 			}}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pageRows отдает транзакции с id из ids, время создания убывает вместе с id
+func pageRows(ids []int64, base time.Time) *MockRows {
+	i := -1
+	return &MockRows{
+		NextFunc: func() bool {
+			i++
+			return i < len(ids)
+		},
+		ScanFunc: func(dest ...interface{}) error {
+			*dest[0].(*int64) = ids[i]
+			*dest[1].(*string) = "from"
+			*dest[2].(*string) = "to"
+			*dest[4].(*time.Time) = base.Add(time.Duration(ids[i]) * time.Second)
+			return nil
+		},
+		CloseFunc: func() {},
+		ErrFunc:   func() error { return nil },
+	}
+}
+
+func TestTransactionRepository_ListTransactions_NextCursor(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	var gotArgs []interface{}
+	mockDB := &MockDB{
+		QueryFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.Rows, error) {
+			gotArgs = args
+			return pageRows([]int64{9, 8, 7}, base), nil
+		},
+	}
+	repo := repository.NewTransactionRepository(mockDB)
+	page, err := repo.ListTransactions(ctx, domain.TransactionFilter{Order: domain.SortDesc, Role: domain.WalletRoleAny, Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Transactions, 2)
+	assert.Equal(t, []interface{}{3}, gotArgs)
+	assert.Equal(t, &domain.TransactionCursor{Order: domain.SortDesc, CreatedAt: base.Add(8 * time.Second), Id: 8}, page.Next)
+}
+
+func TestTransactionRepository_ListTransactions_LastPage(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.Rows, error) {
+			return pageRows([]int64{2, 1}, time.Now()), nil
+		},
+	}
+	repo := repository.NewTransactionRepository(mockDB)
+	page, err := repo.ListTransactions(ctx, domain.TransactionFilter{Order: domain.SortDesc, Role: domain.WalletRoleAny, Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Transactions, 2)
+	assert.Nil(t, page.Next)
+}
+
+func TestTransactionRepository_ListTransactions_SenderAfterCursor(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	var gotSQL string
+	var gotArgs []interface{}
+	mockDB := &MockDB{
+		QueryFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.Rows, error) {
+			gotSQL, gotArgs = sql, args
+			return pageRows(nil, at), nil
+		},
+	}
+	repo := repository.NewTransactionRepository(mockDB)
+	_, err := repo.ListTransactions(ctx, domain.TransactionFilter{
+		Wallet:    "w",
+		Role:      domain.WalletRoleSender,
+		MinAmount: domain.MustParseMoney("1"),
+		Order:     domain.SortAsc,
+		After:     &domain.TransactionCursor{Order: domain.SortAsc, CreatedAt: at, Id: 5},
+		Limit:     10,
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, gotSQL, "WHERE from_wallet = $5 AND amount >= $1 AND created_at >= $2 AND (created_at > $2 OR id > $3)")
+	assert.Contains(t, gotSQL, "ORDER BY created_at ASC, id ASC")
+	assert.NotContains(t, gotSQL, "UNION ALL")
+	assert.Equal(t, []interface{}{domain.MustParseMoney("1"), at, int64(5), 11, "w"}, gotArgs)
+}
+
+func TestTransactionRepository_ListTransactions_AnyRoleUsesBothIndexes(t *testing.T) {
+	ctx := context.Background()
+	var gotSQL string
+	mockDB := &MockDB{
+		QueryFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.Rows, error) {
+			gotSQL = sql
+			return pageRows(nil, time.Now()), nil
+		},
+	}
+	repo := repository.NewTransactionRepository(mockDB)
+	_, err := repo.ListTransactions(ctx, domain.TransactionFilter{Wallet: "w", Role: domain.WalletRoleAny, Order: domain.SortDesc, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(gotSQL, "UNION ALL"))
+	assert.Contains(t, gotSQL, "WHERE from_wallet = $2")
+	assert.Contains(t, gotSQL, "WHERE to_wallet = $2")
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTransactionRepository_CreateReversalTx_Success(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			gotArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*int64) = 43
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	original := &domain.Transaction{Id: 42, From: "from", To: "to", Amount: 10, Currency: "EUR"}
+	id, err := repo.CreateReversalTx(ctx, *mockTx, original)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(43), id)
+	// Отмена идет в обратную сторону и ссылается на исходную транзакцию
+	assert.Equal(t, []interface{}{"to", "from", domain.Money(10), "EUR", int64(42), domain.KindTransfer}, gotArgs)
+}
+
+func TestTransactionRepository_CreateReversalTx_KeepsKind(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			gotArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error { return nil }}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	original := &domain.Transaction{Id: 42, Kind: domain.KindDeposit, From: "treasury", To: "to", Amount: 10, Currency: "USD"}
+	_, err := repo.CreateReversalTx(ctx, *mockTx, original)
+	assert.NoError(t, err)
+	// Отмена пополнения остается пополнением с обратным направлением
+	assert.Equal(t, domain.KindDeposit, gotArgs[5])
+}
+
+func TestTransactionRepository_CreateReversalTx_AlreadyReversed(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: repository.ErrCodeUniqueViolation, constraint: repository.ConstraintUniqueReversal}
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	_, err := repo.CreateReversalTx(ctx, *mockTx, &domain.Transaction{Id: 42})
+	assert.True(t, errors.Is(err, domain.ErrAlreadyReversed))
+}
+
+func TestTransactionRepository_GetTransactionForUpdateTx_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: "no_rows"}
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	_, err := repo.GetTransactionForUpdateTx(ctx, *mockTx, 42)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestTransactionRepository_GetTransactionForUpdateTx_Success(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*int64) = 43
+				*dest[5].(*int64) = 42
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	tr, err := repo.GetTransactionForUpdateTx(ctx, *mockTx, 43)
+	assert.NoError(t, err)
+	assert.True(t, tr.IsReversal())
+	assert.Equal(t, int64(42), tr.ReversalOf)
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTransactionRepository_GetOpeningBalanceTx_Success(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2025, 3, 1, 3, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	var gotArgs []interface{}
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			gotArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*string) = "USD"
+				*dest[1].(*domain.Money) = domain.MustParseMoney("100")
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	currency, balance, err := repo.GetOpeningBalanceTx(ctx, mockTx, "addr", from)
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", currency)
+	assert.Equal(t, domain.MustParseMoney("100"), balance)
+	assert.Equal(t, []interface{}{"addr", from.UTC()}, gotArgs)
+}
+
+func TestTransactionRepository_GetOpeningBalanceTx_WalletNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: "no_rows"}
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	_, _, err := repo.GetOpeningBalanceTx(ctx, mockTx, "addr", time.Now())
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestTransactionRepository_GetStatementLinesTx_Success(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	var gotArgs []interface{}
+	i := -1
+	mockTx := &MockTx{
+		QueryFunc: func(ctx context.Context, sql string, args ...interface{}) (domain.Rows, error) {
+			gotArgs = args
+			return &MockRows{
+				NextFunc: func() bool {
+					i++
+					return i < 2
+				},
+				ScanFunc: func(dest ...interface{}) error {
+					*dest[0].(*int64) = int64(i + 1)
+					*dest[1].(*domain.EntryKind) = domain.EntryTransfer
+					*dest[2].(*int64) = 7
+					*dest[3].(*string) = "other"
+					*dest[4].(*bool) = i == 1
+					*dest[6].(*domain.Money) = domain.MustParseMoney("-1")
+					return nil
+				},
+				CloseFunc: func() {},
+				ErrFunc:   func() error { return nil },
+			}, nil
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	lines, err := repo.GetStatementLinesTx(ctx, mockTx, "addr", from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"addr", from, to}, gotArgs)
+	assert.Len(t, lines, 2)
+	assert.False(t, lines[0].Fee)
+	assert.True(t, lines[1].Fee)
+	assert.Equal(t, "other", lines[1].Counterparty)
+}
+
+func TestTransactionRepository_GetStatementLinesTx_QueryError(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		QueryFunc: func(ctx context.Context, sql string, args ...interface{}) (domain.Rows, error) {
+			return nil, errors.New("db down")
+		},
+	}
+	repo := repository.NewTransactionRepository(nil)
+	_, err := repo.GetStatementLinesTx(ctx, mockTx, "addr", time.Now(), time.Now())
+	assert.True(t, errors.Is(err, domain.ErrInternal))
+}
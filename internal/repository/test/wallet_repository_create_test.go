@@ -17,7 +17,7 @@ func TestWalletRepository_CreateWallet_Success(t *testing.T) {
 		},
 	}
 	repo := repository.NewWalletRepository(mockDB)
-	err := repo.CreateWallet(ctx, "addr", 100)
+	err := repo.CreateWallet(ctx, "addr", "USD", 100)
 	assert.NoError(t, err)
 }
 
@@ -29,7 +29,7 @@ func TestWalletRepository_CreateWallet_Duplicate(t *testing.T) {
 		},
 	}
 	repo := repository.NewWalletRepository(mockDB)
-	err := repo.CreateWallet(ctx, "addr", 100)
+	err := repo.CreateWallet(ctx, "addr", "USD", 100)
 	assert.True(t, errors.Is(err, domain.ErrWalletAlreadyExists))
 }
 
@@ -41,7 +41,7 @@ func TestWalletRepository_CreateWallet_NegativeBalance(t *testing.T) {
 		},
 	}
 	repo := repository.NewWalletRepository(mockDB)
-	err := repo.CreateWallet(ctx, "addr", -100)
+	err := repo.CreateWallet(ctx, "addr", "USD", -100)
 	assert.True(t, errors.Is(err, domain.ErrNegativeBalance))
 }
 
@@ -53,7 +53,7 @@ func TestWalletRepository_CreateWallet_InternalError(t *testing.T) {
 		},
 	}
 	repo := repository.NewWalletRepository(mockDB)
-	err := repo.CreateWallet(ctx, "addr", 100)
+	err := repo.CreateWallet(ctx, "addr", "USD", 100)
 	assert.True(t, errors.Is(err, domain.ErrInternal))
 }
 
@@ -65,7 +65,7 @@ func TestWalletRepository_CreateWalletTx_Success(t *testing.T) {
 		},
 	}
 	repo := repository.NewWalletRepository(nil)
-	err := repo.CreateWalletTx(ctx, mockTx, "addr", 100)
+	err := repo.CreateWalletTx(ctx, mockTx, "addr", "USD", 100)
 	assert.NoError(t, err)
 }
 
@@ -77,7 +77,7 @@ func TestWalletRepository_CreateWalletTx_Duplicate(t *testing.T) {
 		},
 	}
 	repo := repository.NewWalletRepository(nil)
-	err := repo.CreateWalletTx(ctx, mockTx, "addr", 100)
+	err := repo.CreateWalletTx(ctx, mockTx, "addr", "USD", 100)
 	assert.True(t, errors.Is(err, domain.ErrWalletAlreadyExists))
 }
 
@@ -89,7 +89,7 @@ func TestWalletRepository_CreateWalletTx_NegativeBalance(t *testing.T) {
 		},
 	}
 	repo := repository.NewWalletRepository(nil)
-	err := repo.CreateWalletTx(ctx, mockTx, "addr", -100)
+	err := repo.CreateWalletTx(ctx, mockTx, "addr", "USD", -100)
 	assert.True(t, errors.Is(err, domain.ErrNegativeBalance))
 }
 
@@ -101,6 +101,6 @@ func TestWalletRepository_CreateWalletTx_InternalError(t *testing.T) {
 		},
 	}
 	repo := repository.NewWalletRepository(nil)
-	err := repo.CreateWalletTx(ctx, mockTx, "addr", 100)
+	err := repo.CreateWalletTx(ctx, mockTx, "addr", "USD", 100)
 	assert.True(t, errors.Is(err, domain.ErrInternal))
 }
@@ -14,7 +14,8 @@ func TestWalletRepository_GetWalletBalance_Success(t *testing.T) {
 	mockDB := &MockDB{
 		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
 			return &MockRow{ScanFunc: func(dest ...interface{}) error {
-				*dest[0].(*float64) = 123.45
+				*dest[0].(*domain.Money) = domain.MustParseMoney("123.45")
+				*dest[1].(*domain.Money) = domain.MustParseMoney("100.00")
 				return nil
 			}}
 		},
@@ -22,7 +23,7 @@ func TestWalletRepository_GetWalletBalance_Success(t *testing.T) {
 	repo := repository.NewWalletRepository(mockDB)
 	balance, err := repo.GetWalletBalance(ctx, "addr")
 	assert.NoError(t, err)
-	assert.Equal(t, 123.45, balance)
+	assert.Equal(t, domain.Balance{Total: domain.MustParseMoney("123.45"), Available: domain.MustParseMoney("100.00")}, balance)
 }
 
 func TestWalletRepository_GetWalletBalance_NotFound(t *testing.T) {
@@ -59,7 +60,7 @@ func TestWalletRepository_GetWallet_Success(t *testing.T) {
 		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
 			return &MockRow{ScanFunc: func(dest ...interface{}) error {
 				*dest[0].(*string) = "addr"
-				*dest[1].(*float64) = 123.45
+				*dest[1].(*domain.Money) = domain.MustParseMoney("123.45")
 				return nil
 			}}
 		},
@@ -69,7 +70,7 @@ func TestWalletRepository_GetWallet_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, wallet)
 	assert.Equal(t, "addr", wallet.Address)
-	assert.Equal(t, 123.45, wallet.Balance)
+	assert.Equal(t, domain.MustParseMoney("123.45"), wallet.Balance)
 }
 
 func TestWalletRepository_GetWallet_NotFound(t *testing.T) {
@@ -101,3 +102,62 @@ func TestWalletRepository_GetWallet_InternalError(t *testing.T) {
 	assert.True(t, errors.Is(err, domain.ErrInternal))
 	assert.Nil(t, wallet)
 }
+
+func TestWalletRepository_GetWalletForUpdateTx_Success(t *testing.T) {
+	ctx := context.Background()
+	var queries []string
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			queries = append(queries, sql)
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				// Второй запрос читает сумму активных резервов
+				if len(dest) == 1 {
+					*dest[0].(*domain.Money) = domain.MustParseMoney("20.00")
+					return nil
+				}
+				*dest[0].(*string) = "addr"
+				*dest[1].(*domain.Money) = domain.MustParseMoney("50.00")
+				*dest[3].(*string) = "EUR"
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewWalletRepository(nil)
+	wallet, err := repo.GetWalletForUpdateTx(ctx, *mockTx, "addr")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MustParseMoney("50.00"), wallet.Balance)
+	assert.Equal(t, domain.MustParseMoney("20.00"), wallet.Held)
+	assert.Equal(t, domain.MustParseMoney("30.00"), wallet.Available())
+	assert.Equal(t, "EUR", wallet.Currency)
+	assert.Len(t, queries, 2)
+	assert.Contains(t, queries[0], "FOR UPDATE")
+	assert.Contains(t, queries[1], "holds")
+}
+
+func TestWalletRepository_GetWalletForUpdateTx_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: "no_rows"}
+			}}
+		},
+	}
+	repo := repository.NewWalletRepository(nil)
+	_, err := repo.GetWalletForUpdateTx(ctx, *mockTx, "addr")
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestWalletRepository_GetWalletForUpdateTx_Deadlock(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: repository.ErrCodeDeadlockDetected}
+			}}
+		},
+	}
+	repo := repository.NewWalletRepository(nil)
+	_, err := repo.GetWalletForUpdateTx(ctx, *mockTx, "addr")
+	assert.True(t, errors.Is(err, domain.ErrTransactionConflict))
+}
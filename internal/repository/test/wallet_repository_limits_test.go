@@ -0,0 +1,76 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWalletRepository_GetWalletLimits_NoRow(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: "no_rows"}
+			}}
+		},
+	}
+	repo := repository.NewWalletRepository(mockDB)
+	overrides, err := repo.GetWalletLimits(ctx, "addr")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.LimitOverrides{}, overrides)
+}
+
+func TestWalletRepository_GetWalletLimitsTx_Success(t *testing.T) {
+	ctx := context.Background()
+	tx := MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				maxTransfer := domain.MustParseMoney("100")
+				*dest[0].(**domain.Money) = &maxTransfer
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewWalletRepository(&MockDB{})
+	overrides, err := repo.GetWalletLimitsTx(ctx, tx, "addr")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MustParseMoney("100"), *overrides.MaxTransfer)
+	assert.Nil(t, overrides.MaxDailyCount)
+}
+
+func TestWalletRepository_SetWalletLimits_WalletNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+			return nil, &mockDBError{sqlState: repository.ErrCodeForeignKeyViolation}
+		},
+	}
+	repo := repository.NewWalletRepository(mockDB)
+	err := repo.SetWalletLimits(ctx, "addr", domain.LimitOverrides{})
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestTransactionRepository_GetOutflowTx_Success(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	tx := MockTx{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			gotArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*domain.Money) = domain.MustParseMoney("250")
+				*dest[1].(*int) = 3
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewTransactionRepository(&MockDB{})
+	outflow, err := repo.GetOutflowTx(ctx, tx, "addr", 24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Outflow{Amount: domain.MustParseMoney("250"), Count: 3}, outflow)
+	assert.Equal(t, []interface{}{"addr", float64(86400)}, gotArgs)
+}
@@ -0,0 +1,69 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWalletRepository_SetOverdraftLimit_Success(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+			gotArgs = args
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 1 }}, nil
+		},
+	}
+	repo := repository.NewWalletRepository(mockDB)
+	err := repo.SetOverdraftLimit(ctx, "addr", domain.MustParseMoney("500"))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{domain.MustParseMoney("500"), "addr"}, gotArgs)
+}
+
+func TestWalletRepository_SetOverdraftLimit_InUse(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+			return nil, &mockDBError{sqlState: repository.ErrCodeCheckViolation, constraint: repository.ConstraintBalanceOverdraft}
+		},
+	}
+	repo := repository.NewWalletRepository(mockDB)
+	err := repo.SetOverdraftLimit(ctx, "addr", 0)
+	assert.True(t, errors.Is(err, domain.ErrOverdraftInUse))
+}
+
+func TestWalletRepository_SetOverdraftLimit_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 0 }}, nil
+		},
+	}
+	repo := repository.NewWalletRepository(mockDB)
+	err := repo.SetOverdraftLimit(ctx, "addr", 0)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestWalletRepository_GetWallet_Overdraft(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*string) = "addr"
+				*dest[1].(*domain.Money) = domain.MustParseMoney("-120")
+				*dest[5].(*domain.Money) = domain.MustParseMoney("500")
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewWalletRepository(mockDB)
+	wallet, err := repo.GetWallet(ctx, "addr")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MustParseMoney("500"), wallet.OverdraftLimit)
+	assert.Equal(t, domain.MustParseMoney("120"), wallet.CreditUsed())
+	assert.Equal(t, domain.MustParseMoney("380"), wallet.CreditAvailable())
+}
@@ -9,25 +9,42 @@ import (
 	"testing"
 )
 
-func TestWalletRepository_RemoveWallet_Success(t *testing.T) {
-	ctx := context.Background()
-	mockDB := &MockDB{
-		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
-			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 1 }}, nil
+func removeWalletDB(scan func(dest ...interface{}) error) *MockDB {
+	return &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: scan}
 		},
 	}
+}
+
+func TestWalletRepository_RemoveWallet_Success(t *testing.T) {
+	ctx := context.Background()
+	mockDB := removeWalletDB(func(dest ...interface{}) error {
+		*dest[0].(*bool) = false
+		return nil
+	})
 	repo := repository.NewWalletRepository(mockDB)
 	err := repo.RemoveWallet(ctx, "addr")
 	assert.NoError(t, err)
 }
 
+func TestWalletRepository_RemoveWallet_HasPostings(t *testing.T) {
+	ctx := context.Background()
+	// Проводка открытия счета без переводов тоже запрещает удаление
+	mockDB := removeWalletDB(func(dest ...interface{}) error {
+		*dest[0].(*bool) = true
+		return nil
+	})
+	repo := repository.NewWalletRepository(mockDB)
+	err := repo.RemoveWallet(ctx, "addr")
+	assert.True(t, errors.Is(err, domain.ErrWalletHasHistory))
+}
+
 func TestWalletRepository_RemoveWallet_NotFound(t *testing.T) {
 	ctx := context.Background()
-	mockDB := &MockDB{
-		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
-			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 0 }}, nil
-		},
-	}
+	mockDB := removeWalletDB(func(dest ...interface{}) error {
+		return &mockDBError{sqlState: "no_rows"}
+	})
 	repo := repository.NewWalletRepository(mockDB)
 	err := repo.RemoveWallet(ctx, "addr")
 	assert.True(t, errors.Is(err, domain.ErrNotFound))
@@ -35,11 +52,9 @@ func TestWalletRepository_RemoveWallet_NotFound(t *testing.T) {
 
 func TestWalletRepository_RemoveWallet_InternalError(t *testing.T) {
 	ctx := context.Background()
-	mockDB := &MockDB{
-		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
-			return nil, errors.New("fail")
-		},
-	}
+	mockDB := removeWalletDB(func(dest ...interface{}) error {
+		return errors.New("fail")
+	})
 	repo := repository.NewWalletRepository(mockDB)
 	err := repo.RemoveWallet(ctx, "addr")
 	assert.True(t, errors.Is(err, domain.ErrInternal))
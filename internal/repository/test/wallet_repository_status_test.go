@@ -0,0 +1,69 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWalletRepository_ChangeWalletStatusTx_Success(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	var updateArgs, insertArgs []interface{}
+	tx := MockTx{
+		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+			updateArgs = args
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 1 }}, nil
+		},
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			insertArgs = args
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*int64) = 7
+				*dest[1].(*time.Time) = now
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewWalletRepository(&MockDB{})
+	change, err := repo.ChangeWalletStatusTx(ctx, tx, domain.WalletStatusChange{
+		Address:    "addr",
+		FromStatus: domain.WalletActive,
+		ToStatus:   domain.WalletFrozen,
+		Reason:     "fraud check",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), change.Id)
+	assert.Equal(t, now, change.CreatedAt)
+	assert.Equal(t, []interface{}{domain.WalletFrozen, "addr"}, updateArgs)
+	assert.Equal(t, []interface{}{"addr", domain.WalletActive, domain.WalletFrozen, "fraud check"}, insertArgs)
+}
+
+func TestWalletRepository_ChangeWalletStatusTx_NotFound(t *testing.T) {
+	ctx := context.Background()
+	tx := MockTx{
+		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 0 }}, nil
+		},
+	}
+	repo := repository.NewWalletRepository(&MockDB{})
+	_, err := repo.ChangeWalletStatusTx(ctx, tx, domain.WalletStatusChange{Address: "addr", ToStatus: domain.WalletFrozen})
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func TestWalletRepository_RemoveWallet_HasHistory(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				return &mockDBError{sqlState: repository.ErrCodeForeignKeyViolation}
+			}}
+		},
+	}
+	repo := repository.NewWalletRepository(mockDB)
+	err := repo.RemoveWallet(ctx, "addr")
+	assert.True(t, errors.Is(err, domain.ErrWalletHasHistory))
+}
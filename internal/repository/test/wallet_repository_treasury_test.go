@@ -0,0 +1,106 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/repository"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWalletRepository_EnsureTreasuryWallet(t *testing.T) {
+	ctx := context.Background()
+	var gotArgs []interface{}
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+			gotArgs = args
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 1 }}, nil
+		},
+	}
+	repo := repository.NewWalletRepository(mockDB)
+	assert.NoError(t, repo.EnsureTreasuryWallet(ctx, "treasury", "USD"))
+	assert.Equal(t, []interface{}{"treasury", "USD"}, gotArgs)
+}
+
+func TestWalletRepository_EnsureTreasuryWallet_OtherCurrency(t *testing.T) {
+	ctx := context.Background()
+	// Кошелек с таким адресом уже есть в другой валюте: ON CONFLICT ... WHERE не обновляет строку
+	mockDB := &MockDB{
+		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+			return &MockCommandTag{RowsAffectedFunc: func() int64 { return 0 }}, nil
+		},
+	}
+	repo := repository.NewWalletRepository(mockDB)
+	err := repo.EnsureTreasuryWallet(ctx, "treasury", "USD")
+	assert.True(t, errors.Is(err, domain.ErrCurrencyMismatch))
+}
+
+func TestWalletRepository_GetWallet_Treasury(t *testing.T) {
+	ctx := context.Background()
+	mockDB := &MockDB{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+			return &MockRow{ScanFunc: func(dest ...interface{}) error {
+				*dest[0].(*string) = "treasury"
+				*dest[1].(*domain.Money) = domain.MustParseMoney("-500")
+				*dest[6].(*bool) = true
+				return nil
+			}}
+		},
+	}
+	repo := repository.NewWalletRepository(mockDB)
+	w, err := repo.GetWallet(ctx, "treasury")
+	assert.NoError(t, err)
+	assert.True(t, w.Treasury)
+	assert.Equal(t, domain.MustParseMoney("-500"), w.Balance)
+}
+
+func TestWalletRepository_CreateBalanceAdjustmentTx(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	var gotArgs []interface{}
+	tx := MockTx{QueryRowFunc: func(ctx context.Context, sql string, args ...interface{}) repository.Row {
+		gotArgs = args
+		return &MockRow{ScanFunc: func(dest ...interface{}) error {
+			*dest[0].(*int64) = 3
+			*dest[1].(*time.Time) = now
+			return nil
+		}}
+	}}
+	repo := repository.NewWalletRepository(&MockDB{})
+	adjustment, err := repo.CreateBalanceAdjustmentTx(ctx, tx, domain.BalanceAdjustment{
+		Address: "addr", Previous: 100, New: 150, Reason: "correction", EntryId: 12,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), adjustment.Id)
+	assert.Equal(t, now, adjustment.CreatedAt)
+	assert.Equal(t, []interface{}{"addr", domain.Money(100), domain.Money(150), "correction", int64(12)}, gotArgs)
+}
+
+func TestWalletRepository_GetBalanceAdjustments(t *testing.T) {
+	ctx := context.Background()
+	ids := []int64{5, 4}
+	i := -1
+	mockDB := &MockDB{
+		QueryFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.Rows, error) {
+			return &MockRows{
+				NextFunc: func() bool { i++; return i < len(ids) },
+				ScanFunc: func(dest ...interface{}) error {
+					*dest[0].(*int64) = ids[i]
+					*dest[1].(*string) = "addr"
+					*dest[4].(*string) = "correction"
+					return nil
+				},
+				CloseFunc: func() {},
+				ErrFunc:   func() error { return nil },
+			}, nil
+		},
+	}
+	repo := repository.NewWalletRepository(mockDB)
+	adjustments, err := repo.GetBalanceAdjustments(ctx, "addr", 10)
+	assert.NoError(t, err)
+	assert.Len(t, adjustments, 2)
+	assert.Equal(t, int64(5), adjustments[0].Id)
+	assert.Equal(t, "correction", adjustments[1].Reason)
+}
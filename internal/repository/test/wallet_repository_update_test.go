@@ -104,3 +104,15 @@ func TestWalletRepository_UpdateWalletBalanceTx_InternalError(t *testing.T) {
 	err := repo.UpdateWalletBalanceTx(ctx, *mockTx, "addr", 200)
 	assert.True(t, errors.Is(err, domain.ErrInternal))
 }
+
+func TestWalletRepository_UpdateWalletBalanceTx_Overflow(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &MockTx{
+		ExecFunc: func(ctx context.Context, sql string, args ...interface{}) (repository.CommandTag, error) {
+			return nil, &mockDBError{sqlState: repository.ErrCodeNumericOutOfRange}
+		},
+	}
+	repo := repository.NewWalletRepository(nil)
+	err := repo.UpdateWalletBalanceTx(ctx, *mockTx, "addr", domain.MaxMoney)
+	assert.True(t, errors.Is(err, domain.ErrAmountOverflow))
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"TransactionTest/internal/domain"
@@ -16,6 +18,60 @@ func NewTransactionRepository(db IDB) *TransactionRepository {
 	return &TransactionRepository{db: db}
 }
 
+// transactionColumns колонки транзакции в порядке, который ожидает scanTransaction.
+// Детали конвертации пустые у переводов в одной валюте, поэтому заменяются значениями по умолчанию.
+const transactionColumns = `id, from_wallet, to_wallet, amount, created_at, COALESCE(reversal_of, 0), currency,
+              COALESCE(to_currency, ''), COALESCE(to_amount, 0), COALESCE(rate, 0), COALESCE(rate_at, created_at), fee,
+              memo, COALESCE(external_reference, ''), metadata, kind`
+
+// scanTransaction читает строку, выбранную по transactionColumns
+func scanTransaction(row Row, t *domain.Transaction) error {
+	var exchange domain.Exchange
+	var metadata []byte
+	err := row.Scan(
+		&t.Id,
+		&t.From,
+		&t.To,
+		&t.Amount,
+		&t.CreatedAt,
+		&t.ReversalOf,
+		&t.Currency,
+		&exchange.ToCurrency,
+		&exchange.ToAmount,
+		&exchange.Rate,
+		&exchange.RateAt,
+		&t.Fee,
+		&t.Details.Memo,
+		&t.Details.ExternalReference,
+		&metadata,
+		&t.Kind,
+	)
+	if err != nil {
+		return err
+	}
+	if exchange.ToCurrency != "" {
+		t.Exchange = &exchange
+	}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &t.Details.Metadata); err != nil {
+			return fmt.Errorf("decode metadata of transaction %v: %w", t.Id, err)
+		}
+	}
+	return nil
+}
+
+// encodeMetadata кодирует metadata перевода в JSON, пустые данные хранятся как NULL
+func encodeMetadata(metadata map[string]interface{}) ([]byte, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: metadata is not valid JSON: %w", domain.ErrInvalidInput, err)
+	}
+	return encoded, nil
+}
+
 func (tr *TransactionRepository) BeginTX(ctx context.Context) (domain.TxExecutor, error) {
 	tx, err := tr.db.Begin(ctx)
 	if err != nil {
@@ -24,9 +80,11 @@ func (tr *TransactionRepository) BeginTX(ctx context.Context) (domain.TxExecutor
 	return &txAdapter{tx: tx}, nil
 }
 
-func (tr *TransactionRepository) CreateTransaction(ctx context.Context, from, to string, amount float64) (int64, error) {
-	query := `INSERT INTO transactions (from_wallet, to_wallet, amount) 
-              VALUES ($1, $2, $3) RETURNING id`
+func (tr *TransactionRepository) CreateTransaction(ctx context.Context, from, to string, amount domain.Money) (int64, error) {
+	// Валюта перевода - валюта кошелька отправителя
+	query := `INSERT INTO transactions (from_wallet, to_wallet, amount, currency) 
+              SELECT $1, $2, $3, currency FROM wallets WHERE address = $1
+              RETURNING id`
 
 	var transactionId int64
 
@@ -39,9 +97,12 @@ func (tr *TransactionRepository) CreateTransaction(ctx context.Context, from, to
 			if dbErr.SQLState() == ErrCodeCheckViolation && dbErr.ConstraintName() == ConstraintNoSelfTransfer {
 				return 0, domain.ErrSelfTransfer
 			}
-			if dbErr.SQLState() == ErrCodeForeignKeyViolation {
+			if dbErr.SQLState() == ErrCodeForeignKeyViolation || dbErr.SQLState() == "no_rows" {
 				return 0, fmt.Errorf("%w: wallet %s or %s", domain.ErrNotFound, from, to)
 			}
+			if dbErr.SQLState() == ErrCodeNumericOutOfRange {
+				return 0, fmt.Errorf("%w: amount %v", domain.ErrAmountOverflow, amount)
+			}
 		}
 		return 0, fmt.Errorf("%w: %w", domain.ErrInternal, err)
 	}
@@ -49,40 +110,97 @@ func (tr *TransactionRepository) CreateTransaction(ctx context.Context, from, to
 	return transactionId, nil
 }
 
-func (tr *TransactionRepository) CreateTransactionTx(ctx context.Context, tx domain.TxExecutor, from, to string, amount float64) (int64, error) {
-	query := `INSERT INTO transactions (from_wallet, to_wallet, amount) VALUES ($1, $2, $3) RETURNING id`
+// CreateTransactionTx создает транзакцию перевода внутри транзакции БД. fee - комиссия, списанная с отправителя сверх amount,
+// details - сопроводительные данные клиента. Повтор внешнего идентификатора у отправителя возвращает domain.ErrDuplicateReference.
+func (tr *TransactionRepository) CreateTransactionTx(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+	metadata, err := encodeMetadata(details.Metadata)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `INSERT INTO transactions (from_wallet, to_wallet, amount, currency, fee, memo, external_reference, metadata) 
+              SELECT $1, $2, $3, currency, $4, $5, NULLIF($6, ''), $7 FROM wallets WHERE address = $1
+              RETURNING id`
 	var transactionId int64
-	err := tx.QueryRow(ctx, query, from, to, amount).Scan(&transactionId)
+	err = tx.QueryRow(ctx, query, from, to, amount, fee, details.Memo, details.ExternalReference, metadata).Scan(&transactionId)
 	if err != nil {
-		if dbErr, ok := err.(DBError); ok {
-			if dbErr.SQLState() == ErrCodeCheckViolation && dbErr.ConstraintName() == ConstraintAmountPositive {
-				return 0, domain.ErrNegativeAmount
-			}
-			if dbErr.SQLState() == ErrCodeCheckViolation && dbErr.ConstraintName() == ConstraintNoSelfTransfer {
-				return 0, domain.ErrSelfTransfer
-			}
-			if dbErr.SQLState() == ErrCodeForeignKeyViolation {
-				return 0, fmt.Errorf("%w: wallet %s or %s", domain.ErrNotFound, from, to)
-			}
-		}
-		return 0, fmt.Errorf("%w: %w", domain.ErrInternal, err)
+		return 0, createTransactionTxError(err, from, to, amount, details)
+	}
+	return transactionId, nil
+}
+
+// CreateExchangeTx создает транзакцию перевода с конвертацией.
+// amount и комиссия fee списываются в валюте кошелька отправителя, exchange.ToAmount зачисляется получателю.
+func (tr *TransactionRepository) CreateExchangeTx(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, exchange domain.Exchange, details domain.TransferDetails) (int64, error) {
+	metadata, err := encodeMetadata(details.Metadata)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `INSERT INTO transactions (from_wallet, to_wallet, amount, currency, fee, to_currency, to_amount, rate, rate_at,
+                  memo, external_reference, metadata) 
+              SELECT $1, $2, $3, currency, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11 FROM wallets WHERE address = $1
+              RETURNING id`
+	var transactionId int64
+	err = tx.QueryRow(ctx, query, from, to, amount, fee, exchange.ToCurrency, exchange.ToAmount, exchange.Rate, exchange.RateAt,
+		details.Memo, details.ExternalReference, metadata).Scan(&transactionId)
+	if err != nil {
+		return 0, createTransactionTxError(err, from, to, amount, details)
 	}
 	return transactionId, nil
 }
 
+// CreateFundsTx создает транзакцию пополнения (с кошелька казначейства) или вывода (на кошелек казначейства).
+// Валюта - валюта кошелька from, у пополнения и вывода она совпадает с валютой второго кошелька.
+func (tr *TransactionRepository) CreateFundsTx(ctx context.Context, tx domain.TxExecutor, kind domain.TransactionKind, from, to string, amount domain.Money, details domain.TransferDetails) (int64, error) {
+	metadata, err := encodeMetadata(details.Metadata)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `INSERT INTO transactions (kind, from_wallet, to_wallet, amount, currency, memo, external_reference, metadata) 
+              SELECT $1, $2, $3, $4, currency, $5, NULLIF($6, ''), $7 FROM wallets WHERE address = $2
+              RETURNING id`
+	var transactionId int64
+	err = tx.QueryRow(ctx, query, kind, from, to, amount, details.Memo, details.ExternalReference, metadata).Scan(&transactionId)
+	if err != nil {
+		return 0, createTransactionTxError(err, from, to, amount, details)
+	}
+	return transactionId, nil
+}
+
+// createTransactionTxError переводит ошибку вставки транзакции внутри транзакции БД в доменную
+func createTransactionTxError(err error, from, to string, amount domain.Money, details domain.TransferDetails) error {
+	if isTxConflict(err) {
+		return fmt.Errorf("%w: %w", domain.ErrTransactionConflict, err)
+	}
+	if dbErr, ok := err.(DBError); ok {
+		if dbErr.SQLState() == ErrCodeUniqueViolation && dbErr.ConstraintName() == ConstraintUniqueExternalRef {
+			return fmt.Errorf("%w: %q from wallet %s", domain.ErrDuplicateReference, details.ExternalReference, from)
+		}
+		if dbErr.SQLState() == ErrCodeCheckViolation && dbErr.ConstraintName() == ConstraintAmountPositive {
+			return domain.ErrNegativeAmount
+		}
+		if dbErr.SQLState() == ErrCodeCheckViolation && dbErr.ConstraintName() == ConstraintNoSelfTransfer {
+			return domain.ErrSelfTransfer
+		}
+		if dbErr.SQLState() == ErrCodeForeignKeyViolation || dbErr.SQLState() == "no_rows" {
+			return fmt.Errorf("%w: wallet %s or %s", domain.ErrNotFound, from, to)
+		}
+		if dbErr.SQLState() == ErrCodeNumericOutOfRange {
+			return fmt.Errorf("%w: amount %v", domain.ErrAmountOverflow, amount)
+		}
+	}
+	return fmt.Errorf("%w: %w", domain.ErrInternal, err)
+}
+
 func (tr *TransactionRepository) GetTransactionById(ctx context.Context, id int64) (*domain.Transaction, error) {
-	query := `SELECT id, from_wallet, to_wallet, amount, created_at 
+	query := `SELECT ` + transactionColumns + `
     		  FROM transactions WHERE id = $1`
 
 	var t domain.Transaction
 
-	err := tr.db.QueryRow(ctx, query, id).Scan(
-		&t.Id,
-		&t.From,
-		&t.To,
-		&t.Amount,
-		&t.CreatedAt,
-	)
+	err := scanTransaction(tr.db.QueryRow(ctx, query, id), &t)
 
 	if err != nil {
 		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
@@ -95,19 +213,13 @@ func (tr *TransactionRepository) GetTransactionById(ctx context.Context, id int6
 }
 
 func (tr *TransactionRepository) GetTransactionByInfo(ctx context.Context, from, to string, createdAt time.Time) (*domain.Transaction, error) {
-	query := `SELECT id, from_wallet, to_wallet, amount, created_at
+	query := `SELECT ` + transactionColumns + `
               FROM transactions 
               WHERE from_wallet = $1 AND to_wallet = $2 AND created_at = $3`
 
 	var t domain.Transaction
 
-	err := tr.db.QueryRow(ctx, query, from, to, createdAt).Scan(
-		&t.Id,
-		&t.From,
-		&t.To,
-		&t.Amount,
-		&t.CreatedAt,
-	)
+	err := scanTransaction(tr.db.QueryRow(ctx, query, from, to, createdAt), &t)
 
 	if err != nil {
 		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
@@ -119,6 +231,143 @@ func (tr *TransactionRepository) GetTransactionByInfo(ctx context.Context, from,
 	return &t, nil
 }
 
+// GetTransactionTx читает транзакцию внутри транзакции БД без блокировки строки
+func (tr *TransactionRepository) GetTransactionTx(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
+              FROM transactions WHERE id = $1`
+
+	var t domain.Transaction
+
+	err := scanTransaction(tx.QueryRow(ctx, query, id), &t)
+
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return nil, domain.ErrNotFound
+		}
+		if isTxConflict(err) {
+			return nil, fmt.Errorf("%w: failed to read transaction %v: %w", domain.ErrTransactionConflict, id, err)
+		}
+		return nil, fmt.Errorf("%w: failed to read transaction %v: %w", domain.ErrInternal, id, err)
+	}
+
+	return &t, nil
+}
+
+// GetTransactionForUpdateTx читает транзакцию и блокирует ее строку до конца транзакции БД
+func (tr *TransactionRepository) GetTransactionForUpdateTx(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
+              FROM transactions WHERE id = $1 FOR UPDATE`
+
+	var t domain.Transaction
+
+	err := scanTransaction(tx.QueryRow(ctx, query, id), &t)
+
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return nil, domain.ErrNotFound
+		}
+		if isTxConflict(err) {
+			return nil, fmt.Errorf("%w: failed to lock transaction %v: %w", domain.ErrTransactionConflict, id, err)
+		}
+		return nil, fmt.Errorf("%w: failed to lock transaction %v: %w", domain.ErrInternal, id, err)
+	}
+
+	return &t, nil
+}
+
+// CreateReversalTx создает встречную транзакцию, отменяющую original (см. domain.Transaction.Reversed).
+// Повторная отмена той же транзакции отклоняется уникальным индексом по reversal_of.
+func (tr *TransactionRepository) CreateReversalTx(ctx context.Context, tx domain.TxExecutor, original *domain.Transaction) (int64, error) {
+	reversal := original.Reversed()
+	if reversal.Kind == "" {
+		reversal.Kind = domain.KindTransfer
+	}
+
+	query := `INSERT INTO transactions (from_wallet, to_wallet, amount, currency, reversal_of, kind) 
+              VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	args := []interface{}{reversal.From, reversal.To, reversal.Amount, reversal.Currency, reversal.ReversalOf, reversal.Kind}
+	if reversal.Exchange != nil {
+		query = `INSERT INTO transactions (from_wallet, to_wallet, amount, currency, reversal_of, kind, to_currency, to_amount, rate, rate_at) 
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+		args = append(args, reversal.Exchange.ToCurrency, reversal.Exchange.ToAmount, reversal.Exchange.Rate, reversal.Exchange.RateAt)
+	}
+
+	var transactionId int64
+
+	err := tx.QueryRow(ctx, query, args...).Scan(&transactionId)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok {
+			if dbErr.SQLState() == ErrCodeUniqueViolation && dbErr.ConstraintName() == ConstraintUniqueReversal {
+				return 0, fmt.Errorf("%w: transaction %v", domain.ErrAlreadyReversed, original.Id)
+			}
+			if dbErr.SQLState() == ErrCodeForeignKeyViolation {
+				return 0, fmt.Errorf("%w: wallet %s or %s", domain.ErrNotFound, original.From, original.To)
+			}
+		}
+		if isTxConflict(err) {
+			return 0, fmt.Errorf("%w: failed to create reversal: %w", domain.ErrTransactionConflict, err)
+		}
+		return 0, fmt.Errorf("%w: failed to create reversal: %w", domain.ErrInternal, err)
+	}
+
+	return transactionId, nil
+}
+
+// GetOutflowTx считает исходящие переводы кошелька за последние window: сумму вместе с комиссиями и число.
+// Отмены не учитываются - это возврат средств, а не расход кошелька.
+// Вызывается после блокировки кошелька, поэтому параллельные переводы с него уже учтены.
+func (tr *TransactionRepository) GetOutflowTx(ctx context.Context, tx domain.TxExecutor, address string, window time.Duration) (domain.Outflow, error) {
+	query := `SELECT COALESCE(SUM(amount + fee), 0), COUNT(*)
+              FROM transactions
+              WHERE from_wallet = $1 AND reversal_of IS NULL
+                AND created_at > now() - make_interval(secs => $2)`
+
+	var outflow domain.Outflow
+
+	err := tx.QueryRow(ctx, query, address, window.Seconds()).Scan(&outflow.Amount, &outflow.Count)
+	if err != nil {
+		if isTxConflict(err) {
+			return domain.Outflow{}, fmt.Errorf("%w: failed to get outflow of wallet %v: %w", domain.ErrTransactionConflict, address, err)
+		}
+		return domain.Outflow{}, fmt.Errorf("%w: failed to get outflow of wallet %v: %w", domain.ErrInternal, address, err)
+	}
+
+	return outflow, nil
+}
+
+// GetTransactionsByExternalReference ищет переводы по внешнему идентификатору.
+// Идентификатор уникален только в пределах отправителя, поэтому без from может найтись несколько переводов.
+func (tr *TransactionRepository) GetTransactionsByExternalReference(ctx context.Context, reference, from string) ([]domain.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
+              FROM transactions 
+              WHERE external_reference = $1 AND ($2 = '' OR from_wallet = $2)
+              ORDER BY id`
+
+	rows, err := tr.db.Query(ctx, query, reference, from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to find transactions by external reference %q: %w", domain.ErrInternal, reference, err)
+	}
+	defer rows.Close()
+
+	transactions := make([]domain.Transaction, 0)
+
+	for rows.Next() {
+		var t domain.Transaction
+
+		if err := scanTransaction(rows, &t); err != nil {
+			return nil, fmt.Errorf("%w: failed to scan transaction: %w", domain.ErrInternal, err)
+		}
+
+		transactions = append(transactions, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: error while fetching rows: %w", domain.ErrInternal, err)
+	}
+
+	return transactions, nil
+}
+
 func (tr *TransactionRepository) RemoveTransaction(ctx context.Context, id int64) error {
 	query := `DELETE FROM transactions WHERE id = $1`
 
@@ -136,7 +385,7 @@ func (tr *TransactionRepository) RemoveTransaction(ctx context.Context, id int64
 }
 
 func (tr *TransactionRepository) GetLastTransactions(ctx context.Context, limit int) ([]domain.Transaction, error) {
-	query := `SELECT id, from_wallet, to_wallet, amount, created_at 
+	query := `SELECT ` + transactionColumns + `
               FROM transactions 
               ORDER BY created_at DESC
               LIMIT $1`
@@ -152,7 +401,7 @@ func (tr *TransactionRepository) GetLastTransactions(ctx context.Context, limit
 	for rows.Next() {
 		var t domain.Transaction
 
-		if err := rows.Scan(&t.Id, &t.From, &t.To, &t.Amount, &t.CreatedAt); err != nil {
+		if err := scanTransaction(rows, &t); err != nil {
 			return nil, fmt.Errorf("%w: failed to scan transaction: %w", domain.ErrInternal, err)
 		}
 
@@ -165,3 +414,223 @@ func (tr *TransactionRepository) GetLastTransactions(ctx context.Context, limit
 
 	return transactions, nil
 }
+
+// transactionListQuery собирает запрос истории транзакций: условия и их параметры нумеруются по порядку добавления
+type transactionListQuery struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg добавляет параметр запроса и возвращает его placeholder
+func (q *transactionListQuery) arg(v interface{}) string {
+	q.args = append(q.args, v)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (q *transactionListQuery) where(condition string) {
+	q.conditions = append(q.conditions, condition)
+}
+
+// whereClause возвращает условия, общие для всех веток запроса, с дополнительным условием extra
+func (q *transactionListQuery) whereClause(extra string) string {
+	conditions := q.conditions
+	if extra != "" {
+		conditions = append([]string{extra}, conditions...)
+	}
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+// buildTransactionListQuery строит keyset-запрос страницы истории по фильтру.
+// Транзакции упорядочены по (created_at, id); курсор записан как created_at <= $c AND (created_at < $c OR id < $id),
+// чтобы граница по created_at попадала в индекс. Фильтр по кошельку использует индексы (from_wallet, created_at)
+// и (to_wallet, created_at); для любой роли кошелька обе ветки выбираются отдельно и объединяются через UNION ALL,
+// перевод самому себе запрещен, поэтому дублей нет. Выбирается на одну строку больше Limit, чтобы понять,
+// есть ли следующая страница.
+func buildTransactionListQuery(f domain.TransactionFilter) (string, []interface{}) {
+	q := &transactionListQuery{}
+
+	if !f.CreatedFrom.IsZero() {
+		q.where("created_at >= " + q.arg(f.CreatedFrom))
+	}
+	if !f.CreatedTo.IsZero() {
+		q.where("created_at < " + q.arg(f.CreatedTo))
+	}
+	if f.MinAmount > 0 {
+		q.where("amount >= " + q.arg(f.MinAmount))
+	}
+	if f.MaxAmount > 0 {
+		q.where("amount <= " + q.arg(f.MaxAmount))
+	}
+
+	cmp, direction := "<", "DESC"
+	if f.Order == domain.SortAsc {
+		cmp, direction = ">", "ASC"
+	}
+	if f.After != nil {
+		createdAt, id := q.arg(f.After.CreatedAt), q.arg(f.After.Id)
+		q.where(fmt.Sprintf("created_at %s= %s AND (created_at %s %s OR id %s %s)", cmp, createdAt, cmp, createdAt, cmp, id))
+	}
+
+	orderBy := fmt.Sprintf("ORDER BY created_at %s, id %s", direction, direction)
+	limit := q.arg(f.Limit + 1)
+
+	var wallet string
+	if f.Wallet != "" {
+		wallet = q.arg(f.Wallet)
+	}
+
+	branch := func(columns, extra string) string {
+		return `SELECT ` + columns + `
+              FROM transactions
+              ` + q.whereClause(extra) + `
+              ` + orderBy + `
+              LIMIT ` + limit
+	}
+
+	switch {
+	case f.Wallet == "":
+		return branch(transactionColumns, ""), q.args
+	case f.Role == domain.WalletRoleSender:
+		return branch(transactionColumns, "from_wallet = "+wallet), q.args
+	case f.Role == domain.WalletRoleReceiver:
+		return branch(transactionColumns, "to_wallet = "+wallet), q.args
+	}
+
+	query := `SELECT ` + transactionColumns + `
+              FROM (
+              (` + branch("*", "from_wallet = "+wallet) + `)
+              UNION ALL
+              (` + branch("*", "to_wallet = "+wallet) + `)
+              ) transactions
+              ` + orderBy + `
+              LIMIT ` + limit
+	return query, q.args
+}
+
+// ListTransactions возвращает страницу истории транзакций по фильтру.
+// Фильтр должен быть нормализован и проверен (domain.TransactionFilter.Normalize, Validate).
+func (tr *TransactionRepository) ListTransactions(ctx context.Context, filter domain.TransactionFilter) (*domain.TransactionPage, error) {
+	query, args := buildTransactionListQuery(filter)
+
+	rows, err := tr.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list transactions: %w", domain.ErrInternal, err)
+	}
+	defer rows.Close()
+
+	transactions := make([]domain.Transaction, 0, filter.Limit+1)
+
+	for rows.Next() {
+		var t domain.Transaction
+
+		if err := scanTransaction(rows, &t); err != nil {
+			return nil, fmt.Errorf("%w: failed to scan transaction: %w", domain.ErrInternal, err)
+		}
+
+		transactions = append(transactions, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: error while fetching rows: %w", domain.ErrInternal, err)
+	}
+
+	page := &domain.TransactionPage{Transactions: transactions}
+	if len(transactions) > filter.Limit {
+		page.Transactions = transactions[:filter.Limit]
+		last := page.Transactions[filter.Limit-1]
+		page.Next = &domain.TransactionCursor{Order: filter.Order, CreatedAt: last.CreatedAt, Id: last.Id}
+	}
+	return page, nil
+}
+
+// BeginSnapshotTX начинает читающую транзакцию REPEATABLE READ: все ее запросы видят один снимок БД,
+// поэтому переводы, закоммиченные между запросами, не расходятся между частями отчета
+func (tr *TransactionRepository) BeginSnapshotTX(ctx context.Context) (domain.TxExecutor, error) {
+	tx, err := tr.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `SET TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY`); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("set snapshot isolation: %w", err)
+	}
+	return &txAdapter{tx: tx}, nil
+}
+
+// GetOpeningBalanceTx возвращает валюту кошелька и его баланс по журналу до момента before (не включительно):
+// последний снимок раньше before плюс проводки между снимком и before
+func (tr *TransactionRepository) GetOpeningBalanceTx(ctx context.Context, tx domain.TxExecutor, address string, before time.Time) (string, domain.Money, error) {
+	query := `SELECT w.currency, COALESCE(s.balance, 0) + COALESCE((
+                  SELECT SUM(p.amount) FROM postings p
+                  WHERE p.account = w.address
+                    AND p.created_at > COALESCE(s.as_of, '-infinity'::timestamp)
+                    AND p.created_at < $2
+              ), 0)
+              FROM wallets w
+              LEFT JOIN LATERAL (
+                  SELECT as_of, balance FROM balance_snapshots
+                  WHERE account = w.address AND as_of < $2
+                  ORDER BY as_of DESC
+                  LIMIT 1
+              ) s ON true
+              WHERE w.address = $1`
+
+	var currency string
+	var balance domain.Money
+
+	err := tx.QueryRow(ctx, query, address, before.UTC()).Scan(&currency, &balance)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return "", 0, domain.ErrNotFound
+		}
+		return "", 0, fmt.Errorf("%w: failed to get balance of %v before %v: %w", domain.ErrInternal, address, before, err)
+	}
+
+	return currency, balance, nil
+}
+
+// GetStatementLinesTx возвращает проводки кошелька за период [from, to) в хронологическом порядке
+// вместе с другой стороной транзакции и ее комментарием. Комиссия перевода - вторая проводка кошелька
+// в той же записи журнала (у плательщика) либо проводка счета, не участвующего в переводе (у получателя комиссии).
+func (tr *TransactionRepository) GetStatementLinesTx(ctx context.Context, tx domain.TxExecutor, address string, from, to time.Time) ([]domain.StatementLine, error) {
+	query := `SELECT p.id, e.kind, COALESCE(e.transaction_id, 0),
+                     CASE WHEN t.id IS NULL THEN ''
+                          WHEN p.account = t.from_wallet THEN t.to_wallet
+                          ELSE t.from_wallet END,
+                     t.id IS NOT NULL AND (
+                         ROW_NUMBER() OVER (PARTITION BY p.entry_id ORDER BY p.id) > 1
+                         OR p.account NOT IN (t.from_wallet, t.to_wallet)),
+                     COALESCE(t.memo, ''), p.amount, p.created_at
+              FROM postings p
+              JOIN journal_entries e ON e.id = p.entry_id
+              LEFT JOIN transactions t ON t.id = e.transaction_id
+              WHERE p.account = $1 AND p.created_at >= $2 AND p.created_at < $3
+              ORDER BY p.created_at, p.id`
+
+	rows, err := tx.Query(ctx, query, address, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get statement of %v: %w", domain.ErrInternal, address, err)
+	}
+	defer rows.Close()
+
+	lines := make([]domain.StatementLine, 0)
+
+	for rows.Next() {
+		var l domain.StatementLine
+
+		if err := rows.Scan(&l.PostingId, &l.Kind, &l.TransactionId, &l.Counterparty, &l.Fee, &l.Memo, &l.Amount, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%w: failed to scan statement line: %w", domain.ErrInternal, err)
+		}
+
+		lines = append(lines, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: error while fetching rows: %w", domain.ErrInternal, err)
+	}
+
+	return lines, nil
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"TransactionTest/internal/domain"
 	"context"
+	"errors"
+	"fmt"
 )
 
 type txAdapter struct {
@@ -10,7 +12,11 @@ type txAdapter struct {
 }
 
 func (a *txAdapter) Commit(ctx context.Context) error {
-	return a.tx.Commit(ctx)
+	err := a.tx.Commit(ctx)
+	if err != nil && isTxConflict(err) {
+		return fmt.Errorf("%w: %w", domain.ErrTransactionConflict, err)
+	}
+	return err
 }
 
 func (a *txAdapter) Rollback(ctx context.Context) error {
@@ -25,6 +31,10 @@ func (a *txAdapter) QueryRow(ctx context.Context, sql string, args ...interface{
 	return rowAdapter{a.tx.QueryRow(ctx, sql, args...)}
 }
 
+func (a *txAdapter) Query(ctx context.Context, sql string, args ...interface{}) (domain.Rows, error) {
+	return a.tx.Query(ctx, sql, args...)
+}
+
 type rowAdapter struct {
 	row Row
 }
@@ -32,3 +42,22 @@ type rowAdapter struct {
 func (r rowAdapter) Scan(dest ...interface{}) error {
 	return r.row.Scan(dest...)
 }
+
+// isTxConflict проверяет, что БД отклонила транзакцию из-за конфликта сериализации или взаимной блокировки.
+// Такие транзакции можно безопасно повторить целиком.
+func isTxConflict(err error) bool {
+	var dbErr DBError
+	if !errors.As(err, &dbErr) {
+		return false
+	}
+	return dbErr.SQLState() == ErrCodeSerializationFail || dbErr.SQLState() == ErrCodeDeadlockDetected
+}
+
+// isBalanceViolation проверяет, что баланс кошелька вышел за допустимые пределы:
+// ниже нуля (до кредитных линий) или ниже -overdraft_limit
+func isBalanceViolation(dbErr DBError) bool {
+	if dbErr.SQLState() != ErrCodeCheckViolation {
+		return false
+	}
+	return dbErr.ConstraintName() == ConstraintBalanceNonNegative || dbErr.ConstraintName() == ConstraintBalanceOverdraft
+}
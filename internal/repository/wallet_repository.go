@@ -23,15 +23,18 @@ func (tr *WalletRepository) BeginTX(ctx context.Context) (domain.TxExecutor, err
 	return &txAdapter{tx: tx}, nil
 }
 
-func (wr *WalletRepository) CreateWallet(ctx context.Context, address string, balance float64) error {
-	query := `INSERT INTO wallets (address, balance) VALUES ($1, $2)`
+func (wr *WalletRepository) CreateWallet(ctx context.Context, address, currency string, balance domain.Money) error {
+	query := `INSERT INTO wallets (address, currency, balance) VALUES ($1, $2, $3)`
 
-	_, err := wr.db.Exec(ctx, query, address, balance)
+	_, err := wr.db.Exec(ctx, query, address, currency, balance)
 	if err != nil {
 		if dbErr, ok := err.(DBError); ok {
-			if dbErr.SQLState() == ErrCodeCheckViolation && dbErr.ConstraintName() == ConstraintBalanceNonNegative {
+			if isBalanceViolation(dbErr) {
 				return domain.ErrNegativeBalance
 			}
+			if dbErr.SQLState() == ErrCodeNumericOutOfRange {
+				return fmt.Errorf("%w: balance %v", domain.ErrAmountOverflow, balance)
+			}
 			if dbErr.SQLState() == ErrCodeUniqueViolation {
 				return domain.ErrWalletAlreadyExists
 			}
@@ -42,24 +45,68 @@ func (wr *WalletRepository) CreateWallet(ctx context.Context, address string, ba
 	return nil
 }
 
-func (wr *WalletRepository) GetWalletBalance(ctx context.Context, address string) (float64, error) {
-	query := `SELECT balance FROM wallets WHERE address = $1`
+// heldAmountQuery сумма активных резервов кошелька
+const heldAmountQuery = `SELECT COALESCE(SUM(amount), 0) FROM holds
+              WHERE from_wallet = $1 AND status = 'active' AND expires_at > now()`
+
+// GetWalletBalance возвращает баланс по журналу и доступную сумму: за вычетом активных резервов плюс кредитная линия
+func (wr *WalletRepository) GetWalletBalance(ctx context.Context, address string) (domain.Balance, error) {
+	query := `SELECT balance, balance - (` + heldAmountQuery + `) + overdraft_limit
+              FROM wallets WHERE address = $1`
 
-	var balance float64
+	var balance domain.Balance
 
-	err := wr.db.QueryRow(ctx, query, address).Scan(&balance)
+	err := wr.db.QueryRow(ctx, query, address).Scan(&balance.Total, &balance.Available)
 	if err != nil {
 		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
-			return 0, domain.ErrNotFound
+			return domain.Balance{}, domain.ErrNotFound
 		}
-		return 0, fmt.Errorf("%w: failed to find wallet %v: %w", domain.ErrInternal, address, err)
+		return domain.Balance{}, fmt.Errorf("%w: failed to find wallet %v: %w", domain.ErrInternal, address, err)
 	}
 
 	return balance, nil
 }
 
+// GetWalletForUpdateTx читает кошелек и блокирует его строку до конца транзакции.
+// Сумма резервов читается отдельным запросом уже после блокировки, чтобы учесть резервы,
+// созданные транзакцией, которая держала блокировку до нас.
+func (wr *WalletRepository) GetWalletForUpdateTx(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+	query := `SELECT address, balance, created_at, currency, status, overdraft_limit, treasury
+              FROM wallets WHERE address = $1 FOR UPDATE`
+
+	var w domain.Wallet
+
+	err := tx.QueryRow(ctx, query, address).Scan(
+		&w.Address,
+		&w.Balance,
+		&w.CreatedAt,
+		&w.Currency,
+		&w.Status,
+		&w.OverdraftLimit,
+		&w.Treasury,
+	)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return nil, domain.ErrNotFound
+		}
+		if isTxConflict(err) {
+			return nil, fmt.Errorf("%w: failed to lock wallet %v: %w", domain.ErrTransactionConflict, address, err)
+		}
+		return nil, fmt.Errorf("%w: failed to lock wallet %v: %w", domain.ErrInternal, address, err)
+	}
+
+	if err := tx.QueryRow(ctx, heldAmountQuery, address).Scan(&w.Held); err != nil {
+		if isTxConflict(err) {
+			return nil, fmt.Errorf("%w: failed to read holds of wallet %v: %w", domain.ErrTransactionConflict, address, err)
+		}
+		return nil, fmt.Errorf("%w: failed to read holds of wallet %v: %w", domain.ErrInternal, address, err)
+	}
+
+	return &w, nil
+}
+
 func (wr *WalletRepository) GetWallet(ctx context.Context, address string) (*domain.Wallet, error) {
-	query := `SELECT address, balance, created_at 
+	query := `SELECT address, balance, created_at, currency, status, overdraft_limit, treasury
     		  FROM wallets WHERE address = $1`
 
 	var w domain.Wallet
@@ -68,6 +115,10 @@ func (wr *WalletRepository) GetWallet(ctx context.Context, address string) (*dom
 		&w.Address,
 		&w.Balance,
 		&w.CreatedAt,
+		&w.Currency,
+		&w.Status,
+		&w.OverdraftLimit,
+		&w.Treasury,
 	)
 
 	if err != nil {
@@ -80,15 +131,18 @@ func (wr *WalletRepository) GetWallet(ctx context.Context, address string) (*dom
 	return &w, nil
 }
 
-func (wr *WalletRepository) UpdateWalletBalance(ctx context.Context, address string, balance float64) error {
+func (wr *WalletRepository) UpdateWalletBalance(ctx context.Context, address string, balance domain.Money) error {
 	query := `UPDATE wallets SET balance = $1 WHERE address = $2`
 
 	result, err := wr.db.Exec(ctx, query, balance, address)
 	if err != nil {
 		if dbErr, ok := err.(DBError); ok {
-			if dbErr.SQLState() == ErrCodeCheckViolation && dbErr.ConstraintName() == ConstraintBalanceNonNegative {
+			if isBalanceViolation(dbErr) {
 				return domain.ErrNegativeBalance
 			}
+			if dbErr.SQLState() == ErrCodeNumericOutOfRange {
+				return fmt.Errorf("%w: balance %v", domain.ErrAmountOverflow, balance)
+			}
 		}
 		return fmt.Errorf("%w: failed to update wallet %v: %w", domain.ErrInternal, address, err)
 	}
@@ -102,31 +156,104 @@ func (wr *WalletRepository) UpdateWalletBalance(ctx context.Context, address str
 	return nil
 }
 
+// SetOverdraftLimit меняет кредитную линию кошелька. Лимит меньше текущей задолженности
+// отклоняется проверкой баланса в БД с domain.ErrOverdraftInUse.
+func (wr *WalletRepository) SetOverdraftLimit(ctx context.Context, address string, limit domain.Money) error {
+	query := `UPDATE wallets SET overdraft_limit = $1 WHERE address = $2`
+
+	result, err := wr.db.Exec(ctx, query, limit, address)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok {
+			if isBalanceViolation(dbErr) {
+				return fmt.Errorf("%w: wallet %v, limit %v", domain.ErrOverdraftInUse, address, limit)
+			}
+			if dbErr.SQLState() == ErrCodeNumericOutOfRange {
+				return fmt.Errorf("%w: overdraft limit %v", domain.ErrAmountOverflow, limit)
+			}
+		}
+		if isTxConflict(err) {
+			return fmt.Errorf("%w: failed to set overdraft limit of wallet %v: %w", domain.ErrTransactionConflict, address, err)
+		}
+		return fmt.Errorf("%w: failed to set overdraft limit of wallet %v: %w", domain.ErrInternal, address, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// EnsureTreasuryWallet создает кошелек казначейства с нулевым балансом или помечает существующий кошелек
+// как казначейство. Существующий кошелек в другой валюте возвращает domain.ErrCurrencyMismatch.
+func (wr *WalletRepository) EnsureTreasuryWallet(ctx context.Context, address, currency string) error {
+	query := `INSERT INTO wallets (address, currency, balance, treasury) VALUES ($1, $2, 0, true)
+              ON CONFLICT (address) DO UPDATE SET treasury = true
+              WHERE wallets.currency = EXCLUDED.currency`
+
+	result, err := wr.db.Exec(ctx, query, address, currency)
+	if err != nil {
+		return fmt.Errorf("%w: failed to set up treasury wallet %v: %w", domain.ErrInternal, address, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: treasury wallet %v is not in %v", domain.ErrCurrencyMismatch, address, currency)
+	}
+
+	return nil
+}
+
+// RemoveWallet удаляет кошелек без истории. Кошелек с проводками в журнале (в том числе только
+// с проводкой открытия счета) не удаляется: у postings.account нет внешнего ключа на wallets,
+// и удаление оставило бы проводки без счета, а system:equity - несбалансированным.
+// Такой кошелек можно только закрыть.
 func (wr *WalletRepository) RemoveWallet(ctx context.Context, address string) error {
-	query := `DELETE FROM wallets WHERE address = $1`
+	query := `WITH target AS (
+                  SELECT w.address, EXISTS (SELECT 1 FROM postings p WHERE p.account = w.address) AS has_postings
+                  FROM wallets w
+                  WHERE w.address = $1
+                  FOR UPDATE
+              ),
+              deleted AS (
+                  DELETE FROM wallets
+                  WHERE address IN (SELECT address FROM target WHERE NOT has_postings)
+              )
+              SELECT has_postings FROM target`
+
+	var hasPostings bool
 
-	result, err := wr.db.Exec(ctx, query, address)
+	err := wr.db.QueryRow(ctx, query, address).Scan(&hasPostings)
 	if err != nil {
+		if dbErr, ok := err.(DBError); ok {
+			if dbErr.SQLState() == "no_rows" {
+				return domain.ErrNotFound
+			}
+			// На кошелек ссылаются транзакции или резервы
+			if dbErr.SQLState() == ErrCodeForeignKeyViolation {
+				return fmt.Errorf("%w: wallet %v", domain.ErrWalletHasHistory, address)
+			}
+		}
 		return fmt.Errorf("%w: failed to delete wallet %v: %w", domain.ErrInternal, address, err)
 	}
 
-	rowsAffected := result.RowsAffected()
-
-	if rowsAffected == 0 {
-		return domain.ErrNotFound
+	if hasPostings {
+		return fmt.Errorf("%w: wallet %v has journal postings", domain.ErrWalletHasHistory, address)
 	}
 
 	return nil
 }
 
-func (wr *WalletRepository) CreateWalletTx(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error {
-	query := `INSERT INTO wallets (address, balance) VALUES ($1, $2)`
-	_, err := tx.Exec(ctx, query, address, balance)
+func (wr *WalletRepository) CreateWalletTx(ctx context.Context, tx domain.TxExecutor, address, currency string, balance domain.Money) error {
+	query := `INSERT INTO wallets (address, currency, balance) VALUES ($1, $2, $3)`
+	_, err := tx.Exec(ctx, query, address, currency, balance)
 	if err != nil {
 		if dbErr, ok := err.(DBError); ok {
-			if dbErr.SQLState() == ErrCodeCheckViolation && dbErr.ConstraintName() == ConstraintBalanceNonNegative {
+			if isBalanceViolation(dbErr) {
 				return domain.ErrNegativeBalance
 			}
+			if dbErr.SQLState() == ErrCodeNumericOutOfRange {
+				return fmt.Errorf("%w: balance %v", domain.ErrAmountOverflow, balance)
+			}
 			if dbErr.SQLState() == ErrCodeUniqueViolation {
 				return domain.ErrWalletAlreadyExists
 			}
@@ -136,14 +263,20 @@ func (wr *WalletRepository) CreateWalletTx(ctx context.Context, tx domain.TxExec
 	return nil
 }
 
-func (wr *WalletRepository) UpdateWalletBalanceTx(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error {
+func (wr *WalletRepository) UpdateWalletBalanceTx(ctx context.Context, tx domain.TxExecutor, address string, balance domain.Money) error {
 	query := `UPDATE wallets SET balance = $1 WHERE address = $2`
 	result, err := tx.Exec(ctx, query, balance, address)
 	if err != nil {
+		if isTxConflict(err) {
+			return fmt.Errorf("%w: failed to update wallet %v: %w", domain.ErrTransactionConflict, address, err)
+		}
 		if dbErr, ok := err.(DBError); ok {
-			if dbErr.SQLState() == ErrCodeCheckViolation && dbErr.ConstraintName() == ConstraintBalanceNonNegative {
+			if isBalanceViolation(dbErr) {
 				return domain.ErrNegativeBalance
 			}
+			if dbErr.SQLState() == ErrCodeNumericOutOfRange {
+				return fmt.Errorf("%w: balance %v", domain.ErrAmountOverflow, balance)
+			}
 		}
 		return fmt.Errorf("%w: failed to update wallet %v: %w", domain.ErrInternal, address, err)
 	}
@@ -153,3 +286,199 @@ func (wr *WalletRepository) UpdateWalletBalanceTx(ctx context.Context, tx domain
 	}
 	return nil
 }
+
+// ChangeWalletStatusTx меняет статус кошелька и записывает смену в историю.
+// Допустимость перехода проверяет вызывающий код, заблокировав кошелек через GetWalletForUpdateTx.
+func (wr *WalletRepository) ChangeWalletStatusTx(ctx context.Context, tx domain.TxExecutor, change domain.WalletStatusChange) (*domain.WalletStatusChange, error) {
+	query := `UPDATE wallets SET status = $1 WHERE address = $2`
+	result, err := tx.Exec(ctx, query, change.ToStatus, change.Address)
+	if err != nil {
+		if isTxConflict(err) {
+			return nil, fmt.Errorf("%w: failed to change status of wallet %v: %w", domain.ErrTransactionConflict, change.Address, err)
+		}
+		return nil, fmt.Errorf("%w: failed to change status of wallet %v: %w", domain.ErrInternal, change.Address, err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, domain.ErrNotFound
+	}
+
+	query = `INSERT INTO wallet_status_changes (address, from_status, to_status, reason)
+              VALUES ($1, $2, $3, $4)
+              RETURNING id, created_at`
+	err = tx.QueryRow(ctx, query, change.Address, change.FromStatus, change.ToStatus, change.Reason).Scan(&change.Id, &change.CreatedAt)
+	if err != nil {
+		if isTxConflict(err) {
+			return nil, fmt.Errorf("%w: failed to record status change of wallet %v: %w", domain.ErrTransactionConflict, change.Address, err)
+		}
+		return nil, fmt.Errorf("%w: failed to record status change of wallet %v: %w", domain.ErrInternal, change.Address, err)
+	}
+
+	return &change, nil
+}
+
+// GetWalletStatusChanges возвращает последние смены статуса кошелька, новые первыми
+func (wr *WalletRepository) GetWalletStatusChanges(ctx context.Context, address string, limit int) ([]domain.WalletStatusChange, error) {
+	query := `SELECT id, address, from_status, to_status, reason, created_at
+              FROM wallet_status_changes
+              WHERE address = $1
+              ORDER BY id DESC
+              LIMIT $2`
+
+	rows, err := wr.db.Query(ctx, query, address, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get status changes of wallet %v: %w", domain.ErrInternal, address, err)
+	}
+	defer rows.Close()
+
+	changes := make([]domain.WalletStatusChange, 0, limit)
+
+	for rows.Next() {
+		var c domain.WalletStatusChange
+
+		err := rows.Scan(
+			&c.Id,
+			&c.Address,
+			&c.FromStatus,
+			&c.ToStatus,
+			&c.Reason,
+			&c.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to scan wallet status change: %w", domain.ErrInternal, err)
+		}
+
+		changes = append(changes, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: error while fetching rows: %w", domain.ErrInternal, err)
+	}
+
+	return changes, nil
+}
+
+// walletLimitsQuery лимиты, заданные для кошелька, в порядке, который ожидает scanLimitOverrides
+const walletLimitsQuery = `SELECT max_transfer, max_daily_outflow, max_daily_count, max_balance
+              FROM wallet_limits WHERE address = $1`
+
+// scanLimitOverrides читает лимиты кошелька. Кошелек без своих лимитов получает пустые LimitOverrides.
+func scanLimitOverrides(row Row, address string) (domain.LimitOverrides, error) {
+	var o domain.LimitOverrides
+
+	err := row.Scan(&o.MaxTransfer, &o.MaxDailyOutflow, &o.MaxDailyCount, &o.MaxBalance)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == "no_rows" {
+			return domain.LimitOverrides{}, nil
+		}
+		if isTxConflict(err) {
+			return domain.LimitOverrides{}, fmt.Errorf("%w: failed to get limits of wallet %v: %w", domain.ErrTransactionConflict, address, err)
+		}
+		return domain.LimitOverrides{}, fmt.Errorf("%w: failed to get limits of wallet %v: %w", domain.ErrInternal, address, err)
+	}
+
+	return o, nil
+}
+
+// GetWalletLimits возвращает лимиты, заданные для кошелька. Существование кошелька не проверяется.
+func (wr *WalletRepository) GetWalletLimits(ctx context.Context, address string) (domain.LimitOverrides, error) {
+	return scanLimitOverrides(wr.db.QueryRow(ctx, walletLimitsQuery, address), address)
+}
+
+// GetWalletLimitsTx то же, что GetWalletLimits, внутри транзакции перевода
+func (wr *WalletRepository) GetWalletLimitsTx(ctx context.Context, tx domain.TxExecutor, address string) (domain.LimitOverrides, error) {
+	return scanLimitOverrides(tx.QueryRow(ctx, walletLimitsQuery, address), address)
+}
+
+// SetWalletLimits заменяет лимиты кошелька. nil в overrides возвращает лимит к значению по умолчанию.
+func (wr *WalletRepository) SetWalletLimits(ctx context.Context, address string, overrides domain.LimitOverrides) error {
+	query := `INSERT INTO wallet_limits (address, max_transfer, max_daily_outflow, max_daily_count, max_balance)
+              VALUES ($1, $2, $3, $4, $5)
+              ON CONFLICT (address) DO UPDATE
+              SET max_transfer = EXCLUDED.max_transfer,
+                  max_daily_outflow = EXCLUDED.max_daily_outflow,
+                  max_daily_count = EXCLUDED.max_daily_count,
+                  max_balance = EXCLUDED.max_balance,
+                  updated_at = now()`
+
+	_, err := wr.db.Exec(ctx, query,
+		address, overrides.MaxTransfer, overrides.MaxDailyOutflow, overrides.MaxDailyCount, overrides.MaxBalance,
+	)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok {
+			if dbErr.SQLState() == ErrCodeForeignKeyViolation {
+				return fmt.Errorf("%w: wallet %v", domain.ErrNotFound, address)
+			}
+			if dbErr.SQLState() == ErrCodeNumericOutOfRange {
+				return fmt.Errorf("%w: limits of wallet %v", domain.ErrAmountOverflow, address)
+			}
+		}
+		return fmt.Errorf("%w: failed to set limits of wallet %v: %w", domain.ErrInternal, address, err)
+	}
+
+	return nil
+}
+
+// CreateBalanceAdjustmentTx записывает ручное изменение баланса в аудит. EntryId 0 - баланс не изменился
+// и запись журнала не создавалась.
+func (wr *WalletRepository) CreateBalanceAdjustmentTx(ctx context.Context, tx domain.TxExecutor, adjustment domain.BalanceAdjustment) (*domain.BalanceAdjustment, error) {
+	query := `INSERT INTO balance_adjustments (address, previous_balance, new_balance, reason, entry_id)
+              VALUES ($1, $2, $3, $4, NULLIF($5, 0))
+              RETURNING id, created_at`
+
+	err := tx.QueryRow(ctx, query,
+		adjustment.Address, adjustment.Previous, adjustment.New, adjustment.Reason, adjustment.EntryId,
+	).Scan(&adjustment.Id, &adjustment.CreatedAt)
+	if err != nil {
+		if dbErr, ok := err.(DBError); ok && dbErr.SQLState() == ErrCodeForeignKeyViolation {
+			return nil, fmt.Errorf("%w: wallet %v", domain.ErrNotFound, adjustment.Address)
+		}
+		if isTxConflict(err) {
+			return nil, fmt.Errorf("%w: failed to record balance adjustment of wallet %v: %w", domain.ErrTransactionConflict, adjustment.Address, err)
+		}
+		return nil, fmt.Errorf("%w: failed to record balance adjustment of wallet %v: %w", domain.ErrInternal, adjustment.Address, err)
+	}
+
+	return &adjustment, nil
+}
+
+// GetBalanceAdjustments возвращает последние ручные изменения баланса кошелька, новые первыми
+func (wr *WalletRepository) GetBalanceAdjustments(ctx context.Context, address string, limit int) ([]domain.BalanceAdjustment, error) {
+	query := `SELECT id, address, previous_balance, new_balance, reason, COALESCE(entry_id, 0), created_at
+              FROM balance_adjustments
+              WHERE address = $1
+              ORDER BY id DESC
+              LIMIT $2`
+
+	rows, err := wr.db.Query(ctx, query, address, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get balance adjustments of wallet %v: %w", domain.ErrInternal, address, err)
+	}
+	defer rows.Close()
+
+	adjustments := make([]domain.BalanceAdjustment, 0, limit)
+
+	for rows.Next() {
+		var a domain.BalanceAdjustment
+
+		err := rows.Scan(
+			&a.Id,
+			&a.Address,
+			&a.Previous,
+			&a.New,
+			&a.Reason,
+			&a.EntryId,
+			&a.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to scan balance adjustment: %w", domain.ErrInternal, err)
+		}
+
+		adjustments = append(adjustments, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: error while fetching rows: %w", domain.ErrInternal, err)
+	}
+
+	return adjustments, nil
+}
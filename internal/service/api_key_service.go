@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// APIKeyService выдача, отзыв и проверка API-ключей.
+// Сам ключ нигде не сохраняется: в базе лежит его хеш, по которому ключ ищется при проверке.
+type APIKeyService struct {
+	keyRepo IAPIKeyRepository
+	log     logger.Logger
+}
+
+func NewAPIKeyService(kr IAPIKeyRepository, l logger.Logger) *APIKeyService {
+	return &APIKeyService{
+		keyRepo: kr,
+		log:     l,
+	}
+}
+
+// CreateAPIKey выдает новый ключ с правами scopes.
+// Возвращает сохраненный ключ и сам ключ, который больше нигде не будет показан.
+func (ks *APIKeyService) CreateAPIKey(ctx context.Context, name string, scopes []string) (*domain.APIKey, string, domain.ErrorCode) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		ks.log.Warn(ctx, "CreateAPIKey: name is required")
+		return nil, "", domain.CodeInvalidRequestBody
+	}
+
+	parsed, err := domain.ParseScopes(scopes)
+	if err != nil {
+		ks.log.Warn(ctx, "CreateAPIKey: invalid scopes", zap.Error(err))
+		return nil, "", domain.CodeInvalidScope
+	}
+
+	plain, err := domain.GenerateAPIKey()
+	if err != nil {
+		ks.log.Error(ctx, "CreateAPIKey", zap.Error(err))
+		return nil, "", domain.CodeInternal
+	}
+
+	key, err := ks.keyRepo.CreateAPIKey(ctx, name, domain.HashAPIKey(plain), parsed)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidScope) {
+			ks.log.Warn(ctx, "CreateAPIKey: invalid scopes", zap.Error(err))
+			return nil, "", domain.CodeInvalidScope
+		}
+		ks.log.Error(ctx, "CreateAPIKey: failed to create api key", zap.Error(err))
+		return nil, "", domain.CodeInternal
+	}
+
+	ks.log.Info(ctx, "CreateAPIKey: api key created",
+		zap.Int64("api_key_id", key.Id),
+		zap.String("name", key.Name),
+		zap.Any("scopes", key.Scopes))
+	return key, plain, domain.CodeOK
+}
+
+// ListAPIKeys возвращает все ключи, включая отозванные
+func (ks *APIKeyService) ListAPIKeys(ctx context.Context) ([]domain.APIKey, domain.ErrorCode) {
+	keys, err := ks.keyRepo.ListAPIKeys(ctx)
+	if err != nil {
+		ks.log.Error(ctx, "ListAPIKeys", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	ks.log.Info(ctx, "ListAPIKeys: success get api keys", zap.Int("count", len(keys)))
+	return keys, domain.CodeOK
+}
+
+// RevokeAPIKey отзывает ключ, после чего запросы с ним отклоняются. Повторный отзыв не считается ошибкой.
+func (ks *APIKeyService) RevokeAPIKey(ctx context.Context, id int64) domain.ErrorCode {
+	if err := ks.keyRepo.RevokeAPIKey(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			ks.log.Warn(ctx, "RevokeAPIKey: api key not found", zap.Int64("api_key_id", id))
+			return domain.CodeAPIKeyNotFound
+		}
+		ks.log.Error(ctx, "RevokeAPIKey", zap.Error(err))
+		return domain.CodeInternal
+	}
+	ks.log.Info(ctx, "RevokeAPIKey: api key revoked", zap.Int64("api_key_id", id))
+	return domain.CodeOK
+}
+
+// Authenticate находит действующий ключ по его значению.
+// Неизвестный и отозванный ключ одинаково возвращают domain.CodeUnauthorized.
+func (ks *APIKeyService) Authenticate(ctx context.Context, plain string) (*domain.APIKey, domain.ErrorCode) {
+	if plain == "" {
+		return nil, domain.CodeUnauthorized
+	}
+
+	key, err := ks.keyRepo.GetAPIKeyByHash(ctx, domain.HashAPIKey(plain))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			ks.log.Warn(ctx, "Authenticate: unknown api key")
+			return nil, domain.CodeUnauthorized
+		}
+		ks.log.Error(ctx, "Authenticate", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+
+	if key.Revoked() {
+		ks.log.Warn(ctx, "Authenticate: api key revoked", zap.Int64("api_key_id", key.Id))
+		return nil, domain.CodeUnauthorized
+	}
+
+	return key, domain.CodeOK
+}
@@ -0,0 +1,15 @@
+package service
+
+import "TransactionTest/internal/domain"
+
+// checkCurrencyAmount проверяет, что валюта поддерживается и сумма не точнее ее минимальной единицы
+func checkCurrencyAmount(currencies *domain.CurrencyRegistry, code string, amount domain.Money) domain.ErrorCode {
+	currency, err := currencies.Lookup(code)
+	if err != nil {
+		return domain.CodeUnsupportedCurrency
+	}
+	if !currency.Allows(amount) {
+		return domain.CodeInvalidAmount
+	}
+	return domain.CodeOK
+}
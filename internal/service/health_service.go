@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// healthCheckTimeout ограничение на одну проверку готовности, чтобы зависшая БД не держала запрос пробы
+const healthCheckTimeout = 2 * time.Second
+
+// HealthService проверки готовности сервиса обслуживать запросы.
+// Готовность требует доступной БД, схемы на версии самой новой миграции без dirty-состояния
+// и завершенного создания стартовых кошельков; с началом остановки сервер сразу перестает быть готовым.
+type HealthService struct {
+	schemaRepo      ISchemaRepository
+	latestMigration uint
+	log             logger.Logger
+
+	mu           sync.RWMutex
+	seeding      domain.SeedingStatus
+	shuttingDown atomic.Bool
+}
+
+// NewHealthService создает сервис проверок. latestMigration - версия самой новой миграции в каталоге миграций.
+func NewHealthService(sr ISchemaRepository, latestMigration uint, l logger.Logger) *HealthService {
+	return &HealthService{
+		schemaRepo:      sr,
+		latestMigration: latestMigration,
+		log:             l,
+		seeding:         domain.SeedingPending,
+	}
+}
+
+// SetSeedingStatus запоминает результат создания стартовых кошельков
+func (hs *HealthService) SetSeedingStatus(status domain.SeedingStatus) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.seeding = status
+}
+
+// MarkShuttingDown переводит сервис в неготовое состояние. Вызывается до остановки HTTP сервера,
+// чтобы балансировщик перестал направлять запросы, пока текущие дорабатывают.
+func (hs *HealthService) MarkShuttingDown() {
+	hs.shuttingDown.Store(true)
+}
+
+// Readiness выполняет проверки готовности и возвращает результат каждой с ее длительностью.
+// Во время остановки остальные проверки не выполняются.
+func (hs *HealthService) Readiness(ctx context.Context) domain.Readiness {
+	if hs.shuttingDown.Load() {
+		return domain.Readiness{
+			Ready: false,
+			Checks: []domain.HealthCheck{
+				{Name: domain.HealthCheckShutdown, Healthy: false, Detail: "server is shutting down"},
+			},
+		}
+	}
+
+	checks := []domain.HealthCheck{
+		hs.run(ctx, domain.HealthCheckDatabase, hs.checkDatabase),
+		hs.run(ctx, domain.HealthCheckMigrations, hs.checkMigrations),
+		hs.run(ctx, domain.HealthCheckSeeding, hs.checkSeeding),
+	}
+
+	ready := true
+	for _, c := range checks {
+		if !c.Healthy {
+			ready = false
+			hs.log.Warn(ctx, "Readiness: check failed",
+				zap.String("check", c.Name),
+				zap.String("detail", c.Detail))
+		}
+	}
+	return domain.Readiness{Ready: ready, Checks: checks}
+}
+
+// run выполняет проверку с ограничением по времени и замеряет ее длительность
+func (hs *HealthService) run(ctx context.Context, name string, check func(context.Context) (string, error)) domain.HealthCheck {
+	checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := check(checkCtx)
+	result := domain.HealthCheck{Name: name, Healthy: err == nil, Latency: time.Since(start), Detail: detail}
+	if err != nil {
+		result.Detail = err.Error()
+	}
+	return result
+}
+
+func (hs *HealthService) checkDatabase(ctx context.Context) (string, error) {
+	if err := hs.schemaRepo.Ping(ctx); err != nil {
+		return "", err
+	}
+	return "ok", nil
+}
+
+func (hs *HealthService) checkMigrations(ctx context.Context) (string, error) {
+	version, dirty, err := hs.schemaRepo.MigrationVersion(ctx)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return "", fmt.Errorf("migrations not applied, expected version %d", hs.latestMigration)
+		}
+		return "", err
+	}
+	if dirty {
+		return "", fmt.Errorf("database is dirty at version %d", version)
+	}
+	if version != hs.latestMigration {
+		return "", fmt.Errorf("database version %d, expected %d", version, hs.latestMigration)
+	}
+	return fmt.Sprintf("version %d", version), nil
+}
+
+func (hs *HealthService) checkSeeding(context.Context) (string, error) {
+	hs.mu.RLock()
+	status := hs.seeding
+	hs.mu.RUnlock()
+
+	if !status.Completed() {
+		return "", fmt.Errorf("seeding %s", status)
+	}
+	return string(status), nil
+}
@@ -0,0 +1,409 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"TransactionTest/config"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// Сроки резерва, если они не заданы в конфигурации
+const (
+	defaultHoldTTL    = 15 * time.Minute
+	defaultHoldMaxTTL = 7 * 24 * time.Hour
+)
+
+// HoldService резервы средств: резерв уменьшает доступный баланс отправителя,
+// а при захвате превращается в обычный перевод на всю сумму резерва или ее часть.
+type HoldService struct {
+	holdRepo        IHoldRepository
+	walletRepo      IWalletRepository
+	transactionRepo ITransactionRepository
+	ledgerRepo      ILedgerRepository
+	currencies      *domain.CurrencyRegistry
+	limits          transferLimits
+	cfg             config.TransferConfig
+	log             logger.Logger
+}
+
+func NewHoldService(hr IHoldRepository, wr IWalletRepository, tr ITransactionRepository, lr ILedgerRepository, currencies *domain.CurrencyRegistry, limits domain.LimitPolicy, cfg config.TransferConfig, l logger.Logger) *HoldService {
+	if cfg.HoldDefaultTTL <= 0 {
+		cfg.HoldDefaultTTL = defaultHoldTTL
+	}
+	if cfg.HoldMaxTTL <= 0 {
+		cfg.HoldMaxTTL = defaultHoldMaxTTL
+	}
+	return &HoldService{
+		holdRepo:        hr,
+		walletRepo:      wr,
+		transactionRepo: tr,
+		ledgerRepo:      lr,
+		currencies:      currencies,
+		limits:          transferLimits{walletRepo: wr, transactionRepo: tr, policy: limits, log: l},
+		cfg:             cfg,
+		log:             l,
+	}
+}
+
+// CreateHold резервирует средства на кошельке отправителя в пользу получателя.
+// Резерв действует TTL (по умолчанию transfers.HoldDefaultTTL) и не меняет баланс по журналу.
+func (hs *HoldService) CreateHold(ctx context.Context, req domain.HoldRequest) (*domain.Hold, domain.ErrorCode) {
+	if req.From == req.To {
+		hs.log.Warn(ctx, "CreateHold: self hold not allowed")
+		return nil, domain.CodeInvalidTransaction
+	}
+	if req.Amount <= 0 {
+		hs.log.Warn(ctx, "CreateHold: amount must be positive")
+		return nil, domain.CodeNegativeAmount
+	}
+	if err := req.Amount.Check(); err != nil {
+		hs.log.Warn(ctx, "CreateHold: amount out of range", zap.Stringer("amount", req.Amount))
+		return nil, domain.CodeAmountOverflow
+	}
+	if req.TTL == 0 {
+		req.TTL = hs.cfg.HoldDefaultTTL
+	}
+	if req.TTL < 0 || req.TTL > hs.cfg.HoldMaxTTL {
+		hs.log.Warn(ctx, "CreateHold: invalid ttl", zap.Duration("ttl", req.TTL))
+		return nil, domain.CodeInvalidHoldTTL
+	}
+
+	var hold *domain.Hold
+	code := withRetry(ctx, hs.log, hs.cfg, "CreateHold", func() domain.ErrorCode {
+		var attemptCode domain.ErrorCode
+		hold, attemptCode = hs.createHoldTx(ctx, req)
+		return attemptCode
+	})
+	if code != domain.CodeOK {
+		return nil, code
+	}
+
+	hs.log.Info(ctx, "CreateHold: hold created",
+		zap.Int64("hold_id", hold.Id),
+		zap.String("from", req.From),
+		zap.String("to", req.To),
+		zap.Stringer("amount", req.Amount))
+	return hold, domain.CodeOK
+}
+
+func (hs *HoldService) createHoldTx(ctx context.Context, req domain.HoldRequest) (*domain.Hold, domain.ErrorCode) {
+	tx, err := hs.holdRepo.BeginTX(ctx)
+	if err != nil {
+		hs.log.Error(ctx, "CreateHold: failed to begin transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	wallets, code := hs.lockWallets(ctx, tx, "CreateHold", req.From, req.To)
+	if code != domain.CodeOK {
+		return nil, code
+	}
+
+	sender, receiver := wallets[req.From], wallets[req.To]
+	if sender.Currency != receiver.Currency {
+		hs.log.Warn(ctx, "CreateHold: wallets have different currencies",
+			zap.String("from_currency", sender.Currency), zap.String("to_currency", receiver.Currency))
+		return nil, domain.CodeCurrencyMismatch
+	}
+	if code := checkCurrencyAmount(hs.currencies, sender.Currency, req.Amount); code != domain.CodeOK {
+		hs.log.Warn(ctx, "CreateHold: amount not allowed for currency",
+			zap.String("currency", sender.Currency), zap.Stringer("amount", req.Amount))
+		return nil, code
+	}
+	if sender.Available() < req.Amount {
+		hs.log.Warn(ctx, "CreateHold: insufficient funds",
+			zap.Stringer("available", sender.Available()), zap.Stringer("amount", req.Amount))
+		return nil, domain.CodeInsufficientFunds
+	}
+
+	hold, err := hs.holdRepo.CreateHoldTx(ctx, tx, req.From, req.To, sender.Currency, req.Amount, req.TTL)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTransactionConflict):
+			hs.log.Warn(ctx, "CreateHold: conflict while creating hold", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		case errors.Is(err, domain.ErrNotFound):
+			hs.log.Warn(ctx, "CreateHold: wallet not found", zap.Error(err))
+			return nil, domain.CodeWalletNotFound
+		default:
+			hs.log.Error(ctx, "CreateHold: failed to create hold", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			hs.log.Warn(ctx, "CreateHold: conflict on commit", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		}
+		hs.log.Error(ctx, "CreateHold: failed to commit transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed = true
+
+	return hold, domain.CodeOK
+}
+
+func (hs *HoldService) GetHold(ctx context.Context, id int64) (*domain.Hold, domain.ErrorCode) {
+	hold, err := hs.holdRepo.GetHold(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			hs.log.Warn(ctx, "GetHold: hold not found", zap.Int64("id", id))
+			return nil, domain.CodeHoldNotFound
+		}
+		hs.log.Error(ctx, "GetHold", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	hs.log.Info(ctx, "GetHold: success get hold", zap.Int64("id", id))
+	return hold, domain.CodeOK
+}
+
+// CaptureHold списывает зарезервированные средства переводом получателю резерва.
+// amount = 0 означает всю сумму резерва; при частичном захвате остаток освобождается.
+// Захват - это перевод, поэтому на него действуют лимиты кошельков: при CodeLimitExceeded
+// возвращается результат, в котором ExceededLimit - нарушенный лимит.
+func (hs *HoldService) CaptureHold(ctx context.Context, id int64, amount domain.Money) (*domain.TransferResult, domain.ErrorCode) {
+	if amount < 0 {
+		hs.log.Warn(ctx, "CaptureHold: amount must be positive")
+		return nil, domain.CodeNegativeAmount
+	}
+
+	var result *domain.TransferResult
+	code := withRetry(ctx, hs.log, hs.cfg, "CaptureHold", func() domain.ErrorCode {
+		var attemptCode domain.ErrorCode
+		result, attemptCode = hs.captureHoldTx(ctx, id, amount)
+		return attemptCode
+	})
+	if code == domain.CodeLimitExceeded {
+		return result, code
+	}
+	if code != domain.CodeOK {
+		return nil, code
+	}
+
+	hs.log.Info(ctx, "CaptureHold: hold captured",
+		zap.Int64("hold_id", id),
+		zap.Int64("transaction_id", result.TransactionId))
+	return result, domain.CodeOK
+}
+
+func (hs *HoldService) captureHoldTx(ctx context.Context, id int64, amount domain.Money) (*domain.TransferResult, domain.ErrorCode) {
+	tx, err := hs.holdRepo.BeginTX(ctx)
+	if err != nil {
+		hs.log.Error(ctx, "CaptureHold: failed to begin transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	hold, code := hs.lockActiveHold(ctx, tx, "CaptureHold", id)
+	if code != domain.CodeOK {
+		return nil, code
+	}
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if amount > hold.Amount {
+		hs.log.Warn(ctx, "CaptureHold: amount exceeds hold",
+			zap.Stringer("amount", amount), zap.Stringer("hold", hold.Amount))
+		return nil, domain.CodeCaptureExceedsHold
+	}
+	if code := checkCurrencyAmount(hs.currencies, hold.Currency, amount); code != domain.CodeOK {
+		hs.log.Warn(ctx, "CaptureHold: amount not allowed for currency",
+			zap.String("currency", hold.Currency), zap.Stringer("amount", amount))
+		return nil, code
+	}
+
+	wallets, code := hs.lockWallets(ctx, tx, "CaptureHold", hold.From, hold.To)
+	if code != domain.CodeOK {
+		return nil, code
+	}
+
+	// Сам резерв входит в Held отправителя, поэтому его сумма доступна для захвата
+	sender := wallets[hold.From]
+	if sender.Available()+hold.Amount < amount {
+		hs.log.Warn(ctx, "CaptureHold: insufficient funds",
+			zap.Stringer("available", sender.Available()), zap.Stringer("amount", amount))
+		return nil, domain.CodeInsufficientFunds
+	}
+	if limit, code := hs.limits.check(ctx, tx, "CaptureHold", sender, wallets[hold.To], amount, 0, amount, domain.Outflow{}); code != domain.CodeOK {
+		if code == domain.CodeLimitExceeded {
+			return &domain.TransferResult{ExceededLimit: limit}, code
+		}
+		return nil, code
+	}
+
+	transactionId, err := hs.transactionRepo.CreateTransactionTx(ctx, tx, hold.From, hold.To, amount, 0, domain.TransferDetails{})
+	if err == nil {
+		entry := domain.NewTransferEntry(domain.EntryTransfer, transactionId, hold.From, hold.To, hold.Currency, amount)
+		_, err = hs.ledgerRepo.PostEntryTx(ctx, tx, entry)
+	}
+	if err == nil {
+		err = hs.holdRepo.CloseHoldTx(ctx, tx, id, domain.HoldCaptured, amount, transactionId)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInsufficientFunds):
+			hs.log.Warn(ctx, "CaptureHold: insufficient funds", zap.Error(err))
+			return nil, domain.CodeInsufficientFunds
+		case errors.Is(err, domain.ErrAmountOverflow):
+			hs.log.Warn(ctx, "CaptureHold: receiver balance out of range", zap.Error(err))
+			return nil, domain.CodeAmountOverflow
+		case errors.Is(err, domain.ErrHoldNotActive):
+			hs.log.Warn(ctx, "CaptureHold: hold expired during capture", zap.Int64("id", id))
+			return nil, domain.CodeHoldNotActive
+		case errors.Is(err, domain.ErrTransactionConflict):
+			hs.log.Warn(ctx, "CaptureHold: conflict while capturing hold", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		default:
+			hs.log.Error(ctx, "CaptureHold: failed to capture hold", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			hs.log.Warn(ctx, "CaptureHold: conflict on commit", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		}
+		hs.log.Error(ctx, "CaptureHold: failed to commit transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed = true
+
+	return &domain.TransferResult{TransactionId: transactionId}, domain.CodeOK
+}
+
+// VoidHold отменяет активный резерв и освобождает средства
+func (hs *HoldService) VoidHold(ctx context.Context, id int64) domain.ErrorCode {
+	code := withRetry(ctx, hs.log, hs.cfg, "VoidHold", func() domain.ErrorCode {
+		return hs.voidHoldTx(ctx, id)
+	})
+	if code != domain.CodeOK {
+		return code
+	}
+
+	hs.log.Info(ctx, "VoidHold: hold voided", zap.Int64("hold_id", id))
+	return domain.CodeOK
+}
+
+func (hs *HoldService) voidHoldTx(ctx context.Context, id int64) domain.ErrorCode {
+	tx, err := hs.holdRepo.BeginTX(ctx)
+	if err != nil {
+		hs.log.Error(ctx, "VoidHold: failed to begin transaction", zap.Error(err))
+		return domain.CodeInternal
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	if _, code := hs.lockActiveHold(ctx, tx, "VoidHold", id); code != domain.CodeOK {
+		return code
+	}
+
+	if err = hs.holdRepo.CloseHoldTx(ctx, tx, id, domain.HoldVoided, 0, 0); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrHoldNotActive):
+			hs.log.Warn(ctx, "VoidHold: hold expired during void", zap.Int64("id", id))
+			return domain.CodeHoldNotActive
+		case errors.Is(err, domain.ErrTransactionConflict):
+			hs.log.Warn(ctx, "VoidHold: conflict while voiding hold", zap.Error(err))
+			return domain.CodeTransactionConflict
+		default:
+			hs.log.Error(ctx, "VoidHold: failed to void hold", zap.Error(err))
+			return domain.CodeInternal
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			hs.log.Warn(ctx, "VoidHold: conflict on commit", zap.Error(err))
+			return domain.CodeTransactionConflict
+		}
+		hs.log.Error(ctx, "VoidHold: failed to commit transaction", zap.Error(err))
+		return domain.CodeInternal
+	}
+	committed = true
+
+	return domain.CodeOK
+}
+
+// ExpireHolds помечает истекшими резервы с прошедшим сроком
+func (hs *HoldService) ExpireHolds(ctx context.Context) (int64, domain.ErrorCode) {
+	expired, err := hs.holdRepo.ExpireHolds(ctx)
+	if err != nil {
+		hs.log.Error(ctx, "ExpireHolds", zap.Error(err))
+		return 0, domain.CodeInternal
+	}
+	hs.log.Info(ctx, "ExpireHolds: success", zap.Int64("expired", expired))
+	return expired, domain.CodeOK
+}
+
+// lockActiveHold блокирует резерв и проверяет, что он еще активен
+func (hs *HoldService) lockActiveHold(ctx context.Context, tx domain.TxExecutor, operation string, id int64) (*domain.Hold, domain.ErrorCode) {
+	hold, err := hs.holdRepo.GetHoldForUpdateTx(ctx, tx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			hs.log.Warn(ctx, operation+": hold not found", zap.Int64("id", id))
+			return nil, domain.CodeHoldNotFound
+		case errors.Is(err, domain.ErrTransactionConflict):
+			hs.log.Warn(ctx, operation+": conflict while locking hold", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		default:
+			hs.log.Error(ctx, operation+": failed to lock hold", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+	if !hold.IsActive() {
+		hs.log.Warn(ctx, operation+": hold is not active", zap.Int64("id", id), zap.String("status", string(hold.Status)))
+		return nil, domain.CodeHoldNotActive
+	}
+	return hold, domain.CodeOK
+}
+
+// lockWallets блокирует кошельки в порядке возрастания адреса, как и переводы.
+// Замороженные и закрытые кошельки не участвуют ни в резервах, ни в их захвате.
+func (hs *HoldService) lockWallets(ctx context.Context, tx domain.TxExecutor, operation string, addresses ...string) (map[string]*domain.Wallet, domain.ErrorCode) {
+	wallets := make(map[string]*domain.Wallet, len(addresses))
+	for _, address := range lockOrder(addresses...) {
+		wallet, err := hs.walletRepo.GetWalletForUpdateTx(ctx, tx, address)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				hs.log.Warn(ctx, operation+": wallet not found", zap.String("address", address), zap.Error(err))
+				return nil, domain.CodeWalletNotFound
+			case errors.Is(err, domain.ErrTransactionConflict):
+				hs.log.Warn(ctx, operation+": conflict while locking wallet", zap.String("address", address), zap.Error(err))
+				return nil, domain.CodeTransactionConflict
+			default:
+				hs.log.Error(ctx, operation+": failed to lock wallet", zap.String("address", address), zap.Error(err))
+				return nil, domain.CodeInternal
+			}
+		}
+		if code := transferWalletCode(wallet); code != domain.CodeOK {
+			hs.log.Warn(ctx, operation+": wallet can not take part in transfer",
+				zap.String("address", address), zap.String("status", string(wallet.Status)), zap.Bool("treasury", wallet.Treasury))
+			return nil, code
+		}
+		wallets[address] = wallet
+	}
+	return wallets, domain.CodeOK
+}
@@ -9,21 +9,116 @@ import (
 
 type IWalletRepository interface {
 	BeginTX(ctx context.Context) (domain.TxExecutor, error)
-	CreateWalletTx(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error
-	UpdateWalletBalanceTx(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error
-	CreateWallet(ctx context.Context, address string, balance float64) error
-	GetWalletBalance(ctx context.Context, address string) (float64, error)
+	CreateWalletTx(ctx context.Context, tx domain.TxExecutor, address, currency string, balance domain.Money) error
+	GetWalletForUpdateTx(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error)
+	GetWalletBalance(ctx context.Context, address string) (domain.Balance, error)
 	GetWallet(ctx context.Context, address string) (*domain.Wallet, error)
-	UpdateWalletBalance(ctx context.Context, address string, balance float64) error
 	RemoveWallet(ctx context.Context, address string) error
+	ChangeWalletStatusTx(ctx context.Context, tx domain.TxExecutor, change domain.WalletStatusChange) (*domain.WalletStatusChange, error)
+	GetWalletStatusChanges(ctx context.Context, address string, limit int) ([]domain.WalletStatusChange, error)
+	GetWalletLimits(ctx context.Context, address string) (domain.LimitOverrides, error)
+	GetWalletLimitsTx(ctx context.Context, tx domain.TxExecutor, address string) (domain.LimitOverrides, error)
+	SetWalletLimits(ctx context.Context, address string, overrides domain.LimitOverrides) error
+	SetOverdraftLimit(ctx context.Context, address string, limit domain.Money) error
+	EnsureTreasuryWallet(ctx context.Context, address, currency string) error
+	CreateBalanceAdjustmentTx(ctx context.Context, tx domain.TxExecutor, adjustment domain.BalanceAdjustment) (*domain.BalanceAdjustment, error)
+	GetBalanceAdjustments(ctx context.Context, address string, limit int) ([]domain.BalanceAdjustment, error)
 }
 
 type ITransactionRepository interface {
 	BeginTX(ctx context.Context) (domain.TxExecutor, error)
-	CreateTransactionTx(ctx context.Context, tx domain.TxExecutor, from, to string, amount float64) (int64, error)
-	CreateTransaction(ctx context.Context, from, to string, amount float64) (int64, error)
+	CreateTransactionTx(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error)
+	CreateTransaction(ctx context.Context, from, to string, amount domain.Money) (int64, error)
+	CreateExchangeTx(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, exchange domain.Exchange, details domain.TransferDetails) (int64, error)
+	CreateFundsTx(ctx context.Context, tx domain.TxExecutor, kind domain.TransactionKind, from, to string, amount domain.Money, details domain.TransferDetails) (int64, error)
+	GetTransactionTx(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Transaction, error)
+	GetTransactionForUpdateTx(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Transaction, error)
+	CreateReversalTx(ctx context.Context, tx domain.TxExecutor, original *domain.Transaction) (int64, error)
+	GetOutflowTx(ctx context.Context, tx domain.TxExecutor, address string, window time.Duration) (domain.Outflow, error)
 	GetTransactionById(ctx context.Context, id int64) (*domain.Transaction, error)
 	GetTransactionByInfo(ctx context.Context, from, to string, createdAt time.Time) (*domain.Transaction, error)
+	GetTransactionsByExternalReference(ctx context.Context, reference, from string) ([]domain.Transaction, error)
 	RemoveTransaction(ctx context.Context, id int64) error
 	GetLastTransactions(ctx context.Context, limit int) ([]domain.Transaction, error)
+	ListTransactions(ctx context.Context, filter domain.TransactionFilter) (*domain.TransactionPage, error)
+	BeginSnapshotTX(ctx context.Context) (domain.TxExecutor, error)
+	GetOpeningBalanceTx(ctx context.Context, tx domain.TxExecutor, address string, before time.Time) (string, domain.Money, error)
+	GetStatementLinesTx(ctx context.Context, tx domain.TxExecutor, address string, from, to time.Time) ([]domain.StatementLine, error)
+}
+
+type IIdempotencyRepository interface {
+	ReserveKeyTx(ctx context.Context, tx domain.TxExecutor, key, requestHash string, ttl time.Duration) (*domain.IdempotencyRecord, error)
+	CompleteKeyTx(ctx context.Context, tx domain.TxExecutor, key string, transactionId int64) error
+	RemoveExpiredKeys(ctx context.Context) (int64, error)
+}
+
+// IHoldRepository резервы средств. Сумма активных резервов учитывается в IWalletRepository.GetWalletForUpdateTx.
+type IHoldRepository interface {
+	BeginTX(ctx context.Context) (domain.TxExecutor, error)
+	CreateHoldTx(ctx context.Context, tx domain.TxExecutor, from, to, currency string, amount domain.Money, ttl time.Duration) (*domain.Hold, error)
+	GetHold(ctx context.Context, id int64) (*domain.Hold, error)
+	GetHoldForUpdateTx(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Hold, error)
+	CloseHoldTx(ctx context.Context, tx domain.TxExecutor, id int64, status domain.HoldStatus, capturedAmount domain.Money, transactionId int64) error
+	ExpireHolds(ctx context.Context) (int64, error)
+}
+
+// IScheduledTransferRepository очередь отложенных переводов
+type IScheduledTransferRepository interface {
+	CreateScheduledTransfer(ctx context.Context, req domain.ScheduledTransferRequest) (*domain.ScheduledTransfer, error)
+	GetScheduledTransfers(ctx context.Context, status domain.ScheduledStatus, limit int) ([]domain.ScheduledTransfer, error)
+	CancelScheduledTransfer(ctx context.Context, id int64) error
+	ClaimDueTransfer(ctx context.Context, lease time.Duration) (*domain.ScheduledTransfer, error)
+	FinishScheduledTransfer(ctx context.Context, id int64, status domain.ScheduledStatus, transactionId int64, failureCode domain.ErrorCode) error
+}
+
+// IMandateRepository поручения на регулярные переводы и история их попыток
+type IMandateRepository interface {
+	CreateMandate(ctx context.Context, m domain.Mandate) (*domain.Mandate, error)
+	GetMandate(ctx context.Context, id int64) (*domain.Mandate, error)
+	CancelMandate(ctx context.Context, id int64) error
+	ClaimDueMandate(ctx context.Context, lease time.Duration) (*domain.Mandate, error)
+	FinishMandateRun(ctx context.Context, run domain.MandateRun, m domain.Mandate) error
+	GetMandateRuns(ctx context.Context, mandateId int64, limit int) ([]domain.MandateRun, error)
+}
+
+// ILedgerRepository журнал проводок. Балансы кошельков меняются только через PostEntryTx.
+type ILedgerRepository interface {
+	PostEntryTx(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error)
+	GetAccountPostings(ctx context.Context, account string, limit int) ([]domain.Posting, error)
+	GetBalanceAt(ctx context.Context, address string, at time.Time) (domain.Money, error)
+	CreateBalanceSnapshots(ctx context.Context, lag time.Duration) (int64, error)
+	// Сверка: RecordEntryTx пишет запись журнала без изменения балансов кошельков
+	GetBalanceChecks(ctx context.Context) ([]domain.BalanceCheck, error)
+	GetBalanceCheckForUpdateTx(ctx context.Context, tx domain.TxExecutor, address string) (*domain.BalanceCheck, error)
+	RecordEntryTx(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error)
+}
+
+// IAPIKeyRepository API-ключи, которые ищутся по хешу ключа
+type IAPIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, name, keyHash string, scopes []domain.Scope) (*domain.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+}
+
+// ISchemaRepository состояние подключения к БД и версии схемы для проверок готовности
+type ISchemaRepository interface {
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (uint, bool, error)
+}
+
+// ExchangeRateProvider источник курсов для переводов между валютами.
+// Если курса пары нет, возвращает domain.ErrRateNotFound.
+type ExchangeRateProvider interface {
+	GetRate(ctx context.Context, from, to string) (domain.ExchangeRate, error)
+}
+
+// TransferMetrics учитывает результаты переводов, реализуется metrics.Metrics
+type TransferMetrics interface {
+	ObserveTransfer(code domain.ErrorCode, amount domain.Money)
+}
+
+// TransferSender выполняет перевод между кошельками, реализуется TransactionService
+type TransferSender interface {
+	SendMoney(ctx context.Context, req domain.TransferRequest) (*domain.TransferResult, domain.ErrorCode)
 }
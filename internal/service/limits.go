@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// transferLimits проверяет лимиты кошельков при переводе между клиентскими кошельками.
+// Одна проверка на SendMoney, SendBatch и CaptureHold, чтобы ни один путь перевода не обходил лимиты.
+type transferLimits struct {
+	walletRepo      IWalletRepository
+	transactionRepo ITransactionRepository
+	policy          domain.LimitPolicy
+	log             logger.Logger
+}
+
+// check проверяет лимиты отправителя (сумма перевода, сумма и число исходящих переводов за окно)
+// и лимит баланса получателя. Оба кошелька уже заблокированы, поэтому параллельные переводы
+// с того же кошелька не обойдут дневные лимиты. pending - исходящие переводы отправителя,
+// уже принятые в этой транзакции БД, но еще не записанные (предыдущие ноги пакета).
+// Возвращает нарушенный лимит вместе с CodeLimitExceeded.
+func (tl transferLimits) check(ctx context.Context, tx domain.TxExecutor, operation string, sender, receiver *domain.Wallet, amount, fee, credited domain.Money, pending domain.Outflow) (domain.LimitKind, domain.ErrorCode) {
+	senderLimits, code := tl.walletLimits(ctx, tx, operation, sender.Address)
+	if code != domain.CodeOK {
+		return "", code
+	}
+	// Исходящие переводы за окно нужны только для дневных лимитов
+	outflow := pending
+	if senderLimits.MaxDailyOutflow > 0 || senderLimits.MaxDailyCount > 0 {
+		recorded, err := tl.transactionRepo.GetOutflowTx(ctx, tx, sender.Address, tl.policy.Window)
+		if err != nil {
+			if errors.Is(err, domain.ErrTransactionConflict) {
+				tl.log.Warn(ctx, operation+": conflict while reading wallet outflow", zap.Error(err))
+				return "", domain.CodeTransactionConflict
+			}
+			tl.log.Error(ctx, operation+": failed to read wallet outflow", zap.Error(err))
+			return "", domain.CodeInternal
+		}
+		outflow.Amount += recorded.Amount
+		outflow.Count += recorded.Count
+	}
+	if limit := senderLimits.CheckOutgoing(amount, fee, outflow); limit != "" {
+		tl.log.Warn(ctx, operation+": sender limit exceeded",
+			zap.String("address", sender.Address), zap.String("limit", string(limit)),
+			zap.Stringer("amount", amount), zap.Stringer("outflow", outflow.Amount), zap.Int("count", outflow.Count))
+		return limit, domain.CodeLimitExceeded
+	}
+
+	receiverLimits, code := tl.walletLimits(ctx, tx, operation, receiver.Address)
+	if code != domain.CodeOK {
+		return "", code
+	}
+	if limit := receiverLimits.CheckIncoming(receiver.Balance, credited); limit != "" {
+		tl.log.Warn(ctx, operation+": receiver limit exceeded",
+			zap.String("address", receiver.Address), zap.String("limit", string(limit)),
+			zap.Stringer("balance", receiver.Balance), zap.Stringer("credited", credited))
+		return limit, domain.CodeLimitExceeded
+	}
+	return "", domain.CodeOK
+}
+
+// walletLimits возвращает действующие лимиты кошелька с учетом значений по умолчанию
+func (tl transferLimits) walletLimits(ctx context.Context, tx domain.TxExecutor, operation, address string) (domain.WalletLimits, domain.ErrorCode) {
+	overrides, err := tl.walletRepo.GetWalletLimitsTx(ctx, tx, address)
+	if err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			tl.log.Warn(ctx, operation+": conflict while reading wallet limits", zap.Error(err))
+			return domain.WalletLimits{}, domain.CodeTransactionConflict
+		}
+		tl.log.Error(ctx, operation+": failed to read wallet limits", zap.String("address", address), zap.Error(err))
+		return domain.WalletLimits{}, domain.CodeInternal
+	}
+	return overrides.Apply(tl.policy.Defaults), domain.CodeOK
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"TransactionTest/config"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// Политика повторов регулярных переводов, если она не задана в конфигурации
+const (
+	defaultMandateMaxAttempts = 3
+	defaultMandateRetryDelay  = time.Hour
+)
+
+// retryableMandateCodes ошибки, после которых запуск поручения повторяется.
+// Остальные (нет кошелька, разные валюты и т.п.) повтором не исправить, запуск сразу считается неудачным.
+var retryableMandateCodes = map[domain.ErrorCode]bool{
+	domain.CodeInsufficientFunds:   true,
+	domain.CodeTransactionConflict: true,
+	domain.CodeRateUnavailable:     true,
+	domain.CodeInternal:            true,
+}
+
+// MandateService регулярные переводы: по расписанию поручения фоновая задача выполняет переводы
+// через TransferSender, поэтому к ним применяются те же проверки и комиссии, что и к обычным.
+type MandateService struct {
+	mandateRepo IMandateRepository
+	sender      TransferSender
+	cfg         config.TransferConfig
+	log         logger.Logger
+}
+
+func NewMandateService(mr IMandateRepository, sender TransferSender, cfg config.TransferConfig, l logger.Logger) *MandateService {
+	if cfg.ScheduledBatchSize <= 0 {
+		cfg.ScheduledBatchSize = defaultScheduledBatchSize
+	}
+	if cfg.ScheduledLease <= 0 {
+		cfg.ScheduledLease = defaultScheduledLease
+	}
+	if cfg.MandateMaxAttempts <= 0 {
+		cfg.MandateMaxAttempts = defaultMandateMaxAttempts
+	}
+	if cfg.MandateRetryDelay <= 0 {
+		cfg.MandateRetryDelay = defaultMandateRetryDelay
+	}
+	return &MandateService{
+		mandateRepo: mr,
+		sender:      sender,
+		cfg:         cfg,
+		log:         l,
+	}
+}
+
+// CreateMandate создает поручение и рассчитывает его первый запуск.
+// Баланс и валюты проверяются при каждом запуске, а не при создании.
+func (ms *MandateService) CreateMandate(ctx context.Context, req domain.MandateRequest) (*domain.Mandate, domain.ErrorCode) {
+	if req.From == req.To {
+		ms.log.Warn(ctx, "CreateMandate: self transfer not allowed")
+		return nil, domain.CodeInvalidTransaction
+	}
+	if req.Amount <= 0 {
+		ms.log.Warn(ctx, "CreateMandate: amount must be positive")
+		return nil, domain.CodeNegativeAmount
+	}
+	if err := req.Amount.Check(); err != nil {
+		ms.log.Warn(ctx, "CreateMandate: amount out of range", zap.Stringer("amount", req.Amount))
+		return nil, domain.CodeAmountOverflow
+	}
+
+	mandate, err := domain.NewMandate(req, time.Now())
+	if err != nil {
+		ms.log.Warn(ctx, "CreateMandate: invalid recurrence", zap.Error(err))
+		return nil, domain.CodeInvalidRecurrence
+	}
+
+	created, err := ms.mandateRepo.CreateMandate(ctx, mandate)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			ms.log.Warn(ctx, "CreateMandate: wallet not found", zap.Error(err))
+			return nil, domain.CodeWalletNotFound
+		case errors.Is(err, domain.ErrAmountOverflow):
+			ms.log.Warn(ctx, "CreateMandate: amount out of range", zap.Error(err))
+			return nil, domain.CodeAmountOverflow
+		default:
+			ms.log.Error(ctx, "CreateMandate: failed to create mandate", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+
+	ms.log.Info(ctx, "CreateMandate: mandate created",
+		zap.Int64("mandate_id", created.Id),
+		zap.String("from", req.From),
+		zap.String("to", req.To),
+		zap.Stringer("amount", req.Amount),
+		zap.Time("first_run_at", created.ScheduledAt))
+	return created, domain.CodeOK
+}
+
+func (ms *MandateService) GetMandate(ctx context.Context, id int64) (*domain.Mandate, domain.ErrorCode) {
+	mandate, err := ms.mandateRepo.GetMandate(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			ms.log.Warn(ctx, "GetMandate: mandate not found", zap.Int64("id", id))
+			return nil, domain.CodeMandateNotFound
+		}
+		ms.log.Error(ctx, "GetMandate", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	ms.log.Info(ctx, "GetMandate: success get mandate", zap.Int64("id", id))
+	return mandate, domain.CodeOK
+}
+
+// CancelMandate останавливает активное поручение
+func (ms *MandateService) CancelMandate(ctx context.Context, id int64) domain.ErrorCode {
+	if err := ms.mandateRepo.CancelMandate(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			ms.log.Warn(ctx, "CancelMandate: mandate not found", zap.Int64("id", id))
+			return domain.CodeMandateNotFound
+		case errors.Is(err, domain.ErrMandateNotActive):
+			ms.log.Warn(ctx, "CancelMandate: mandate is not active", zap.Int64("id", id))
+			return domain.CodeMandateNotActive
+		default:
+			ms.log.Error(ctx, "CancelMandate", zap.Error(err))
+			return domain.CodeInternal
+		}
+	}
+	ms.log.Info(ctx, "CancelMandate: mandate canceled", zap.Int64("id", id))
+	return domain.CodeOK
+}
+
+// GetMandateRuns возвращает историю попыток поручения, новые первыми
+func (ms *MandateService) GetMandateRuns(ctx context.Context, id int64, limit int) ([]domain.MandateRun, domain.ErrorCode) {
+	if limit <= 0 {
+		ms.log.Warn(ctx, "GetMandateRuns: limit must be greater than zero")
+		return nil, domain.CodeInvalidLimit
+	}
+	if _, code := ms.GetMandate(ctx, id); code != domain.CodeOK {
+		return nil, code
+	}
+
+	runs, err := ms.mandateRepo.GetMandateRuns(ctx, id, limit)
+	if err != nil {
+		ms.log.Error(ctx, "GetMandateRuns", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	ms.log.Info(ctx, "GetMandateRuns: success get mandate runs", zap.Int64("id", id), zap.Int("limit", limit))
+	return runs, domain.CodeOK
+}
+
+// ExecuteDue выполняет наступившие попытки поручений, не больше transfers.ScheduledBatchSize за вызов.
+// Как и для отложенных переводов, при остановке сервера текущая попытка доводится до конца.
+// Возвращает число выполненных попыток.
+func (ms *MandateService) ExecuteDue(ctx context.Context) (int, domain.ErrorCode) {
+	executed := 0
+	for executed < ms.cfg.ScheduledBatchSize && ctx.Err() == nil {
+		mandate, err := ms.mandateRepo.ClaimDueMandate(ctx, ms.cfg.ScheduledLease)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				break
+			}
+			ms.log.Error(ctx, "ExecuteDue: failed to claim mandate", zap.Error(err))
+			return executed, domain.CodeInternal
+		}
+
+		ms.execute(context.WithoutCancel(ctx), mandate)
+		executed++
+	}
+
+	if executed > 0 {
+		ms.log.Info(ctx, "ExecuteDue: mandates executed", zap.Int("executed", executed))
+	}
+	return executed, domain.CodeOK
+}
+
+// execute выполняет одну попытку поручения, записывает ее в историю и сдвигает расписание.
+// Неудачная попытка с временной ошибкой повторяется через MandateRetryDelay * номер попытки,
+// пока не исчерпан MandateMaxAttempts; после этого запуск пропускается и поручение ждет следующего.
+func (ms *MandateService) execute(ctx context.Context, mandate *domain.Mandate) {
+	run := domain.MandateRun{
+		MandateId:   mandate.Id,
+		Occurrence:  mandate.Occurrence,
+		Attempt:     mandate.Attempt,
+		ScheduledAt: mandate.ScheduledAt,
+		Status:      domain.MandateRunSucceeded,
+	}
+
+	result, code := ms.sender.SendMoney(ctx, mandate.TransferRequest())
+	switch {
+	case code == domain.CodeOK:
+		run.TransactionId = result.TransactionId
+		mandate.Advance()
+	case retryableMandateCodes[code] && mandate.Attempt < ms.cfg.MandateMaxAttempts:
+		run.Status = domain.MandateRunRetrying
+		run.FailureCode = code
+		mandate.Retry(time.Now().Add(ms.cfg.MandateRetryDelay * time.Duration(mandate.Attempt)))
+	default:
+		run.Status = domain.MandateRunFailed
+		run.FailureCode = code
+		mandate.Advance()
+	}
+
+	if err := ms.mandateRepo.FinishMandateRun(ctx, run, *mandate); err != nil {
+		ms.log.Error(ctx, "ExecuteDue: failed to record mandate run",
+			zap.Int64("mandate_id", mandate.Id), zap.Error(err))
+		return
+	}
+
+	ms.log.Info(ctx, "ExecuteDue: mandate run recorded",
+		zap.Int64("mandate_id", mandate.Id),
+		zap.Int("occurrence", run.Occurrence),
+		zap.Int("attempt", run.Attempt),
+		zap.String("status", string(run.Status)),
+		zap.String("error_code", string(code)))
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"TransactionTest/config"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// ReconciliationService сверка сохраненных балансов кошельков с журналом проводок.
+// wallets.balance - проекция журнала, но прямые изменения в БД и данные до появления журнала
+// могут развести их. Сверка находит такие кошельки, а в режиме исправления вносит расхождение
+// в журнал корректирующей записью, не меняя сам баланс.
+type ReconciliationService struct {
+	walletRepo IWalletRepository
+	ledgerRepo ILedgerRepository
+	cfg        config.TransferConfig
+	log        logger.Logger
+}
+
+func NewReconciliationService(wr IWalletRepository, lr ILedgerRepository, cfg config.TransferConfig, l logger.Logger) *ReconciliationService {
+	return &ReconciliationService{
+		walletRepo: wr,
+		ledgerRepo: lr,
+		cfg:        cfg,
+		log:        l,
+	}
+}
+
+// Reconcile сверяет все кошельки и возвращает отчет о расхождениях.
+// С repair для каждого расхождения записывается корректирующая запись; кошелек, который не удалось
+// исправить, остается в отчете с Repaired = false, сверка остальных продолжается.
+func (rs *ReconciliationService) Reconcile(ctx context.Context, repair bool) (*domain.ReconciliationReport, domain.ErrorCode) {
+	report := &domain.ReconciliationReport{StartedAt: time.Now().UTC(), Repair: repair}
+
+	checks, err := rs.ledgerRepo.GetBalanceChecks(ctx)
+	if err != nil {
+		rs.log.Error(ctx, "Reconcile: failed to get balance checks", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	report.Checked = len(checks)
+
+	for _, check := range checks {
+		if check.Drift() == 0 {
+			continue
+		}
+		rs.log.Warn(ctx, "Reconcile: balance drift",
+			zap.String("address", check.Address),
+			zap.Stringer("stored", check.Stored),
+			zap.Stringer("expected", check.Expected),
+			zap.Stringer("drift", check.Drift()))
+
+		drift := domain.WalletDrift{BalanceCheck: check}
+		if repair {
+			var repaired *domain.BalanceCheck
+			code := withRetry(ctx, rs.log, rs.cfg, "Reconcile", func() domain.ErrorCode {
+				var code domain.ErrorCode
+				repaired, code = rs.repairTx(ctx, check.Address)
+				return code
+			})
+			if code == domain.CodeOK && repaired != nil {
+				drift.BalanceCheck = *repaired
+				drift.Repaired = true
+			}
+		}
+		report.Drifts = append(report.Drifts, drift)
+	}
+
+	report.FinishedAt = time.Now().UTC()
+	rs.log.Info(ctx, "Reconcile: finished",
+		zap.Int("checked", report.Checked),
+		zap.Int("drifts", len(report.Drifts)),
+		zap.Int("repaired", report.Repaired()),
+		zap.Bool("repair", repair))
+	return report, domain.CodeOK
+}
+
+// repairTx перепроверяет расхождение под блокировкой кошелька и вносит его в журнал.
+// Возвращает исправленное расхождение либо nil, если оно исчезло до блокировки.
+func (rs *ReconciliationService) repairTx(ctx context.Context, address string) (*domain.BalanceCheck, domain.ErrorCode) {
+	tx, err := rs.walletRepo.BeginTX(ctx)
+	if err != nil {
+		rs.log.Error(ctx, "Reconcile: failed to begin transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	check, err := rs.ledgerRepo.GetBalanceCheckForUpdateTx(ctx, tx, address)
+	if err == nil && check.Drift() != 0 {
+		_, err = rs.ledgerRepo.RecordEntryTx(ctx, tx, check.CorrectionEntry())
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			rs.log.Warn(ctx, "Reconcile: wallet removed before repair", zap.String("address", address))
+			return nil, domain.CodeWalletNotFound
+		case errors.Is(err, domain.ErrTransactionConflict):
+			rs.log.Warn(ctx, "Reconcile: conflict on repair", zap.String("address", address), zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		default:
+			rs.log.Error(ctx, "Reconcile: failed to repair", zap.String("address", address), zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+	if check.Drift() == 0 {
+		return nil, domain.CodeOK
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			rs.log.Warn(ctx, "Reconcile: conflict on commit", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		}
+		rs.log.Error(ctx, "Reconcile: failed to commit transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed = true
+
+	rs.log.Info(ctx, "Reconcile: drift repaired",
+		zap.String("address", address), zap.Stringer("drift", check.Drift()))
+	return check, domain.CodeOK
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"TransactionTest/config"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// withRetry повторяет attempt, пока тот возвращает CodeTransactionConflict и не исчерпан бюджет повторов.
+// Пауза между попытками растет линейно и прерывается отменой контекста.
+func withRetry(ctx context.Context, log logger.Logger, cfg config.TransferConfig, operation string, attempt func() domain.ErrorCode) domain.ErrorCode {
+	code := attempt()
+	for retry := 1; code == domain.CodeTransactionConflict && retry <= cfg.MaxRetries; retry++ {
+		log.Warn(ctx, operation+": retrying after transaction conflict", zap.Int("retry", retry))
+		select {
+		case <-time.After(cfg.RetryDelay * time.Duration(retry)):
+		case <-ctx.Done():
+			log.Warn(ctx, operation+": retry canceled", zap.Error(ctx.Err()))
+			return code
+		}
+		code = attempt()
+	}
+	return code
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"TransactionTest/config"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// Параметры выполнения отложенных переводов, если они не заданы в конфигурации
+const (
+	defaultScheduledBatchSize = 100
+	defaultScheduledLease     = 5 * time.Minute
+)
+
+// ScheduledTransferService отложенные переводы: хранит их до наступления ExecuteAt,
+// после чего фоновая задача выполняет их через TransferSender и записывает результат.
+type ScheduledTransferService struct {
+	scheduledRepo IScheduledTransferRepository
+	sender        TransferSender
+	cfg           config.TransferConfig
+	log           logger.Logger
+}
+
+func NewScheduledTransferService(sr IScheduledTransferRepository, sender TransferSender, cfg config.TransferConfig, l logger.Logger) *ScheduledTransferService {
+	if cfg.ScheduledBatchSize <= 0 {
+		cfg.ScheduledBatchSize = defaultScheduledBatchSize
+	}
+	if cfg.ScheduledLease <= 0 {
+		cfg.ScheduledLease = defaultScheduledLease
+	}
+	return &ScheduledTransferService{
+		scheduledRepo: sr,
+		sender:        sender,
+		cfg:           cfg,
+		log:           l,
+	}
+}
+
+// ScheduleTransfer сохраняет перевод, который будет выполнен не раньше req.ExecuteAt.
+// Баланс и валюты проверяются при выполнении, а не при создании.
+func (ss *ScheduledTransferService) ScheduleTransfer(ctx context.Context, req domain.ScheduledTransferRequest) (*domain.ScheduledTransfer, domain.ErrorCode) {
+	if req.From == req.To {
+		ss.log.Warn(ctx, "ScheduleTransfer: self transfer not allowed")
+		return nil, domain.CodeInvalidTransaction
+	}
+	if req.Amount <= 0 {
+		ss.log.Warn(ctx, "ScheduleTransfer: amount must be positive")
+		return nil, domain.CodeNegativeAmount
+	}
+	if err := req.Amount.Check(); err != nil {
+		ss.log.Warn(ctx, "ScheduleTransfer: amount out of range", zap.Stringer("amount", req.Amount))
+		return nil, domain.CodeAmountOverflow
+	}
+	if !req.ExecuteAt.After(time.Now()) {
+		ss.log.Warn(ctx, "ScheduleTransfer: execute_at must be in the future", zap.Time("execute_at", req.ExecuteAt))
+		return nil, domain.CodeInvalidExecuteAt
+	}
+
+	scheduled, err := ss.scheduledRepo.CreateScheduledTransfer(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			ss.log.Warn(ctx, "ScheduleTransfer: wallet not found", zap.Error(err))
+			return nil, domain.CodeWalletNotFound
+		case errors.Is(err, domain.ErrAmountOverflow):
+			ss.log.Warn(ctx, "ScheduleTransfer: amount out of range", zap.Error(err))
+			return nil, domain.CodeAmountOverflow
+		default:
+			ss.log.Error(ctx, "ScheduleTransfer: failed to create scheduled transfer", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+
+	ss.log.Info(ctx, "ScheduleTransfer: transfer scheduled",
+		zap.Int64("scheduled_id", scheduled.Id),
+		zap.String("from", req.From),
+		zap.String("to", req.To),
+		zap.Stringer("amount", req.Amount),
+		zap.Time("execute_at", req.ExecuteAt))
+	return scheduled, domain.CodeOK
+}
+
+// GetScheduledTransfers возвращает отложенные переводы в состоянии status (пусто - в любом)
+func (ss *ScheduledTransferService) GetScheduledTransfers(ctx context.Context, status domain.ScheduledStatus, limit int) ([]domain.ScheduledTransfer, domain.ErrorCode) {
+	if limit <= 0 {
+		ss.log.Warn(ctx, "GetScheduledTransfers: limit must be greater than zero")
+		return nil, domain.CodeInvalidLimit
+	}
+
+	transfers, err := ss.scheduledRepo.GetScheduledTransfers(ctx, status, limit)
+	if err != nil {
+		ss.log.Error(ctx, "GetScheduledTransfers", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	ss.log.Info(ctx, "GetScheduledTransfers: success get scheduled transfers", zap.Int("limit", limit))
+	return transfers, domain.CodeOK
+}
+
+// CancelScheduledTransfer отменяет перевод, который еще не начал выполняться
+func (ss *ScheduledTransferService) CancelScheduledTransfer(ctx context.Context, id int64) domain.ErrorCode {
+	if err := ss.scheduledRepo.CancelScheduledTransfer(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			ss.log.Warn(ctx, "CancelScheduledTransfer: scheduled transfer not found", zap.Int64("id", id))
+			return domain.CodeScheduledNotFound
+		case errors.Is(err, domain.ErrScheduledNotPending):
+			ss.log.Warn(ctx, "CancelScheduledTransfer: scheduled transfer is not pending", zap.Int64("id", id))
+			return domain.CodeScheduledNotPending
+		default:
+			ss.log.Error(ctx, "CancelScheduledTransfer", zap.Error(err))
+			return domain.CodeInternal
+		}
+	}
+	ss.log.Info(ctx, "CancelScheduledTransfer: scheduled transfer canceled", zap.Int64("id", id))
+	return domain.CodeOK
+}
+
+// ExecuteDue выполняет наступившие переводы, не больше transfers.ScheduledBatchSize за вызов.
+// Между переводами проверяет ctx: при остановке сервера текущий перевод доводится до конца,
+// а следующие остаются в очереди. Возвращает число выполненных (успешно или нет) переводов.
+func (ss *ScheduledTransferService) ExecuteDue(ctx context.Context) (int, domain.ErrorCode) {
+	executed := 0
+	for executed < ss.cfg.ScheduledBatchSize && ctx.Err() == nil {
+		scheduled, err := ss.scheduledRepo.ClaimDueTransfer(ctx, ss.cfg.ScheduledLease)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				break
+			}
+			ss.log.Error(ctx, "ExecuteDue: failed to claim scheduled transfer", zap.Error(err))
+			return executed, domain.CodeInternal
+		}
+
+		// Забранный перевод выполняется без отмены, иначе остановка сервера записала бы его как неудачный
+		ss.execute(context.WithoutCancel(ctx), scheduled)
+		executed++
+	}
+
+	if executed > 0 {
+		ss.log.Info(ctx, "ExecuteDue: success", zap.Int("executed", executed))
+	}
+	return executed, domain.CodeOK
+}
+
+// execute выполняет один перевод и записывает результат.
+// Если результат записать не удалось, перевод останется в running и после ScheduledLease
+// будет выполнен повторно: ключ идемпотентности вернет уже созданную транзакцию.
+func (ss *ScheduledTransferService) execute(ctx context.Context, scheduled *domain.ScheduledTransfer) {
+	status := domain.ScheduledSucceeded
+	var transactionId int64
+
+	result, code := ss.sender.SendMoney(ctx, scheduled.TransferRequest())
+	if code != domain.CodeOK {
+		status = domain.ScheduledFailed
+		ss.log.Warn(ctx, "ExecuteDue: scheduled transfer failed",
+			zap.Int64("scheduled_id", scheduled.Id),
+			zap.String("error_code", string(code)))
+	} else {
+		transactionId = result.TransactionId
+	}
+
+	if err := ss.scheduledRepo.FinishScheduledTransfer(ctx, scheduled.Id, status, transactionId, code); err != nil {
+		ss.log.Error(ctx, "ExecuteDue: failed to record scheduled transfer result",
+			zap.Int64("scheduled_id", scheduled.Id), zap.Error(err))
+		return
+	}
+
+	ss.log.Info(ctx, "ExecuteDue: scheduled transfer executed",
+		zap.Int64("scheduled_id", scheduled.Id),
+		zap.String("status", string(status)),
+		zap.Int64("transaction_id", transactionId))
+}
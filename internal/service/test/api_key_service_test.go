@@ -0,0 +1,99 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+	"TransactionTest/internal/service"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func newKS(repo service.IAPIKeyRepository) *service.APIKeyService {
+	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(zap.FatalLevel)
+	log, _ := logger.New(&cfg)
+	return service.NewAPIKeyService(repo, log)
+}
+
+func TestAPIKeyService_CreateAPIKey_StoresHash(t *testing.T) {
+	var storedHash string
+	var storedScopes []domain.Scope
+	repo := &MockAPIKeyRepository{
+		CreateAPIKeyFunc: func(ctx context.Context, name, keyHash string, scopes []domain.Scope) (*domain.APIKey, error) {
+			storedHash, storedScopes = keyHash, scopes
+			return &domain.APIKey{Id: 2, Name: name, Scopes: scopes}, nil
+		},
+	}
+	ks := newKS(repo)
+
+	key, plain, code := ks.CreateAPIKey(context.Background(), "billing", []string{"read", "transfer"})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(2), key.Id)
+	assert.NotEmpty(t, plain)
+	assert.NotEqual(t, plain, storedHash)
+	assert.Equal(t, domain.HashAPIKey(plain), storedHash)
+	assert.Equal(t, []domain.Scope{domain.ScopeRead, domain.ScopeTransfer}, storedScopes)
+}
+
+func TestAPIKeyService_CreateAPIKey_Validation(t *testing.T) {
+	ks := newKS(&MockAPIKeyRepository{})
+
+	_, _, code := ks.CreateAPIKey(context.Background(), " ", []string{"read"})
+	assert.Equal(t, domain.CodeInvalidRequestBody, code)
+
+	_, _, code = ks.CreateAPIKey(context.Background(), "billing", []string{"superuser"})
+	assert.Equal(t, domain.CodeInvalidScope, code)
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	revokedAt := time.Now()
+	keys := map[string]*domain.APIKey{
+		domain.HashAPIKey("tk_active"):  {Id: 1, Scopes: []domain.Scope{domain.ScopeRead}},
+		domain.HashAPIKey("tk_revoked"): {Id: 2, Scopes: []domain.Scope{domain.ScopeAdmin}, RevokedAt: &revokedAt},
+	}
+	repo := &MockAPIKeyRepository{
+		GetAPIKeyByHashFunc: func(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+			if key, ok := keys[keyHash]; ok {
+				return key, nil
+			}
+			return nil, domain.ErrNotFound
+		},
+	}
+	ks := newKS(repo)
+
+	key, code := ks.Authenticate(context.Background(), "tk_active")
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(1), key.Id)
+
+	_, code = ks.Authenticate(context.Background(), "tk_revoked")
+	assert.Equal(t, domain.CodeUnauthorized, code)
+
+	_, code = ks.Authenticate(context.Background(), "tk_unknown")
+	assert.Equal(t, domain.CodeUnauthorized, code)
+
+	_, code = ks.Authenticate(context.Background(), "")
+	assert.Equal(t, domain.CodeUnauthorized, code)
+}
+
+func TestAPIKeyService_Authenticate_RepoError(t *testing.T) {
+	repo := &MockAPIKeyRepository{
+		GetAPIKeyByHashFunc: func(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+			return nil, errors.New("db down")
+		},
+	}
+	_, code := newKS(repo).Authenticate(context.Background(), "tk_active")
+	assert.Equal(t, domain.CodeInternal, code)
+}
+
+func TestAPIKeyService_RevokeAPIKey_NotFound(t *testing.T) {
+	repo := &MockAPIKeyRepository{
+		RevokeAPIKeyFunc: func(ctx context.Context, id int64) error {
+			return domain.ErrNotFound
+		},
+	}
+	assert.Equal(t, domain.CodeAPIKeyNotFound, newKS(repo).RevokeAPIKey(context.Background(), 9))
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+	"TransactionTest/internal/service"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func newHealthService(repo service.ISchemaRepository, latest uint) *service.HealthService {
+	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(zap.FatalLevel)
+	log, _ := logger.New(&cfg)
+	return service.NewHealthService(repo, latest, log)
+}
+
+func healthySchemaRepo(version uint, dirty bool) *MockSchemaRepository {
+	return &MockSchemaRepository{
+		PingFunc: func(ctx context.Context) error { return nil },
+		MigrationVersionFunc: func(ctx context.Context) (uint, bool, error) {
+			return version, dirty, nil
+		},
+	}
+}
+
+func checkByName(r domain.Readiness, name string) domain.HealthCheck {
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	return domain.HealthCheck{}
+}
+
+func TestHealthService_Readiness_Ready(t *testing.T) {
+	hs := newHealthService(healthySchemaRepo(21, false), 21)
+	hs.SetSeedingStatus(domain.SeedingAlreadySeeded)
+
+	r := hs.Readiness(context.Background())
+	assert.True(t, r.Ready)
+	assert.Len(t, r.Checks, 3)
+	assert.Equal(t, "version 21", checkByName(r, domain.HealthCheckMigrations).Detail)
+	assert.Equal(t, "already_seeded", checkByName(r, domain.HealthCheckSeeding).Detail)
+}
+
+func TestHealthService_Readiness_SeedingPending(t *testing.T) {
+	hs := newHealthService(healthySchemaRepo(21, false), 21)
+
+	r := hs.Readiness(context.Background())
+	assert.False(t, r.Ready)
+	assert.False(t, checkByName(r, domain.HealthCheckSeeding).Healthy)
+	assert.True(t, checkByName(r, domain.HealthCheckDatabase).Healthy)
+}
+
+func TestHealthService_Readiness_MigrationBehind(t *testing.T) {
+	hs := newHealthService(healthySchemaRepo(20, false), 21)
+	hs.SetSeedingStatus(domain.SeedingSeeded)
+
+	r := hs.Readiness(context.Background())
+	assert.False(t, r.Ready)
+	assert.Equal(t, "database version 20, expected 21", checkByName(r, domain.HealthCheckMigrations).Detail)
+}
+
+func TestHealthService_Readiness_Dirty(t *testing.T) {
+	hs := newHealthService(healthySchemaRepo(21, true), 21)
+	hs.SetSeedingStatus(domain.SeedingSeeded)
+
+	r := hs.Readiness(context.Background())
+	assert.False(t, r.Ready)
+	assert.False(t, checkByName(r, domain.HealthCheckMigrations).Healthy)
+}
+
+func TestHealthService_Readiness_DatabaseDown(t *testing.T) {
+	repo := &MockSchemaRepository{
+		PingFunc: func(ctx context.Context) error { return errors.New("connection refused") },
+		MigrationVersionFunc: func(ctx context.Context) (uint, bool, error) {
+			return 0, false, errors.New("connection refused")
+		},
+	}
+	hs := newHealthService(repo, 21)
+	hs.SetSeedingStatus(domain.SeedingSeeded)
+
+	r := hs.Readiness(context.Background())
+	assert.False(t, r.Ready)
+	assert.Equal(t, "connection refused", checkByName(r, domain.HealthCheckDatabase).Detail)
+}
+
+func TestHealthService_Readiness_ShuttingDown(t *testing.T) {
+	pinged := false
+	repo := healthySchemaRepo(21, false)
+	repo.PingFunc = func(ctx context.Context) error {
+		pinged = true
+		return nil
+	}
+	hs := newHealthService(repo, 21)
+	hs.SetSeedingStatus(domain.SeedingSeeded)
+	hs.MarkShuttingDown()
+
+	r := hs.Readiness(context.Background())
+	assert.False(t, r.Ready)
+	assert.Equal(t, []domain.HealthCheck{{Name: domain.HealthCheckShutdown, Detail: "server is shutting down"}}, r.Checks)
+	assert.False(t, pinged)
+}
@@ -0,0 +1,270 @@
+package test
+
+import (
+	"TransactionTest/config"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+	"TransactionTest/internal/service"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func newHS(hr *MockHoldRepository, wr *MockWalletRepository, tr *MockTransactionRepository, lr *MockLedgerRepository) *service.HoldService {
+	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(zap.FatalLevel)
+	log, _ := logger.New(&cfg)
+	return service.NewHoldService(hr, wr, tr, lr, testCurrencies(), domain.LimitPolicy{}, config.TransferConfig{MaxRetries: 3, HoldMaxTTL: time.Hour}, log)
+}
+
+// newHoldWalletRepo кошельки в USD с балансом 100 и суммой активных резервов held
+func newHoldWalletRepo(held map[string]domain.Money) *MockWalletRepository {
+	return &MockWalletRepository{
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: domain.MustParseMoney("100"), Held: held[address]}, nil
+		},
+	}
+}
+
+func activeHold(amount string) *domain.Hold {
+	return &domain.Hold{Id: 7, From: "from", To: "to", Amount: domain.MustParseMoney(amount), Currency: "USD", Status: domain.HoldActive}
+}
+
+func TestHoldService_CreateHold_Success(t *testing.T) {
+	var gotTTL time.Duration
+	hr := &MockHoldRepository{
+		CreateHoldTxFunc: func(ctx context.Context, tx domain.TxExecutor, from, to, currency string, amount domain.Money, ttl time.Duration) (*domain.Hold, error) {
+			gotTTL = ttl
+			return &domain.Hold{Id: 7, From: from, To: to, Amount: amount, Currency: currency, Status: domain.HoldActive}, nil
+		},
+	}
+	hs := newHS(hr, newHoldWalletRepo(nil), &MockTransactionRepository{}, &MockLedgerRepository{})
+
+	hold, code := hs.CreateHold(context.Background(), domain.HoldRequest{From: "from", To: "to", Amount: domain.MustParseMoney("25")})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(7), hold.Id)
+	assert.Equal(t, "USD", hold.Currency)
+	assert.Equal(t, 15*time.Minute, gotTTL)
+}
+
+func TestHoldService_CreateHold_ValidationErrors(t *testing.T) {
+	hs := newHS(&MockHoldRepository{}, newHoldWalletRepo(nil), &MockTransactionRepository{}, &MockLedgerRepository{})
+	ctx := context.Background()
+
+	_, code := hs.CreateHold(ctx, domain.HoldRequest{From: "a", To: "a", Amount: 10})
+	assert.Equal(t, domain.CodeInvalidTransaction, code)
+
+	_, code = hs.CreateHold(ctx, domain.HoldRequest{From: "a", To: "b", Amount: 0})
+	assert.Equal(t, domain.CodeNegativeAmount, code)
+
+	_, code = hs.CreateHold(ctx, domain.HoldRequest{From: "a", To: "b", Amount: 10, TTL: 2 * time.Hour})
+	assert.Equal(t, domain.CodeInvalidHoldTTL, code)
+}
+
+func TestHoldService_CreateHold_HeldReducesAvailable(t *testing.T) {
+	hs := newHS(&MockHoldRepository{}, newHoldWalletRepo(map[string]domain.Money{"from": domain.MustParseMoney("80")}), &MockTransactionRepository{}, &MockLedgerRepository{})
+
+	// Баланс 100, из них 80 уже зарезервировано
+	_, code := hs.CreateHold(context.Background(), domain.HoldRequest{From: "from", To: "to", Amount: domain.MustParseMoney("25")})
+	assert.Equal(t, domain.CodeInsufficientFunds, code)
+}
+
+func TestHoldService_CreateHold_CurrencyMismatch(t *testing.T) {
+	hs := newHS(&MockHoldRepository{}, newCurrencyWalletRepo(map[string]string{"from": "USD", "to": "EUR"}), &MockTransactionRepository{}, &MockLedgerRepository{})
+
+	_, code := hs.CreateHold(context.Background(), domain.HoldRequest{From: "from", To: "to", Amount: 10})
+	assert.Equal(t, domain.CodeCurrencyMismatch, code)
+}
+
+func TestHoldService_GetHold_NotFound(t *testing.T) {
+	hr := &MockHoldRepository{
+		GetHoldFunc: func(ctx context.Context, id int64) (*domain.Hold, error) {
+			return nil, domain.ErrNotFound
+		},
+	}
+	hs := newHS(hr, &MockWalletRepository{}, &MockTransactionRepository{}, &MockLedgerRepository{})
+
+	hold, code := hs.GetHold(context.Background(), 7)
+	assert.Equal(t, domain.CodeHoldNotFound, code)
+	assert.Nil(t, hold)
+}
+
+func TestHoldService_CaptureHold_Full(t *testing.T) {
+	var captured domain.Money
+	var posted domain.JournalEntry
+	hr := &MockHoldRepository{
+		GetHoldForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Hold, error) {
+			return activeHold("25"), nil
+		},
+		CloseHoldTxFunc: func(ctx context.Context, tx domain.TxExecutor, id int64, status domain.HoldStatus, capturedAmount domain.Money, transactionId int64) error {
+			assert.Equal(t, domain.HoldCaptured, status)
+			assert.Equal(t, int64(3), transactionId)
+			captured = capturedAmount
+			return nil
+		},
+	}
+	tr := newBeginTx()
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		return 3, nil
+	}
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			posted = entry
+			return 1, nil
+		},
+	}
+	// Весь баланс отправителя зарезервирован, но захват самого резерва проходит
+	hs := newHS(hr, newHoldWalletRepo(map[string]domain.Money{"from": domain.MustParseMoney("100")}), tr, lr)
+
+	res, code := hs.CaptureHold(context.Background(), 7, 0)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(3), res.TransactionId)
+	assert.Equal(t, domain.MustParseMoney("25"), captured)
+	assert.Equal(t, []domain.Posting{
+		{Account: "from", Currency: "USD", Amount: domain.MustParseMoney("-25")},
+		{Account: "to", Currency: "USD", Amount: domain.MustParseMoney("25")},
+	}, posted.Postings)
+}
+
+func TestHoldService_CaptureHold_Partial(t *testing.T) {
+	var captured domain.Money
+	hr := &MockHoldRepository{
+		GetHoldForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Hold, error) {
+			return activeHold("25"), nil
+		},
+		CloseHoldTxFunc: func(ctx context.Context, tx domain.TxExecutor, id int64, status domain.HoldStatus, capturedAmount domain.Money, transactionId int64) error {
+			captured = capturedAmount
+			return nil
+		},
+	}
+	hs := newHS(hr, newHoldWalletRepo(nil), newBeginTx(), &MockLedgerRepository{})
+
+	_, code := hs.CaptureHold(context.Background(), 7, domain.MustParseMoney("10"))
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, domain.MustParseMoney("10"), captured)
+}
+
+func TestHoldService_CaptureHold_ExceedsHold(t *testing.T) {
+	hr := &MockHoldRepository{
+		GetHoldForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Hold, error) {
+			return activeHold("25"), nil
+		},
+	}
+	tr := newBeginTx()
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		t.Fatal("capture above the hold must not create a transaction")
+		return 0, nil
+	}
+	hs := newHS(hr, newHoldWalletRepo(nil), tr, &MockLedgerRepository{})
+
+	_, code := hs.CaptureHold(context.Background(), 7, domain.MustParseMoney("30"))
+	assert.Equal(t, domain.CodeCaptureExceedsHold, code)
+}
+
+func TestHoldService_CaptureHold_NotActive(t *testing.T) {
+	hr := &MockHoldRepository{
+		GetHoldForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Hold, error) {
+			hold := activeHold("25")
+			hold.Status = domain.HoldExpired
+			return hold, nil
+		},
+	}
+	hs := newHS(hr, newHoldWalletRepo(nil), newBeginTx(), &MockLedgerRepository{})
+
+	_, code := hs.CaptureHold(context.Background(), 7, 0)
+	assert.Equal(t, domain.CodeHoldNotActive, code)
+}
+
+func TestHoldService_CaptureHold_ExpiredDuringCapture(t *testing.T) {
+	hr := &MockHoldRepository{
+		GetHoldForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Hold, error) {
+			return activeHold("25"), nil
+		},
+		CloseHoldTxFunc: func(ctx context.Context, tx domain.TxExecutor, id int64, status domain.HoldStatus, capturedAmount domain.Money, transactionId int64) error {
+			return domain.ErrHoldNotActive
+		},
+	}
+	hs := newHS(hr, newHoldWalletRepo(nil), newBeginTx(), &MockLedgerRepository{})
+
+	_, code := hs.CaptureHold(context.Background(), 7, 0)
+	assert.Equal(t, domain.CodeHoldNotActive, code)
+}
+
+func TestHoldService_CaptureHold_LimitExceeded(t *testing.T) {
+	m := domain.MustParseMoney
+	hr := &MockHoldRepository{
+		GetHoldForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Hold, error) {
+			return activeHold("25"), nil
+		},
+	}
+	tr := newBeginTx()
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		t.Fatal("capture over the limit must not create a transaction")
+		return 0, nil
+	}
+	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(zap.FatalLevel)
+	log, _ := logger.New(&cfg)
+	hs := service.NewHoldService(hr, newHoldWalletRepo(nil), tr, &MockLedgerRepository{}, testCurrencies(),
+		domain.LimitPolicy{Defaults: domain.WalletLimits{MaxTransfer: m("20")}}, config.TransferConfig{MaxRetries: 3, HoldMaxTTL: time.Hour}, log)
+
+	res, code := hs.CaptureHold(context.Background(), 7, 0)
+	assert.Equal(t, domain.CodeLimitExceeded, code)
+	assert.Equal(t, domain.LimitMaxTransfer, res.ExceededLimit)
+
+	// Частичный захват в пределах лимита проходит
+	hr.CloseHoldTxFunc = func(ctx context.Context, tx domain.TxExecutor, id int64, status domain.HoldStatus, capturedAmount domain.Money, transactionId int64) error {
+		return nil
+	}
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		return 3, nil
+	}
+	_, code = hs.CaptureHold(context.Background(), 7, m("20"))
+	assert.Equal(t, domain.CodeOK, code)
+}
+
+func TestHoldService_VoidHold_Success(t *testing.T) {
+	var status domain.HoldStatus
+	hr := &MockHoldRepository{
+		GetHoldForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Hold, error) {
+			return activeHold("25"), nil
+		},
+		CloseHoldTxFunc: func(ctx context.Context, tx domain.TxExecutor, id int64, s domain.HoldStatus, capturedAmount domain.Money, transactionId int64) error {
+			status = s
+			return nil
+		},
+	}
+	hs := newHS(hr, &MockWalletRepository{}, &MockTransactionRepository{}, &MockLedgerRepository{})
+
+	code := hs.VoidHold(context.Background(), 7)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, domain.HoldVoided, status)
+}
+
+func TestHoldService_VoidHold_NotFound(t *testing.T) {
+	hr := &MockHoldRepository{
+		GetHoldForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Hold, error) {
+			return nil, domain.ErrNotFound
+		},
+	}
+	hs := newHS(hr, &MockWalletRepository{}, &MockTransactionRepository{}, &MockLedgerRepository{})
+
+	assert.Equal(t, domain.CodeHoldNotFound, hs.VoidHold(context.Background(), 7))
+}
+
+func TestHoldService_ExpireHolds_InternalError(t *testing.T) {
+	hr := &MockHoldRepository{
+		ExpireHoldsFunc: func(ctx context.Context) (int64, error) {
+			return 0, errors.New("fail")
+		},
+	}
+	hs := newHS(hr, &MockWalletRepository{}, &MockTransactionRepository{}, &MockLedgerRepository{})
+
+	expired, code := hs.ExpireHolds(context.Background())
+	assert.Equal(t, domain.CodeInternal, code)
+	assert.Equal(t, int64(0), expired)
+}
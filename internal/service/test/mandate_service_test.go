@@ -0,0 +1,262 @@
+package test
+
+import (
+	"TransactionTest/config"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+	"TransactionTest/internal/service"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func newMS(repo *MockMandateRepository, sender *MockTransferSender, cfg config.TransferConfig) *service.MandateService {
+	logCfg := zap.NewProductionConfig()
+	logCfg.Level = zap.NewAtomicLevelAt(zap.FatalLevel)
+	log, _ := logger.New(&logCfg)
+	return service.NewMandateService(repo, sender, cfg, log)
+}
+
+// dueMandate наступивший первый запуск ежедневного поручения
+func dueMandate(maxRuns int) domain.Mandate {
+	scheduledAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	return domain.Mandate{
+		Id:          7,
+		From:        "from",
+		To:          "to",
+		Amount:      domain.MustParseMoney("50"),
+		Recurrence:  domain.Recurrence{Frequency: domain.FrequencyDaily, Interval: 1},
+		StartAt:     scheduledAt,
+		MaxRuns:     maxRuns,
+		Status:      domain.MandateActive,
+		Occurrence:  1,
+		Attempt:     1,
+		ScheduledAt: scheduledAt,
+		NextRunAt:   &scheduledAt,
+	}
+}
+
+// newMandateQueue очередь наступивших поручений и запись результатов FinishMandateRun
+func newMandateQueue(due ...domain.Mandate) (*MockMandateRepository, *[]domain.MandateRun, *[]domain.Mandate) {
+	var runs []domain.MandateRun
+	var states []domain.Mandate
+	repo := &MockMandateRepository{
+		ClaimDueMandateFunc: func(ctx context.Context, lease time.Duration) (*domain.Mandate, error) {
+			if len(due) == 0 {
+				return nil, domain.ErrNotFound
+			}
+			next := due[0]
+			due = due[1:]
+			return &next, nil
+		},
+		FinishMandateRunFunc: func(ctx context.Context, run domain.MandateRun, m domain.Mandate) error {
+			runs = append(runs, run)
+			states = append(states, m)
+			return nil
+		},
+	}
+	return repo, &runs, &states
+}
+
+func TestMandateService_CreateMandate_Success(t *testing.T) {
+	var saved domain.Mandate
+	repo := &MockMandateRepository{
+		CreateMandateFunc: func(ctx context.Context, m domain.Mandate) (*domain.Mandate, error) {
+			saved = m
+			m.Id = 5
+			return &m, nil
+		},
+	}
+	ms := newMS(repo, &MockTransferSender{}, config.TransferConfig{})
+
+	startAt := time.Now().Add(24 * time.Hour)
+	mandate, code := ms.CreateMandate(context.Background(), domain.MandateRequest{
+		From: "from", To: "to", Amount: 100,
+		Recurrence: domain.Recurrence{Frequency: domain.FrequencyMonthly, Interval: 1},
+		StartAt:    startAt,
+		MaxRuns:    12,
+	})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(5), mandate.Id)
+	assert.Equal(t, domain.MandateActive, saved.Status)
+	assert.True(t, startAt.Equal(saved.ScheduledAt))
+	assert.Equal(t, 12, saved.MaxRuns)
+}
+
+func TestMandateService_CreateMandate_ValidationErrors(t *testing.T) {
+	repo := &MockMandateRepository{
+		CreateMandateFunc: func(ctx context.Context, m domain.Mandate) (*domain.Mandate, error) {
+			return nil, domain.ErrNotFound
+		},
+	}
+	ms := newMS(repo, &MockTransferSender{}, config.TransferConfig{})
+	ctx := context.Background()
+	daily := domain.Recurrence{Frequency: domain.FrequencyDaily, Interval: 1}
+
+	_, code := ms.CreateMandate(ctx, domain.MandateRequest{From: "a", To: "a", Amount: 10, Recurrence: daily})
+	assert.Equal(t, domain.CodeInvalidTransaction, code)
+
+	_, code = ms.CreateMandate(ctx, domain.MandateRequest{From: "a", To: "b", Amount: 0, Recurrence: daily})
+	assert.Equal(t, domain.CodeNegativeAmount, code)
+
+	_, code = ms.CreateMandate(ctx, domain.MandateRequest{From: "a", To: "b", Amount: 10,
+		Recurrence: domain.Recurrence{Frequency: domain.FrequencyCron, Cron: "0 25 * * *"}})
+	assert.Equal(t, domain.CodeInvalidRecurrence, code)
+
+	past := time.Now().Add(-time.Hour)
+	_, code = ms.CreateMandate(ctx, domain.MandateRequest{From: "a", To: "b", Amount: 10, Recurrence: daily, EndAt: &past})
+	assert.Equal(t, domain.CodeInvalidRecurrence, code)
+
+	_, code = ms.CreateMandate(ctx, domain.MandateRequest{From: "a", To: "b", Amount: 10, Recurrence: daily})
+	assert.Equal(t, domain.CodeWalletNotFound, code)
+}
+
+func TestMandateService_CancelMandate(t *testing.T) {
+	repo := &MockMandateRepository{
+		CancelMandateFunc: func(ctx context.Context, id int64) error {
+			switch id {
+			case 1:
+				return nil
+			case 2:
+				return domain.ErrMandateNotActive
+			default:
+				return domain.ErrNotFound
+			}
+		},
+	}
+	ms := newMS(repo, &MockTransferSender{}, config.TransferConfig{})
+	ctx := context.Background()
+
+	assert.Equal(t, domain.CodeOK, ms.CancelMandate(ctx, 1))
+	assert.Equal(t, domain.CodeMandateNotActive, ms.CancelMandate(ctx, 2))
+	assert.Equal(t, domain.CodeMandateNotFound, ms.CancelMandate(ctx, 3))
+}
+
+func TestMandateService_GetMandateRuns(t *testing.T) {
+	repo := &MockMandateRepository{
+		GetMandateFunc: func(ctx context.Context, id int64) (*domain.Mandate, error) {
+			if id != 7 {
+				return nil, domain.ErrNotFound
+			}
+			return &domain.Mandate{Id: 7}, nil
+		},
+		GetMandateRunsFunc: func(ctx context.Context, mandateId int64, limit int) ([]domain.MandateRun, error) {
+			return []domain.MandateRun{{Id: 2, MandateId: mandateId}, {Id: 1, MandateId: mandateId}}, nil
+		},
+	}
+	ms := newMS(repo, &MockTransferSender{}, config.TransferConfig{})
+	ctx := context.Background()
+
+	runs, code := ms.GetMandateRuns(ctx, 7, 10)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Len(t, runs, 2)
+
+	_, code = ms.GetMandateRuns(ctx, 8, 10)
+	assert.Equal(t, domain.CodeMandateNotFound, code)
+
+	_, code = ms.GetMandateRuns(ctx, 7, 0)
+	assert.Equal(t, domain.CodeInvalidLimit, code)
+}
+
+func TestMandateService_ExecuteDue_SuccessAdvances(t *testing.T) {
+	mandate := dueMandate(0)
+	repo, runs, states := newMandateQueue(mandate)
+	var gotReq domain.TransferRequest
+	sender := &MockTransferSender{
+		SendMoneyFunc: func(ctx context.Context, req domain.TransferRequest) (*domain.TransferResult, domain.ErrorCode) {
+			gotReq = req
+			return &domain.TransferResult{TransactionId: 42}, domain.CodeOK
+		},
+	}
+	ms := newMS(repo, sender, config.TransferConfig{})
+
+	executed, code := ms.ExecuteDue(context.Background())
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, 1, executed)
+	assert.Equal(t, "mandate:7:1", gotReq.IdempotencyKey)
+	assert.Equal(t, mandate.Amount, gotReq.Amount)
+
+	assert.Len(t, *runs, 1)
+	run := (*runs)[0]
+	assert.Equal(t, domain.MandateRunSucceeded, run.Status)
+	assert.Equal(t, int64(42), run.TransactionId)
+	assert.Equal(t, 1, run.Occurrence)
+
+	state := (*states)[0]
+	assert.Equal(t, domain.MandateActive, state.Status)
+	assert.Equal(t, 1, state.RunsCount)
+	assert.Equal(t, 2, state.Occurrence)
+	assert.Equal(t, mandate.ScheduledAt.Add(24*time.Hour), *state.NextRunAt)
+}
+
+func TestMandateService_ExecuteDue_RetryableFailure(t *testing.T) {
+	repo, runs, states := newMandateQueue(dueMandate(0))
+	sender := &MockTransferSender{
+		SendMoneyFunc: func(ctx context.Context, req domain.TransferRequest) (*domain.TransferResult, domain.ErrorCode) {
+			return nil, domain.CodeInsufficientFunds
+		},
+	}
+	ms := newMS(repo, sender, config.TransferConfig{MandateMaxAttempts: 3, MandateRetryDelay: time.Hour})
+
+	before := time.Now()
+	_, code := ms.ExecuteDue(context.Background())
+	assert.Equal(t, domain.CodeOK, code)
+
+	run := (*runs)[0]
+	assert.Equal(t, domain.MandateRunRetrying, run.Status)
+	assert.Equal(t, domain.CodeInsufficientFunds, run.FailureCode)
+
+	state := (*states)[0]
+	assert.Equal(t, domain.MandateActive, state.Status)
+	assert.Equal(t, 0, state.RunsCount)
+	assert.Equal(t, 1, state.Occurrence)
+	assert.Equal(t, 2, state.Attempt)
+	assert.False(t, state.NextRunAt.Before(before.Add(time.Hour)))
+}
+
+func TestMandateService_ExecuteDue_AttemptsExhausted(t *testing.T) {
+	last := dueMandate(1)
+	last.Attempt = 3
+	permanent := dueMandate(0)
+	permanent.Id = 8
+	repo, runs, states := newMandateQueue(last, permanent)
+	sender := &MockTransferSender{
+		SendMoneyFunc: func(ctx context.Context, req domain.TransferRequest) (*domain.TransferResult, domain.ErrorCode) {
+			if req.IdempotencyKey == "mandate:7:1" {
+				return nil, domain.CodeInsufficientFunds
+			}
+			return nil, domain.CodeCurrencyMismatch
+		},
+	}
+	ms := newMS(repo, sender, config.TransferConfig{MandateMaxAttempts: 3})
+
+	executed, _ := ms.ExecuteDue(context.Background())
+	assert.Equal(t, 2, executed)
+
+	// Последняя попытка: запуск пропущен, лимит в один запуск исчерпан
+	assert.Equal(t, domain.MandateRunFailed, (*runs)[0].Status)
+	assert.Equal(t, domain.MandateCompleted, (*states)[0].Status)
+	assert.Nil(t, (*states)[0].NextRunAt)
+
+	// Неисправимая ошибка не повторяется, поручение ждет следующего запуска
+	assert.Equal(t, domain.MandateRunFailed, (*runs)[1].Status)
+	assert.Equal(t, domain.CodeCurrencyMismatch, (*runs)[1].FailureCode)
+	assert.Equal(t, domain.MandateActive, (*states)[1].Status)
+	assert.Equal(t, 2, (*states)[1].Occurrence)
+	assert.Equal(t, 1, (*states)[1].Attempt)
+}
+
+func TestMandateService_ExecuteDue_StopsOnCanceledContext(t *testing.T) {
+	repo, runs, _ := newMandateQueue(dueMandate(0))
+	ms := newMS(repo, &MockTransferSender{}, config.TransferConfig{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	executed, code := ms.ExecuteDue(ctx)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, 0, executed)
+	assert.Empty(t, *runs)
+}
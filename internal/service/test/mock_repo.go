@@ -8,25 +8,33 @@ import (
 )
 
 type MockWalletRepository struct {
-	BeginTXFunc               func(ctx context.Context) (domain.TxExecutor, error)
-	CreateWalletFunc          func(ctx context.Context, address string, balance float64) error
-	GetWalletBalanceFunc      func(ctx context.Context, address string) (float64, error)
-	GetWalletFunc             func(ctx context.Context, address string) (*domain.Wallet, error)
-	UpdateWalletBalanceFunc   func(ctx context.Context, address string, balance float64) error
-	RemoveWalletFunc          func(ctx context.Context, address string) error
-	CreateWalletTxFunc        func(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error
-	UpdateWalletBalanceTxFunc func(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error
+	BeginTXFunc          func(ctx context.Context) (domain.TxExecutor, error)
+	GetWalletBalanceFunc func(ctx context.Context, address string) (domain.Balance, error)
+	GetWalletFunc        func(ctx context.Context, address string) (*domain.Wallet, error)
+	RemoveWalletFunc     func(ctx context.Context, address string) error
+	CreateWalletTxFunc   func(ctx context.Context, tx domain.TxExecutor, address, currency string, balance domain.Money) error
+
+	GetWalletForUpdateTxFunc func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error)
+
+	ChangeWalletStatusTxFunc   func(ctx context.Context, tx domain.TxExecutor, change domain.WalletStatusChange) (*domain.WalletStatusChange, error)
+	GetWalletStatusChangesFunc func(ctx context.Context, address string, limit int) ([]domain.WalletStatusChange, error)
+
+	GetWalletLimitsFunc   func(ctx context.Context, address string) (domain.LimitOverrides, error)
+	GetWalletLimitsTxFunc func(ctx context.Context, tx domain.TxExecutor, address string) (domain.LimitOverrides, error)
+	SetWalletLimitsFunc   func(ctx context.Context, address string, overrides domain.LimitOverrides) error
+
+	SetOverdraftLimitFunc func(ctx context.Context, address string, limit domain.Money) error
+
+	EnsureTreasuryWalletFunc      func(ctx context.Context, address, currency string) error
+	CreateBalanceAdjustmentTxFunc func(ctx context.Context, tx domain.TxExecutor, adjustment domain.BalanceAdjustment) (*domain.BalanceAdjustment, error)
+	GetBalanceAdjustmentsFunc     func(ctx context.Context, address string, limit int) ([]domain.BalanceAdjustment, error)
 }
 
 func (m *MockWalletRepository) BeginTX(ctx context.Context) (domain.TxExecutor, error) {
 	return m.BeginTXFunc(ctx)
 }
 
-func (m *MockWalletRepository) CreateWallet(ctx context.Context, address string, balance float64) error {
-	return m.CreateWalletFunc(ctx, address, balance)
-}
-
-func (m *MockWalletRepository) GetWalletBalance(ctx context.Context, address string) (float64, error) {
+func (m *MockWalletRepository) GetWalletBalance(ctx context.Context, address string) (domain.Balance, error) {
 	return m.GetWalletBalanceFunc(ctx, address)
 }
 
@@ -34,10 +42,6 @@ func (m *MockWalletRepository) GetWallet(ctx context.Context, address string) (*
 	return m.GetWalletFunc(ctx, address)
 }
 
-func (m *MockWalletRepository) UpdateWalletBalance(ctx context.Context, address string, balance float64) error {
-	return m.UpdateWalletBalanceFunc(ctx, address, balance)
-}
-
 func (m *MockWalletRepository) RemoveWallet(ctx context.Context, address string) error {
 	if m.RemoveWalletFunc != nil {
 		return m.RemoveWalletFunc(ctx, address)
@@ -45,22 +49,79 @@ func (m *MockWalletRepository) RemoveWallet(ctx context.Context, address string)
 	return nil
 }
 
-func (m *MockWalletRepository) CreateWalletTx(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error {
-	return m.CreateWalletTxFunc(ctx, tx, address, balance)
+func (m *MockWalletRepository) CreateWalletTx(ctx context.Context, tx domain.TxExecutor, address, currency string, balance domain.Money) error {
+	return m.CreateWalletTxFunc(ctx, tx, address, currency, balance)
+}
+
+func (m *MockWalletRepository) GetWalletForUpdateTx(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+	return m.GetWalletForUpdateTxFunc(ctx, tx, address)
+}
+
+func (m *MockWalletRepository) ChangeWalletStatusTx(ctx context.Context, tx domain.TxExecutor, change domain.WalletStatusChange) (*domain.WalletStatusChange, error) {
+	return m.ChangeWalletStatusTxFunc(ctx, tx, change)
+}
+
+func (m *MockWalletRepository) GetWalletStatusChanges(ctx context.Context, address string, limit int) ([]domain.WalletStatusChange, error) {
+	return m.GetWalletStatusChangesFunc(ctx, address, limit)
+}
+
+func (m *MockWalletRepository) GetWalletLimits(ctx context.Context, address string) (domain.LimitOverrides, error) {
+	return m.GetWalletLimitsFunc(ctx, address)
+}
+
+// GetWalletLimitsTx без заданной функции возвращает кошелек без своих лимитов
+func (m *MockWalletRepository) GetWalletLimitsTx(ctx context.Context, tx domain.TxExecutor, address string) (domain.LimitOverrides, error) {
+	if m.GetWalletLimitsTxFunc != nil {
+		return m.GetWalletLimitsTxFunc(ctx, tx, address)
+	}
+	return domain.LimitOverrides{}, nil
+}
+
+func (m *MockWalletRepository) SetWalletLimits(ctx context.Context, address string, overrides domain.LimitOverrides) error {
+	return m.SetWalletLimitsFunc(ctx, address, overrides)
+}
+
+func (m *MockWalletRepository) SetOverdraftLimit(ctx context.Context, address string, limit domain.Money) error {
+	return m.SetOverdraftLimitFunc(ctx, address, limit)
+}
+
+func (m *MockWalletRepository) EnsureTreasuryWallet(ctx context.Context, address, currency string) error {
+	return m.EnsureTreasuryWalletFunc(ctx, address, currency)
 }
 
-func (m *MockWalletRepository) UpdateWalletBalanceTx(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error {
-	return m.UpdateWalletBalanceTxFunc(ctx, tx, address, balance)
+func (m *MockWalletRepository) CreateBalanceAdjustmentTx(ctx context.Context, tx domain.TxExecutor, adjustment domain.BalanceAdjustment) (*domain.BalanceAdjustment, error) {
+	if m.CreateBalanceAdjustmentTxFunc != nil {
+		return m.CreateBalanceAdjustmentTxFunc(ctx, tx, adjustment)
+	}
+	return &adjustment, nil
+}
+
+func (m *MockWalletRepository) GetBalanceAdjustments(ctx context.Context, address string, limit int) ([]domain.BalanceAdjustment, error) {
+	return m.GetBalanceAdjustmentsFunc(ctx, address, limit)
 }
 
 type MockTransactionRepository struct {
 	BeginTXFunc              func(ctx context.Context) (domain.TxExecutor, error)
-	CreateTransactionFunc    func(ctx context.Context, from, to string, amount float64) (int64, error)
-	CreateTransactionTxFunc  func(ctx context.Context, tx domain.TxExecutor, from, to string, amount float64) (int64, error)
+	CreateTransactionFunc    func(ctx context.Context, from, to string, amount domain.Money) (int64, error)
+	CreateTransactionTxFunc  func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error)
 	GetTransactionByIdFunc   func(ctx context.Context, id int64) (*domain.Transaction, error)
 	GetTransactionByInfoFunc func(ctx context.Context, from, to string, createdAt time.Time) (*domain.Transaction, error)
 	RemoveTransactionFunc    func(ctx context.Context, id int64) error
 	GetLastTransactionsFunc  func(ctx context.Context, limit int) ([]domain.Transaction, error)
+	ListTransactionsFunc     func(ctx context.Context, filter domain.TransactionFilter) (*domain.TransactionPage, error)
+
+	GetTransactionTxFunc          func(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Transaction, error)
+	GetTransactionForUpdateTxFunc func(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Transaction, error)
+	CreateReversalTxFunc          func(ctx context.Context, tx domain.TxExecutor, original *domain.Transaction) (int64, error)
+	CreateExchangeTxFunc          func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, exchange domain.Exchange, details domain.TransferDetails) (int64, error)
+	CreateFundsTxFunc             func(ctx context.Context, tx domain.TxExecutor, kind domain.TransactionKind, from, to string, amount domain.Money, details domain.TransferDetails) (int64, error)
+
+	GetTransactionsByExternalReferenceFunc func(ctx context.Context, reference, from string) ([]domain.Transaction, error)
+	GetOutflowTxFunc                       func(ctx context.Context, tx domain.TxExecutor, address string, window time.Duration) (domain.Outflow, error)
+
+	BeginSnapshotTXFunc     func(ctx context.Context) (domain.TxExecutor, error)
+	GetOpeningBalanceTxFunc func(ctx context.Context, tx domain.TxExecutor, address string, before time.Time) (string, domain.Money, error)
+	GetStatementLinesTxFunc func(ctx context.Context, tx domain.TxExecutor, address string, from, to time.Time) ([]domain.StatementLine, error)
 }
 
 func (m *MockTransactionRepository) BeginTX(ctx context.Context) (domain.TxExecutor, error) {
@@ -70,12 +131,12 @@ func (m *MockTransactionRepository) BeginTX(ctx context.Context) (domain.TxExecu
 	return nil, errors.New("BeginTX not implemented")
 }
 
-func (m *MockTransactionRepository) CreateTransaction(ctx context.Context, from, to string, amount float64) (int64, error) {
+func (m *MockTransactionRepository) CreateTransaction(ctx context.Context, from, to string, amount domain.Money) (int64, error) {
 	return m.CreateTransactionFunc(ctx, from, to, amount)
 }
 
-func (m *MockTransactionRepository) CreateTransactionTx(ctx context.Context, tx domain.TxExecutor, from, to string, amount float64) (int64, error) {
-	return m.CreateTransactionTxFunc(ctx, tx, from, to, amount)
+func (m *MockTransactionRepository) CreateTransactionTx(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+	return m.CreateTransactionTxFunc(ctx, tx, from, to, amount, fee, details)
 }
 
 func (m *MockTransactionRepository) GetTransactionById(ctx context.Context, id int64) (*domain.Transaction, error) {
@@ -94,10 +155,121 @@ func (m *MockTransactionRepository) GetLastTransactions(ctx context.Context, lim
 	return m.GetLastTransactionsFunc(ctx, limit)
 }
 
-type MockTxExecutor struct{}
+func (m *MockTransactionRepository) ListTransactions(ctx context.Context, filter domain.TransactionFilter) (*domain.TransactionPage, error) {
+	return m.ListTransactionsFunc(ctx, filter)
+}
+
+func (m *MockTransactionRepository) BeginSnapshotTX(ctx context.Context) (domain.TxExecutor, error) {
+	if m.BeginSnapshotTXFunc != nil {
+		return m.BeginSnapshotTXFunc(ctx)
+	}
+	return &MockTxExecutor{}, nil
+}
+
+func (m *MockTransactionRepository) GetOpeningBalanceTx(ctx context.Context, tx domain.TxExecutor, address string, before time.Time) (string, domain.Money, error) {
+	return m.GetOpeningBalanceTxFunc(ctx, tx, address, before)
+}
+
+func (m *MockTransactionRepository) GetStatementLinesTx(ctx context.Context, tx domain.TxExecutor, address string, from, to time.Time) ([]domain.StatementLine, error) {
+	return m.GetStatementLinesTxFunc(ctx, tx, address, from, to)
+}
+
+func (m *MockTransactionRepository) GetTransactionTx(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Transaction, error) {
+	return m.GetTransactionTxFunc(ctx, tx, id)
+}
+
+func (m *MockTransactionRepository) GetTransactionForUpdateTx(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Transaction, error) {
+	return m.GetTransactionForUpdateTxFunc(ctx, tx, id)
+}
+
+func (m *MockTransactionRepository) CreateReversalTx(ctx context.Context, tx domain.TxExecutor, original *domain.Transaction) (int64, error) {
+	return m.CreateReversalTxFunc(ctx, tx, original)
+}
+
+func (m *MockTransactionRepository) CreateExchangeTx(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, exchange domain.Exchange, details domain.TransferDetails) (int64, error) {
+	return m.CreateExchangeTxFunc(ctx, tx, from, to, amount, fee, exchange, details)
+}
+
+func (m *MockTransactionRepository) CreateFundsTx(ctx context.Context, tx domain.TxExecutor, kind domain.TransactionKind, from, to string, amount domain.Money, details domain.TransferDetails) (int64, error) {
+	return m.CreateFundsTxFunc(ctx, tx, kind, from, to, amount, details)
+}
+
+func (m *MockTransactionRepository) GetOutflowTx(ctx context.Context, tx domain.TxExecutor, address string, window time.Duration) (domain.Outflow, error) {
+	return m.GetOutflowTxFunc(ctx, tx, address, window)
+}
+
+func (m *MockTransactionRepository) GetTransactionsByExternalReference(ctx context.Context, reference, from string) ([]domain.Transaction, error) {
+	return m.GetTransactionsByExternalReferenceFunc(ctx, reference, from)
+}
+
+type MockLedgerRepository struct {
+	PostEntryTxFunc                func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error)
+	GetAccountPostingsFunc         func(ctx context.Context, account string, limit int) ([]domain.Posting, error)
+	GetBalanceAtFunc               func(ctx context.Context, address string, at time.Time) (domain.Money, error)
+	CreateBalanceSnapshotsFunc     func(ctx context.Context, lag time.Duration) (int64, error)
+	GetBalanceChecksFunc           func(ctx context.Context) ([]domain.BalanceCheck, error)
+	GetBalanceCheckForUpdateTxFunc func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.BalanceCheck, error)
+	RecordEntryTxFunc              func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error)
+}
+
+func (m *MockLedgerRepository) PostEntryTx(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+	if m.PostEntryTxFunc != nil {
+		return m.PostEntryTxFunc(ctx, tx, entry)
+	}
+	return 1, nil
+}
+
+func (m *MockLedgerRepository) GetAccountPostings(ctx context.Context, account string, limit int) ([]domain.Posting, error) {
+	return m.GetAccountPostingsFunc(ctx, account, limit)
+}
+
+func (m *MockLedgerRepository) GetBalanceAt(ctx context.Context, address string, at time.Time) (domain.Money, error) {
+	return m.GetBalanceAtFunc(ctx, address, at)
+}
+
+func (m *MockLedgerRepository) CreateBalanceSnapshots(ctx context.Context, lag time.Duration) (int64, error) {
+	return m.CreateBalanceSnapshotsFunc(ctx, lag)
+}
+
+func (m *MockLedgerRepository) GetBalanceChecks(ctx context.Context) ([]domain.BalanceCheck, error) {
+	return m.GetBalanceChecksFunc(ctx)
+}
+
+func (m *MockLedgerRepository) GetBalanceCheckForUpdateTx(ctx context.Context, tx domain.TxExecutor, address string) (*domain.BalanceCheck, error) {
+	return m.GetBalanceCheckForUpdateTxFunc(ctx, tx, address)
+}
+
+func (m *MockLedgerRepository) RecordEntryTx(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+	if m.RecordEntryTxFunc != nil {
+		return m.RecordEntryTxFunc(ctx, tx, entry)
+	}
+	return 1, nil
+}
+
+type MockIdempotencyRepository struct {
+	ReserveKeyTxFunc      func(ctx context.Context, tx domain.TxExecutor, key, requestHash string, ttl time.Duration) (*domain.IdempotencyRecord, error)
+	CompleteKeyTxFunc     func(ctx context.Context, tx domain.TxExecutor, key string, transactionId int64) error
+	RemoveExpiredKeysFunc func(ctx context.Context) (int64, error)
+}
+
+func (m *MockIdempotencyRepository) ReserveKeyTx(ctx context.Context, tx domain.TxExecutor, key, requestHash string, ttl time.Duration) (*domain.IdempotencyRecord, error) {
+	return m.ReserveKeyTxFunc(ctx, tx, key, requestHash, ttl)
+}
+
+func (m *MockIdempotencyRepository) CompleteKeyTx(ctx context.Context, tx domain.TxExecutor, key string, transactionId int64) error {
+	return m.CompleteKeyTxFunc(ctx, tx, key, transactionId)
+}
+
+func (m *MockIdempotencyRepository) RemoveExpiredKeys(ctx context.Context) (int64, error) {
+	return m.RemoveExpiredKeysFunc(ctx)
+}
+
+type MockTxExecutor struct {
+	CommitErr error
+}
 
 func (m *MockTxExecutor) Commit(ctx context.Context) error {
-	return nil
+	return m.CommitErr
 }
 
 func (m *MockTxExecutor) Rollback(ctx context.Context) error {
@@ -111,3 +283,165 @@ func (m *MockTxExecutor) Exec(ctx context.Context, sql string, arguments ...inte
 func (m *MockTxExecutor) QueryRow(ctx context.Context, sql string, args ...interface{}) domain.Row {
 	return nil
 }
+
+func (m *MockTxExecutor) Query(ctx context.Context, sql string, args ...interface{}) (domain.Rows, error) {
+	return nil, nil
+}
+
+type MockRateProvider struct {
+	GetRateFunc func(ctx context.Context, from, to string) (domain.ExchangeRate, error)
+}
+
+func (m *MockRateProvider) GetRate(ctx context.Context, from, to string) (domain.ExchangeRate, error) {
+	return m.GetRateFunc(ctx, from, to)
+}
+
+type MockHoldRepository struct {
+	BeginTXFunc            func(ctx context.Context) (domain.TxExecutor, error)
+	CreateHoldTxFunc       func(ctx context.Context, tx domain.TxExecutor, from, to, currency string, amount domain.Money, ttl time.Duration) (*domain.Hold, error)
+	GetHoldFunc            func(ctx context.Context, id int64) (*domain.Hold, error)
+	GetHoldForUpdateTxFunc func(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Hold, error)
+	CloseHoldTxFunc        func(ctx context.Context, tx domain.TxExecutor, id int64, status domain.HoldStatus, capturedAmount domain.Money, transactionId int64) error
+	ExpireHoldsFunc        func(ctx context.Context) (int64, error)
+}
+
+func (m *MockHoldRepository) BeginTX(ctx context.Context) (domain.TxExecutor, error) {
+	if m.BeginTXFunc != nil {
+		return m.BeginTXFunc(ctx)
+	}
+	return &MockTxExecutor{}, nil
+}
+
+func (m *MockHoldRepository) CreateHoldTx(ctx context.Context, tx domain.TxExecutor, from, to, currency string, amount domain.Money, ttl time.Duration) (*domain.Hold, error) {
+	return m.CreateHoldTxFunc(ctx, tx, from, to, currency, amount, ttl)
+}
+
+func (m *MockHoldRepository) GetHold(ctx context.Context, id int64) (*domain.Hold, error) {
+	return m.GetHoldFunc(ctx, id)
+}
+
+func (m *MockHoldRepository) GetHoldForUpdateTx(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Hold, error) {
+	return m.GetHoldForUpdateTxFunc(ctx, tx, id)
+}
+
+func (m *MockHoldRepository) CloseHoldTx(ctx context.Context, tx domain.TxExecutor, id int64, status domain.HoldStatus, capturedAmount domain.Money, transactionId int64) error {
+	if m.CloseHoldTxFunc != nil {
+		return m.CloseHoldTxFunc(ctx, tx, id, status, capturedAmount, transactionId)
+	}
+	return nil
+}
+
+func (m *MockHoldRepository) ExpireHolds(ctx context.Context) (int64, error) {
+	return m.ExpireHoldsFunc(ctx)
+}
+
+type MockScheduledTransferRepository struct {
+	CreateScheduledTransferFunc func(ctx context.Context, req domain.ScheduledTransferRequest) (*domain.ScheduledTransfer, error)
+	GetScheduledTransfersFunc   func(ctx context.Context, status domain.ScheduledStatus, limit int) ([]domain.ScheduledTransfer, error)
+	CancelScheduledTransferFunc func(ctx context.Context, id int64) error
+	ClaimDueTransferFunc        func(ctx context.Context, lease time.Duration) (*domain.ScheduledTransfer, error)
+	FinishScheduledTransferFunc func(ctx context.Context, id int64, status domain.ScheduledStatus, transactionId int64, failureCode domain.ErrorCode) error
+}
+
+func (m *MockScheduledTransferRepository) CreateScheduledTransfer(ctx context.Context, req domain.ScheduledTransferRequest) (*domain.ScheduledTransfer, error) {
+	return m.CreateScheduledTransferFunc(ctx, req)
+}
+
+func (m *MockScheduledTransferRepository) GetScheduledTransfers(ctx context.Context, status domain.ScheduledStatus, limit int) ([]domain.ScheduledTransfer, error) {
+	return m.GetScheduledTransfersFunc(ctx, status, limit)
+}
+
+func (m *MockScheduledTransferRepository) CancelScheduledTransfer(ctx context.Context, id int64) error {
+	return m.CancelScheduledTransferFunc(ctx, id)
+}
+
+func (m *MockScheduledTransferRepository) ClaimDueTransfer(ctx context.Context, lease time.Duration) (*domain.ScheduledTransfer, error) {
+	return m.ClaimDueTransferFunc(ctx, lease)
+}
+
+func (m *MockScheduledTransferRepository) FinishScheduledTransfer(ctx context.Context, id int64, status domain.ScheduledStatus, transactionId int64, failureCode domain.ErrorCode) error {
+	if m.FinishScheduledTransferFunc != nil {
+		return m.FinishScheduledTransferFunc(ctx, id, status, transactionId, failureCode)
+	}
+	return nil
+}
+
+type MockTransferSender struct {
+	SendMoneyFunc func(ctx context.Context, req domain.TransferRequest) (*domain.TransferResult, domain.ErrorCode)
+}
+
+func (m *MockTransferSender) SendMoney(ctx context.Context, req domain.TransferRequest) (*domain.TransferResult, domain.ErrorCode) {
+	return m.SendMoneyFunc(ctx, req)
+}
+
+type MockMandateRepository struct {
+	CreateMandateFunc    func(ctx context.Context, m domain.Mandate) (*domain.Mandate, error)
+	GetMandateFunc       func(ctx context.Context, id int64) (*domain.Mandate, error)
+	CancelMandateFunc    func(ctx context.Context, id int64) error
+	ClaimDueMandateFunc  func(ctx context.Context, lease time.Duration) (*domain.Mandate, error)
+	FinishMandateRunFunc func(ctx context.Context, run domain.MandateRun, m domain.Mandate) error
+	GetMandateRunsFunc   func(ctx context.Context, mandateId int64, limit int) ([]domain.MandateRun, error)
+}
+
+func (m *MockMandateRepository) CreateMandate(ctx context.Context, mandate domain.Mandate) (*domain.Mandate, error) {
+	return m.CreateMandateFunc(ctx, mandate)
+}
+
+func (m *MockMandateRepository) GetMandate(ctx context.Context, id int64) (*domain.Mandate, error) {
+	return m.GetMandateFunc(ctx, id)
+}
+
+func (m *MockMandateRepository) CancelMandate(ctx context.Context, id int64) error {
+	return m.CancelMandateFunc(ctx, id)
+}
+
+func (m *MockMandateRepository) ClaimDueMandate(ctx context.Context, lease time.Duration) (*domain.Mandate, error) {
+	return m.ClaimDueMandateFunc(ctx, lease)
+}
+
+func (m *MockMandateRepository) FinishMandateRun(ctx context.Context, run domain.MandateRun, mandate domain.Mandate) error {
+	if m.FinishMandateRunFunc != nil {
+		return m.FinishMandateRunFunc(ctx, run, mandate)
+	}
+	return nil
+}
+
+func (m *MockMandateRepository) GetMandateRuns(ctx context.Context, mandateId int64, limit int) ([]domain.MandateRun, error) {
+	return m.GetMandateRunsFunc(ctx, mandateId, limit)
+}
+
+type MockAPIKeyRepository struct {
+	CreateAPIKeyFunc    func(ctx context.Context, name, keyHash string, scopes []domain.Scope) (*domain.APIKey, error)
+	GetAPIKeyByHashFunc func(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	ListAPIKeysFunc     func(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKeyFunc    func(ctx context.Context, id int64) error
+}
+
+func (m *MockAPIKeyRepository) CreateAPIKey(ctx context.Context, name, keyHash string, scopes []domain.Scope) (*domain.APIKey, error) {
+	return m.CreateAPIKeyFunc(ctx, name, keyHash, scopes)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	return m.GetAPIKeyByHashFunc(ctx, keyHash)
+}
+
+func (m *MockAPIKeyRepository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	return m.ListAPIKeysFunc(ctx)
+}
+
+func (m *MockAPIKeyRepository) RevokeAPIKey(ctx context.Context, id int64) error {
+	return m.RevokeAPIKeyFunc(ctx, id)
+}
+
+type MockSchemaRepository struct {
+	PingFunc             func(ctx context.Context) error
+	MigrationVersionFunc func(ctx context.Context) (uint, bool, error)
+}
+
+func (m *MockSchemaRepository) Ping(ctx context.Context) error {
+	return m.PingFunc(ctx)
+}
+
+func (m *MockSchemaRepository) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	return m.MigrationVersionFunc(ctx)
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"TransactionTest/config"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+	"TransactionTest/internal/service"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func newRS(walletRepo service.IWalletRepository, ledgerRepo service.ILedgerRepository) *service.ReconciliationService {
+	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(zap.FatalLevel)
+	log, _ := logger.New(&cfg)
+	return service.NewReconciliationService(walletRepo, ledgerRepo, config.TransferConfig{}, log)
+}
+
+func newTxWalletRepo() *MockWalletRepository {
+	return &MockWalletRepository{
+		BeginTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
+			return &MockTxExecutor{}, nil
+		},
+	}
+}
+
+var reconcileChecks = []domain.BalanceCheck{
+	{Address: "ok", Currency: "USD", Stored: 100, Expected: 100},
+	{Address: "drift", Currency: "USD", Stored: 15000, Expected: 10000},
+}
+
+func TestReconciliationService_Reconcile_Report(t *testing.T) {
+	recorded := false
+	lr := &MockLedgerRepository{
+		GetBalanceChecksFunc: func(ctx context.Context) ([]domain.BalanceCheck, error) {
+			return reconcileChecks, nil
+		},
+		RecordEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			recorded = true
+			return 1, nil
+		},
+	}
+	report, code := newRS(newTxWalletRepo(), lr).Reconcile(context.Background(), false)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, 2, report.Checked)
+	assert.Len(t, report.Drifts, 1)
+	assert.Equal(t, "drift", report.Drifts[0].Address)
+	assert.Equal(t, domain.Money(5000), report.Drifts[0].Drift())
+	assert.False(t, report.Drifts[0].Repaired)
+	assert.False(t, recorded, "без repair журнал не меняется")
+}
+
+func TestReconciliationService_Reconcile_Repair(t *testing.T) {
+	var entries []domain.JournalEntry
+	lr := &MockLedgerRepository{
+		GetBalanceChecksFunc: func(ctx context.Context) ([]domain.BalanceCheck, error) {
+			return reconcileChecks, nil
+		},
+		GetBalanceCheckForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.BalanceCheck, error) {
+			// Под блокировкой расхождение уже другое - в отчет попадает перепроверенное
+			return &domain.BalanceCheck{Address: address, Currency: "USD", Stored: 15000, Expected: 12000}, nil
+		},
+		RecordEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			entries = append(entries, entry)
+			return 1, nil
+		},
+	}
+	report, code := newRS(newTxWalletRepo(), lr).Reconcile(context.Background(), true)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, 1, report.Repaired())
+	assert.Equal(t, domain.Money(3000), report.Drifts[0].Drift())
+	assert.Len(t, entries, 1)
+	assert.Equal(t, domain.EntryCorrection, entries[0].Kind)
+	assert.Equal(t, []domain.Posting{
+		{Account: domain.AccountEquity, Currency: "USD", Amount: -3000},
+		{Account: "drift", Currency: "USD", Amount: 3000},
+	}, entries[0].Postings)
+}
+
+func TestReconciliationService_Reconcile_DriftGoneBeforeRepair(t *testing.T) {
+	lr := &MockLedgerRepository{
+		GetBalanceChecksFunc: func(ctx context.Context) ([]domain.BalanceCheck, error) {
+			return reconcileChecks, nil
+		},
+		GetBalanceCheckForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.BalanceCheck, error) {
+			return &domain.BalanceCheck{Address: address, Currency: "USD", Stored: 100, Expected: 100}, nil
+		},
+		RecordEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			t.Fatal("correction must not be recorded")
+			return 0, nil
+		},
+	}
+	report, code := newRS(newTxWalletRepo(), lr).Reconcile(context.Background(), true)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Len(t, report.Drifts, 1)
+	assert.False(t, report.Drifts[0].Repaired)
+}
+
+func TestReconciliationService_Reconcile_RepairFailureContinues(t *testing.T) {
+	checks := []domain.BalanceCheck{
+		{Address: "a", Currency: "USD", Stored: 200, Expected: 100},
+		{Address: "b", Currency: "USD", Stored: 50, Expected: 100},
+	}
+	lr := &MockLedgerRepository{
+		GetBalanceChecksFunc: func(ctx context.Context) ([]domain.BalanceCheck, error) {
+			return checks, nil
+		},
+		GetBalanceCheckForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.BalanceCheck, error) {
+			for _, c := range checks {
+				if c.Address == address {
+					return &c, nil
+				}
+			}
+			return nil, domain.ErrNotFound
+		},
+		RecordEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			if entry.Postings[1].Account == "a" {
+				return 0, errors.Join(domain.ErrInternal, errors.New("db down"))
+			}
+			return 1, nil
+		},
+	}
+	report, code := newRS(newTxWalletRepo(), lr).Reconcile(context.Background(), true)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Len(t, report.Drifts, 2)
+	assert.False(t, report.Drifts[0].Repaired)
+	assert.True(t, report.Drifts[1].Repaired)
+}
+
+func TestReconciliationService_Reconcile_Error(t *testing.T) {
+	lr := &MockLedgerRepository{
+		GetBalanceChecksFunc: func(ctx context.Context) ([]domain.BalanceCheck, error) {
+			return nil, errors.Join(domain.ErrInternal, errors.New("db down"))
+		},
+	}
+	_, code := newRS(newTxWalletRepo(), lr).Reconcile(context.Background(), false)
+	assert.Equal(t, domain.CodeInternal, code)
+}
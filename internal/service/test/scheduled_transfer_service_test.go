@@ -0,0 +1,201 @@
+package test
+
+import (
+	"TransactionTest/config"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+	"TransactionTest/internal/service"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func newSS(repo *MockScheduledTransferRepository, sender *MockTransferSender, cfg config.TransferConfig) *service.ScheduledTransferService {
+	logCfg := zap.NewProductionConfig()
+	logCfg.Level = zap.NewAtomicLevelAt(zap.FatalLevel)
+	log, _ := logger.New(&logCfg)
+	return service.NewScheduledTransferService(repo, sender, cfg, log)
+}
+
+// newDueQueue очередь наступивших переводов: ClaimDueTransfer отдает их по одному, затем ErrNotFound
+func newDueQueue(due ...domain.ScheduledTransfer) *MockScheduledTransferRepository {
+	return &MockScheduledTransferRepository{
+		ClaimDueTransferFunc: func(ctx context.Context, lease time.Duration) (*domain.ScheduledTransfer, error) {
+			if len(due) == 0 {
+				return nil, domain.ErrNotFound
+			}
+			next := due[0]
+			due = due[1:]
+			return &next, nil
+		},
+	}
+}
+
+func TestScheduledTransferService_ScheduleTransfer_Success(t *testing.T) {
+	executeAt := time.Now().Add(time.Hour)
+	repo := &MockScheduledTransferRepository{
+		CreateScheduledTransferFunc: func(ctx context.Context, req domain.ScheduledTransferRequest) (*domain.ScheduledTransfer, error) {
+			return &domain.ScheduledTransfer{Id: 3, From: req.From, To: req.To, Amount: req.Amount, ExecuteAt: req.ExecuteAt, Status: domain.ScheduledPending}, nil
+		},
+	}
+	ss := newSS(repo, &MockTransferSender{}, config.TransferConfig{})
+
+	scheduled, code := ss.ScheduleTransfer(context.Background(), domain.ScheduledTransferRequest{From: "from", To: "to", Amount: 100, ExecuteAt: executeAt})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(3), scheduled.Id)
+	assert.Equal(t, domain.ScheduledPending, scheduled.Status)
+}
+
+func TestScheduledTransferService_ScheduleTransfer_ValidationErrors(t *testing.T) {
+	ss := newSS(&MockScheduledTransferRepository{}, &MockTransferSender{}, config.TransferConfig{})
+	ctx := context.Background()
+	future := time.Now().Add(time.Hour)
+
+	_, code := ss.ScheduleTransfer(ctx, domain.ScheduledTransferRequest{From: "a", To: "a", Amount: 10, ExecuteAt: future})
+	assert.Equal(t, domain.CodeInvalidTransaction, code)
+
+	_, code = ss.ScheduleTransfer(ctx, domain.ScheduledTransferRequest{From: "a", To: "b", Amount: -1, ExecuteAt: future})
+	assert.Equal(t, domain.CodeNegativeAmount, code)
+
+	_, code = ss.ScheduleTransfer(ctx, domain.ScheduledTransferRequest{From: "a", To: "b", Amount: 10, ExecuteAt: time.Now().Add(-time.Minute)})
+	assert.Equal(t, domain.CodeInvalidExecuteAt, code)
+}
+
+func TestScheduledTransferService_ScheduleTransfer_WalletNotFound(t *testing.T) {
+	repo := &MockScheduledTransferRepository{
+		CreateScheduledTransferFunc: func(ctx context.Context, req domain.ScheduledTransferRequest) (*domain.ScheduledTransfer, error) {
+			return nil, domain.ErrNotFound
+		},
+	}
+	ss := newSS(repo, &MockTransferSender{}, config.TransferConfig{})
+
+	_, code := ss.ScheduleTransfer(context.Background(), domain.ScheduledTransferRequest{From: "a", To: "b", Amount: 10, ExecuteAt: time.Now().Add(time.Hour)})
+	assert.Equal(t, domain.CodeWalletNotFound, code)
+}
+
+func TestScheduledTransferService_GetScheduledTransfers_InvalidLimit(t *testing.T) {
+	ss := newSS(&MockScheduledTransferRepository{}, &MockTransferSender{}, config.TransferConfig{})
+
+	transfers, code := ss.GetScheduledTransfers(context.Background(), "", 0)
+	assert.Equal(t, domain.CodeInvalidLimit, code)
+	assert.Nil(t, transfers)
+}
+
+func TestScheduledTransferService_CancelScheduledTransfer_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		code domain.ErrorCode
+	}{
+		{nil, domain.CodeOK},
+		{domain.ErrNotFound, domain.CodeScheduledNotFound},
+		{domain.ErrScheduledNotPending, domain.CodeScheduledNotPending},
+		{errors.New("fail"), domain.CodeInternal},
+	}
+	for _, tt := range tests {
+		repo := &MockScheduledTransferRepository{
+			CancelScheduledTransferFunc: func(ctx context.Context, id int64) error {
+				return tt.err
+			},
+		}
+		ss := newSS(repo, &MockTransferSender{}, config.TransferConfig{})
+		assert.Equal(t, tt.code, ss.CancelScheduledTransfer(context.Background(), 3))
+	}
+}
+
+func TestScheduledTransferService_ExecuteDue_RecordsResults(t *testing.T) {
+	type finished struct {
+		status        domain.ScheduledStatus
+		transactionId int64
+		failureCode   domain.ErrorCode
+	}
+	results := map[int64]finished{}
+	var keys []string
+
+	repo := newDueQueue(
+		domain.ScheduledTransfer{Id: 1, From: "a", To: "b", Amount: 100},
+		domain.ScheduledTransfer{Id: 2, From: "c", To: "b", Amount: 100},
+	)
+	repo.FinishScheduledTransferFunc = func(ctx context.Context, id int64, status domain.ScheduledStatus, transactionId int64, failureCode domain.ErrorCode) error {
+		results[id] = finished{status, transactionId, failureCode}
+		return nil
+	}
+	sender := &MockTransferSender{
+		SendMoneyFunc: func(ctx context.Context, req domain.TransferRequest) (*domain.TransferResult, domain.ErrorCode) {
+			keys = append(keys, req.IdempotencyKey)
+			if req.From == "c" {
+				return nil, domain.CodeInsufficientFunds
+			}
+			return &domain.TransferResult{TransactionId: 42}, domain.CodeOK
+		},
+	}
+	ss := newSS(repo, sender, config.TransferConfig{})
+
+	executed, code := ss.ExecuteDue(context.Background())
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, 2, executed)
+	assert.Equal(t, []string{"scheduled:1", "scheduled:2"}, keys)
+	assert.Equal(t, finished{domain.ScheduledSucceeded, 42, domain.CodeOK}, results[1])
+	assert.Equal(t, finished{domain.ScheduledFailed, 0, domain.CodeInsufficientFunds}, results[2])
+}
+
+func TestScheduledTransferService_ExecuteDue_BatchSize(t *testing.T) {
+	repo := newDueQueue(
+		domain.ScheduledTransfer{Id: 1, From: "a", To: "b", Amount: 100},
+		domain.ScheduledTransfer{Id: 2, From: "a", To: "b", Amount: 100},
+		domain.ScheduledTransfer{Id: 3, From: "a", To: "b", Amount: 100},
+	)
+	sender := &MockTransferSender{
+		SendMoneyFunc: func(ctx context.Context, req domain.TransferRequest) (*domain.TransferResult, domain.ErrorCode) {
+			return &domain.TransferResult{TransactionId: 1}, domain.CodeOK
+		},
+	}
+	ss := newSS(repo, sender, config.TransferConfig{ScheduledBatchSize: 2})
+
+	executed, code := ss.ExecuteDue(context.Background())
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, 2, executed)
+}
+
+func TestScheduledTransferService_ExecuteDue_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	repo := newDueQueue(
+		domain.ScheduledTransfer{Id: 1, From: "a", To: "b", Amount: 100},
+		domain.ScheduledTransfer{Id: 2, From: "a", To: "b", Amount: 100},
+	)
+	var finishedStatus domain.ScheduledStatus
+	repo.FinishScheduledTransferFunc = func(ctx context.Context, id int64, status domain.ScheduledStatus, transactionId int64, failureCode domain.ErrorCode) error {
+		finishedStatus = status
+		return nil
+	}
+	sender := &MockTransferSender{
+		SendMoneyFunc: func(sendCtx context.Context, req domain.TransferRequest) (*domain.TransferResult, domain.ErrorCode) {
+			// Остановка во время перевода не прерывает его
+			cancel()
+			assert.NoError(t, sendCtx.Err())
+			return &domain.TransferResult{TransactionId: 1}, domain.CodeOK
+		},
+	}
+	ss := newSS(repo, sender, config.TransferConfig{})
+
+	executed, code := ss.ExecuteDue(ctx)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, 1, executed)
+	assert.Equal(t, domain.ScheduledSucceeded, finishedStatus)
+}
+
+func TestScheduledTransferService_ExecuteDue_ClaimError(t *testing.T) {
+	repo := &MockScheduledTransferRepository{
+		ClaimDueTransferFunc: func(ctx context.Context, lease time.Duration) (*domain.ScheduledTransfer, error) {
+			return nil, errors.New("fail")
+		},
+	}
+	ss := newSS(repo, &MockTransferSender{}, config.TransferConfig{})
+
+	executed, code := ss.ExecuteDue(context.Background())
+	assert.Equal(t, domain.CodeInternal, code)
+	assert.Equal(t, 0, executed)
+}
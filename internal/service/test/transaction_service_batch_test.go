@@ -0,0 +1,145 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newBatchWalletRepo(balances map[string]domain.Money, locked *[]string) *MockWalletRepository {
+	return &MockWalletRepository{
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			if locked != nil {
+				*locked = append(*locked, address)
+			}
+			balance, ok := balances[address]
+			if !ok {
+				return nil, domain.ErrNotFound
+			}
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: balance}, nil
+		},
+	}
+}
+
+func TestTransactionService_SendBatch_Empty(t *testing.T) {
+	ts := newTS(&MockWalletRepository{}, newBeginTx())
+	_, code := ts.SendBatch(context.Background(), nil)
+	assert.Equal(t, domain.CodeInvalidTransaction, code)
+}
+
+func TestTransactionService_SendBatch_ValidationErrors(t *testing.T) {
+	ts := newTS(&MockWalletRepository{}, &MockTransactionRepository{})
+	res, code := ts.SendBatch(context.Background(), []domain.TransferLeg{
+		{From: "a", To: "b", Amount: 10},
+		{From: "a", To: "a", Amount: 10},
+		{From: "a", To: "b", Amount: 0},
+	})
+	assert.Equal(t, domain.CodeBatchRejected, code)
+	assert.Equal(t, []domain.LegError{
+		{Index: 1, Code: domain.CodeInvalidTransaction},
+		{Index: 2, Code: domain.CodeNegativeAmount},
+	}, res.LegErrors)
+}
+
+func TestTransactionService_SendBatch_Success(t *testing.T) {
+	var locked []string
+	wr := newBatchWalletRepo(map[string]domain.Money{"payer": 100, "b": 0, "a": 0}, &locked)
+	tr := newBeginTx()
+	nextId := int64(0)
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		nextId++
+		return nextId, nil
+	}
+	posted := 0
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			posted++
+			return int64(posted), nil
+		},
+	}
+	ts := newTSWithLedger(wr, tr, lr)
+	res, code := ts.SendBatch(context.Background(), []domain.TransferLeg{
+		{From: "payer", To: "b", Amount: 60},
+		{From: "payer", To: "a", Amount: 40},
+	})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, []int64{1, 2}, res.TransactionIds)
+	assert.Equal(t, 2, posted)
+	// Каждый кошелек блокируется один раз в порядке возрастания адреса
+	assert.Equal(t, []string{"a", "b", "payer"}, locked)
+}
+
+func TestTransactionService_SendBatch_CumulativeInsufficientFunds(t *testing.T) {
+	wr := newBatchWalletRepo(map[string]domain.Money{"payer": 100, "a": 0, "b": 0}, nil)
+	tr := newBeginTx()
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		t.Fatal("rejected batch must not create transactions")
+		return 0, nil
+	}
+	ts := newTS(wr, tr)
+	res, code := ts.SendBatch(context.Background(), []domain.TransferLeg{
+		{From: "payer", To: "a", Amount: 60},
+		{From: "payer", To: "b", Amount: 60},
+	})
+	assert.Equal(t, domain.CodeBatchRejected, code)
+	assert.Equal(t, []domain.LegError{{Index: 1, Code: domain.CodeInsufficientFunds}}, res.LegErrors)
+}
+
+func TestTransactionService_SendBatch_ChainedLegs(t *testing.T) {
+	// Вторая нога тратит средства, зачисленные первой
+	wr := newBatchWalletRepo(map[string]domain.Money{"a": 50, "b": 0, "c": 0}, nil)
+	ts := newTS(wr, newBeginTx())
+	_, code := ts.SendBatch(context.Background(), []domain.TransferLeg{
+		{From: "a", To: "b", Amount: 50},
+		{From: "b", To: "c", Amount: 50},
+	})
+	assert.Equal(t, domain.CodeOK, code)
+}
+
+func TestTransactionService_SendBatch_WalletNotFound(t *testing.T) {
+	wr := newBatchWalletRepo(map[string]domain.Money{"a": 50, "b": 0}, nil)
+	ts := newTS(wr, newBeginTx())
+	res, code := ts.SendBatch(context.Background(), []domain.TransferLeg{
+		{From: "a", To: "b", Amount: 10},
+		{From: "a", To: "missing", Amount: 10},
+	})
+	assert.Equal(t, domain.CodeBatchRejected, code)
+	assert.Equal(t, []domain.LegError{{Index: 1, Code: domain.CodeWalletNotFound}}, res.LegErrors)
+}
+
+func TestTransactionService_SendBatch_InternalErrorRollsBack(t *testing.T) {
+	wr := newBatchWalletRepo(map[string]domain.Money{"a": 50, "b": 0}, nil)
+	committed := false
+	tr := newBeginTx()
+	tr.BeginTXFunc = func(ctx context.Context) (domain.TxExecutor, error) {
+		return &commitTrackingTx{committed: &committed}, nil
+	}
+	calls := 0
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		calls++
+		if calls == 2 {
+			return 0, errors.New("fail")
+		}
+		return int64(calls), nil
+	}
+	ts := newTS(wr, tr)
+	res, code := ts.SendBatch(context.Background(), []domain.TransferLeg{
+		{From: "a", To: "b", Amount: 10},
+		{From: "a", To: "b", Amount: 10},
+	})
+	assert.Equal(t, domain.CodeInternal, code)
+	assert.Nil(t, res)
+	assert.False(t, committed)
+}
+
+type commitTrackingTx struct {
+	MockTxExecutor
+	committed *bool
+}
+
+func (t *commitTrackingTx) Commit(ctx context.Context) error {
+	*t.committed = true
+	return nil
+}
@@ -0,0 +1,162 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// lockingLedger эмулирует построчные блокировки Postgres (SELECT ... FOR UPDATE) в памяти:
+// блокировка держится до Commit/Rollback, а записи видны другим только после Commit.
+type lockingLedger struct {
+	mu       sync.Mutex
+	balances map[string]domain.Money
+	locks    map[string]*sync.Mutex
+	lastID   int64
+}
+
+type lockingTx struct {
+	MockTxExecutor
+	ledger *lockingLedger
+	held   []string
+	staged map[string]domain.Money
+	done   bool
+}
+
+func newLockingLedger(balances map[string]domain.Money) *lockingLedger {
+	l := &lockingLedger{balances: balances, locks: map[string]*sync.Mutex{}}
+	for address := range balances {
+		l.locks[address] = &sync.Mutex{}
+	}
+	return l
+}
+
+func (l *lockingLedger) begin(ctx context.Context) (domain.TxExecutor, error) {
+	return &lockingTx{ledger: l, staged: map[string]domain.Money{}}, nil
+}
+
+func (l *lockingLedger) lock(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+	ltx := tx.(*lockingTx)
+	lock, ok := l.locks[address]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	lock.Lock()
+	ltx.held = append(ltx.held, address)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return &domain.Wallet{Address: address, Currency: "USD", Balance: l.balances[address]}, nil
+}
+
+func (l *lockingLedger) post(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+	ltx := tx.(*lockingTx)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, p := range entry.Postings {
+		balance, ok := ltx.staged[p.Account]
+		if !ok {
+			balance = l.balances[p.Account]
+		}
+		ltx.staged[p.Account] = balance + p.Amount
+	}
+	return 1, nil
+}
+
+func (l *lockingLedger) createTransaction(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+	return atomic.AddInt64(&l.lastID, 1), nil
+}
+
+func (l *lockingLedger) total() domain.Money {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var sum domain.Money
+	for _, b := range l.balances {
+		sum += b
+	}
+	return sum
+}
+
+func (t *lockingTx) Commit(ctx context.Context) error {
+	t.ledger.mu.Lock()
+	for address, balance := range t.staged {
+		t.ledger.balances[address] = balance
+	}
+	t.ledger.mu.Unlock()
+	t.release()
+	return nil
+}
+
+func (t *lockingTx) Rollback(ctx context.Context) error {
+	t.release()
+	return nil
+}
+
+func (t *lockingTx) release() {
+	if t.done {
+		return
+	}
+	t.done = true
+	for _, address := range t.held {
+		t.ledger.locks[address].Unlock()
+	}
+}
+
+func TestTransactionService_SendMoney_ConcurrentTransfersKeepTotal(t *testing.T) {
+	wallets := []string{"w1", "w2", "w3", "w4", "w5"}
+	balances := map[string]domain.Money{}
+	for _, w := range wallets {
+		balances[w] = domain.MustParseMoney("1000.00")
+	}
+	ledger := newLockingLedger(balances)
+	before := ledger.total()
+
+	wr := &MockWalletRepository{
+		GetWalletForUpdateTxFunc: ledger.lock,
+	}
+	tr := &MockTransactionRepository{
+		BeginTXFunc:             ledger.begin,
+		CreateTransactionTxFunc: ledger.createTransaction,
+	}
+	ts := newTSWithLedger(wr, tr, &MockLedgerRepository{PostEntryTxFunc: ledger.post})
+
+	var wg sync.WaitGroup
+	var succeeded int64
+	for i := 0; i < 500; i++ {
+		from := wallets[i%len(wallets)]
+		to := wallets[(i*3+1)%len(wallets)]
+		if from == to {
+			continue
+		}
+		amount := domain.Money((i%13 + 1) * 1733)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: from, To: to, Amount: amount}); code == domain.CodeOK {
+				atomic.AddInt64(&succeeded, 1)
+			}
+		}()
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(10 * time.Second):
+		t.Fatal("transfers deadlocked")
+	}
+
+	assert.Equal(t, before, ledger.total())
+	assert.Equal(t, atomic.LoadInt64(&succeeded), ledger.lastID)
+	for address, balance := range ledger.balances {
+		assert.GreaterOrEqual(t, int64(balance), int64(0), address)
+	}
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newCurrencyWalletRepo(currencies map[string]string) *MockWalletRepository {
+	return &MockWalletRepository{
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			currency, ok := currencies[address]
+			if !ok {
+				return nil, domain.ErrNotFound
+			}
+			return &domain.Wallet{Address: address, Currency: currency, Balance: 10000}, nil
+		},
+	}
+}
+
+func TestTransactionService_SendMoney_CurrencyMismatch(t *testing.T) {
+	tr := newBeginTx()
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		t.Fatal("transfer between currencies must not create a transaction")
+		return 0, nil
+	}
+	ts := newTS(newCurrencyWalletRepo(map[string]string{"from": "USD", "to": "EUR"}), tr)
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
+	assert.Equal(t, domain.CodeCurrencyMismatch, code)
+}
+
+func TestTransactionService_SendMoney_CurrencyPrecision(t *testing.T) {
+	ts := newTS(newCurrencyWalletRepo(map[string]string{"from": "JPY", "to": "JPY"}), newBeginTx())
+	// В JPY нет дробной части, поэтому 1.50 недопустимо
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 150})
+	assert.Equal(t, domain.CodeInvalidAmount, code)
+
+	_, code = ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 200})
+	assert.Equal(t, domain.CodeOK, code)
+}
+
+func TestTransactionService_SendMoney_PostsInWalletCurrency(t *testing.T) {
+	var posted domain.JournalEntry
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			posted = entry
+			return 1, nil
+		},
+	}
+	ts := newTSWithLedger(newCurrencyWalletRepo(map[string]string{"from": "EUR", "to": "EUR"}), newBeginTx(), lr)
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
+	assert.Equal(t, domain.CodeOK, code)
+	for _, p := range posted.Postings {
+		assert.Equal(t, "EUR", p.Currency)
+	}
+}
+
+func TestTransactionService_SendBatch_CurrencyMismatch(t *testing.T) {
+	ts := newTS(newCurrencyWalletRepo(map[string]string{"a": "USD", "b": "USD", "c": "EUR"}), newBeginTx())
+	res, code := ts.SendBatch(context.Background(), []domain.TransferLeg{
+		{From: "a", To: "b", Amount: 10},
+		{From: "a", To: "c", Amount: 10},
+	})
+	assert.Equal(t, domain.CodeBatchRejected, code)
+	assert.Equal(t, []domain.LegError{{Index: 1, Code: domain.CodeCurrencyMismatch}}, res.LegErrors)
+}
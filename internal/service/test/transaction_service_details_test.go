@@ -0,0 +1,64 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTransactionService_SendMoney_StoresDetails(t *testing.T) {
+	wr := &MockWalletRepository{
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: 100}, nil
+		},
+	}
+	var stored domain.TransferDetails
+	tr := newBeginTx()
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		stored = details
+		return 1, nil
+	}
+	ts := newTS(wr, tr)
+
+	details := domain.TransferDetails{Memo: "memo", ExternalReference: "order-1", Metadata: map[string]interface{}{"k": "v"}}
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10, Details: details})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, details, stored)
+}
+
+func TestTransactionService_SendMoney_DuplicateReference(t *testing.T) {
+	wr := &MockWalletRepository{
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: 100}, nil
+		},
+	}
+	tr := newBeginTx()
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		return 0, fmt.Errorf("%w: %q", domain.ErrDuplicateReference, details.ExternalReference)
+	}
+	ts := newTS(wr, tr)
+
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10,
+		Details: domain.TransferDetails{ExternalReference: "order-1"}})
+	assert.Equal(t, domain.CodeDuplicateReference, code)
+}
+
+func TestTransactionService_FindTransactionsByExternalReference(t *testing.T) {
+	tr := &MockTransactionRepository{
+		GetTransactionsByExternalReferenceFunc: func(ctx context.Context, reference, from string) ([]domain.Transaction, error) {
+			assert.Equal(t, "order-1", reference)
+			assert.Equal(t, "from", from)
+			return []domain.Transaction{{Id: 1, Details: domain.TransferDetails{ExternalReference: reference}}}, nil
+		},
+	}
+	ts := newTS(&MockWalletRepository{}, tr)
+
+	transactions, code := ts.FindTransactionsByExternalReference(context.Background(), "order-1", "from")
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Len(t, transactions, 1)
+
+	_, code = ts.FindTransactionsByExternalReference(context.Background(), "", "")
+	assert.Equal(t, domain.CodeInvalidRequestBody, code)
+}
@@ -0,0 +1,172 @@
+package test
+
+import (
+	"TransactionTest/config"
+	"TransactionTest/internal/domain"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var testRateAt = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestRates(rates map[string]string) *MockRateProvider {
+	return &MockRateProvider{
+		GetRateFunc: func(ctx context.Context, from, to string) (domain.ExchangeRate, error) {
+			rate, ok := rates[from+"/"+to]
+			if !ok {
+				return domain.ExchangeRate{}, domain.ErrRateNotFound
+			}
+			return domain.ExchangeRate{From: from, To: to, Rate: mustParseRate(rate), Timestamp: testRateAt}, nil
+		},
+	}
+}
+
+func mustParseRate(s string) domain.Rate {
+	r, err := domain.ParseRate(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func newExchangeTxRepo(created *domain.Exchange) *MockTransactionRepository {
+	tr := newBeginTx()
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		panic("transfer between currencies must use CreateExchangeTx")
+	}
+	tr.CreateExchangeTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, exchange domain.Exchange, details domain.TransferDetails) (int64, error) {
+		*created = exchange
+		return 7, nil
+	}
+	return tr
+}
+
+func TestTransactionService_SendMoney_Exchange(t *testing.T) {
+	var created domain.Exchange
+	var posted domain.JournalEntry
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			posted = entry
+			return 1, nil
+		},
+	}
+	ts := newTSWithRates(
+		newCurrencyWalletRepo(map[string]string{"from": "USD", "to": "EUR"}),
+		newExchangeTxRepo(&created), lr, &MockIdempotencyRepository{},
+		newTestRates(map[string]string{"USD/EUR": "0.92"}),
+		config.TransferConfig{ExchangeSpreadBps: 50},
+	)
+	res, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: domain.MustParseMoney("100"), Convert: true})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(7), res.TransactionId)
+
+	// 0.92 за вычетом спреда 0.5% = 0.9154
+	want := domain.Exchange{ToCurrency: "EUR", ToAmount: domain.MustParseMoney("91.54"), Rate: mustParseRate("0.9154"), RateAt: testRateAt}
+	assert.Equal(t, want, created)
+	assert.Equal(t, &want, res.Exchange)
+
+	assert.NoError(t, posted.Validate())
+	assert.Equal(t, []domain.Posting{
+		{Account: "from", Currency: "USD", Amount: domain.MustParseMoney("-100")},
+		{Account: domain.AccountFX, Currency: "USD", Amount: domain.MustParseMoney("100")},
+		{Account: domain.AccountFX, Currency: "EUR", Amount: domain.MustParseMoney("-91.54")},
+		{Account: "to", Currency: "EUR", Amount: domain.MustParseMoney("91.54")},
+	}, posted.Postings)
+}
+
+func TestTransactionService_SendMoney_ExchangeRoundsToCurrency(t *testing.T) {
+	var created domain.Exchange
+	ts := newTSWithRates(
+		newCurrencyWalletRepo(map[string]string{"from": "USD", "to": "JPY"}),
+		newExchangeTxRepo(&created), &MockLedgerRepository{}, &MockIdempotencyRepository{},
+		newTestRates(map[string]string{"USD/JPY": "149.8"}),
+		config.TransferConfig{},
+	)
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: domain.MustParseMoney("1"), Convert: true})
+	assert.Equal(t, domain.CodeOK, code)
+	// 149.80 JPY округляется вниз до целых иен
+	assert.Equal(t, domain.MustParseMoney("149"), created.ToAmount)
+}
+
+func TestTransactionService_SendMoney_ExchangeTooSmall(t *testing.T) {
+	var created domain.Exchange
+	ts := newTSWithRates(
+		newCurrencyWalletRepo(map[string]string{"from": "JPY", "to": "USD"}),
+		newExchangeTxRepo(&created), &MockLedgerRepository{}, &MockIdempotencyRepository{},
+		newTestRates(map[string]string{"JPY/USD": "0.0066"}),
+		config.TransferConfig{},
+	)
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: domain.MustParseMoney("1"), Convert: true})
+	assert.Equal(t, domain.CodeInvalidAmount, code)
+}
+
+func TestTransactionService_SendMoney_ExchangeRateNotFound(t *testing.T) {
+	var created domain.Exchange
+	ts := newTSWithRates(
+		newCurrencyWalletRepo(map[string]string{"from": "USD", "to": "EUR"}),
+		newExchangeTxRepo(&created), &MockLedgerRepository{}, &MockIdempotencyRepository{},
+		newTestRates(nil),
+		config.TransferConfig{},
+	)
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 100, Convert: true})
+	assert.Equal(t, domain.CodeRateUnavailable, code)
+}
+
+func TestTransactionService_SendMoney_ExchangeNotConfigured(t *testing.T) {
+	var created domain.Exchange
+	ts := newTS(newCurrencyWalletRepo(map[string]string{"from": "USD", "to": "EUR"}), newExchangeTxRepo(&created))
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 100, Convert: true})
+	assert.Equal(t, domain.CodeRateUnavailable, code)
+}
+
+func TestTransactionService_SendMoney_ExchangeProviderError(t *testing.T) {
+	var created domain.Exchange
+	rates := &MockRateProvider{
+		GetRateFunc: func(ctx context.Context, from, to string) (domain.ExchangeRate, error) {
+			return domain.ExchangeRate{}, errors.New("fail")
+		},
+	}
+	ts := newTSWithRates(
+		newCurrencyWalletRepo(map[string]string{"from": "USD", "to": "EUR"}),
+		newExchangeTxRepo(&created), &MockLedgerRepository{}, &MockIdempotencyRepository{},
+		rates, config.TransferConfig{},
+	)
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 100, Convert: true})
+	assert.Equal(t, domain.CodeInternal, code)
+}
+
+func TestTransactionService_SendMoney_SameCurrencyIgnoresConvert(t *testing.T) {
+	ts := newTS(newCurrencyWalletRepo(map[string]string{"from": "EUR", "to": "EUR"}), newBeginTx())
+	res, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 100, Convert: true})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Nil(t, res.Exchange)
+}
+
+func TestTransactionService_ReverseTransaction_Exchange(t *testing.T) {
+	original := &domain.Transaction{
+		Id: 1, From: "from", To: "to", Amount: domain.MustParseMoney("100"), Currency: "USD",
+		Exchange: &domain.Exchange{ToCurrency: "EUR", ToAmount: domain.MustParseMoney("91.54"), Rate: mustParseRate("0.9154"), RateAt: testRateAt},
+	}
+	// У получателя 91.54 EUR - ровно столько, сколько он получил
+	wr, tr := newReverseRepos(original, map[string]domain.Money{"from": 0, "to": domain.MustParseMoney("91.54")})
+	var posted domain.JournalEntry
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			posted = entry
+			return 1, nil
+		},
+	}
+	ts := newTSWithLedger(wr, tr, lr)
+	_, code := ts.ReverseTransaction(context.Background(), 1)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.NoError(t, posted.Validate())
+	assert.Equal(t, []domain.Posting{
+		{Account: "to", Currency: "EUR", Amount: domain.MustParseMoney("-91.54")},
+		{Account: domain.AccountFX, Currency: "EUR", Amount: domain.MustParseMoney("91.54")},
+		{Account: domain.AccountFX, Currency: "USD", Amount: domain.MustParseMoney("-100")},
+		{Account: "from", Currency: "USD", Amount: domain.MustParseMoney("100")},
+	}, posted.Postings)
+}
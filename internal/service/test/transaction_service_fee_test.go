@@ -0,0 +1,93 @@
+package test
+
+import (
+	"TransactionTest/config"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/service"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// newTestFees тариф USD: 1% от суммы, но не меньше 0.50, комиссия зачисляется на кошелек "fees"
+func newTestFees() *domain.FeeSchedules {
+	fees, err := domain.NewFeeSchedules([]domain.FeeSchedule{
+		{Currency: "USD", Collector: "fees", PercentBps: 100, Min: domain.MustParseMoney("0.50")},
+	}, testCurrencies())
+	if err != nil {
+		panic(err)
+	}
+	return fees
+}
+
+func newFeeTS(wr *MockWalletRepository, tr *MockTransactionRepository, lr *MockLedgerRepository) *service.TransactionService {
+	return newTSWithFees(wr, tr, lr, &MockIdempotencyRepository{}, nil, newTestFees(), config.TransferConfig{})
+}
+
+func TestTransactionService_SendMoney_Fee(t *testing.T) {
+	var createdFee domain.Money
+	var posted domain.JournalEntry
+	tr := newBeginTx()
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		createdFee = fee
+		return 3, nil
+	}
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			posted = entry
+			return 1, nil
+		},
+	}
+	ts := newFeeTS(newCurrencyWalletRepo(map[string]string{"from": "USD", "to": "USD", "fees": "USD"}), tr, lr)
+
+	res, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: domain.MustParseMoney("80")})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, domain.MustParseMoney("0.80"), res.Fee)
+	assert.Equal(t, domain.MustParseMoney("0.80"), createdFee)
+
+	assert.NoError(t, posted.Validate())
+	assert.Equal(t, []domain.Posting{
+		{Account: "from", Currency: "USD", Amount: domain.MustParseMoney("-80")},
+		{Account: "to", Currency: "USD", Amount: domain.MustParseMoney("80")},
+		{Account: "from", Currency: "USD", Amount: domain.MustParseMoney("-0.80")},
+		{Account: "fees", Currency: "USD", Amount: domain.MustParseMoney("0.80")},
+	}, posted.Postings)
+}
+
+func TestTransactionService_SendMoney_FeeInsufficientFunds(t *testing.T) {
+	ts := newFeeTS(newCurrencyWalletRepo(map[string]string{"from": "USD", "to": "USD", "fees": "USD"}), newBeginTx(), &MockLedgerRepository{})
+
+	// Баланс 100: сумма проходит, а сумма вместе с комиссией 1.00 - нет
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: domain.MustParseMoney("100")})
+	assert.Equal(t, domain.CodeInsufficientFunds, code)
+}
+
+func TestTransactionService_SendMoney_NoFeeSchedule(t *testing.T) {
+	var createdFee domain.Money = -1
+	tr := newBeginTx()
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		createdFee = fee
+		return 3, nil
+	}
+	// Для JPY тарифа нет, кошелек-сборщик не нужен
+	ts := newFeeTS(newCurrencyWalletRepo(map[string]string{"from": "JPY", "to": "JPY"}), tr, &MockLedgerRepository{})
+
+	res, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: domain.MustParseMoney("100")})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, domain.Money(0), res.Fee)
+	assert.Equal(t, domain.Money(0), createdFee)
+}
+
+func TestTransactionService_SendMoney_FeeCollectorMissing(t *testing.T) {
+	ts := newFeeTS(newCurrencyWalletRepo(map[string]string{"from": "USD", "to": "USD"}), newBeginTx(), &MockLedgerRepository{})
+
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: domain.MustParseMoney("10")})
+	assert.Equal(t, domain.CodeInternal, code)
+}
+
+func TestTransactionService_SendMoney_FeeCollectorCurrencyMismatch(t *testing.T) {
+	ts := newFeeTS(newCurrencyWalletRepo(map[string]string{"from": "USD", "to": "USD", "fees": "EUR"}), newBeginTx(), &MockLedgerRepository{})
+
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: domain.MustParseMoney("10")})
+	assert.Equal(t, domain.CodeInternal, code)
+}
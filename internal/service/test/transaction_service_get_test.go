@@ -1,6 +1,7 @@
 package test
 
 import (
+	"TransactionTest/config"
 	"TransactionTest/internal/domain"
 	"TransactionTest/internal/logger"
 	"TransactionTest/internal/service"
@@ -14,17 +15,117 @@ import (
 )
 
 func newTS(walletRepo service.IWalletRepository, txRepo service.ITransactionRepository) *service.TransactionService {
+	return newTSWithRepos(walletRepo, txRepo, &MockLedgerRepository{}, &MockIdempotencyRepository{})
+}
+
+func newTSWithLedger(walletRepo service.IWalletRepository, txRepo service.ITransactionRepository, ledgerRepo service.ILedgerRepository) *service.TransactionService {
+	return newTSWithRepos(walletRepo, txRepo, ledgerRepo, &MockIdempotencyRepository{})
+}
+
+func newTSWithIdempotency(walletRepo service.IWalletRepository, txRepo service.ITransactionRepository, idemRepo service.IIdempotencyRepository) *service.TransactionService {
+	return newTSWithRepos(walletRepo, txRepo, &MockLedgerRepository{}, idemRepo)
+}
+
+func newTSWithRepos(
+	walletRepo service.IWalletRepository,
+	txRepo service.ITransactionRepository,
+	ledgerRepo service.ILedgerRepository,
+	idemRepo service.IIdempotencyRepository,
+) *service.TransactionService {
+	return newTSWithConfig(walletRepo, txRepo, ledgerRepo, idemRepo, config.TransferConfig{MaxRetries: 3})
+}
+
+func newTSWithConfig(
+	walletRepo service.IWalletRepository,
+	txRepo service.ITransactionRepository,
+	ledgerRepo service.ILedgerRepository,
+	idemRepo service.IIdempotencyRepository,
+	transferCfg config.TransferConfig,
+) *service.TransactionService {
+	return newTSWithRates(walletRepo, txRepo, ledgerRepo, idemRepo, nil, transferCfg)
+}
+
+func newTSWithRates(
+	walletRepo service.IWalletRepository,
+	txRepo service.ITransactionRepository,
+	ledgerRepo service.ILedgerRepository,
+	idemRepo service.IIdempotencyRepository,
+	rates service.ExchangeRateProvider,
+	transferCfg config.TransferConfig,
+) *service.TransactionService {
+	return newTSWithFees(walletRepo, txRepo, ledgerRepo, idemRepo, rates, nil, transferCfg)
+}
+
+func newTSWithLimits(
+	walletRepo service.IWalletRepository,
+	txRepo service.ITransactionRepository,
+	limits domain.LimitPolicy,
+) *service.TransactionService {
+	return newTSWithPolicies(walletRepo, txRepo, &MockLedgerRepository{}, &MockIdempotencyRepository{}, nil, nil, limits, config.TransferConfig{MaxRetries: 3})
+}
+
+func newTSWithFees(
+	walletRepo service.IWalletRepository,
+	txRepo service.ITransactionRepository,
+	ledgerRepo service.ILedgerRepository,
+	idemRepo service.IIdempotencyRepository,
+	rates service.ExchangeRateProvider,
+	fees *domain.FeeSchedules,
+	transferCfg config.TransferConfig,
+) *service.TransactionService {
+	return newTSWithPolicies(walletRepo, txRepo, ledgerRepo, idemRepo, rates, fees, domain.LimitPolicy{}, transferCfg)
+}
+
+func newTSWithPolicies(
+	walletRepo service.IWalletRepository,
+	txRepo service.ITransactionRepository,
+	ledgerRepo service.ILedgerRepository,
+	idemRepo service.IIdempotencyRepository,
+	rates service.ExchangeRateProvider,
+	fees *domain.FeeSchedules,
+	limits domain.LimitPolicy,
+	transferCfg config.TransferConfig,
+) *service.TransactionService {
 	cfg := zap.NewProductionConfig()
 	cfg.Level = zap.NewAtomicLevelAt(zap.FatalLevel)
 	log, _ := logger.New(&cfg)
-	return service.NewTransactionService(txRepo, walletRepo, log)
+	return service.NewTransactionService(txRepo, walletRepo, ledgerRepo, idemRepo, testCurrencies(), rates, fees, limits, transferCfg, nil, log)
+}
+
+// testCurrencies справочник валют для тестов: USD по умолчанию, JPY без дробной части
+func testCurrencies() *domain.CurrencyRegistry {
+	currencies, err := domain.NewCurrencyRegistry("USD", []domain.Currency{
+		{Code: "USD", Decimals: 2},
+		{Code: "EUR", Decimals: 2},
+		{Code: "JPY", Decimals: 0},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return currencies
 }
 
 func newWS(walletRepo service.IWalletRepository) *service.WalletService {
+	return newWSWithLedger(walletRepo, &MockLedgerRepository{})
+}
+
+func newWSWithLedger(walletRepo service.IWalletRepository, ledgerRepo service.ILedgerRepository) *service.WalletService {
+	return newWSWithLimits(walletRepo, ledgerRepo, domain.LimitPolicy{})
+}
+
+func newWSWithLimits(walletRepo service.IWalletRepository, ledgerRepo service.ILedgerRepository, limits domain.LimitPolicy) *service.WalletService {
+	return newWSWithTransactions(walletRepo, ledgerRepo, &MockTransactionRepository{}, limits)
+}
+
+func newWSWithTransactions(walletRepo service.IWalletRepository, ledgerRepo service.ILedgerRepository, transactionRepo service.ITransactionRepository, limits domain.LimitPolicy) *service.WalletService {
+	return newWSWithTreasury(walletRepo, ledgerRepo, transactionRepo, nil, limits)
+}
+
+func newWSWithTreasury(walletRepo service.IWalletRepository, ledgerRepo service.ILedgerRepository, transactionRepo service.ITransactionRepository, treasury *domain.Treasury, limits domain.LimitPolicy) *service.WalletService {
 	cfg := zap.NewProductionConfig()
 	cfg.Level = zap.NewAtomicLevelAt(zap.FatalLevel)
 	log, _ := logger.New(&cfg)
-	return service.NewWalletService(walletRepo, log)
+	return service.NewWalletService(walletRepo, ledgerRepo, transactionRepo, testCurrencies(), treasury, limits, log)
 }
 
 func TestTransactionService_GetLastTransactions_InvalidLimit(t *testing.T) {
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newIdempotentWalletRepo() *MockWalletRepository {
+	return &MockWalletRepository{
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: 100}, nil
+		},
+	}
+}
+
+func TestTransactionService_SendMoney_IdempotencyKeyStored(t *testing.T) {
+	var storedKey string
+	var storedId int64
+	ir := &MockIdempotencyRepository{
+		ReserveKeyTxFunc: func(ctx context.Context, tx domain.TxExecutor, key, requestHash string, ttl time.Duration) (*domain.IdempotencyRecord, error) {
+			return nil, nil
+		},
+		CompleteKeyTxFunc: func(ctx context.Context, tx domain.TxExecutor, key string, transactionId int64) error {
+			storedKey, storedId = key, transactionId
+			return nil
+		},
+	}
+	tr := newBeginTx()
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		return 7, nil
+	}
+	ts := newTSWithIdempotency(newIdempotentWalletRepo(), tr, ir)
+
+	res, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10, IdempotencyKey: "k1"})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(7), res.TransactionId)
+	assert.False(t, res.Replayed)
+	assert.Equal(t, "k1", storedKey)
+	assert.Equal(t, int64(7), storedId)
+}
+
+func TestTransactionService_SendMoney_IdempotencyReplay(t *testing.T) {
+	req := domain.TransferRequest{From: "from", To: "to", Amount: 10, IdempotencyKey: "k1"}
+	ir := &MockIdempotencyRepository{
+		ReserveKeyTxFunc: func(ctx context.Context, tx domain.TxExecutor, key, requestHash string, ttl time.Duration) (*domain.IdempotencyRecord, error) {
+			return &domain.IdempotencyRecord{Key: key, RequestHash: req.Fingerprint(), TransactionId: 7}, nil
+		},
+	}
+	exchange := &domain.Exchange{ToCurrency: "EUR", ToAmount: 9, Rate: 90000000}
+	tr := newBeginTx()
+	tr.GetTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Transaction, error) {
+		return &domain.Transaction{Id: id, From: "from", To: "to", Amount: 10, Fee: 1, Exchange: exchange}, nil
+	}
+	// Кошельки не должны трогаться и перевод не создается: у мока нет функций, вызов привел бы к панике
+	ts := newTSWithIdempotency(&MockWalletRepository{}, tr, ir)
+
+	res, code := ts.SendMoney(context.Background(), req)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(7), res.TransactionId)
+	assert.True(t, res.Replayed)
+	// Повтор возвращает то же, что первый вызов: комиссию и курс конвертации
+	assert.Equal(t, domain.Money(1), res.Fee)
+	assert.Equal(t, exchange, res.Exchange)
+}
+
+func TestTransactionService_SendMoney_IdempotencyMismatch(t *testing.T) {
+	other := domain.TransferRequest{From: "from", To: "to", Amount: 99}
+	ir := &MockIdempotencyRepository{
+		ReserveKeyTxFunc: func(ctx context.Context, tx domain.TxExecutor, key, requestHash string, ttl time.Duration) (*domain.IdempotencyRecord, error) {
+			return &domain.IdempotencyRecord{Key: key, RequestHash: other.Fingerprint(), TransactionId: 7}, nil
+		},
+	}
+	ts := newTSWithIdempotency(&MockWalletRepository{}, newBeginTx(), ir)
+
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10, IdempotencyKey: "k1"})
+	assert.Equal(t, domain.CodeIdempotencyMismatch, code)
+}
+
+func TestTransactionService_SendMoney_IdempotencyReserveError(t *testing.T) {
+	ir := &MockIdempotencyRepository{
+		ReserveKeyTxFunc: func(ctx context.Context, tx domain.TxExecutor, key, requestHash string, ttl time.Duration) (*domain.IdempotencyRecord, error) {
+			return nil, errors.New("fail")
+		},
+	}
+	ts := newTSWithIdempotency(&MockWalletRepository{}, newBeginTx(), ir)
+
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10, IdempotencyKey: "k1"})
+	assert.Equal(t, domain.CodeInternal, code)
+}
+
+func TestTransactionService_SendMoney_WithoutKeySkipsIdempotency(t *testing.T) {
+	ts := newTSWithIdempotency(newIdempotentWalletRepo(), newBeginTx(), &MockIdempotencyRepository{})
+
+	res, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(1), res.TransactionId)
+}
+
+func TestTransactionService_PurgeExpiredIdempotencyKeys(t *testing.T) {
+	ir := &MockIdempotencyRepository{
+		RemoveExpiredKeysFunc: func(ctx context.Context) (int64, error) {
+			return 3, nil
+		},
+	}
+	ts := newTSWithIdempotency(&MockWalletRepository{}, &MockTransactionRepository{}, ir)
+
+	removed, code := ts.PurgeExpiredIdempotencyKeys(context.Background())
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(3), removed)
+}
@@ -0,0 +1,206 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// newLimitWalletRepo кошельки USD с заданными балансами и своими лимитами
+func newLimitWalletRepo(balances map[string]domain.Money, overrides map[string]domain.LimitOverrides) *MockWalletRepository {
+	return &MockWalletRepository{
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: balances[address], Status: domain.WalletActive}, nil
+		},
+		GetWalletLimitsTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (domain.LimitOverrides, error) {
+			return overrides[address], nil
+		},
+	}
+}
+
+func TestTransactionService_SendMoney_DefaultMaxTransfer(t *testing.T) {
+	m := domain.MustParseMoney
+	wr := newLimitWalletRepo(map[string]domain.Money{"from": m("1000")}, nil)
+	ts := newTSWithLimits(wr, newBeginTx(), domain.LimitPolicy{Defaults: domain.WalletLimits{MaxTransfer: m("100")}})
+
+	res, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: m("100.01")})
+	assert.Equal(t, domain.CodeLimitExceeded, code)
+	assert.Equal(t, domain.LimitMaxTransfer, res.ExceededLimit)
+
+	_, code = ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: m("100")})
+	assert.Equal(t, domain.CodeOK, code)
+}
+
+func TestTransactionService_SendMoney_WalletOverrideLiftsDefault(t *testing.T) {
+	m := domain.MustParseMoney
+	noLimit := domain.Money(0)
+	wr := newLimitWalletRepo(map[string]domain.Money{"from": m("1000")},
+		map[string]domain.LimitOverrides{"from": {MaxTransfer: &noLimit}})
+	ts := newTSWithLimits(wr, newBeginTx(), domain.LimitPolicy{Defaults: domain.WalletLimits{MaxTransfer: m("100")}})
+
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: m("500")})
+	assert.Equal(t, domain.CodeOK, code)
+}
+
+func TestTransactionService_SendMoney_DailyOutflowOverWindow(t *testing.T) {
+	m := domain.MustParseMoney
+	outflowLimit := m("300")
+	wr := newLimitWalletRepo(map[string]domain.Money{"from": m("1000")},
+		map[string]domain.LimitOverrides{"from": {MaxDailyOutflow: &outflowLimit}})
+
+	var window time.Duration
+	tr := newBeginTx()
+	tr.GetOutflowTxFunc = func(ctx context.Context, tx domain.TxExecutor, address string, w time.Duration) (domain.Outflow, error) {
+		window = w
+		assert.Equal(t, "from", address)
+		return domain.Outflow{Amount: m("250"), Count: 2}, nil
+	}
+	ts := newTSWithLimits(wr, tr, domain.LimitPolicy{Window: 24 * time.Hour})
+
+	res, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: m("60")})
+	assert.Equal(t, domain.CodeLimitExceeded, code)
+	assert.Equal(t, domain.LimitMaxDailyOutflow, res.ExceededLimit)
+	assert.Equal(t, 24*time.Hour, window)
+
+	_, code = ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: m("50")})
+	assert.Equal(t, domain.CodeOK, code)
+}
+
+func TestTransactionService_SendMoney_DailyCount(t *testing.T) {
+	m := domain.MustParseMoney
+	tr := newBeginTx()
+	tr.GetOutflowTxFunc = func(ctx context.Context, tx domain.TxExecutor, address string, w time.Duration) (domain.Outflow, error) {
+		return domain.Outflow{Amount: m("5"), Count: 5}, nil
+	}
+	ts := newTSWithLimits(newLimitWalletRepo(map[string]domain.Money{"from": m("1000")}, nil), tr,
+		domain.LimitPolicy{Defaults: domain.WalletLimits{MaxDailyCount: 5}, Window: time.Hour})
+
+	res, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: m("1")})
+	assert.Equal(t, domain.CodeLimitExceeded, code)
+	assert.Equal(t, domain.LimitMaxDailyCount, res.ExceededLimit)
+}
+
+func TestTransactionService_SendMoney_ReceiverMaxBalance(t *testing.T) {
+	m := domain.MustParseMoney
+	maxBalance := m("500")
+	wr := newLimitWalletRepo(map[string]domain.Money{"from": m("1000"), "to": m("450")},
+		map[string]domain.LimitOverrides{"to": {MaxBalance: &maxBalance}})
+	ts := newTSWithLimits(wr, newBeginTx(), domain.LimitPolicy{})
+
+	res, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: m("50.01")})
+	assert.Equal(t, domain.CodeLimitExceeded, code)
+	assert.Equal(t, domain.LimitMaxBalance, res.ExceededLimit)
+
+	_, code = ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: m("50")})
+	assert.Equal(t, domain.CodeOK, code)
+}
+
+func TestTransactionService_SendMoney_NoDailyLimitsSkipsOutflow(t *testing.T) {
+	// GetOutflowTxFunc не задан: без дневных лимитов выборка за окно не нужна
+	ts := newTSWithLimits(newLimitWalletRepo(map[string]domain.Money{"from": 1000}, nil), newBeginTx(), domain.LimitPolicy{})
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
+	assert.Equal(t, domain.CodeOK, code)
+}
+
+func TestTransactionService_SendBatch_MaxTransferPerLeg(t *testing.T) {
+	m := domain.MustParseMoney
+	tr := newBeginTx()
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		t.Fatal("rejected batch must not create transactions")
+		return 0, nil
+	}
+	ts := newTSWithLimits(newLimitWalletRepo(map[string]domain.Money{"from": m("1000")}, nil), tr,
+		domain.LimitPolicy{Defaults: domain.WalletLimits{MaxTransfer: m("100")}})
+
+	res, code := ts.SendBatch(context.Background(), []domain.TransferLeg{
+		{From: "from", To: "a", Amount: m("50")},
+		{From: "from", To: "b", Amount: m("150")},
+	})
+	assert.Equal(t, domain.CodeBatchRejected, code)
+	assert.Equal(t, []domain.LegError{{Index: 1, Code: domain.CodeLimitExceeded, Limit: domain.LimitMaxTransfer}}, res.LegErrors)
+}
+
+func TestTransactionService_SendBatch_EarlierLegsCountTowardDailyLimits(t *testing.T) {
+	m := domain.MustParseMoney
+	outflowLimit := m("300")
+	wr := newLimitWalletRepo(map[string]domain.Money{"from": m("1000")},
+		map[string]domain.LimitOverrides{"from": {MaxDailyOutflow: &outflowLimit}})
+	tr := newBeginTx()
+	tr.GetOutflowTxFunc = func(ctx context.Context, tx domain.TxExecutor, address string, w time.Duration) (domain.Outflow, error) {
+		// Ноги пакета еще не записаны, в БД только прежние переводы
+		return domain.Outflow{Amount: m("100"), Count: 1}, nil
+	}
+	ts := newTSWithLimits(wr, tr, domain.LimitPolicy{Window: 24 * time.Hour})
+
+	// Каждая нога по отдельности укладывается в лимит, вместе с предыдущими - нет
+	res, code := ts.SendBatch(context.Background(), []domain.TransferLeg{
+		{From: "from", To: "a", Amount: m("150")},
+		{From: "from", To: "b", Amount: m("60")},
+	})
+	assert.Equal(t, domain.CodeBatchRejected, code)
+	assert.Equal(t, []domain.LegError{{Index: 1, Code: domain.CodeLimitExceeded, Limit: domain.LimitMaxDailyOutflow}}, res.LegErrors)
+}
+
+func TestTransactionService_SendBatch_ReceiverMaxBalanceAcrossLegs(t *testing.T) {
+	m := domain.MustParseMoney
+	ts := newTSWithLimits(newLimitWalletRepo(map[string]domain.Money{"a": m("100"), "b": m("100"), "to": m("50")}, nil), newBeginTx(),
+		domain.LimitPolicy{Defaults: domain.WalletLimits{MaxBalance: m("100")}})
+
+	res, code := ts.SendBatch(context.Background(), []domain.TransferLeg{
+		{From: "a", To: "to", Amount: m("40")},
+		{From: "b", To: "to", Amount: m("20")},
+	})
+	assert.Equal(t, domain.CodeBatchRejected, code)
+	assert.Equal(t, []domain.LegError{{Index: 1, Code: domain.CodeLimitExceeded, Limit: domain.LimitMaxBalance}}, res.LegErrors)
+}
+
+func TestWalletService_GetWalletLimits_MergesDefaults(t *testing.T) {
+	m := domain.MustParseMoney
+	maxTransfer := m("50")
+	wr := &MockWalletRepository{
+		GetWalletFunc: func(ctx context.Context, address string) (*domain.Wallet, error) {
+			return &domain.Wallet{Address: address}, nil
+		},
+		GetWalletLimitsFunc: func(ctx context.Context, address string) (domain.LimitOverrides, error) {
+			return domain.LimitOverrides{MaxTransfer: &maxTransfer}, nil
+		},
+	}
+	ws := newWSWithLimits(wr, &MockLedgerRepository{}, domain.LimitPolicy{
+		Defaults: domain.WalletLimits{MaxTransfer: m("100"), MaxDailyCount: 10},
+		Window:   24 * time.Hour,
+	})
+
+	settings, code := ws.GetWalletLimits(context.Background(), "addr")
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, domain.WalletLimits{MaxTransfer: m("50"), MaxDailyCount: 10}, settings.Effective)
+	assert.Equal(t, 24*time.Hour, settings.Window)
+}
+
+func TestWalletService_GetWalletLimits_NotFound(t *testing.T) {
+	wr := &MockWalletRepository{
+		GetWalletFunc: func(ctx context.Context, address string) (*domain.Wallet, error) {
+			return nil, domain.ErrNotFound
+		},
+	}
+	_, code := newWS(wr).GetWalletLimits(context.Background(), "addr")
+	assert.Equal(t, domain.CodeWalletNotFound, code)
+}
+
+func TestWalletService_SetWalletLimits_Negative(t *testing.T) {
+	negative := domain.Money(-1)
+	ws := newWS(&MockWalletRepository{})
+	_, code := ws.SetWalletLimits(context.Background(), "addr", domain.LimitOverrides{MaxBalance: &negative})
+	assert.Equal(t, domain.CodeInvalidLimit, code)
+}
+
+func TestWalletService_SetWalletLimits_NotFound(t *testing.T) {
+	wr := &MockWalletRepository{
+		SetWalletLimitsFunc: func(ctx context.Context, address string, overrides domain.LimitOverrides) error {
+			return domain.ErrNotFound
+		},
+	}
+	_, code := newWS(wr).SetWalletLimits(context.Background(), "addr", domain.LimitOverrides{})
+	assert.Equal(t, domain.CodeWalletNotFound, code)
+}
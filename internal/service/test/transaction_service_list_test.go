@@ -0,0 +1,57 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTransactionService_ListTransactions_AppliesDefaults(t *testing.T) {
+	var got domain.TransactionFilter
+	txRepo := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter domain.TransactionFilter) (*domain.TransactionPage, error) {
+			got = filter
+			return &domain.TransactionPage{Transactions: []domain.Transaction{{Id: 1}}}, nil
+		},
+	}
+	ts := newTS(&MockWalletRepository{}, txRepo)
+
+	page, code := ts.ListTransactions(context.Background(), domain.TransactionFilter{Wallet: "w"})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Len(t, page.Transactions, 1)
+	assert.Equal(t, domain.TransactionFilter{
+		Wallet: "w",
+		Role:   domain.WalletRoleAny,
+		Order:  domain.SortDesc,
+		Limit:  domain.DefaultTransactionPageSize,
+	}, got)
+}
+
+func TestTransactionService_ListTransactions_InvalidFilter(t *testing.T) {
+	ts := newTS(&MockWalletRepository{}, &MockTransactionRepository{})
+	_, code := ts.ListTransactions(context.Background(), domain.TransactionFilter{Role: domain.WalletRoleReceiver})
+	assert.Equal(t, domain.CodeInvalidFilter, code)
+}
+
+func TestTransactionService_ListTransactions_CursorOrderMismatch(t *testing.T) {
+	ts := newTS(&MockWalletRepository{}, &MockTransactionRepository{})
+	_, code := ts.ListTransactions(context.Background(), domain.TransactionFilter{
+		Order: domain.SortAsc,
+		After: &domain.TransactionCursor{Order: domain.SortDesc, Id: 3},
+	})
+	assert.Equal(t, domain.CodeInvalidCursor, code)
+}
+
+func TestTransactionService_ListTransactions_InternalError(t *testing.T) {
+	txRepo := &MockTransactionRepository{
+		ListTransactionsFunc: func(ctx context.Context, filter domain.TransactionFilter) (*domain.TransactionPage, error) {
+			return nil, errors.New("db down")
+		},
+	}
+	ts := newTS(&MockWalletRepository{}, txRepo)
+	page, code := ts.ListTransactions(context.Background(), domain.TransactionFilter{})
+	assert.Equal(t, domain.CodeInternal, code)
+	assert.Nil(t, page)
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"TransactionTest/config"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+	"TransactionTest/internal/service"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+type observedTransfer struct {
+	code   domain.ErrorCode
+	amount domain.Money
+}
+
+type mockTransferMetrics struct {
+	observed []observedTransfer
+}
+
+func (m *mockTransferMetrics) ObserveTransfer(code domain.ErrorCode, amount domain.Money) {
+	m.observed = append(m.observed, observedTransfer{code: code, amount: amount})
+}
+
+func newTSWithMetrics(walletRepo service.IWalletRepository, txRepo service.ITransactionRepository, m service.TransferMetrics) *service.TransactionService {
+	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(zap.FatalLevel)
+	log, _ := logger.New(&cfg)
+	return service.NewTransactionService(txRepo, walletRepo, &MockLedgerRepository{}, &MockIdempotencyRepository{}, testCurrencies(), nil, nil, domain.LimitPolicy{}, config.TransferConfig{MaxRetries: 3}, m, log)
+}
+
+func TestTransactionService_SendMoney_ObservesOutcome(t *testing.T) {
+	m := &mockTransferMetrics{}
+	ts := newTSWithMetrics(&MockWalletRepository{}, newBeginTx(), m)
+
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "addr", To: "addr", Amount: 10})
+
+	assert.Equal(t, domain.CodeInvalidTransaction, code)
+	assert.Equal(t, []observedTransfer{{code: domain.CodeInvalidTransaction, amount: 10}}, m.observed)
+}
+
+func TestTransactionService_SendMoney_ObservesWalletNotFound(t *testing.T) {
+	m := &mockTransferMetrics{}
+	wr := &MockWalletRepository{
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return nil, domain.ErrNotFound
+		},
+	}
+	ts := newTSWithMetrics(wr, newBeginTx(), m)
+
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 25})
+
+	assert.Equal(t, domain.CodeWalletNotFound, code)
+	assert.Equal(t, []observedTransfer{{code: domain.CodeWalletNotFound, amount: 25}}, m.observed)
+}
+
+func TestTransactionService_SendMoney_ReplayNotObserved(t *testing.T) {
+	m := &mockTransferMetrics{}
+	req := domain.TransferRequest{From: "from", To: "to", Amount: 10, IdempotencyKey: "k1"}
+	ir := &MockIdempotencyRepository{
+		ReserveKeyTxFunc: func(ctx context.Context, tx domain.TxExecutor, key, requestHash string, ttl time.Duration) (*domain.IdempotencyRecord, error) {
+			return &domain.IdempotencyRecord{Key: key, RequestHash: req.Fingerprint(), TransactionId: 7}, nil
+		},
+	}
+	tr := newBeginTx()
+	tr.GetTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Transaction, error) {
+		return &domain.Transaction{Id: id, From: "from", To: "to", Amount: 10}, nil
+	}
+	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(zap.FatalLevel)
+	log, _ := logger.New(&cfg)
+	ts := service.NewTransactionService(tr, &MockWalletRepository{}, &MockLedgerRepository{}, ir, testCurrencies(), nil, nil, domain.LimitPolicy{}, config.TransferConfig{MaxRetries: 3}, m, log)
+
+	res, code := ts.SendMoney(context.Background(), req)
+
+	assert.Equal(t, domain.CodeOK, code)
+	assert.True(t, res.Replayed)
+	// Перевод учтен при первом вызове, повтор метрики не меняет
+	assert.Empty(t, m.observed)
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// newOverdraftWalletRepo кошельки USD с заданными балансами, у "from" кредитная линия overdraft
+func newOverdraftWalletRepo(balances map[string]domain.Money, overdraft domain.Money) *MockWalletRepository {
+	return &MockWalletRepository{
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			wallet := &domain.Wallet{Address: address, Currency: "USD", Balance: balances[address], Status: domain.WalletActive}
+			if address == "from" {
+				wallet.OverdraftLimit = overdraft
+			}
+			return wallet, nil
+		},
+	}
+}
+
+func TestTransactionService_SendMoney_SpendsIntoOverdraft(t *testing.T) {
+	m := domain.MustParseMoney
+	wr := newOverdraftWalletRepo(map[string]domain.Money{"from": m("-50")}, m("100"))
+	ts := newTS(wr, newBeginTx())
+
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: m("50")})
+	assert.Equal(t, domain.CodeOK, code)
+}
+
+func TestTransactionService_SendMoney_BeyondOverdraft(t *testing.T) {
+	m := domain.MustParseMoney
+	wr := newOverdraftWalletRepo(map[string]domain.Money{"from": m("-50")}, m("100"))
+	ts := newTS(wr, newBeginTx())
+
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: m("50.01")})
+	assert.Equal(t, domain.CodeInsufficientFunds, code)
+}
+
+func TestTransactionService_SendBatch_UsesOverdraft(t *testing.T) {
+	m := domain.MustParseMoney
+	wr := newOverdraftWalletRepo(map[string]domain.Money{"from": m("10"), "to": 0, "other": 0}, m("100"))
+	ts := newTS(wr, newBeginTx())
+
+	_, code := ts.SendBatch(context.Background(), []domain.TransferLeg{
+		{From: "from", To: "to", Amount: m("60")},
+		{From: "from", To: "other", Amount: m("50")},
+	})
+	assert.Equal(t, domain.CodeOK, code)
+
+	res, code := ts.SendBatch(context.Background(), []domain.TransferLeg{
+		{From: "from", To: "to", Amount: m("60")},
+		{From: "from", To: "other", Amount: m("50.01")},
+	})
+	assert.Equal(t, domain.CodeBatchRejected, code)
+	assert.Equal(t, []domain.LegError{{Index: 1, Code: domain.CodeInsufficientFunds}}, res.LegErrors)
+}
+
+func TestWalletService_SetOverdraftLimit_InUse(t *testing.T) {
+	wr := &MockWalletRepository{
+		SetOverdraftLimitFunc: func(ctx context.Context, address string, limit domain.Money) error {
+			return domain.ErrOverdraftInUse
+		},
+	}
+	code := newWS(wr).SetOverdraftLimit(context.Background(), "addr", 0)
+	assert.Equal(t, domain.CodeOverdraftInUse, code)
+}
+
+func TestWalletService_SetOverdraftLimit_Negative(t *testing.T) {
+	code := newWS(&MockWalletRepository{}).SetOverdraftLimit(context.Background(), "addr", -1)
+	assert.Equal(t, domain.CodeNegativeAmount, code)
+}
+
+func TestWalletService_SetOverdraftLimit_Success(t *testing.T) {
+	var got domain.Money
+	wr := &MockWalletRepository{
+		SetOverdraftLimitFunc: func(ctx context.Context, address string, limit domain.Money) error {
+			got = limit
+			return nil
+		},
+	}
+	code := newWS(wr).SetOverdraftLimit(context.Background(), "addr", domain.MustParseMoney("500"))
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, domain.MustParseMoney("500"), got)
+}
@@ -1,20 +1,38 @@
 package test
 
 import (
+	"TransactionTest/config"
 	"TransactionTest/internal/domain"
+	"TransactionTest/internal/service"
 	"context"
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
+func newAdminTS(tr service.ITransactionRepository) *service.TransactionService {
+	return newTSWithConfig(&MockWalletRepository{}, tr, &MockLedgerRepository{}, &MockIdempotencyRepository{}, config.TransferConfig{AdminHardDelete: true})
+}
+
+func TestTransactionService_RemoveTransaction_Disabled(t *testing.T) {
+	tr := &MockTransactionRepository{
+		RemoveTransactionFunc: func(ctx context.Context, id int64) error {
+			t.Fatal("hard delete must not reach repository")
+			return nil
+		},
+	}
+	ts := newTS(&MockWalletRepository{}, tr)
+	code := ts.RemoveTransaction(context.Background(), 1)
+	assert.Equal(t, domain.CodeForbidden, code)
+}
+
 func TestTransactionService_RemoveTransaction_NotFound(t *testing.T) {
 	tr := &MockTransactionRepository{
 		RemoveTransactionFunc: func(ctx context.Context, id int64) error {
 			return domain.ErrNotFound
 		},
 	}
-	ts := newTS(&MockWalletRepository{}, tr)
+	ts := newAdminTS(tr)
 	code := ts.RemoveTransaction(context.Background(), 1)
 	assert.Equal(t, domain.CodeTransactionNotFound, code)
 }
@@ -25,7 +43,7 @@ func TestTransactionService_RemoveTransaction_InternalError(t *testing.T) {
 			return errors.New("fail")
 		},
 	}
-	ts := newTS(&MockWalletRepository{}, tr)
+	ts := newAdminTS(tr)
 	code := ts.RemoveTransaction(context.Background(), 1)
 	assert.Equal(t, domain.CodeInternal, code)
 }
@@ -36,7 +54,7 @@ func TestTransactionService_RemoveTransaction_Success(t *testing.T) {
 			return nil
 		},
 	}
-	ts := newTS(&MockWalletRepository{}, tr)
+	ts := newAdminTS(tr)
 	code := ts.RemoveTransaction(context.Background(), 1)
 	assert.Equal(t, domain.CodeOK, code)
 }
@@ -0,0 +1,135 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newReverseRepos(original *domain.Transaction, balances map[string]domain.Money) (*MockWalletRepository, *MockTransactionRepository) {
+	wr := &MockWalletRepository{
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			balance, ok := balances[address]
+			if !ok {
+				return nil, domain.ErrNotFound
+			}
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: balance}, nil
+		},
+	}
+	tr := newBeginTx()
+	tr.GetTransactionForUpdateTxFunc = func(ctx context.Context, tx domain.TxExecutor, id int64) (*domain.Transaction, error) {
+		if original == nil || id != original.Id {
+			return nil, domain.ErrNotFound
+		}
+		return original, nil
+	}
+	tr.CreateReversalTxFunc = func(ctx context.Context, tx domain.TxExecutor, original *domain.Transaction) (int64, error) {
+		return 2, nil
+	}
+	return wr, tr
+}
+
+func TestTransactionService_ReverseTransaction_Success(t *testing.T) {
+	original := &domain.Transaction{Id: 1, From: "from", To: "to", Amount: 10}
+	wr, tr := newReverseRepos(original, map[string]domain.Money{"from": 90, "to": 110})
+	var posted []domain.JournalEntry
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			posted = append(posted, entry)
+			return 1, nil
+		},
+	}
+	ts := newTSWithLedger(wr, tr, lr)
+	res, code := ts.ReverseTransaction(context.Background(), 1)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(2), res.TransactionId)
+	assert.Len(t, posted, 1)
+	assert.Equal(t, domain.EntryReversal, posted[0].Kind)
+	assert.Equal(t, int64(2), posted[0].TransactionId)
+	assert.Equal(t, []domain.Posting{
+		{Account: "to", Amount: -10},
+		{Account: "from", Amount: 10},
+	}, posted[0].Postings)
+}
+
+func TestTransactionService_ReverseTransaction_FeeNotRefunded(t *testing.T) {
+	original := &domain.Transaction{Id: 1, From: "from", To: "to", Amount: 10, Fee: 1}
+	wr, tr := newReverseRepos(original, map[string]domain.Money{"from": 89, "to": 110})
+	var posted []domain.JournalEntry
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			posted = append(posted, entry)
+			return 1, nil
+		},
+	}
+	ts := newTSWithLedger(wr, tr, lr)
+	_, code := ts.ReverseTransaction(context.Background(), 1)
+	assert.Equal(t, domain.CodeOK, code)
+	// Отправителю возвращается только сумма перевода, проводок по кошельку-сборщику нет
+	assert.Len(t, posted, 1)
+	assert.Equal(t, []domain.Posting{
+		{Account: "to", Amount: -10},
+		{Account: "from", Amount: 10},
+	}, posted[0].Postings)
+}
+
+func TestTransactionService_ReverseTransaction_NotFound(t *testing.T) {
+	wr, tr := newReverseRepos(nil, nil)
+	ts := newTS(wr, tr)
+	_, code := ts.ReverseTransaction(context.Background(), 1)
+	assert.Equal(t, domain.CodeTransactionNotFound, code)
+}
+
+func TestTransactionService_ReverseTransaction_RecipientInsufficientFunds(t *testing.T) {
+	original := &domain.Transaction{Id: 1, From: "from", To: "to", Amount: 10}
+	wr, tr := newReverseRepos(original, map[string]domain.Money{"from": 90, "to": 5})
+	tr.CreateReversalTxFunc = func(ctx context.Context, tx domain.TxExecutor, original *domain.Transaction) (int64, error) {
+		t.Fatal("reversal must not be created")
+		return 0, nil
+	}
+	ts := newTS(wr, tr)
+	_, code := ts.ReverseTransaction(context.Background(), 1)
+	assert.Equal(t, domain.CodeInsufficientFunds, code)
+}
+
+func TestTransactionService_ReverseTransaction_AlreadyReversed(t *testing.T) {
+	original := &domain.Transaction{Id: 1, From: "from", To: "to", Amount: 10}
+	wr, tr := newReverseRepos(original, map[string]domain.Money{"from": 90, "to": 110})
+	tr.CreateReversalTxFunc = func(ctx context.Context, tx domain.TxExecutor, original *domain.Transaction) (int64, error) {
+		return 0, domain.ErrAlreadyReversed
+	}
+	ts := newTS(wr, tr)
+	_, code := ts.ReverseTransaction(context.Background(), 1)
+	assert.Equal(t, domain.CodeAlreadyReversed, code)
+}
+
+func TestTransactionService_ReverseTransaction_ReversalOfReversal(t *testing.T) {
+	reversal := &domain.Transaction{Id: 2, From: "to", To: "from", Amount: 10, ReversalOf: 1}
+	wr, tr := newReverseRepos(reversal, map[string]domain.Money{"from": 100, "to": 100})
+	ts := newTS(wr, tr)
+	_, code := ts.ReverseTransaction(context.Background(), 2)
+	assert.Equal(t, domain.CodeInvalidTransaction, code)
+}
+
+func TestTransactionService_ReverseTransaction_WalletRemoved(t *testing.T) {
+	original := &domain.Transaction{Id: 1, From: "from", To: "to", Amount: 10}
+	wr, tr := newReverseRepos(original, map[string]domain.Money{"to": 110})
+	ts := newTS(wr, tr)
+	_, code := ts.ReverseTransaction(context.Background(), 1)
+	assert.Equal(t, domain.CodeWalletNotFound, code)
+}
+
+func TestTransactionService_ReverseTransaction_InternalErrorOnPost(t *testing.T) {
+	original := &domain.Transaction{Id: 1, From: "from", To: "to", Amount: 10}
+	wr, tr := newReverseRepos(original, map[string]domain.Money{"from": 90, "to": 110})
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			return 0, errors.New("fail")
+		},
+	}
+	ts := newTSWithLedger(wr, tr, lr)
+	_, code := ts.ReverseTransaction(context.Background(), 1)
+	assert.Equal(t, domain.CodeInternal, code)
+}
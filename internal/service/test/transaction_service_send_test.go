@@ -8,206 +8,232 @@ import (
 	"testing"
 )
 
-func TestTransactionService_SendMoney_SelfTransfer(t *testing.T) {
-	wr := &MockWalletRepository{
-		GetWalletBalanceFunc: func(ctx context.Context, address string) (float64, error) {
-			return 100, nil
-		},
-		UpdateWalletBalanceTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error {
-			return errors.New("fail")
-		},
-	}
-
-	tr := &MockTransactionRepository{
+func newBeginTx() *MockTransactionRepository {
+	return &MockTransactionRepository{
 		BeginTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
 			return &MockTxExecutor{}, nil
 		},
+		CreateTransactionTxFunc: func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+			return 1, nil
+		},
 	}
+}
 
-	ts := newTS(wr, tr)
-	code := ts.SendMoney(context.Background(), "addr", "addr", 10)
+func TestTransactionService_SendMoney_SelfTransfer(t *testing.T) {
+	ts := newTS(&MockWalletRepository{}, newBeginTx())
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "addr", To: "addr", Amount: 10})
 	assert.Equal(t, domain.CodeInvalidTransaction, code)
 }
 
 func TestTransactionService_SendMoney_NegativeAmount(t *testing.T) {
-	wr := &MockWalletRepository{
-		GetWalletBalanceFunc: func(ctx context.Context, address string) (float64, error) {
-			return 100, nil
-		},
-		UpdateWalletBalanceTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error {
-			return errors.New("fail")
-		},
-	}
-
-	tr := &MockTransactionRepository{
-		BeginTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
-			return &MockTxExecutor{}, nil
-		},
-	}
-
-	ts := newTS(wr, tr)
-	code := ts.SendMoney(context.Background(), "from", "to", -1)
+	ts := newTS(&MockWalletRepository{}, newBeginTx())
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: -1})
 	assert.Equal(t, domain.CodeNegativeAmount, code)
 }
 
 func TestTransactionService_SendMoney_SenderNotFound(t *testing.T) {
 	wr := &MockWalletRepository{
-		GetWalletBalanceFunc: func(ctx context.Context, address string) (float64, error) {
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
 			if address == "from" {
-				return 0, domain.ErrNotFound
+				return nil, domain.ErrNotFound
 			}
-			return 100, nil
-		},
-
-		UpdateWalletBalanceTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error {
-			return errors.New("fail")
-		},
-	}
-
-	tr := &MockTransactionRepository{
-		BeginTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
-			return &MockTxExecutor{}, nil
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: 100}, nil
 		},
 	}
-
-	ts := newTS(wr, tr)
-
-	code := ts.SendMoney(context.Background(), "from", "to", 10)
+	ts := newTS(wr, newBeginTx())
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
 	assert.Equal(t, domain.CodeWalletNotFound, code)
 }
 
 func TestTransactionService_SendMoney_ReceiverNotFound(t *testing.T) {
-	calls := 0
 	wr := &MockWalletRepository{
-		GetWalletBalanceFunc: func(ctx context.Context, address string) (float64, error) {
-			calls++
-			if calls == 1 {
-				return 100, nil
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			if address == "to" {
+				return nil, domain.ErrNotFound
 			}
-			return 0, domain.ErrNotFound
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: 100}, nil
 		},
 	}
-	ts := newTS(wr, &MockTransactionRepository{})
-	code := ts.SendMoney(context.Background(), "from", "to", 10)
+	ts := newTS(wr, newBeginTx())
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
 	assert.Equal(t, domain.CodeWalletNotFound, code)
 }
 
-func TestTransactionService_SendMoney_InsufficientFunds(t *testing.T) {
+func TestTransactionService_SendMoney_LocksInAddressOrder(t *testing.T) {
+	var locked []string
 	wr := &MockWalletRepository{
-		GetWalletBalanceFunc: func(ctx context.Context, address string) (float64, error) {
-			return 5, nil
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			locked = append(locked, address)
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: 100}, nil
 		},
 	}
-	ts := newTS(wr, &MockTransactionRepository{})
-	code := ts.SendMoney(context.Background(), "from", "to", 10)
-	assert.Equal(t, domain.CodeInsufficientFunds, code)
+	ts := newTS(wr, newBeginTx())
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "zzz", To: "aaa", Amount: 10})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, []string{"aaa", "zzz"}, locked)
 }
 
-func TestTransactionService_SendMoney_InternalErrorOnSender(t *testing.T) {
+func TestTransactionService_SendMoney_InsufficientFunds(t *testing.T) {
 	wr := &MockWalletRepository{
-		GetWalletBalanceFunc: func(ctx context.Context, address string) (float64, error) {
-			return 0, errors.New("fail")
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: 5}, nil
 		},
 	}
-	ts := newTS(wr, &MockTransactionRepository{})
-	code := ts.SendMoney(context.Background(), "from", "to", 10)
-	assert.Equal(t, domain.CodeInternal, code)
+	ts := newTS(wr, newBeginTx())
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
+	assert.Equal(t, domain.CodeInsufficientFunds, code)
+}
+
+func TestTransactionService_SendMoney_HeldFundsUnavailable(t *testing.T) {
+	// Баланс 100 покрывает перевод, но 95 из них зарезервировано
+	ts := newTS(newHoldWalletRepo(map[string]domain.Money{"from": domain.MustParseMoney("95")}), newBeginTx())
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: domain.MustParseMoney("10")})
+	assert.Equal(t, domain.CodeInsufficientFunds, code)
 }
 
-func TestTransactionService_SendMoney_InternalErrorOnReceiver(t *testing.T) {
-	calls := 0
+func TestTransactionService_SendMoney_InternalErrorOnLock(t *testing.T) {
 	wr := &MockWalletRepository{
-		GetWalletBalanceFunc: func(ctx context.Context, address string) (float64, error) {
-			calls++
-			if calls == 1 {
-				return 100, nil
-			}
-			return 0, errors.New("fail")
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return nil, errors.New("fail")
 		},
 	}
-	ts := newTS(wr, &MockTransactionRepository{})
-	code := ts.SendMoney(context.Background(), "from", "to", 10)
+	ts := newTS(wr, newBeginTx())
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
+	assert.Equal(t, domain.CodeInternal, code)
+}
+
+func TestTransactionService_SendMoney_InternalErrorOnBegin(t *testing.T) {
+	ts := newTS(&MockWalletRepository{}, &MockTransactionRepository{})
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
 	assert.Equal(t, domain.CodeInternal, code)
 }
 
-func TestTransactionService_SendMoney_InternalErrorOnUpdate(t *testing.T) {
+func TestTransactionService_SendMoney_InternalErrorOnPost(t *testing.T) {
 	wr := &MockWalletRepository{
-		GetWalletBalanceFunc: func(ctx context.Context, address string) (float64, error) {
-			return 100, nil
-		},
-		UpdateWalletBalanceTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error {
-			return errors.New("fail")
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: 100}, nil
 		},
 	}
-	tr := &MockTransactionRepository{
-		BeginTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
-			return &MockTxExecutor{}, nil
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			return 0, errors.New("fail")
 		},
 	}
-
-	ts := newTS(wr, tr)
-	code := ts.SendMoney(context.Background(), "from", "to", 10)
+	ts := newTSWithLedger(wr, newBeginTx(), lr)
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
 	assert.Equal(t, domain.CodeInternal, code)
 }
 
 func TestTransactionService_SendMoney_InternalErrorOnCreateTx(t *testing.T) {
 	wr := &MockWalletRepository{
-		GetWalletBalanceFunc: func(ctx context.Context, address string) (float64, error) {
-			return 100, nil
-		},
-		UpdateWalletBalanceTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error {
-			return nil
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: 100}, nil
 		},
 	}
-	tr := &MockTransactionRepository{
-		CreateTransactionTxFunc: func(ctx context.Context, tx domain.TxExecutor, from, to string, amount float64) (int64, error) {
-			return 0, errors.New("fail")
-		},
+	tr := newBeginTx()
+	tr.CreateTransactionTxFunc = func(ctx context.Context, tx domain.TxExecutor, from, to string, amount, fee domain.Money, details domain.TransferDetails) (int64, error) {
+		return 0, errors.New("fail")
 	}
 	ts := newTS(wr, tr)
-	code := ts.SendMoney(context.Background(), "from", "to", 10)
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
 	assert.Equal(t, domain.CodeInternal, code)
 }
 
 func TestTransactionService_SendMoney_InternalErrorOnCommit(t *testing.T) {
 	wr := &MockWalletRepository{
-		GetWalletBalanceFunc: func(ctx context.Context, address string) (float64, error) {
-			return 100, nil
-		},
-		UpdateWalletBalanceTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error {
-			return nil
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: 100}, nil
 		},
 	}
-	tr := &MockTransactionRepository{
-		CreateTransactionTxFunc: func(ctx context.Context, tx domain.TxExecutor, from, to string, amount float64) (int64, error) {
-			return 1, nil
-		},
+	tr := newBeginTx()
+	tr.BeginTXFunc = func(ctx context.Context) (domain.TxExecutor, error) {
+		return &MockTxExecutor{CommitErr: errors.New("fail")}, nil
 	}
 	ts := newTS(wr, tr)
-	// эмулируем ошибку коммита через мок транзакции, если нужно
-	// здесь предполагается, что BeginTX и tx.Commit будут замоканы в реальном тесте
-	code := ts.SendMoney(context.Background(), "from", "to", 10)
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
 	assert.Equal(t, domain.CodeInternal, code)
 }
 
 func TestTransactionService_SendMoney_Success(t *testing.T) {
+	var posted []domain.JournalEntry
 	wr := &MockWalletRepository{
-		GetWalletBalanceFunc: func(ctx context.Context, address string) (float64, error) {
-			return 100, nil
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: 100}, nil
 		},
-		UpdateWalletBalanceTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error {
-			return nil
+	}
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			posted = append(posted, entry)
+			return 1, nil
 		},
 	}
-	tr := &MockTransactionRepository{
-		BeginTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
-			return &MockTxExecutor{}, nil
+	ts := newTSWithLedger(wr, newBeginTx(), lr)
+	res, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(1), res.TransactionId)
+	assert.Len(t, posted, 1)
+	assert.Equal(t, domain.EntryTransfer, posted[0].Kind)
+	assert.Equal(t, int64(1), posted[0].TransactionId)
+	assert.Equal(t, []domain.Posting{
+		{Account: "from", Currency: "USD", Amount: -10},
+		{Account: "to", Currency: "USD", Amount: 10},
+	}, posted[0].Postings)
+	assert.NoError(t, posted[0].Validate())
+}
+
+func TestTransactionService_SendMoney_AmountOverflow(t *testing.T) {
+	ts := newTS(&MockWalletRepository{}, &MockTransactionRepository{})
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: domain.MaxMoney + 1})
+	assert.Equal(t, domain.CodeAmountOverflow, code)
+}
+
+func TestTransactionService_SendMoney_ReceiverOverflow(t *testing.T) {
+	wr := &MockWalletRepository{
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: 100}, nil
 		},
-		CreateTransactionTxFunc: func(ctx context.Context, tx domain.TxExecutor, from, to string, amount float64) (int64, error) {
-			return 1, nil
+	}
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			return 0, domain.ErrAmountOverflow
+		},
+	}
+	ts := newTSWithLedger(wr, newBeginTx(), lr)
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
+	assert.Equal(t, domain.CodeAmountOverflow, code)
+}
+
+func TestTransactionService_SendMoney_RetryOnConflict(t *testing.T) {
+	attempts := 0
+	wr := &MockWalletRepository{
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: 100}, nil
 		},
 	}
+	tr := newBeginTx()
+	tr.BeginTXFunc = func(ctx context.Context) (domain.TxExecutor, error) {
+		attempts++
+		if attempts < 3 {
+			return &MockTxExecutor{CommitErr: domain.ErrTransactionConflict}, nil
+		}
+		return &MockTxExecutor{}, nil
+	}
 	ts := newTS(wr, tr)
-	code := ts.SendMoney(context.Background(), "from", "to", 10)
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
 	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestTransactionService_SendMoney_RetryBudgetExhausted(t *testing.T) {
+	attempts := 0
+	wr := &MockWalletRepository{
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			attempts++
+			return nil, domain.ErrTransactionConflict
+		},
+	}
+	ts := newTS(wr, newBeginTx())
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
+	assert.Equal(t, domain.CodeTransactionConflict, code)
+	assert.Equal(t, 4, attempts) // первая попытка + MaxRetries
 }
@@ -0,0 +1,177 @@
+package test
+
+import (
+	"TransactionTest/config"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+	"TransactionTest/internal/service"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+const testTreasury = "treasury"
+
+// testTreasuryWallets казначейство только в USD
+func testTreasuryWallets() *domain.Treasury {
+	treasury, err := domain.NewTreasury([]domain.TreasuryWallet{{Currency: "USD", Address: testTreasury}}, testCurrencies())
+	if err != nil {
+		panic(err)
+	}
+	return treasury
+}
+
+func newTreasuryService(wr service.IWalletRepository, tr service.ITransactionRepository, lr service.ILedgerRepository, limits domain.LimitPolicy) *service.TreasuryService {
+	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(zap.FatalLevel)
+	log, _ := logger.New(&cfg)
+	return service.NewTreasuryService(wr, tr, lr, testCurrencies(), testTreasuryWallets(), limits, config.TransferConfig{MaxRetries: 3}, log)
+}
+
+// newTreasuryWalletRepo клиентские кошельки с заданными балансами и казначейство USD
+func newTreasuryWalletRepo(balances map[string]domain.Money, currency string) *MockWalletRepository {
+	wallet := func(address string) *domain.Wallet {
+		if address == testTreasury {
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: balances[address], Status: domain.WalletActive, Treasury: true}
+		}
+		return &domain.Wallet{Address: address, Currency: currency, Balance: balances[address], Status: domain.WalletActive}
+	}
+	return &MockWalletRepository{
+		GetWalletFunc: func(ctx context.Context, address string) (*domain.Wallet, error) {
+			if _, ok := balances[address]; !ok {
+				return nil, domain.ErrNotFound
+			}
+			return wallet(address), nil
+		},
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return wallet(address), nil
+		},
+	}
+}
+
+// newFundsTxRepo запоминает тип и участников созданной транзакции
+func newFundsTxRepo(kind *domain.TransactionKind, from, to *string) *MockTransactionRepository {
+	return &MockTransactionRepository{
+		BeginTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
+			return &MockTxExecutor{}, nil
+		},
+		CreateFundsTxFunc: func(ctx context.Context, tx domain.TxExecutor, k domain.TransactionKind, f, t string, amount domain.Money, details domain.TransferDetails) (int64, error) {
+			*kind, *from, *to = k, f, t
+			return 7, nil
+		},
+	}
+}
+
+func TestTreasuryService_Deposit(t *testing.T) {
+	m := domain.MustParseMoney
+	var kind domain.TransactionKind
+	var from, to string
+	var posted domain.JournalEntry
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			posted = entry
+			return 1, nil
+		},
+	}
+	wr := newTreasuryWalletRepo(map[string]domain.Money{"addr": 0, testTreasury: 0}, "USD")
+	ts := newTreasuryService(wr, newFundsTxRepo(&kind, &from, &to), lr, domain.LimitPolicy{})
+
+	res, code := ts.Deposit(context.Background(), domain.FundsRequest{Address: "addr", Amount: m("25")})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(7), res.TransactionId)
+	assert.Equal(t, domain.KindDeposit, kind)
+	assert.Equal(t, testTreasury, from)
+	assert.Equal(t, "addr", to)
+	assert.Equal(t, domain.EntryDeposit, posted.Kind)
+	assert.Equal(t, int64(7), posted.TransactionId)
+}
+
+func TestTreasuryService_Withdraw(t *testing.T) {
+	m := domain.MustParseMoney
+	var kind domain.TransactionKind
+	var from, to string
+	// Казначейство уже в минусе: это выданные клиентам деньги, вывод возвращает их обратно
+	wr := newTreasuryWalletRepo(map[string]domain.Money{"addr": m("30"), testTreasury: m("-30")}, "USD")
+	ts := newTreasuryService(wr, newFundsTxRepo(&kind, &from, &to), &MockLedgerRepository{}, domain.LimitPolicy{})
+
+	_, code := ts.Withdraw(context.Background(), domain.FundsRequest{Address: "addr", Amount: m("30")})
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, domain.KindWithdrawal, kind)
+	assert.Equal(t, "addr", from)
+	assert.Equal(t, testTreasury, to)
+}
+
+func TestTreasuryService_Withdraw_InsufficientFunds(t *testing.T) {
+	m := domain.MustParseMoney
+	var kind domain.TransactionKind
+	var from, to string
+	wr := newTreasuryWalletRepo(map[string]domain.Money{"addr": m("30"), testTreasury: 0}, "USD")
+	ts := newTreasuryService(wr, newFundsTxRepo(&kind, &from, &to), &MockLedgerRepository{}, domain.LimitPolicy{})
+
+	_, code := ts.Withdraw(context.Background(), domain.FundsRequest{Address: "addr", Amount: m("30.01")})
+	assert.Equal(t, domain.CodeInsufficientFunds, code)
+}
+
+func TestTreasuryService_Deposit_MaxBalance(t *testing.T) {
+	m := domain.MustParseMoney
+	var kind domain.TransactionKind
+	var from, to string
+	wr := newTreasuryWalletRepo(map[string]domain.Money{"addr": m("90"), testTreasury: 0}, "USD")
+	limits := domain.LimitPolicy{Defaults: domain.WalletLimits{MaxBalance: m("100")}}
+	ts := newTreasuryService(wr, newFundsTxRepo(&kind, &from, &to), &MockLedgerRepository{}, limits)
+
+	res, code := ts.Deposit(context.Background(), domain.FundsRequest{Address: "addr", Amount: m("10.01")})
+	assert.Equal(t, domain.CodeLimitExceeded, code)
+	assert.Equal(t, domain.LimitMaxBalance, res.ExceededLimit)
+}
+
+func TestTreasuryService_NoTreasuryForCurrency(t *testing.T) {
+	wr := newTreasuryWalletRepo(map[string]domain.Money{"addr": 0}, "EUR")
+	ts := newTreasuryService(wr, &MockTransactionRepository{}, &MockLedgerRepository{}, domain.LimitPolicy{})
+
+	_, code := ts.Deposit(context.Background(), domain.FundsRequest{Address: "addr", Amount: 1})
+	assert.Equal(t, domain.CodeNoTreasury, code)
+}
+
+func TestTreasuryService_TreasuryWallet(t *testing.T) {
+	wr := newTreasuryWalletRepo(map[string]domain.Money{testTreasury: 0}, "USD")
+	ts := newTreasuryService(wr, &MockTransactionRepository{}, &MockLedgerRepository{}, domain.LimitPolicy{})
+
+	_, code := ts.Withdraw(context.Background(), domain.FundsRequest{Address: testTreasury, Amount: 1})
+	assert.Equal(t, domain.CodeTreasuryWallet, code)
+}
+
+func TestTreasuryService_Validation(t *testing.T) {
+	ts := newTreasuryService(&MockWalletRepository{}, &MockTransactionRepository{}, &MockLedgerRepository{}, domain.LimitPolicy{})
+
+	_, code := ts.Deposit(context.Background(), domain.FundsRequest{Address: "addr", Amount: 0})
+	assert.Equal(t, domain.CodeNegativeAmount, code)
+
+	wr := newTreasuryWalletRepo(map[string]domain.Money{}, "USD")
+	ts = newTreasuryService(wr, &MockTransactionRepository{}, &MockLedgerRepository{}, domain.LimitPolicy{})
+	_, code = ts.Deposit(context.Background(), domain.FundsRequest{Address: "addr", Amount: 1})
+	assert.Equal(t, domain.CodeWalletNotFound, code)
+}
+
+func TestTreasuryService_SetupWallets(t *testing.T) {
+	var got []string
+	wr := &MockWalletRepository{
+		EnsureTreasuryWalletFunc: func(ctx context.Context, address, currency string) error {
+			got = append(got, currency+":"+address)
+			return nil
+		},
+	}
+	ts := newTreasuryService(wr, &MockTransactionRepository{}, &MockLedgerRepository{}, domain.LimitPolicy{})
+
+	assert.NoError(t, ts.SetupWallets(context.Background()))
+	assert.Equal(t, []string{"USD:" + testTreasury}, got)
+}
+
+func TestTransactionService_SendMoney_TreasuryWallet(t *testing.T) {
+	wr := newTreasuryWalletRepo(map[string]domain.Money{"addr": 0, testTreasury: 0}, "USD")
+	ts := newTS(wr, newBeginTx())
+
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: testTreasury, To: "addr", Amount: 1})
+	assert.Equal(t, domain.CodeTreasuryWallet, code)
+}
@@ -0,0 +1,58 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWalletService_GetBalanceAt_Success(t *testing.T) {
+	at := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	lr := &MockLedgerRepository{
+		GetBalanceAtFunc: func(ctx context.Context, address string, gotAt time.Time) (domain.Money, error) {
+			assert.Equal(t, "addr", address)
+			assert.Equal(t, at, gotAt)
+			return domain.MustParseMoney("42"), nil
+		},
+	}
+	balance, code := newWSWithLedger(&MockWalletRepository{}, lr).GetBalanceAt(context.Background(), "addr", at)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, domain.MustParseMoney("42"), balance)
+}
+
+func TestWalletService_GetBalanceAt_WalletNotFound(t *testing.T) {
+	lr := &MockLedgerRepository{
+		GetBalanceAtFunc: func(ctx context.Context, address string, at time.Time) (domain.Money, error) {
+			return 0, domain.ErrNotFound
+		},
+	}
+	_, code := newWSWithLedger(&MockWalletRepository{}, lr).GetBalanceAt(context.Background(), "addr", time.Now())
+	assert.Equal(t, domain.CodeWalletNotFound, code)
+}
+
+func TestWalletService_SnapshotBalances(t *testing.T) {
+	var gotLag time.Duration
+	lr := &MockLedgerRepository{
+		CreateBalanceSnapshotsFunc: func(ctx context.Context, lag time.Duration) (int64, error) {
+			gotLag = lag
+			return 5, nil
+		},
+	}
+	created, code := newWSWithLedger(&MockWalletRepository{}, lr).SnapshotBalances(context.Background())
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, int64(5), created)
+	assert.True(t, gotLag > 0)
+}
+
+func TestWalletService_SnapshotBalances_Error(t *testing.T) {
+	lr := &MockLedgerRepository{
+		CreateBalanceSnapshotsFunc: func(ctx context.Context, lag time.Duration) (int64, error) {
+			return 0, errors.Join(domain.ErrInternal, errors.New("db down"))
+		},
+	}
+	_, code := newWSWithLedger(&MockWalletRepository{}, lr).SnapshotBalances(context.Background())
+	assert.Equal(t, domain.CodeInternal, code)
+}
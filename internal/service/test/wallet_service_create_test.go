@@ -7,45 +7,179 @@ import (
 	"testing"
 )
 
+func newCreateWalletRepo(createErr error) *MockWalletRepository {
+	return &MockWalletRepository{
+		BeginTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
+			return &MockTxExecutor{}, nil
+		},
+		CreateWalletTxFunc: func(ctx context.Context, tx domain.TxExecutor, address, currency string, balance domain.Money) error {
+			return createErr
+		},
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			return &domain.Wallet{Address: address, Currency: "USD", Status: domain.WalletActive, Treasury: true}, nil
+		},
+	}
+}
+
+// newFundsRepo записывает пополнения, созданные при открытии кошелька
+func newFundsRepo(kinds *[]domain.TransactionKind) *MockTransactionRepository {
+	return &MockTransactionRepository{
+		CreateFundsTxFunc: func(ctx context.Context, tx domain.TxExecutor, kind domain.TransactionKind, from, to string, amount domain.Money, details domain.TransferDetails) (int64, error) {
+			*kinds = append(*kinds, kind)
+			return 5, nil
+		},
+	}
+}
+
 func TestWalletService_CreateWallet_Negative(t *testing.T) {
 	ws := newWS(&MockWalletRepository{})
-	addr, code := ws.CreateWallet(context.Background(), -1)
+	addr, code := ws.CreateWallet(context.Background(), "", -1)
 	assert.Equal(t, "", addr)
 	assert.Equal(t, domain.CodeNegativeBalance, code)
 }
 
 func TestWalletService_CreateWallet_Duplicate(t *testing.T) {
-	repo := &MockWalletRepository{
-		CreateWalletFunc: func(ctx context.Context, address string, balance float64) error {
-			return domain.ErrWalletAlreadyExists
-		},
-	}
-	ws := newWS(repo)
-	addr, code := ws.CreateWallet(context.Background(), 100)
+	ws := newWS(newCreateWalletRepo(domain.ErrWalletAlreadyExists))
+	addr, code := ws.CreateWallet(context.Background(), "", 100)
 	assert.Equal(t, "", addr)
 	assert.Equal(t, domain.CodeDuplicateWallet, code)
 }
 
 func TestWalletService_CreateWallet_Internal(t *testing.T) {
-	repo := &MockWalletRepository{
-		CreateWalletFunc: func(ctx context.Context, address string, balance float64) error {
-			return domain.ErrInternal
-		},
-	}
-	ws := newWS(repo)
-	addr, code := ws.CreateWallet(context.Background(), 100)
+	ws := newWS(newCreateWalletRepo(domain.ErrInternal))
+	addr, code := ws.CreateWallet(context.Background(), "", 100)
 	assert.Equal(t, "", addr)
 	assert.Equal(t, domain.CodeInternal, code)
 }
 
 func TestWalletService_CreateWallet_Success(t *testing.T) {
-	repo := &MockWalletRepository{
-		CreateWalletFunc: func(ctx context.Context, address string, balance float64) error {
-			return nil
+	var created domain.Money = -1
+	var posted []domain.JournalEntry
+	var kinds []domain.TransactionKind
+	repo := newCreateWalletRepo(nil)
+	repo.CreateWalletTxFunc = func(ctx context.Context, tx domain.TxExecutor, address, currency string, balance domain.Money) error {
+		created = balance
+		return nil
+	}
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			posted = append(posted, entry)
+			return 1, nil
 		},
 	}
-	ws := newWS(repo)
-	addr, code := ws.CreateWallet(context.Background(), 100)
+	ws := newWSWithTreasury(repo, lr, newFundsRepo(&kinds), testTreasuryWallets(), domain.LimitPolicy{})
+	addr, code := ws.CreateWallet(context.Background(), "", 100)
 	assert.NotEmpty(t, addr)
 	assert.Equal(t, domain.CodeOK, code)
+	// Начальный баланс зачисляется пополнением с казначейства, а не записью в wallets
+	assert.Equal(t, domain.Money(0), created)
+	assert.Equal(t, []domain.TransactionKind{domain.KindDeposit}, kinds)
+	assert.Len(t, posted, 1)
+	assert.Equal(t, domain.EntryDeposit, posted[0].Kind)
+	assert.Equal(t, int64(5), posted[0].TransactionId)
+	assert.Equal(t, []domain.Posting{
+		{Account: testTreasury, Currency: "USD", Amount: -100},
+		{Account: addr, Currency: "USD", Amount: 100},
+	}, posted[0].Postings)
+}
+
+func TestWalletService_CreateWallet_NoTreasury(t *testing.T) {
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			t.Fatal("balance without treasury must not be posted")
+			return 0, nil
+		},
+	}
+	// Казначейство есть только в USD
+	ws := newWSWithTreasury(newCreateWalletRepo(nil), lr, &MockTransactionRepository{}, testTreasuryWallets(), domain.LimitPolicy{})
+	addr, code := ws.CreateWallet(context.Background(), "EUR", 100)
+	assert.Equal(t, "", addr)
+	assert.Equal(t, domain.CodeNoTreasury, code)
+}
+
+func TestWalletService_CreateWallet_FrozenTreasury(t *testing.T) {
+	repo := newCreateWalletRepo(nil)
+	repo.GetWalletForUpdateTxFunc = func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+		return &domain.Wallet{Address: address, Currency: "USD", Status: domain.WalletFrozen, Treasury: true}, nil
+	}
+	ws := newWSWithTreasury(repo, &MockLedgerRepository{}, &MockTransactionRepository{}, testTreasuryWallets(), domain.LimitPolicy{})
+	_, code := ws.CreateWallet(context.Background(), "", 100)
+	assert.Equal(t, domain.CodeWalletFrozen, code)
+}
+
+func TestWalletService_CreateWallet_MaxBalance(t *testing.T) {
+	ws := newWSWithTreasury(&MockWalletRepository{}, &MockLedgerRepository{}, &MockTransactionRepository{}, testTreasuryWallets(),
+		domain.LimitPolicy{Defaults: domain.WalletLimits{MaxBalance: 50}})
+	_, code := ws.CreateWallet(context.Background(), "", 100)
+	assert.Equal(t, domain.CodeLimitExceeded, code)
+}
+
+func TestWalletService_CreateWallet_ZeroBalanceNoEntry(t *testing.T) {
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			t.Fatal("unexpected journal entry")
+			return 0, nil
+		},
+	}
+	ws := newWSWithLedger(newCreateWalletRepo(nil), lr)
+	_, code := ws.CreateWallet(context.Background(), "", 0)
+	assert.Equal(t, domain.CodeOK, code)
+}
+
+func TestWalletService_CreateWallet_PostError(t *testing.T) {
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			return 0, domain.ErrInternal
+		},
+	}
+	var kinds []domain.TransactionKind
+	ws := newWSWithTreasury(newCreateWalletRepo(nil), lr, newFundsRepo(&kinds), testTreasuryWallets(), domain.LimitPolicy{})
+	addr, code := ws.CreateWallet(context.Background(), "", 100)
+	assert.Equal(t, "", addr)
+	assert.Equal(t, domain.CodeInternal, code)
+}
+
+func TestWalletService_CreateWallet_Overflow(t *testing.T) {
+	ws := newWS(&MockWalletRepository{})
+	_, code := ws.CreateWallet(context.Background(), "", domain.MaxMoney+1)
+	assert.Equal(t, domain.CodeAmountOverflow, code)
+}
+
+func TestWalletService_CreateWallet_UnsupportedCurrency(t *testing.T) {
+	ws := newWS(&MockWalletRepository{})
+	addr, code := ws.CreateWallet(context.Background(), "XYZ", 100)
+	assert.Equal(t, "", addr)
+	assert.Equal(t, domain.CodeUnsupportedCurrency, code)
+}
+
+func TestWalletService_CreateWallet_CurrencyPrecision(t *testing.T) {
+	ws := newWS(&MockWalletRepository{})
+	_, code := ws.CreateWallet(context.Background(), "JPY", domain.MustParseMoney("10.50"))
+	assert.Equal(t, domain.CodeInvalidAmount, code)
+}
+
+func TestWalletService_CreateWallet_WithCurrency(t *testing.T) {
+	var currency string
+	repo := newCreateWalletRepo(nil)
+	repo.CreateWalletTxFunc = func(ctx context.Context, tx domain.TxExecutor, address, cur string, balance domain.Money) error {
+		currency = cur
+		return nil
+	}
+	ws := newWS(repo)
+	_, code := ws.CreateWallet(context.Background(), "EUR", 0)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, "EUR", currency)
+}
+
+func TestWalletService_CreateWallet_DefaultCurrency(t *testing.T) {
+	var currency string
+	repo := newCreateWalletRepo(nil)
+	repo.CreateWalletTxFunc = func(ctx context.Context, tx domain.TxExecutor, address, cur string, balance domain.Money) error {
+		currency = cur
+		return nil
+	}
+	ws := newWS(repo)
+	_, code := ws.CreateWallet(context.Background(), "", 0)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, "USD", currency)
 }
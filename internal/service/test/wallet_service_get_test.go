@@ -10,37 +10,38 @@ import (
 
 func TestWalletService_GetBalance_NotFound(t *testing.T) {
 	repo := &MockWalletRepository{
-		GetWalletBalanceFunc: func(ctx context.Context, address string) (float64, error) {
-			return 0, domain.ErrNotFound
+		GetWalletBalanceFunc: func(ctx context.Context, address string) (domain.Balance, error) {
+			return domain.Balance{}, domain.ErrNotFound
 		},
 	}
 	ws := newWS(repo)
 	bal, code := ws.GetBalance(context.Background(), "addr")
-	assert.Equal(t, float64(0), bal)
+	assert.Equal(t, domain.Balance{}, bal)
 	assert.Equal(t, domain.CodeWalletNotFound, code)
 }
 
 func TestWalletService_GetBalance_Internal(t *testing.T) {
 	repo := &MockWalletRepository{
-		GetWalletBalanceFunc: func(ctx context.Context, address string) (float64, error) {
-			return 0, errors.New("fail")
+		GetWalletBalanceFunc: func(ctx context.Context, address string) (domain.Balance, error) {
+			return domain.Balance{}, errors.New("fail")
 		},
 	}
 	ws := newWS(repo)
 	bal, code := ws.GetBalance(context.Background(), "addr")
-	assert.Equal(t, float64(0), bal)
+	assert.Equal(t, domain.Balance{}, bal)
 	assert.Equal(t, domain.CodeInternal, code)
 }
 
 func TestWalletService_GetBalance_Success(t *testing.T) {
 	repo := &MockWalletRepository{
-		GetWalletBalanceFunc: func(ctx context.Context, address string) (float64, error) {
-			return 123.45, nil
+		GetWalletBalanceFunc: func(ctx context.Context, address string) (domain.Balance, error) {
+			return domain.Balance{Total: domain.MustParseMoney("123.45"), Available: domain.MustParseMoney("100")}, nil
 		},
 	}
 	ws := newWS(repo)
 	bal, code := ws.GetBalance(context.Background(), "addr")
-	assert.Equal(t, 123.45, bal)
+	assert.Equal(t, domain.MustParseMoney("123.45"), bal.Total)
+	assert.Equal(t, domain.MustParseMoney("100"), bal.Available)
 	assert.Equal(t, domain.CodeOK, code)
 }
 
@@ -24,17 +24,24 @@ func TestWalletService_CreateWalletsForSeeding_BeginError(t *testing.T) {
 
 func TestWalletService_CreateWalletsForSeeding_Success(t *testing.T) {
 	calls := 0
+	var opened domain.Money
 	repo := &MockWalletRepository{
 		BeginTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
 			return &MockTxExecutor{}, nil
 		},
-		CreateWalletTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error {
+		CreateWalletTxFunc: func(ctx context.Context, tx domain.TxExecutor, address, currency string, balance domain.Money) error {
 			calls++
 			return nil
 		},
 	}
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			opened += entry.Postings[1].Amount
+			return 1, nil
+		},
+	}
 
-	ws := newWS(repo)
+	ws := newWSWithLedger(repo, lr)
 	done, errChan, ok := ws.CreateWalletsForSeeding(context.Background(), 2, 100, false)
 	assert.True(t, ok)
 	for range done {
@@ -43,6 +50,30 @@ func TestWalletService_CreateWalletsForSeeding_Success(t *testing.T) {
 		assert.NoError(t, err)
 	}
 	assert.Equal(t, 2, calls)
+	assert.Equal(t, domain.Money(200), opened)
+}
+
+func TestWalletService_CreateWalletsForSeeding_FundedFromTreasury(t *testing.T) {
+	var kinds []domain.TransactionKind
+	var entries []domain.EntryKind
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			entries = append(entries, entry.Kind)
+			return 1, nil
+		},
+	}
+
+	// С казначейством в валюте по умолчанию стартовые кошельки пополняются с него, как через API
+	ws := newWSWithTreasury(newCreateWalletRepo(nil), lr, newFundsRepo(&kinds), testTreasuryWallets(), domain.LimitPolicy{})
+	done, errChan, ok := ws.CreateWalletsForSeeding(context.Background(), 2, 100, false)
+	assert.True(t, ok)
+	for range done {
+	}
+	for err := range errChan {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []domain.TransactionKind{domain.KindDeposit, domain.KindDeposit}, kinds)
+	assert.Equal(t, []domain.EntryKind{domain.EntryDeposit, domain.EntryDeposit}, entries)
 }
 
 func TestWalletService_CreateWalletsForSeeding_CreateError(t *testing.T) {
@@ -50,7 +81,7 @@ func TestWalletService_CreateWalletsForSeeding_CreateError(t *testing.T) {
 		BeginTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
 			return &MockTxExecutor{}, nil
 		},
-		CreateWalletTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error {
+		CreateWalletTxFunc: func(ctx context.Context, tx domain.TxExecutor, address, currency string, balance domain.Money) error {
 			return errors.New("fail to create wallet")
 		},
 	}
@@ -73,7 +104,7 @@ func TestWalletService_CreateWalletsForSeeding_FailOnError(t *testing.T) {
 		BeginTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
 			return &MockTxExecutor{}, nil
 		},
-		CreateWalletTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string, balance float64) error {
+		CreateWalletTxFunc: func(ctx context.Context, tx domain.TxExecutor, address, currency string, balance domain.Money) error {
 			return errors.New("fail to create wallet")
 		},
 	}
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWalletService_GetStatement_Success(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	snapshot := &MockTxExecutor{}
+	tr := &MockTransactionRepository{
+		BeginSnapshotTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
+			return snapshot, nil
+		},
+		GetOpeningBalanceTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string, before time.Time) (string, domain.Money, error) {
+			assert.Same(t, snapshot, tx)
+			assert.Equal(t, from, before)
+			return "USD", domain.MustParseMoney("100"), nil
+		},
+		GetStatementLinesTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string, gotFrom, gotTo time.Time) ([]domain.StatementLine, error) {
+			// Баланс на начало и проводки читаются из одного снимка
+			assert.Same(t, snapshot, tx)
+			assert.Equal(t, from, gotFrom)
+			assert.Equal(t, to, gotTo)
+			return []domain.StatementLine{
+				{PostingId: 1, Amount: domain.MustParseMoney("-30")},
+				{PostingId: 2, Amount: domain.MustParseMoney("5")},
+			}, nil
+		},
+	}
+	ws := newWSWithTransactions(&MockWalletRepository{}, &MockLedgerRepository{}, tr, domain.LimitPolicy{})
+
+	statement, code := ws.GetStatement(context.Background(), "addr", from, to)
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, "USD", statement.Currency)
+	assert.Equal(t, domain.MustParseMoney("75"), statement.ClosingBalance)
+	assert.Equal(t, domain.MustParseMoney("5"), statement.TotalCredits)
+	assert.Equal(t, domain.MustParseMoney("30"), statement.TotalDebits)
+	assert.Equal(t, domain.MustParseMoney("70"), statement.Lines[0].Balance)
+}
+
+func TestWalletService_GetStatement_EmptyPeriod(t *testing.T) {
+	at := time.Now()
+	ws := newWSWithTransactions(&MockWalletRepository{}, &MockLedgerRepository{}, &MockTransactionRepository{}, domain.LimitPolicy{})
+	_, code := ws.GetStatement(context.Background(), "addr", at, at)
+	assert.Equal(t, domain.CodeInvalidPeriod, code)
+}
+
+func TestWalletService_GetStatement_WalletNotFound(t *testing.T) {
+	tr := &MockTransactionRepository{
+		GetOpeningBalanceTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string, before time.Time) (string, domain.Money, error) {
+			return "", 0, domain.ErrNotFound
+		},
+	}
+	ws := newWSWithTransactions(&MockWalletRepository{}, &MockLedgerRepository{}, tr, domain.LimitPolicy{})
+	_, code := ws.GetStatement(context.Background(), "addr", time.Now().Add(-time.Hour), time.Now())
+	assert.Equal(t, domain.CodeWalletNotFound, code)
+}
+
+func TestWalletService_GetStatement_BeginFails(t *testing.T) {
+	tr := &MockTransactionRepository{
+		BeginSnapshotTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
+			return nil, errors.New("db down")
+		},
+	}
+	ws := newWSWithTransactions(&MockWalletRepository{}, &MockLedgerRepository{}, tr, domain.LimitPolicy{})
+	_, code := ws.GetStatement(context.Background(), "addr", time.Now().Add(-time.Hour), time.Now())
+	assert.Equal(t, domain.CodeInternal, code)
+}
@@ -0,0 +1,166 @@
+package test
+
+import (
+	"TransactionTest/internal/domain"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// newStatusWalletRepo кошельки с заданными статусами и балансом 100, смены статуса записываются в changes
+func newStatusWalletRepo(statuses map[string]domain.WalletStatus, changes *[]domain.WalletStatusChange) *MockWalletRepository {
+	return &MockWalletRepository{
+		BeginTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
+			return &MockTxExecutor{}, nil
+		},
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			status, ok := statuses[address]
+			if !ok {
+				return nil, domain.ErrNotFound
+			}
+			balance := domain.Money(100)
+			if status == domain.WalletClosed {
+				balance = 0
+			}
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: balance, Status: status}, nil
+		},
+		ChangeWalletStatusTxFunc: func(ctx context.Context, tx domain.TxExecutor, change domain.WalletStatusChange) (*domain.WalletStatusChange, error) {
+			change.Id = int64(len(*changes) + 1)
+			*changes = append(*changes, change)
+			return &change, nil
+		},
+	}
+}
+
+func TestWalletService_FreezeWallet_Success(t *testing.T) {
+	var changes []domain.WalletStatusChange
+	ws := newWS(newStatusWalletRepo(map[string]domain.WalletStatus{"addr": domain.WalletActive}, &changes))
+
+	change, code := ws.FreezeWallet(context.Background(), "addr", "suspicious activity")
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, domain.WalletActive, change.FromStatus)
+	assert.Equal(t, domain.WalletFrozen, change.ToStatus)
+	assert.Equal(t, "suspicious activity", change.Reason)
+	assert.Len(t, changes, 1)
+}
+
+func TestWalletService_FreezeWallet_ReasonRequired(t *testing.T) {
+	var changes []domain.WalletStatusChange
+	ws := newWS(newStatusWalletRepo(map[string]domain.WalletStatus{"addr": domain.WalletActive}, &changes))
+
+	_, code := ws.FreezeWallet(context.Background(), "addr", "")
+	assert.Equal(t, domain.CodeInvalidRequestBody, code)
+	assert.Empty(t, changes)
+}
+
+func TestWalletService_FreezeWallet_NotFound(t *testing.T) {
+	var changes []domain.WalletStatusChange
+	ws := newWS(newStatusWalletRepo(map[string]domain.WalletStatus{}, &changes))
+
+	_, code := ws.FreezeWallet(context.Background(), "addr", "reason")
+	assert.Equal(t, domain.CodeWalletNotFound, code)
+}
+
+func TestWalletService_UnfreezeWallet_NotFrozen(t *testing.T) {
+	var changes []domain.WalletStatusChange
+	ws := newWS(newStatusWalletRepo(map[string]domain.WalletStatus{"addr": domain.WalletActive}, &changes))
+
+	_, code := ws.UnfreezeWallet(context.Background(), "addr", "reason")
+	assert.Equal(t, domain.CodeInvalidWalletStatus, code)
+	assert.Empty(t, changes)
+}
+
+func TestWalletService_UnfreezeWallet_Success(t *testing.T) {
+	var changes []domain.WalletStatusChange
+	ws := newWS(newStatusWalletRepo(map[string]domain.WalletStatus{"addr": domain.WalletFrozen}, &changes))
+
+	change, code := ws.UnfreezeWallet(context.Background(), "addr", "checked")
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, domain.WalletActive, change.ToStatus)
+}
+
+func TestWalletService_CloseWallet_NotEmpty(t *testing.T) {
+	var changes []domain.WalletStatusChange
+	ws := newWS(newStatusWalletRepo(map[string]domain.WalletStatus{"addr": domain.WalletActive}, &changes))
+
+	_, code := ws.CloseWallet(context.Background(), "addr", "customer request")
+	assert.Equal(t, domain.CodeWalletNotEmpty, code)
+	assert.Empty(t, changes)
+}
+
+func TestWalletService_CloseWallet_ActiveHolds(t *testing.T) {
+	var changes []domain.WalletStatusChange
+	wr := newStatusWalletRepo(map[string]domain.WalletStatus{"addr": domain.WalletActive}, &changes)
+	wr.GetWalletForUpdateTxFunc = func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+		return &domain.Wallet{Address: address, Currency: "USD", Balance: 0, Held: 30, OverdraftLimit: 50, Status: domain.WalletActive}, nil
+	}
+	ws := newWS(wr)
+
+	_, code := ws.CloseWallet(context.Background(), "addr", "customer request")
+	assert.Equal(t, domain.CodeWalletHasHolds, code)
+	assert.Empty(t, changes)
+}
+
+func TestWalletService_CloseWallet_FrozenNotAllowed(t *testing.T) {
+	var changes []domain.WalletStatusChange
+	ws := newWS(newStatusWalletRepo(map[string]domain.WalletStatus{"addr": domain.WalletFrozen}, &changes))
+
+	_, code := ws.CloseWallet(context.Background(), "addr", "customer request")
+	assert.Equal(t, domain.CodeInvalidWalletStatus, code)
+}
+
+func TestWalletService_CloseWallet_Success(t *testing.T) {
+	var changes []domain.WalletStatusChange
+	wr := newStatusWalletRepo(map[string]domain.WalletStatus{"addr": domain.WalletActive}, &changes)
+	wr.GetWalletForUpdateTxFunc = func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+		return &domain.Wallet{Address: address, Currency: "USD", Balance: 0, Status: domain.WalletActive}, nil
+	}
+	ws := newWS(wr)
+
+	change, code := ws.CloseWallet(context.Background(), "addr", "customer request")
+	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, domain.WalletClosed, change.ToStatus)
+}
+
+func TestWalletService_RemoveWallet_HasHistory(t *testing.T) {
+	repo := &MockWalletRepository{
+		RemoveWalletFunc: func(ctx context.Context, address string) error {
+			return domain.ErrWalletHasHistory
+		},
+	}
+
+	ws := newWS(repo)
+	code := ws.RemoveWallet(context.Background(), "addr")
+	assert.Equal(t, domain.CodeWalletHasHistory, code)
+}
+
+func TestTransactionService_SendMoney_ReceiverFrozen(t *testing.T) {
+	var changes []domain.WalletStatusChange
+	wr := newStatusWalletRepo(map[string]domain.WalletStatus{"from": domain.WalletActive, "to": domain.WalletFrozen}, &changes)
+	ts := newTS(wr, newBeginTx())
+
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
+	assert.Equal(t, domain.CodeWalletFrozen, code)
+}
+
+func TestTransactionService_SendMoney_SenderClosed(t *testing.T) {
+	var changes []domain.WalletStatusChange
+	wr := newStatusWalletRepo(map[string]domain.WalletStatus{"from": domain.WalletClosed, "to": domain.WalletActive}, &changes)
+	ts := newTS(wr, newBeginTx())
+
+	_, code := ts.SendMoney(context.Background(), domain.TransferRequest{From: "from", To: "to", Amount: 10})
+	assert.Equal(t, domain.CodeWalletClosed, code)
+}
+
+func TestTransactionService_SendBatch_FrozenLeg(t *testing.T) {
+	var changes []domain.WalletStatusChange
+	wr := newStatusWalletRepo(map[string]domain.WalletStatus{"a": domain.WalletActive, "b": domain.WalletActive, "c": domain.WalletFrozen}, &changes)
+	ts := newTS(wr, newBeginTx())
+
+	res, code := ts.SendBatch(context.Background(), []domain.TransferLeg{
+		{From: "a", To: "b", Amount: 10},
+		{From: "a", To: "c", Amount: 10},
+	})
+	assert.Equal(t, domain.CodeBatchRejected, code)
+	assert.Equal(t, []domain.LegError{{Index: 1, Code: domain.CodeWalletFrozen}}, res.LegErrors)
+}
@@ -8,41 +8,106 @@ import (
 	"testing"
 )
 
+func newUpdateBalanceRepo(balance domain.Money, lockErr error) *MockWalletRepository {
+	return &MockWalletRepository{
+		BeginTXFunc: func(ctx context.Context) (domain.TxExecutor, error) {
+			return &MockTxExecutor{}, nil
+		},
+		GetWalletForUpdateTxFunc: func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+			if lockErr != nil {
+				return nil, lockErr
+			}
+			return &domain.Wallet{Address: address, Currency: "USD", Balance: balance}, nil
+		},
+	}
+}
+
 func TestWalletService_UpdateBalance_Negative(t *testing.T) {
 	ws := newWS(&MockWalletRepository{})
-	code := ws.UpdateBalance(context.Background(), "addr", -1)
+	_, code := ws.UpdateBalance(context.Background(), "addr", -1, "correction")
 	assert.Equal(t, domain.CodeNegativeBalance, code)
 }
 
 func TestWalletService_UpdateBalance_NotFound(t *testing.T) {
-	repo := &MockWalletRepository{
-		UpdateWalletBalanceFunc: func(ctx context.Context, address string, balance float64) error {
-			return domain.ErrNotFound
-		},
-	}
-	ws := newWS(repo)
-	code := ws.UpdateBalance(context.Background(), "addr", 100)
+	ws := newWS(newUpdateBalanceRepo(0, domain.ErrNotFound))
+	_, code := ws.UpdateBalance(context.Background(), "addr", 100, "correction")
 	assert.Equal(t, domain.CodeWalletNotFound, code)
 }
 
 func TestWalletService_UpdateBalance_Internal(t *testing.T) {
-	repo := &MockWalletRepository{
-		UpdateWalletBalanceFunc: func(ctx context.Context, address string, balance float64) error {
-			return errors.New("fail")
+	ws := newWS(newUpdateBalanceRepo(0, errors.New("fail")))
+	_, code := ws.UpdateBalance(context.Background(), "addr", 100, "correction")
+	assert.Equal(t, domain.CodeInternal, code)
+}
+
+func TestWalletService_UpdateBalance_PostError(t *testing.T) {
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			return 0, errors.New("fail")
 		},
 	}
-	ws := newWS(repo)
-	code := ws.UpdateBalance(context.Background(), "addr", 100)
+	ws := newWSWithLedger(newUpdateBalanceRepo(30, nil), lr)
+	_, code := ws.UpdateBalance(context.Background(), "addr", 100, "correction")
 	assert.Equal(t, domain.CodeInternal, code)
 }
 
 func TestWalletService_UpdateBalance_Success(t *testing.T) {
-	repo := &MockWalletRepository{
-		UpdateWalletBalanceFunc: func(ctx context.Context, address string, balance float64) error {
-			return nil
+	var posted []domain.JournalEntry
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			posted = append(posted, entry)
+			return 1, nil
 		},
 	}
-	ws := newWS(repo)
-	code := ws.UpdateBalance(context.Background(), "addr", 100)
+	ws := newWSWithLedger(newUpdateBalanceRepo(130, nil), lr)
+	adjustment, code := ws.UpdateBalance(context.Background(), "addr", 100, "correction")
 	assert.Equal(t, domain.CodeOK, code)
+	assert.Equal(t, domain.BalanceAdjustment{Address: "addr", Previous: 130, New: 100, Reason: "correction", EntryId: 1}, *adjustment)
+	// Корректировка проводится на разницу между новым и текущим балансом
+	assert.Len(t, posted, 1)
+	assert.Equal(t, domain.EntryAdjustment, posted[0].Kind)
+	assert.Equal(t, []domain.Posting{
+		{Account: domain.AccountEquity, Currency: "USD", Amount: 30},
+		{Account: "addr", Currency: "USD", Amount: -30},
+	}, posted[0].Postings)
+}
+
+func TestWalletService_UpdateBalance_Unchanged(t *testing.T) {
+	lr := &MockLedgerRepository{
+		PostEntryTxFunc: func(ctx context.Context, tx domain.TxExecutor, entry domain.JournalEntry) (int64, error) {
+			t.Fatal("unexpected journal entry")
+			return 0, nil
+		},
+	}
+	ws := newWSWithLedger(newUpdateBalanceRepo(100, nil), lr)
+	adjustment, code := ws.UpdateBalance(context.Background(), "addr", 100, "correction")
+	assert.Equal(t, domain.CodeOK, code)
+	// Корректировка без изменения баланса тоже попадает в аудит, но без записи журнала
+	assert.Equal(t, int64(0), adjustment.EntryId)
+}
+
+func TestWalletService_UpdateBalance_ReasonRequired(t *testing.T) {
+	ws := newWS(&MockWalletRepository{})
+	_, code := ws.UpdateBalance(context.Background(), "addr", 100, "")
+	assert.Equal(t, domain.CodeInvalidRequestBody, code)
+}
+
+func TestWalletService_UpdateBalance_Treasury(t *testing.T) {
+	wr := newUpdateBalanceRepo(0, nil)
+	wr.GetWalletForUpdateTxFunc = func(ctx context.Context, tx domain.TxExecutor, address string) (*domain.Wallet, error) {
+		return &domain.Wallet{Address: address, Currency: "USD", Treasury: true}, nil
+	}
+	ws := newWS(wr)
+	_, code := ws.UpdateBalance(context.Background(), "addr", 100, "correction")
+	assert.Equal(t, domain.CodeTreasuryWallet, code)
+}
+
+func TestWalletService_UpdateBalance_AuditError(t *testing.T) {
+	wr := newUpdateBalanceRepo(100, nil)
+	wr.CreateBalanceAdjustmentTxFunc = func(ctx context.Context, tx domain.TxExecutor, adjustment domain.BalanceAdjustment) (*domain.BalanceAdjustment, error) {
+		return nil, errors.New("fail")
+	}
+	ws := newWS(wr)
+	_, code := ws.UpdateBalance(context.Background(), "addr", 100, "correction")
+	assert.Equal(t, domain.CodeInternal, code)
 }
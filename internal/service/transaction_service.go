@@ -3,103 +3,592 @@ package service
 import (
 	"context"
 	"errors"
+	"sort"
 	"time"
 
+	"TransactionTest/config"
 	"TransactionTest/internal/domain"
 	"TransactionTest/internal/logger"
 
 	"go.uber.org/zap"
 )
 
+// defaultIdempotencyTTL используется, если срок хранения ключей не задан в конфигурации
+const defaultIdempotencyTTL = 24 * time.Hour
+
 type TransactionService struct {
 	transactionRepo ITransactionRepository
 	walletRepo      IWalletRepository
+	ledgerRepo      ILedgerRepository
+	idempotencyRepo IIdempotencyRepository
+	currencies      *domain.CurrencyRegistry
+	rates           ExchangeRateProvider
+	fees            *domain.FeeSchedules
+	limits          transferLimits
+	cfg             config.TransferConfig
+	metrics         TransferMetrics
 	log             logger.Logger
 }
 
-func NewTransactionService(tr ITransactionRepository, wr IWalletRepository, l logger.Logger) *TransactionService {
+// noTransferMetrics используется, когда метрики не подключены
+type noTransferMetrics struct{}
+
+func (noTransferMetrics) ObserveTransfer(domain.ErrorCode, domain.Money) {}
+
+// NewTransactionService создает сервис переводов. metrics может быть nil, тогда переводы не учитываются.
+func NewTransactionService(tr ITransactionRepository, wr IWalletRepository, lr ILedgerRepository, ir IIdempotencyRepository, currencies *domain.CurrencyRegistry, rates ExchangeRateProvider, fees *domain.FeeSchedules, limits domain.LimitPolicy, cfg config.TransferConfig, metrics TransferMetrics, l logger.Logger) *TransactionService {
+	if cfg.IdempotencyTTL <= 0 {
+		cfg.IdempotencyTTL = defaultIdempotencyTTL
+	}
+	if metrics == nil {
+		metrics = noTransferMetrics{}
+	}
 	return &TransactionService{
 		transactionRepo: tr,
 		walletRepo:      wr,
+		ledgerRepo:      lr,
+		idempotencyRepo: ir,
+		currencies:      currencies,
+		rates:           rates,
+		fees:            fees,
+		limits:          transferLimits{walletRepo: wr, transactionRepo: tr, policy: limits, log: l},
+		cfg:             cfg,
+		metrics:         metrics,
 		log:             l,
 	}
 }
 
-func (ts *TransactionService) SendMoney(ctx context.Context, from, to string, amount float64) domain.ErrorCode {
-	if from == to {
+// SendMoney переводит средства между кошельками.
+// Если в запросе указан ключ идемпотентности, повтор с тем же ключом и теми же данными
+// вернет результат первого перевода, а с другими данными — CodeIdempotencyMismatch.
+// При CodeLimitExceeded возвращается результат, в котором ExceededLimit - нарушенный лимит кошелька.
+// Результат каждого вызова учитывается в метриках переводов, кроме повторов по ключу идемпотентности:
+// перевод уже учтен при первом вызове.
+func (ts *TransactionService) SendMoney(ctx context.Context, req domain.TransferRequest) (*domain.TransferResult, domain.ErrorCode) {
+	result, code := ts.sendMoney(ctx, req)
+	if result == nil || !result.Replayed {
+		ts.metrics.ObserveTransfer(code, req.Amount)
+	}
+	return result, code
+}
+
+func (ts *TransactionService) sendMoney(ctx context.Context, req domain.TransferRequest) (*domain.TransferResult, domain.ErrorCode) {
+	if req.From == req.To {
 		ts.log.Warn(ctx, "SendMoney: self transfer not allowed")
-		return domain.CodeInvalidTransaction
+		return nil, domain.CodeInvalidTransaction
 	}
-	if amount <= 0 {
+	if req.Amount <= 0 {
 		ts.log.Warn(ctx, "SendMoney: amount must be positive")
-		return domain.CodeNegativeAmount
+		return nil, domain.CodeNegativeAmount
+	}
+	if err := req.Amount.Check(); err != nil {
+		ts.log.Warn(ctx, "SendMoney: amount out of range", zap.Stringer("amount", req.Amount))
+		return nil, domain.CodeAmountOverflow
 	}
 
-	fromBalance, err := ts.walletRepo.GetWalletBalance(ctx, from)
-	if err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			ts.log.Warn(ctx, "SendMoney: sender wallet not found", zap.Error(err))
-			return domain.CodeWalletNotFound
-		}
-		ts.log.Error(ctx, "SendMoney: failed to get sender balance", zap.Error(err))
-		return domain.CodeInternal
+	var result *domain.TransferResult
+	code := ts.withRetry(ctx, "SendMoney", func() domain.ErrorCode {
+		var attemptCode domain.ErrorCode
+		result, attemptCode = ts.sendMoneyTx(ctx, req)
+		return attemptCode
+	})
+	if code == domain.CodeLimitExceeded {
+		return result, code
 	}
-	if fromBalance < amount {
-		ts.log.Warn(ctx, "SendMoney: insufficient funds", zap.Float64("balance", fromBalance), zap.Float64("amount", amount))
-		return domain.CodeInsufficientFunds
+	if code != domain.CodeOK {
+		return nil, code
 	}
 
-	toBalance, err := ts.walletRepo.GetWalletBalance(ctx, to)
-	if err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			ts.log.Warn(ctx, "SendMoney: receiver wallet not found", zap.Error(err))
-			return domain.CodeWalletNotFound
-		}
-		ts.log.Error(ctx, "SendMoney: failed to get receiver balance", zap.Error(err))
-		return domain.CodeInternal
+	if result.Replayed {
+		ts.log.Info(ctx, "SendMoney: replayed result for idempotency key",
+			zap.String("idempotency_key", req.IdempotencyKey),
+			zap.Int64("transaction_id", result.TransactionId))
+		return result, domain.CodeOK
 	}
 
-	newFromBalance := fromBalance - amount
-	newToBalance := toBalance + amount
+	ts.log.Info(ctx, "SendMoney: transaction completed successfully",
+		zap.Int64("transaction_id", result.TransactionId),
+		zap.String("from", req.From),
+		zap.String("to", req.To),
+		zap.Stringer("amount", req.Amount))
+	return result, domain.CodeOK
+}
+
+// sendMoneyTx выполняет одну попытку перевода в отдельной транзакции БД.
+// Оба кошелька блокируются в порядке возрастания адреса, поэтому встречные переводы не создают deadlock.
+// Кошелек-сборщик комиссии блокируется последним: его адрес зависит от валюты отправителя.
+func (ts *TransactionService) sendMoneyTx(ctx context.Context, req domain.TransferRequest) (*domain.TransferResult, domain.ErrorCode) {
+	from, to, amount := req.From, req.To, req.Amount
 
 	tx, err := ts.transactionRepo.BeginTX(ctx)
 	if err != nil {
 		ts.log.Error(ctx, "SendMoney: failed to begin transaction", zap.Error(err))
-		return domain.CodeInternal
+		return nil, domain.CodeInternal
 	}
+	committed := false
 	defer func() {
-		if err != nil {
+		if !committed {
 			tx.Rollback(ctx)
 		}
 	}()
 
-	err = ts.walletRepo.UpdateWalletBalanceTx(ctx, tx, from, newFromBalance)
-	if err != nil {
-		ts.log.Error(ctx, "SendMoney: failed to update sender balance", zap.Error(err))
-		return domain.CodeInternal
+	if req.IdempotencyKey != "" {
+		record, err := ts.idempotencyRepo.ReserveKeyTx(ctx, tx, req.IdempotencyKey, req.Fingerprint(), ts.cfg.IdempotencyTTL)
+		if err != nil {
+			if errors.Is(err, domain.ErrTransactionConflict) {
+				ts.log.Warn(ctx, "SendMoney: conflict while reserving idempotency key", zap.Error(err))
+				return nil, domain.CodeTransactionConflict
+			}
+			ts.log.Error(ctx, "SendMoney: failed to reserve idempotency key", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+		if record != nil {
+			if record.RequestHash != req.Fingerprint() {
+				ts.log.Warn(ctx, "SendMoney: idempotency key reused with different payload",
+					zap.String("idempotency_key", req.IdempotencyKey))
+				return nil, domain.CodeIdempotencyMismatch
+			}
+			return ts.replayedResult(ctx, tx, record.TransactionId)
+		}
 	}
-	err = ts.walletRepo.UpdateWalletBalanceTx(ctx, tx, to, newToBalance)
-	if err != nil {
-		ts.log.Error(ctx, "SendMoney: failed to update receiver balance", zap.Error(err))
-		return domain.CodeInternal
+
+	wallets := make(map[string]*domain.Wallet, 2)
+	for _, address := range lockOrder(from, to) {
+		wallet, err := ts.walletRepo.GetWalletForUpdateTx(ctx, tx, address)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				ts.log.Warn(ctx, "SendMoney: wallet not found", zap.String("address", address), zap.Error(err))
+				return nil, domain.CodeWalletNotFound
+			case errors.Is(err, domain.ErrTransactionConflict):
+				ts.log.Warn(ctx, "SendMoney: conflict while locking wallet", zap.String("address", address), zap.Error(err))
+				return nil, domain.CodeTransactionConflict
+			default:
+				ts.log.Error(ctx, "SendMoney: failed to lock wallet", zap.String("address", address), zap.Error(err))
+				return nil, domain.CodeInternal
+			}
+		}
+		wallets[address] = wallet
+	}
+
+	sender, receiver := wallets[from], wallets[to]
+	for _, wallet := range []*domain.Wallet{sender, receiver} {
+		if code := transferWalletCode(wallet); code != domain.CodeOK {
+			ts.log.Warn(ctx, "SendMoney: wallet can not take part in transfer",
+				zap.String("address", wallet.Address), zap.String("status", string(wallet.Status)), zap.Bool("treasury", wallet.Treasury))
+			return nil, code
+		}
+	}
+	if code := checkCurrencyAmount(ts.currencies, sender.Currency, amount); code != domain.CodeOK {
+		ts.log.Warn(ctx, "SendMoney: amount not allowed for currency",
+			zap.String("currency", sender.Currency), zap.Stringer("amount", amount))
+		return nil, code
+	}
+
+	var exchange *domain.Exchange
+	if sender.Currency != receiver.Currency {
+		if !req.Convert {
+			ts.log.Warn(ctx, "SendMoney: wallets have different currencies",
+				zap.String("from_currency", sender.Currency), zap.String("to_currency", receiver.Currency))
+			return nil, domain.CodeCurrencyMismatch
+		}
+		var code domain.ErrorCode
+		if exchange, code = ts.quote(ctx, sender.Currency, receiver.Currency, amount); code != domain.CodeOK {
+			return nil, code
+		}
+	}
+
+	fee, collector, code := ts.quoteFee(ctx, tx, sender, amount)
+	if code != domain.CodeOK {
+		return nil, code
+	}
+
+	// Зарезервированные средства недоступны для перевода
+	if sender.Available() < amount+fee {
+		ts.log.Warn(ctx, "SendMoney: insufficient funds",
+			zap.Stringer("available", sender.Available()), zap.Stringer("amount", amount), zap.Stringer("fee", fee))
+		return nil, domain.CodeInsufficientFunds
+	}
+
+	credited := amount
+	if exchange != nil {
+		credited = exchange.ToAmount
+	}
+	if limit, code := ts.limits.check(ctx, tx, "SendMoney", sender, receiver, amount, fee, credited, domain.Outflow{}); code != domain.CodeOK {
+		if code == domain.CodeLimitExceeded {
+			return &domain.TransferResult{ExceededLimit: limit}, code
+		}
+		return nil, code
+	}
+
+	var transactionId int64
+	if exchange != nil {
+		transactionId, err = ts.transactionRepo.CreateExchangeTx(ctx, tx, from, to, amount, fee, *exchange, req.Details)
+	} else {
+		transactionId, err = ts.transactionRepo.CreateTransactionTx(ctx, tx, from, to, amount, fee, req.Details)
 	}
-	_, err = ts.transactionRepo.CreateTransactionTx(ctx, tx, from, to, amount)
 	if err != nil {
-		ts.log.Error(ctx, "SendMoney: failed to create transaction record", zap.Error(err))
-		return domain.CodeInternal
+		switch {
+		case errors.Is(err, domain.ErrTransactionConflict):
+			ts.log.Warn(ctx, "SendMoney: conflict while creating transaction record", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		case errors.Is(err, domain.ErrDuplicateReference):
+			ts.log.Warn(ctx, "SendMoney: external reference already used", zap.Error(err))
+			return nil, domain.CodeDuplicateReference
+		default:
+			ts.log.Error(ctx, "SendMoney: failed to create transaction record", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
 	}
+
+	transaction := domain.Transaction{Id: transactionId, From: from, To: to, Amount: amount, Currency: sender.Currency, Exchange: exchange, Fee: fee}
+	entry := transaction.Entry(domain.EntryTransfer).WithFee(from, collector, sender.Currency, fee)
+	if _, err = ts.ledgerRepo.PostEntryTx(ctx, tx, entry); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInsufficientFunds):
+			ts.log.Warn(ctx, "SendMoney: insufficient funds", zap.Error(err))
+			return nil, domain.CodeInsufficientFunds
+		case errors.Is(err, domain.ErrAmountOverflow):
+			ts.log.Warn(ctx, "SendMoney: receiver balance out of range", zap.Error(err))
+			return nil, domain.CodeAmountOverflow
+		case errors.Is(err, domain.ErrTransactionConflict):
+			ts.log.Warn(ctx, "SendMoney: conflict while posting journal entry", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		default:
+			ts.log.Error(ctx, "SendMoney: failed to post journal entry", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+
+	if req.IdempotencyKey != "" {
+		if err = ts.idempotencyRepo.CompleteKeyTx(ctx, tx, req.IdempotencyKey, transactionId); err != nil {
+			if errors.Is(err, domain.ErrTransactionConflict) {
+				ts.log.Warn(ctx, "SendMoney: conflict while storing idempotency key", zap.Error(err))
+				return nil, domain.CodeTransactionConflict
+			}
+			ts.log.Error(ctx, "SendMoney: failed to store idempotency key", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+
 	if err = tx.Commit(ctx); err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			ts.log.Warn(ctx, "SendMoney: conflict on commit", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		}
 		ts.log.Error(ctx, "SendMoney: failed to commit transaction", zap.Error(err))
-		return domain.CodeInternal
+		return nil, domain.CodeInternal
 	}
+	committed = true
 
-	ts.log.Info(ctx, "SendMoney: transaction completed successfully",
-		zap.String("from", from),
-		zap.String("to", to),
-		zap.Float64("amount", amount))
+	return &domain.TransferResult{TransactionId: transactionId, Exchange: exchange, Fee: fee}, domain.CodeOK
+}
+
+// replayedResult восстанавливает результат первого перевода по ключу идемпотентности:
+// комиссию и детали конвертации берет из сохраненной транзакции, чтобы повтор вернул тот же ответ
+func (ts *TransactionService) replayedResult(ctx context.Context, tx domain.TxExecutor, transactionId int64) (*domain.TransferResult, domain.ErrorCode) {
+	original, err := ts.transactionRepo.GetTransactionTx(ctx, tx, transactionId)
+	if err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			ts.log.Warn(ctx, "SendMoney: conflict while reading replayed transaction", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		}
+		ts.log.Error(ctx, "SendMoney: failed to read replayed transaction",
+			zap.Int64("transaction_id", transactionId), zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	return &domain.TransferResult{
+		TransactionId: original.Id,
+		Exchange:      original.Exchange,
+		Fee:           original.Fee,
+		Replayed:      true,
+	}, domain.CodeOK
+}
+
+// quoteFee рассчитывает комиссию за перевод amount с кошелька sender по тарифу его валюты
+// и блокирует кошелек-сборщик. Возвращает нулевую комиссию, если тарифа нет или отправитель сам сборщик.
+func (ts *TransactionService) quoteFee(ctx context.Context, tx domain.TxExecutor, sender *domain.Wallet, amount domain.Money) (domain.Money, string, domain.ErrorCode) {
+	fee, collector, err := ts.fees.Fee(sender.Currency, amount)
+	if err != nil {
+		ts.log.Warn(ctx, "SendMoney: fee out of range", zap.Stringer("amount", amount), zap.Error(err))
+		return 0, "", domain.CodeAmountOverflow
+	}
+	if fee == 0 || collector == sender.Address {
+		return 0, "", domain.CodeOK
+	}
+
+	wallet, err := ts.walletRepo.GetWalletForUpdateTx(ctx, tx, collector)
+	if err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			ts.log.Warn(ctx, "SendMoney: conflict while locking fee collector", zap.Error(err))
+			return 0, "", domain.CodeTransactionConflict
+		}
+		// Сборщик задается в конфигурации, поэтому его отсутствие - ошибка настройки, а не клиента
+		ts.log.Error(ctx, "SendMoney: failed to lock fee collector", zap.String("collector", collector), zap.Error(err))
+		return 0, "", domain.CodeInternal
+	}
+	if wallet.Currency != sender.Currency {
+		ts.log.Error(ctx, "SendMoney: fee collector has different currency",
+			zap.String("collector", collector), zap.String("currency", wallet.Currency))
+		return 0, "", domain.CodeInternal
+	}
+	return fee, collector, domain.CodeOK
+}
+
+// quote рассчитывает сумму зачисления для перевода между валютами: курс провайдера уменьшается на спред,
+// а сконвертированная сумма округляется вниз до минимальной единицы валюты получателя.
+func (ts *TransactionService) quote(ctx context.Context, from, to string, amount domain.Money) (*domain.Exchange, domain.ErrorCode) {
+	if ts.rates == nil {
+		ts.log.Warn(ctx, "SendMoney: exchange rates are not configured")
+		return nil, domain.CodeRateUnavailable
+	}
+
+	rate, err := ts.rates.GetRate(ctx, from, to)
+	if err != nil {
+		if errors.Is(err, domain.ErrRateNotFound) {
+			ts.log.Warn(ctx, "SendMoney: exchange rate not found", zap.String("from", from), zap.String("to", to))
+			return nil, domain.CodeRateUnavailable
+		}
+		ts.log.Error(ctx, "SendMoney: failed to get exchange rate", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+
+	target, err := ts.currencies.Lookup(to)
+	if err != nil {
+		ts.log.Warn(ctx, "SendMoney: receiver currency is not supported", zap.String("currency", to))
+		return nil, domain.CodeUnsupportedCurrency
+	}
+
+	effective := rate.Rate.WithSpread(ts.cfg.ExchangeSpreadBps)
+	converted, err := effective.Convert(amount)
+	if err != nil {
+		ts.log.Warn(ctx, "SendMoney: converted amount out of range", zap.Stringer("amount", amount))
+		return nil, domain.CodeAmountOverflow
+	}
+	converted = target.Floor(converted)
+	if converted <= 0 {
+		ts.log.Warn(ctx, "SendMoney: amount too small to convert", zap.Stringer("amount", amount))
+		return nil, domain.CodeInvalidAmount
+	}
+
+	return &domain.Exchange{
+		ToCurrency: to,
+		ToAmount:   converted,
+		Rate:       effective,
+		RateAt:     rate.Timestamp,
+	}, domain.CodeOK
+}
+
+// SendBatch атомарно проводит пакет переводов: либо проходят все ноги, либо ни одна.
+// Если пакет отклонен, BatchResult.LegErrors содержит причины по каждой проблемной ноге.
+func (ts *TransactionService) SendBatch(ctx context.Context, legs []domain.TransferLeg) (*domain.BatchResult, domain.ErrorCode) {
+	if len(legs) == 0 {
+		ts.log.Warn(ctx, "SendBatch: empty batch")
+		return nil, domain.CodeInvalidTransaction
+	}
+
+	var legErrors []domain.LegError
+	for i, leg := range legs {
+		if code := validateLeg(leg); code != domain.CodeOK {
+			legErrors = append(legErrors, domain.LegError{Index: i, Code: code})
+		}
+	}
+	if len(legErrors) > 0 {
+		ts.log.Warn(ctx, "SendBatch: batch validation failed", zap.Int("rejected_legs", len(legErrors)))
+		return &domain.BatchResult{LegErrors: legErrors}, domain.CodeBatchRejected
+	}
+
+	var result *domain.BatchResult
+	code := ts.withRetry(ctx, "SendBatch", func() domain.ErrorCode {
+		var attemptCode domain.ErrorCode
+		result, attemptCode = ts.sendBatchTx(ctx, legs)
+		return attemptCode
+	})
+	if code != domain.CodeOK {
+		return result, code
+	}
+
+	ts.log.Info(ctx, "SendBatch: batch completed successfully", zap.Int("legs", len(legs)))
+	return result, domain.CodeOK
+}
+
+// validateLeg проверяет ногу пакета теми же правилами, что и одиночный перевод
+func validateLeg(leg domain.TransferLeg) domain.ErrorCode {
+	switch {
+	case leg.From == leg.To:
+		return domain.CodeInvalidTransaction
+	case leg.Amount <= 0:
+		return domain.CodeNegativeAmount
+	case leg.Amount.Check() != nil:
+		return domain.CodeAmountOverflow
+	}
 	return domain.CodeOK
 }
 
+// sendBatchTx выполняет одну попытку пакетного перевода в одной транзакции БД.
+// Все кошельки пакета блокируются заранее в порядке возрастания адреса, затем ноги
+// проверяются последовательно на текущих балансах с учетом предыдущих ног, включая лимиты кошельков.
+// Комиссии по тарифам (секция fees) берутся только с одиночных переводов SendMoney.
+func (ts *TransactionService) sendBatchTx(ctx context.Context, legs []domain.TransferLeg) (*domain.BatchResult, domain.ErrorCode) {
+	tx, err := ts.transactionRepo.BeginTX(ctx)
+	if err != nil {
+		ts.log.Error(ctx, "SendBatch: failed to begin transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	addresses := make([]string, 0, len(legs)*2)
+	seen := make(map[string]bool, len(legs)*2)
+	for _, leg := range legs {
+		for _, address := range []string{leg.From, leg.To} {
+			if !seen[address] {
+				seen[address] = true
+				addresses = append(addresses, address)
+			}
+		}
+	}
+
+	wallets := make(map[string]*domain.Wallet, len(addresses))
+	balances := make(map[string]domain.Money, len(addresses))
+	for _, address := range lockOrder(addresses...) {
+		wallet, err := ts.walletRepo.GetWalletForUpdateTx(ctx, tx, address)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				// Отсутствующий кошелек отмечается в ошибках ног ниже
+				continue
+			case errors.Is(err, domain.ErrTransactionConflict):
+				ts.log.Warn(ctx, "SendBatch: conflict while locking wallet", zap.String("address", address), zap.Error(err))
+				return nil, domain.CodeTransactionConflict
+			default:
+				ts.log.Error(ctx, "SendBatch: failed to lock wallet", zap.String("address", address), zap.Error(err))
+				return nil, domain.CodeInternal
+			}
+		}
+		wallets[address] = wallet
+		balances[address] = wallet.Balance
+	}
+
+	var legErrors []domain.LegError
+	pending := make(map[string]domain.Outflow, len(addresses))
+	for i, leg := range legs {
+		sender, fromFound := wallets[leg.From]
+		receiver, toFound := wallets[leg.To]
+		if !fromFound || !toFound {
+			legErrors = append(legErrors, domain.LegError{Index: i, Code: domain.CodeWalletNotFound})
+			continue
+		}
+		if code := transferWalletCode(sender); code != domain.CodeOK {
+			legErrors = append(legErrors, domain.LegError{Index: i, Code: code})
+			continue
+		}
+		if code := transferWalletCode(receiver); code != domain.CodeOK {
+			legErrors = append(legErrors, domain.LegError{Index: i, Code: code})
+			continue
+		}
+		if sender.Currency != receiver.Currency {
+			legErrors = append(legErrors, domain.LegError{Index: i, Code: domain.CodeCurrencyMismatch})
+			continue
+		}
+		if code := checkCurrencyAmount(ts.currencies, sender.Currency, leg.Amount); code != domain.CodeOK {
+			legErrors = append(legErrors, domain.LegError{Index: i, Code: code})
+			continue
+		}
+		switch {
+		case balances[leg.From]-sender.Held+sender.OverdraftLimit < leg.Amount:
+			legErrors = append(legErrors, domain.LegError{Index: i, Code: domain.CodeInsufficientFunds})
+		case (balances[leg.To] + leg.Amount).Check() != nil:
+			legErrors = append(legErrors, domain.LegError{Index: i, Code: domain.CodeAmountOverflow})
+		default:
+			// Лимиты проверяются на балансе получателя с учетом предыдущих ног,
+			// а предыдущие ноги отправителя входят в его дневные лимиты
+			legReceiver := *receiver
+			legReceiver.Balance = balances[leg.To]
+			limit, code := ts.limits.check(ctx, tx, "SendBatch", sender, &legReceiver, leg.Amount, 0, leg.Amount, pending[leg.From])
+			switch code {
+			case domain.CodeOK:
+				balances[leg.From] -= leg.Amount
+				balances[leg.To] += leg.Amount
+				pending[leg.From] = domain.Outflow{Amount: pending[leg.From].Amount + leg.Amount, Count: pending[leg.From].Count + 1}
+			case domain.CodeLimitExceeded:
+				legErrors = append(legErrors, domain.LegError{Index: i, Code: code, Limit: limit})
+			default:
+				return nil, code
+			}
+		}
+	}
+	if len(legErrors) > 0 {
+		ts.log.Warn(ctx, "SendBatch: batch rejected", zap.Int("rejected_legs", len(legErrors)))
+		return &domain.BatchResult{LegErrors: legErrors}, domain.CodeBatchRejected
+	}
+
+	transactionIds := make([]int64, 0, len(legs))
+	for i, leg := range legs {
+		transactionId, err := ts.transactionRepo.CreateTransactionTx(ctx, tx, leg.From, leg.To, leg.Amount, 0, domain.TransferDetails{})
+		if err == nil {
+			entry := domain.NewTransferEntry(domain.EntryTransfer, transactionId, leg.From, leg.To, wallets[leg.From].Currency, leg.Amount)
+			_, err = ts.ledgerRepo.PostEntryTx(ctx, tx, entry)
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrTransactionConflict):
+				ts.log.Warn(ctx, "SendBatch: conflict while applying leg", zap.Int("leg", i), zap.Error(err))
+				return nil, domain.CodeTransactionConflict
+			case errors.Is(err, domain.ErrInsufficientFunds):
+				ts.log.Warn(ctx, "SendBatch: insufficient funds", zap.Int("leg", i), zap.Error(err))
+				return &domain.BatchResult{LegErrors: []domain.LegError{{Index: i, Code: domain.CodeInsufficientFunds}}}, domain.CodeBatchRejected
+			case errors.Is(err, domain.ErrAmountOverflow):
+				ts.log.Warn(ctx, "SendBatch: balance out of range", zap.Int("leg", i), zap.Error(err))
+				return &domain.BatchResult{LegErrors: []domain.LegError{{Index: i, Code: domain.CodeAmountOverflow}}}, domain.CodeBatchRejected
+			default:
+				ts.log.Error(ctx, "SendBatch: failed to apply leg", zap.Int("leg", i), zap.Error(err))
+				return nil, domain.CodeInternal
+			}
+		}
+		transactionIds = append(transactionIds, transactionId)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			ts.log.Warn(ctx, "SendBatch: conflict on commit", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		}
+		ts.log.Error(ctx, "SendBatch: failed to commit transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed = true
+
+	return &domain.BatchResult{TransactionIds: transactionIds}, domain.CodeOK
+}
+
+// PurgeExpiredIdempotencyKeys удаляет ключи идемпотентности с истекшим сроком хранения
+func (ts *TransactionService) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, domain.ErrorCode) {
+	removed, err := ts.idempotencyRepo.RemoveExpiredKeys(ctx)
+	if err != nil {
+		ts.log.Error(ctx, "PurgeExpiredIdempotencyKeys", zap.Error(err))
+		return 0, domain.CodeInternal
+	}
+	ts.log.Info(ctx, "PurgeExpiredIdempotencyKeys: success", zap.Int64("removed", removed))
+	return removed, domain.CodeOK
+}
+
+// withRetry повторяет attempt, пока тот возвращает CodeTransactionConflict и не исчерпан бюджет повторов
+func (ts *TransactionService) withRetry(ctx context.Context, operation string, attempt func() domain.ErrorCode) domain.ErrorCode {
+	return withRetry(ctx, ts.log, ts.cfg, operation, attempt)
+}
+
+// lockOrder возвращает адреса в порядке, в котором их нужно блокировать
+func lockOrder(addresses ...string) []string {
+	ordered := append([]string(nil), addresses...)
+	sort.Strings(ordered)
+	return ordered
+}
+
 func (ts *TransactionService) GetLastTransactions(ctx context.Context, limit int) ([]domain.Transaction, domain.ErrorCode) {
 	if limit <= 0 {
 		ts.log.Warn(ctx, "GetLastTransactions: limit must be greater than zero")
@@ -115,6 +604,29 @@ func (ts *TransactionService) GetLastTransactions(ctx context.Context, limit int
 	return transactions, domain.CodeOK
 }
 
+// ListTransactions возвращает страницу истории транзакций по фильтру.
+// Незаданные порядок, роль кошелька и размер страницы заменяются значениями по умолчанию.
+func (ts *TransactionService) ListTransactions(ctx context.Context, filter domain.TransactionFilter) (*domain.TransactionPage, domain.ErrorCode) {
+	filter = filter.Normalize()
+	if err := filter.Validate(); err != nil {
+		ts.log.Warn(ctx, "ListTransactions: invalid filter", zap.Error(err))
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			return nil, domain.CodeInvalidCursor
+		}
+		return nil, domain.CodeInvalidFilter
+	}
+
+	page, err := ts.transactionRepo.ListTransactions(ctx, filter)
+	if err != nil {
+		ts.log.Error(ctx, "ListTransactions", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	ts.log.Info(ctx, "ListTransactions: success list transactions",
+		zap.Int("count", len(page.Transactions)),
+		zap.Bool("has_next", page.Next != nil))
+	return page, domain.CodeOK
+}
+
 func (ts *TransactionService) GetTransactionById(ctx context.Context, id int64) (*domain.Transaction, domain.ErrorCode) {
 	transaction, err := ts.transactionRepo.GetTransactionById(ctx, id)
 	if err != nil {
@@ -149,7 +661,166 @@ func (ts *TransactionService) GetTransactionByInfo(ctx context.Context, from, to
 	return transaction, domain.CodeOK
 }
 
+// FindTransactionsByExternalReference ищет переводы по внешнему идентификатору, from необязательный фильтр по отправителю.
+// Если ничего не найдено, возвращает пустой список.
+func (ts *TransactionService) FindTransactionsByExternalReference(ctx context.Context, reference, from string) ([]domain.Transaction, domain.ErrorCode) {
+	if reference == "" {
+		ts.log.Warn(ctx, "FindTransactionsByExternalReference: empty external reference")
+		return nil, domain.CodeInvalidRequestBody
+	}
+
+	transactions, err := ts.transactionRepo.GetTransactionsByExternalReference(ctx, reference, from)
+	if err != nil {
+		ts.log.Error(ctx, "FindTransactionsByExternalReference", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	ts.log.Info(ctx, "FindTransactionsByExternalReference: success find transactions",
+		zap.String("external_reference", reference),
+		zap.String("from", from),
+		zap.Int("count", len(transactions)))
+	return transactions, domain.CodeOK
+}
+
+// ReverseTransaction отменяет транзакцию встречным переводом, связанным с исходным.
+// Балансы восстанавливаются атомарно; если у получателя уже нет нужной суммы, отмена не проводится.
+// Возвращается только сумма перевода: комиссия исходного перевода остается у сборщика (см. domain.Transaction.Reversed).
+func (ts *TransactionService) ReverseTransaction(ctx context.Context, id int64) (*domain.TransferResult, domain.ErrorCode) {
+	var result *domain.TransferResult
+	code := ts.withRetry(ctx, "ReverseTransaction", func() domain.ErrorCode {
+		var attemptCode domain.ErrorCode
+		result, attemptCode = ts.reverseTransactionTx(ctx, id)
+		return attemptCode
+	})
+	if code != domain.CodeOK {
+		return nil, code
+	}
+
+	ts.log.Info(ctx, "ReverseTransaction: transaction reversed successfully",
+		zap.Int64("id", id),
+		zap.Int64("reversal_id", result.TransactionId))
+	return result, domain.CodeOK
+}
+
+func (ts *TransactionService) reverseTransactionTx(ctx context.Context, id int64) (*domain.TransferResult, domain.ErrorCode) {
+	tx, err := ts.transactionRepo.BeginTX(ctx)
+	if err != nil {
+		ts.log.Error(ctx, "ReverseTransaction: failed to begin transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	// Блокировка исходной транзакции сериализует конкурентные отмены
+	original, err := ts.transactionRepo.GetTransactionForUpdateTx(ctx, tx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			ts.log.Warn(ctx, "ReverseTransaction: transaction not found", zap.Int64("id", id))
+			return nil, domain.CodeTransactionNotFound
+		case errors.Is(err, domain.ErrTransactionConflict):
+			ts.log.Warn(ctx, "ReverseTransaction: conflict while locking transaction", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		default:
+			ts.log.Error(ctx, "ReverseTransaction: failed to lock transaction", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+	if original.IsReversal() {
+		ts.log.Warn(ctx, "ReverseTransaction: reversal cannot be reversed", zap.Int64("id", id))
+		return nil, domain.CodeInvalidTransaction
+	}
+
+	wallets := make(map[string]*domain.Wallet, 2)
+	for _, address := range lockOrder(original.From, original.To) {
+		wallet, err := ts.walletRepo.GetWalletForUpdateTx(ctx, tx, address)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				ts.log.Warn(ctx, "ReverseTransaction: wallet not found", zap.String("address", address), zap.Error(err))
+				return nil, domain.CodeWalletNotFound
+			case errors.Is(err, domain.ErrTransactionConflict):
+				ts.log.Warn(ctx, "ReverseTransaction: conflict while locking wallet", zap.String("address", address), zap.Error(err))
+				return nil, domain.CodeTransactionConflict
+			default:
+				ts.log.Error(ctx, "ReverseTransaction: failed to lock wallet", zap.String("address", address), zap.Error(err))
+				return nil, domain.CodeInternal
+			}
+		}
+		if code := walletStatusCode(wallet); code != domain.CodeOK {
+			ts.log.Warn(ctx, "ReverseTransaction: wallet is not active",
+				zap.String("address", address), zap.String("status", string(wallet.Status)))
+			return nil, code
+		}
+		wallets[address] = wallet
+	}
+
+	// При конвертации получатель возвращает зачисленную ему сумму в своей валюте.
+	// Отмена пополнения и вывода проходит через казначейство, которому хватает средств всегда.
+	reversal := original.Reversed()
+	if recipient := wallets[original.To]; !recipient.CanSpend(reversal.Amount) {
+		ts.log.Warn(ctx, "ReverseTransaction: recipient has insufficient funds",
+			zap.Stringer("available", recipient.Available()), zap.Stringer("amount", reversal.Amount))
+		return nil, domain.CodeInsufficientFunds
+	}
+
+	reversalId, err := ts.transactionRepo.CreateReversalTx(ctx, tx, original)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrAlreadyReversed):
+			ts.log.Warn(ctx, "ReverseTransaction: transaction already reversed", zap.Int64("id", id))
+			return nil, domain.CodeAlreadyReversed
+		case errors.Is(err, domain.ErrTransactionConflict):
+			ts.log.Warn(ctx, "ReverseTransaction: conflict while creating reversal", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		default:
+			ts.log.Error(ctx, "ReverseTransaction: failed to create reversal", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+
+	reversal.Id = reversalId
+	if _, err = ts.ledgerRepo.PostEntryTx(ctx, tx, reversal.Entry(domain.EntryReversal)); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInsufficientFunds):
+			ts.log.Warn(ctx, "ReverseTransaction: recipient has insufficient funds", zap.Error(err))
+			return nil, domain.CodeInsufficientFunds
+		case errors.Is(err, domain.ErrAmountOverflow):
+			ts.log.Warn(ctx, "ReverseTransaction: sender balance out of range", zap.Error(err))
+			return nil, domain.CodeAmountOverflow
+		case errors.Is(err, domain.ErrTransactionConflict):
+			ts.log.Warn(ctx, "ReverseTransaction: conflict while posting journal entry", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		default:
+			ts.log.Error(ctx, "ReverseTransaction: failed to post journal entry", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			ts.log.Warn(ctx, "ReverseTransaction: conflict on commit", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		}
+		ts.log.Error(ctx, "ReverseTransaction: failed to commit transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed = true
+
+	return &domain.TransferResult{TransactionId: reversalId}, domain.CodeOK
+}
+
+// RemoveTransaction физически удаляет транзакцию. Балансы и журнал не меняются,
+// поэтому операция доступна только в админском режиме (transfers.AdminHardDelete).
 func (ts *TransactionService) RemoveTransaction(ctx context.Context, id int64) domain.ErrorCode {
+	if !ts.cfg.AdminHardDelete {
+		ts.log.Warn(ctx, "RemoveTransaction: hard delete is disabled", zap.Int64("id", id))
+		return domain.CodeForbidden
+	}
+
 	err := ts.transactionRepo.RemoveTransaction(ctx, id)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"TransactionTest/config"
+	"TransactionTest/internal/domain"
+	"TransactionTest/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// TreasuryService пополнения и выводы средств. Деньги приходят в систему и уходят из нее
+// через кошелек казначейства в валюте кошелька: его баланс может быть отрицательным
+// и показывает, сколько средств выдано клиентам.
+type TreasuryService struct {
+	walletRepo      IWalletRepository
+	transactionRepo ITransactionRepository
+	ledgerRepo      ILedgerRepository
+	currencies      *domain.CurrencyRegistry
+	treasury        *domain.Treasury
+	limits          domain.LimitPolicy
+	cfg             config.TransferConfig
+	log             logger.Logger
+}
+
+func NewTreasuryService(wr IWalletRepository, tr ITransactionRepository, lr ILedgerRepository, currencies *domain.CurrencyRegistry, treasury *domain.Treasury, limits domain.LimitPolicy, cfg config.TransferConfig, l logger.Logger) *TreasuryService {
+	return &TreasuryService{
+		walletRepo:      wr,
+		transactionRepo: tr,
+		ledgerRepo:      lr,
+		currencies:      currencies,
+		treasury:        treasury,
+		limits:          limits,
+		cfg:             cfg,
+		log:             l,
+	}
+}
+
+// SetupWallets создает кошельки казначейства из конфигурации или помечает уже существующие.
+// Вызывается при старте после миграций.
+func (ts *TreasuryService) SetupWallets(ctx context.Context) error {
+	for _, w := range ts.treasury.Wallets() {
+		if err := ts.walletRepo.EnsureTreasuryWallet(ctx, w.Address, w.Currency); err != nil {
+			return err
+		}
+		ts.log.Info(ctx, "SetupWallets: treasury wallet ready", zap.String("address", w.Address), zap.String("currency", w.Currency))
+	}
+	return nil
+}
+
+// Deposit зачисляет средства на кошелек с кошелька казначейства его валюты.
+// При CodeLimitExceeded возвращается результат, в котором ExceededLimit - нарушенный лимит кошелька.
+func (ts *TreasuryService) Deposit(ctx context.Context, req domain.FundsRequest) (*domain.TransferResult, domain.ErrorCode) {
+	return ts.move(ctx, "Deposit", domain.KindDeposit, req)
+}
+
+// Withdraw списывает средства с кошелька на кошелек казначейства его валюты.
+// При CodeLimitExceeded возвращается результат, в котором ExceededLimit - нарушенный лимит кошелька.
+func (ts *TreasuryService) Withdraw(ctx context.Context, req domain.FundsRequest) (*domain.TransferResult, domain.ErrorCode) {
+	return ts.move(ctx, "Withdraw", domain.KindWithdrawal, req)
+}
+
+func (ts *TreasuryService) move(ctx context.Context, operation string, kind domain.TransactionKind, req domain.FundsRequest) (*domain.TransferResult, domain.ErrorCode) {
+	if req.Amount <= 0 {
+		ts.log.Warn(ctx, operation+": amount must be positive")
+		return nil, domain.CodeNegativeAmount
+	}
+	if err := req.Amount.Check(); err != nil {
+		ts.log.Warn(ctx, operation+": amount out of range", zap.Stringer("amount", req.Amount))
+		return nil, domain.CodeAmountOverflow
+	}
+
+	// Валюта кошелька не меняется, поэтому казначейство выбирается до транзакции
+	wallet, err := ts.walletRepo.GetWallet(ctx, req.Address)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			ts.log.Warn(ctx, operation+": wallet not found", zap.String("address", req.Address))
+			return nil, domain.CodeWalletNotFound
+		}
+		ts.log.Error(ctx, operation+": failed to get wallet", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	if wallet.Treasury {
+		ts.log.Warn(ctx, operation+": treasury wallet can not be funded", zap.String("address", req.Address))
+		return nil, domain.CodeTreasuryWallet
+	}
+	treasury, ok := ts.treasury.Wallet(wallet.Currency)
+	if !ok {
+		ts.log.Warn(ctx, operation+": no treasury wallet for currency", zap.String("currency", wallet.Currency))
+		return nil, domain.CodeNoTreasury
+	}
+
+	from, to := treasury, req.Address
+	if kind == domain.KindWithdrawal {
+		from, to = req.Address, treasury
+	}
+
+	var result *domain.TransferResult
+	code := withRetry(ctx, ts.log, ts.cfg, operation, func() domain.ErrorCode {
+		var attemptCode domain.ErrorCode
+		result, attemptCode = ts.moveTx(ctx, operation, kind, from, to, req)
+		return attemptCode
+	})
+	if code == domain.CodeLimitExceeded {
+		return result, code
+	}
+	if code != domain.CodeOK {
+		return nil, code
+	}
+
+	ts.log.Info(ctx, operation+": completed successfully",
+		zap.Int64("transaction_id", result.TransactionId),
+		zap.String("from", from),
+		zap.String("to", to),
+		zap.Stringer("amount", req.Amount))
+	return result, domain.CodeOK
+}
+
+// moveTx выполняет одну попытку пополнения или вывода. Кошельки блокируются в порядке возрастания адреса,
+// как и при переводах, поэтому операции с казначейством не создают deadlock с переводами.
+func (ts *TreasuryService) moveTx(ctx context.Context, operation string, kind domain.TransactionKind, from, to string, req domain.FundsRequest) (*domain.TransferResult, domain.ErrorCode) {
+	tx, err := ts.transactionRepo.BeginTX(ctx)
+	if err != nil {
+		ts.log.Error(ctx, operation+": failed to begin transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	wallets := make(map[string]*domain.Wallet, 2)
+	for _, address := range lockOrder(from, to) {
+		wallet, err := ts.walletRepo.GetWalletForUpdateTx(ctx, tx, address)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				ts.log.Warn(ctx, operation+": wallet not found", zap.String("address", address), zap.Error(err))
+				return nil, domain.CodeWalletNotFound
+			case errors.Is(err, domain.ErrTransactionConflict):
+				ts.log.Warn(ctx, operation+": conflict while locking wallet", zap.String("address", address), zap.Error(err))
+				return nil, domain.CodeTransactionConflict
+			default:
+				ts.log.Error(ctx, operation+": failed to lock wallet", zap.String("address", address), zap.Error(err))
+				return nil, domain.CodeInternal
+			}
+		}
+		if code := walletStatusCode(wallet); code != domain.CodeOK {
+			ts.log.Warn(ctx, operation+": wallet is not active",
+				zap.String("address", address), zap.String("status", string(wallet.Status)))
+			return nil, code
+		}
+		wallets[address] = wallet
+	}
+
+	sender, receiver := wallets[from], wallets[to]
+	if sender.Currency != receiver.Currency {
+		// Кошелек казначейства из конфигурации занят кошельком в другой валюте
+		ts.log.Error(ctx, operation+": treasury wallet currency mismatch",
+			zap.String("from_currency", sender.Currency), zap.String("to_currency", receiver.Currency))
+		return nil, domain.CodeCurrencyMismatch
+	}
+	if code := checkCurrencyAmount(ts.currencies, sender.Currency, req.Amount); code != domain.CodeOK {
+		ts.log.Warn(ctx, operation+": amount not allowed for currency",
+			zap.String("currency", sender.Currency), zap.Stringer("amount", req.Amount))
+		return nil, code
+	}
+	if !sender.CanSpend(req.Amount) {
+		ts.log.Warn(ctx, operation+": insufficient funds",
+			zap.Stringer("available", sender.Available()), zap.Stringer("amount", req.Amount))
+		return nil, domain.CodeInsufficientFunds
+	}
+	if limit, code := ts.checkLimits(ctx, tx, operation, kind, wallets[req.Address], req.Amount); code != domain.CodeOK {
+		if code == domain.CodeLimitExceeded {
+			return &domain.TransferResult{ExceededLimit: limit}, code
+		}
+		return nil, code
+	}
+
+	transactionId, err := ts.transactionRepo.CreateFundsTx(ctx, tx, kind, from, to, req.Amount, req.Details)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTransactionConflict):
+			ts.log.Warn(ctx, operation+": conflict while creating transaction record", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		case errors.Is(err, domain.ErrDuplicateReference):
+			ts.log.Warn(ctx, operation+": external reference already used", zap.Error(err))
+			return nil, domain.CodeDuplicateReference
+		default:
+			ts.log.Error(ctx, operation+": failed to create transaction record", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+
+	entryKind := domain.EntryDeposit
+	if kind == domain.KindWithdrawal {
+		entryKind = domain.EntryWithdrawal
+	}
+	entry := domain.NewTransferEntry(entryKind, transactionId, from, to, sender.Currency, req.Amount)
+	if _, err = ts.ledgerRepo.PostEntryTx(ctx, tx, entry); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInsufficientFunds):
+			ts.log.Warn(ctx, operation+": insufficient funds", zap.Error(err))
+			return nil, domain.CodeInsufficientFunds
+		case errors.Is(err, domain.ErrAmountOverflow):
+			ts.log.Warn(ctx, operation+": balance out of range", zap.Error(err))
+			return nil, domain.CodeAmountOverflow
+		case errors.Is(err, domain.ErrTransactionConflict):
+			ts.log.Warn(ctx, operation+": conflict while posting journal entry", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		default:
+			ts.log.Error(ctx, operation+": failed to post journal entry", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			ts.log.Warn(ctx, operation+": conflict on commit", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		}
+		ts.log.Error(ctx, operation+": failed to commit transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed = true
+
+	return &domain.TransferResult{TransactionId: transactionId}, domain.CodeOK
+}
+
+// checkLimits проверяет лимиты клиентского кошелька: для пополнения - лимит баланса,
+// для вывода - лимиты исходящих переводов. Казначейство лимитами не ограничено.
+func (ts *TreasuryService) checkLimits(ctx context.Context, tx domain.TxExecutor, operation string, kind domain.TransactionKind, wallet *domain.Wallet, amount domain.Money) (domain.LimitKind, domain.ErrorCode) {
+	overrides, err := ts.walletRepo.GetWalletLimitsTx(ctx, tx, wallet.Address)
+	if err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			ts.log.Warn(ctx, operation+": conflict while reading wallet limits", zap.Error(err))
+			return "", domain.CodeTransactionConflict
+		}
+		ts.log.Error(ctx, operation+": failed to read wallet limits", zap.String("address", wallet.Address), zap.Error(err))
+		return "", domain.CodeInternal
+	}
+	limits := overrides.Apply(ts.limits.Defaults)
+
+	if kind == domain.KindDeposit {
+		if limit := limits.CheckIncoming(wallet.Balance, amount); limit != "" {
+			ts.log.Warn(ctx, operation+": wallet limit exceeded",
+				zap.String("address", wallet.Address), zap.String("limit", string(limit)),
+				zap.Stringer("balance", wallet.Balance), zap.Stringer("amount", amount))
+			return limit, domain.CodeLimitExceeded
+		}
+		return "", domain.CodeOK
+	}
+
+	var outflow domain.Outflow
+	if limits.MaxDailyOutflow > 0 || limits.MaxDailyCount > 0 {
+		if outflow, err = ts.transactionRepo.GetOutflowTx(ctx, tx, wallet.Address, ts.limits.Window); err != nil {
+			if errors.Is(err, domain.ErrTransactionConflict) {
+				ts.log.Warn(ctx, operation+": conflict while reading wallet outflow", zap.Error(err))
+				return "", domain.CodeTransactionConflict
+			}
+			ts.log.Error(ctx, operation+": failed to read wallet outflow", zap.Error(err))
+			return "", domain.CodeInternal
+		}
+	}
+	if limit := limits.CheckOutgoing(amount, 0, outflow); limit != "" {
+		ts.log.Warn(ctx, operation+": wallet limit exceeded",
+			zap.String("address", wallet.Address), zap.String("limit", string(limit)),
+			zap.Stringer("amount", amount), zap.Stringer("outflow", outflow.Amount), zap.Int("count", outflow.Count))
+		return limit, domain.CodeLimitExceeded
+	}
+	return "", domain.CodeOK
+}
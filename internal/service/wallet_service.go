@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"TransactionTest/internal/domain"
 	"TransactionTest/internal/logger"
@@ -11,27 +13,119 @@ import (
 	"go.uber.org/zap"
 )
 
+// balanceSnapshotLag насколько снимок балансов отстает от текущего момента.
+// Проводки получают created_at по началу своей транзакции, и снимок не должен опередить
+// еще не закоммиченные переводы.
+const balanceSnapshotLag = time.Minute
+
 type WalletService struct {
-	walletRepo IWalletRepository
-	log        logger.Logger
+	walletRepo      IWalletRepository
+	ledgerRepo      ILedgerRepository
+	transactionRepo ITransactionRepository
+	currencies      *domain.CurrencyRegistry
+	treasury        *domain.Treasury
+	limits          domain.LimitPolicy
+	log             logger.Logger
 }
 
-func NewWalletService(wr IWalletRepository, l logger.Logger) *WalletService {
+func NewWalletService(wr IWalletRepository, lr ILedgerRepository, tr ITransactionRepository, currencies *domain.CurrencyRegistry, treasury *domain.Treasury, limits domain.LimitPolicy, l logger.Logger) *WalletService {
 	return &WalletService{
-		walletRepo: wr,
-		log:        l,
+		walletRepo:      wr,
+		ledgerRepo:      lr,
+		transactionRepo: tr,
+		currencies:      currencies,
+		treasury:        treasury,
+		limits:          limits,
+		log:             l,
+	}
+}
+
+// openWalletTx создает кошелек с нулевым балансом и зачисляет начальный баланс пополнением
+// с кошелька казначейства его валюты, как POST /deposit: транзакцией deposit и ее проводкой.
+// Без казначейства в валюте возвращает ErrNoTreasury; только при equityFallback (стартовые кошельки)
+// баланс зачисляется проводкой открытия счета со счета system:equity.
+func (ws *WalletService) openWalletTx(ctx context.Context, tx domain.TxExecutor, address, currency string, balance domain.Money, equityFallback bool) error {
+	if err := ws.walletRepo.CreateWalletTx(ctx, tx, address, currency, 0); err != nil {
+		return err
+	}
+	if balance == 0 {
+		return nil
+	}
+	treasury, ok := ws.treasury.Wallet(currency)
+	if !ok {
+		if !equityFallback {
+			return fmt.Errorf("%w: %s", domain.ErrNoTreasury, currency)
+		}
+		_, err := ws.ledgerRepo.PostEntryTx(ctx, tx, domain.NewTransferEntry(domain.EntryOpening, 0, domain.AccountEquity, address, currency, balance))
+		return err
+	}
+
+	// Новый кошелек до коммита никому не виден, блокировать нужно только казначейство
+	wallet, err := ws.walletRepo.GetWalletForUpdateTx(ctx, tx, treasury)
+	if err != nil {
+		return err
+	}
+	switch {
+	case wallet.Status == domain.WalletFrozen:
+		return fmt.Errorf("%w: treasury wallet %s", domain.ErrWalletFrozen, treasury)
+	case wallet.Status == domain.WalletClosed:
+		return fmt.Errorf("%w: treasury wallet %s", domain.ErrWalletClosed, treasury)
+	case wallet.Currency != currency:
+		return fmt.Errorf("%w: treasury wallet %s is in %s", domain.ErrInternal, treasury, wallet.Currency)
+	}
+
+	transactionId, err := ws.transactionRepo.CreateFundsTx(ctx, tx, domain.KindDeposit, treasury, address, balance, domain.TransferDetails{})
+	if err != nil {
+		return err
 	}
+	_, err = ws.ledgerRepo.PostEntryTx(ctx, tx, domain.NewTransferEntry(domain.EntryDeposit, transactionId, treasury, address, currency, balance))
+	return err
 }
 
-func (ws *WalletService) CreateWallet(ctx context.Context, balance float64) (string, domain.ErrorCode) {
+// CreateWallet открывает кошелек в указанной валюте. Пустой код означает валюту по умолчанию.
+// Ненулевой начальный баланс зачисляется пополнением с кошелька казначейства в той же транзакции БД;
+// без казначейства в валюте возвращается CodeNoTreasury.
+func (ws *WalletService) CreateWallet(ctx context.Context, currencyCode string, balance domain.Money) (string, domain.ErrorCode) {
+	currency, err := ws.currencies.Lookup(currencyCode)
+	if err != nil {
+		ws.log.Warn(ctx, "CreateWallet: unsupported currency", zap.String("currency", currencyCode))
+		return "", domain.CodeUnsupportedCurrency
+	}
 	if balance < 0 {
 		ws.log.Warn(ctx, "CreateWallet: negative balance not allowed")
 		return "", domain.CodeNegativeBalance
 	}
+	if err := balance.Check(); err != nil {
+		ws.log.Warn(ctx, "CreateWallet: balance out of range", zap.Stringer("balance", balance))
+		return "", domain.CodeAmountOverflow
+	}
+	if !currency.Allows(balance) {
+		ws.log.Warn(ctx, "CreateWallet: balance not allowed for currency",
+			zap.String("currency", currency.Code), zap.Stringer("balance", balance))
+		return "", domain.CodeInvalidAmount
+	}
+	// У нового кошелька нет своих лимитов, действуют значения по умолчанию
+	if limit := ws.limits.Defaults.CheckIncoming(0, balance); limit != "" {
+		ws.log.Warn(ctx, "CreateWallet: balance exceeds wallet limit",
+			zap.String("limit", string(limit)), zap.Stringer("balance", balance))
+		return "", domain.CodeLimitExceeded
+	}
 
 	address := uuid.New().String()
 
-	if err := ws.walletRepo.CreateWallet(ctx, address, balance); err != nil {
+	tx, err := ws.walletRepo.BeginTX(ctx)
+	if err != nil {
+		ws.log.Error(ctx, "CreateWallet: failed to begin transaction", zap.Error(err))
+		return "", domain.CodeInternal
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	if err := ws.openWalletTx(ctx, tx, address, currency.Code, balance, false); err != nil {
 		switch {
 		case errors.Is(err, domain.ErrInternal): // Для ускорения проверок
 			ws.log.Error(ctx, "CreateWalett", zap.Error(err))
@@ -39,32 +133,131 @@ func (ws *WalletService) CreateWallet(ctx context.Context, balance float64) (str
 		case errors.Is(err, domain.ErrWalletAlreadyExists):
 			ws.log.Warn(ctx, "CreateWalett", zap.Error(err))
 			return "", domain.CodeDuplicateWallet
+		case errors.Is(err, domain.ErrAmountOverflow):
+			ws.log.Warn(ctx, "CreateWalett", zap.Error(err))
+			return "", domain.CodeAmountOverflow
+		case errors.Is(err, domain.ErrTransactionConflict):
+			ws.log.Warn(ctx, "CreateWalett", zap.Error(err))
+			return "", domain.CodeTransactionConflict
 		case errors.Is(err, domain.ErrNegativeBalance): // Никогда не сработает
 			ws.log.Warn(ctx, "CreateWalett", zap.Error(err))
 			return "", domain.CodeNegativeBalance
+		case errors.Is(err, domain.ErrNoTreasury):
+			ws.log.Warn(ctx, "CreateWallet: no treasury wallet for currency", zap.String("currency", currency.Code))
+			return "", domain.CodeNoTreasury
+		case errors.Is(err, domain.ErrWalletFrozen):
+			ws.log.Warn(ctx, "CreateWallet: treasury wallet is frozen", zap.Error(err))
+			return "", domain.CodeWalletFrozen
+		case errors.Is(err, domain.ErrWalletClosed):
+			ws.log.Warn(ctx, "CreateWallet: treasury wallet is closed", zap.Error(err))
+			return "", domain.CodeWalletClosed
 		default:
 			ws.log.Error(ctx, "CreateWalett: unexpected", zap.Error(err))
 			return "", domain.CodeInternal
 		}
 	}
+
+	if err := tx.Commit(ctx); err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			ws.log.Warn(ctx, "CreateWallet: conflict on commit", zap.Error(err))
+			return "", domain.CodeTransactionConflict
+		}
+		ws.log.Error(ctx, "CreateWallet: failed to commit transaction", zap.Error(err))
+		return "", domain.CodeInternal
+	}
+	committed = true
+
 	ws.log.Info(ctx, "CreateWallet: success create wallet", zap.String("address", address))
 	return address, domain.CodeOK
 }
 
-func (ws *WalletService) GetBalance(ctx context.Context, address string) (float64, domain.ErrorCode) {
+// GetBalance возвращает баланс кошелька по журналу и доступный баланс за вычетом активных резервов
+func (ws *WalletService) GetBalance(ctx context.Context, address string) (domain.Balance, domain.ErrorCode) {
 	balance, err := ws.walletRepo.GetWalletBalance(ctx, address)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			ws.log.Warn(ctx, "GetBalance: wallet not found", zap.Error(err))
-			return 0, domain.CodeWalletNotFound
+			return domain.Balance{}, domain.CodeWalletNotFound
 		}
 		ws.log.Error(ctx, "GetBalance", zap.Error(err))
+		return domain.Balance{}, domain.CodeInternal
+	}
+	ws.log.Info(ctx, "GetBalance: success get wallet", zap.String("address", address),
+		zap.Stringer("balance", balance.Total), zap.Stringer("available", balance.Available))
+	return balance, domain.CodeOK
+}
+
+// GetBalanceAt возвращает баланс кошелька по журналу на момент at.
+// До создания кошелька баланс нулевой, момент в будущем дает текущий баланс.
+func (ws *WalletService) GetBalanceAt(ctx context.Context, address string, at time.Time) (domain.Money, domain.ErrorCode) {
+	balance, err := ws.ledgerRepo.GetBalanceAt(ctx, address, at)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			ws.log.Warn(ctx, "GetBalanceAt: wallet not found", zap.Error(err))
+			return 0, domain.CodeWalletNotFound
+		}
+		ws.log.Error(ctx, "GetBalanceAt", zap.Error(err))
 		return 0, domain.CodeInternal
 	}
-	ws.log.Info(ctx, "GetBalance: success get wallet", zap.String("address", address), zap.Float64("balance", balance))
+	ws.log.Info(ctx, "GetBalanceAt: success get balance", zap.String("address", address),
+		zap.Time("at", at), zap.Stringer("balance", balance))
 	return balance, domain.CodeOK
 }
 
+// GetStatement возвращает выписку по кошельку за период [from, to): баланс на начало, проводки
+// с балансом после каждой, обороты и баланс на конец. Баланс на начало и проводки читаются
+// в одной транзакции REPEATABLE READ, поэтому параллельные переводы не нарушают сходимость выписки.
+func (ws *WalletService) GetStatement(ctx context.Context, address string, from, to time.Time) (*domain.Statement, domain.ErrorCode) {
+	if !from.Before(to) {
+		ws.log.Warn(ctx, "GetStatement: empty period", zap.Time("from", from), zap.Time("to", to))
+		return nil, domain.CodeInvalidPeriod
+	}
+
+	tx, err := ws.transactionRepo.BeginSnapshotTX(ctx)
+	if err != nil {
+		ws.log.Error(ctx, "GetStatement: failed to begin transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	// Транзакция только читает данные, откат завершает ее так же, как коммит
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	currency, opening, err := ws.transactionRepo.GetOpeningBalanceTx(ctx, tx, address, from)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			ws.log.Warn(ctx, "GetStatement: wallet not found", zap.Error(err))
+			return nil, domain.CodeWalletNotFound
+		}
+		ws.log.Error(ctx, "GetStatement", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+
+	lines, err := ws.transactionRepo.GetStatementLinesTx(ctx, tx, address, from, to)
+	if err != nil {
+		ws.log.Error(ctx, "GetStatement", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+
+	statement := domain.NewStatement(address, currency, from, to, opening, lines)
+	ws.log.Info(ctx, "GetStatement: success get statement", zap.String("address", address),
+		zap.Time("from", from), zap.Time("to", to), zap.Int("count", len(lines)),
+		zap.Stringer("opening", statement.OpeningBalance), zap.Stringer("closing", statement.ClosingBalance))
+	return statement, domain.CodeOK
+}
+
+// SnapshotBalances сохраняет снимки балансов кошельков, изменившихся с прошлого снимка,
+// чтобы GetBalanceAt не суммировал всю историю счета. Возвращает число снимков.
+func (ws *WalletService) SnapshotBalances(ctx context.Context) (int64, domain.ErrorCode) {
+	created, err := ws.ledgerRepo.CreateBalanceSnapshots(ctx, balanceSnapshotLag)
+	if err != nil {
+		ws.log.Error(ctx, "SnapshotBalances", zap.Error(err))
+		return 0, domain.CodeInternal
+	}
+	ws.log.Info(ctx, "SnapshotBalances: success", zap.Int64("created", created))
+	return created, domain.CodeOK
+}
+
 func (ws *WalletService) GetWallet(ctx context.Context, address string) (*domain.Wallet, domain.ErrorCode) {
 	wallet, err := ws.walletRepo.GetWallet(ctx, address)
 	if err != nil {
@@ -79,52 +272,413 @@ func (ws *WalletService) GetWallet(ctx context.Context, address string) (*domain
 	return wallet, domain.CodeOK
 }
 
-func (ws *WalletService) UpdateBalance(ctx context.Context, address string, newBalance float64) domain.ErrorCode {
+// UpdateBalance устанавливает баланс кошелька вручную. Изменение проводится корректирующей записью журнала
+// и сохраняется в аудит вместе с причиной. Баланс кошелька казначейства меняется только пополнениями и выводами.
+func (ws *WalletService) UpdateBalance(ctx context.Context, address string, newBalance domain.Money, reason string) (*domain.BalanceAdjustment, domain.ErrorCode) {
+	if reason == "" {
+		ws.log.Warn(ctx, "UpdateBalance: reason is required")
+		return nil, domain.CodeInvalidRequestBody
+	}
 	if newBalance < 0 {
 		ws.log.Warn(ctx, "UpdateBalance: negative balance not allowed")
-		return domain.CodeNegativeBalance
+		return nil, domain.CodeNegativeBalance
+	}
+	if err := newBalance.Check(); err != nil {
+		ws.log.Warn(ctx, "UpdateBalance: balance out of range", zap.Stringer("newBalance", newBalance))
+		return nil, domain.CodeAmountOverflow
 	}
 
-	err := ws.walletRepo.UpdateWalletBalance(ctx, address, newBalance)
+	tx, err := ws.walletRepo.BeginTX(ctx)
+	if err != nil {
+		ws.log.Error(ctx, "UpdateBalance: failed to begin transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	// Ручное изменение баланса проводится корректирующей записью на разницу с текущим балансом
+	var adjustment *domain.BalanceAdjustment
+	wallet, err := ws.walletRepo.GetWalletForUpdateTx(ctx, tx, address)
+	if err == nil {
+		// Замороженному кошельку корректировки разрешены, закрытому - нет
+		if wallet.Treasury {
+			ws.log.Warn(ctx, "UpdateBalance: treasury wallet balance is managed by deposits and withdrawals", zap.String("address", address))
+			return nil, domain.CodeTreasuryWallet
+		}
+		if wallet.Status == domain.WalletClosed {
+			ws.log.Warn(ctx, "UpdateBalance: wallet is closed", zap.String("address", address))
+			return nil, domain.CodeWalletClosed
+		}
+		if code := checkCurrencyAmount(ws.currencies, wallet.Currency, newBalance); code != domain.CodeOK {
+			ws.log.Warn(ctx, "UpdateBalance: balance not allowed for currency",
+				zap.String("currency", wallet.Currency), zap.Stringer("newBalance", newBalance))
+			return nil, code
+		}
+		adjustment = &domain.BalanceAdjustment{Address: address, Previous: wallet.Balance, New: newBalance, Reason: reason}
+		if newBalance != wallet.Balance {
+			entry := domain.NewTransferEntry(domain.EntryAdjustment, 0, domain.AccountEquity, address, wallet.Currency, newBalance-wallet.Balance)
+			adjustment.EntryId, err = ws.ledgerRepo.PostEntryTx(ctx, tx, entry)
+		}
+		if err == nil {
+			adjustment, err = ws.walletRepo.CreateBalanceAdjustmentTx(ctx, tx, *adjustment)
+		}
+	}
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrNotFound):
 			ws.log.Warn(ctx, "UpdateBalance: wallet not found", zap.Error(err))
-			return domain.CodeWalletNotFound
-		case errors.Is(err, domain.ErrNegativeBalance): // Никогда не сработает
+			return nil, domain.CodeWalletNotFound
+		case errors.Is(err, domain.ErrInsufficientFunds), errors.Is(err, domain.ErrNegativeBalance): // Никогда не сработает
+			ws.log.Warn(ctx, "UpdateBalance", zap.Error(err))
+			return nil, domain.CodeNegativeBalance
+		case errors.Is(err, domain.ErrAmountOverflow):
+			ws.log.Warn(ctx, "UpdateBalance", zap.Error(err))
+			return nil, domain.CodeAmountOverflow
+		case errors.Is(err, domain.ErrTransactionConflict):
 			ws.log.Warn(ctx, "UpdateBalance", zap.Error(err))
-			return domain.CodeNegativeBalance
+			return nil, domain.CodeTransactionConflict
 		default:
 			ws.log.Error(ctx, "UpdateBalance", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			ws.log.Warn(ctx, "UpdateBalance: conflict on commit", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		}
+		ws.log.Error(ctx, "UpdateBalance: failed to commit transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed = true
+
+	ws.log.Info(ctx, "UpdateBalance: success update wallet",
+		zap.String("address", address),
+		zap.Stringer("previousBalance", adjustment.Previous),
+		zap.Stringer("newBalance", newBalance),
+		zap.String("reason", reason))
+	return adjustment, domain.CodeOK
+}
+
+// SetOverdraftLimit задает кредитную линию кошелька: переводы могут уводить баланс до -limit.
+// Лимит нельзя опустить ниже текущей задолженности.
+func (ws *WalletService) SetOverdraftLimit(ctx context.Context, address string, limit domain.Money) domain.ErrorCode {
+	if limit < 0 {
+		ws.log.Warn(ctx, "SetOverdraftLimit: negative limit not allowed")
+		return domain.CodeNegativeAmount
+	}
+	if err := limit.Check(); err != nil {
+		ws.log.Warn(ctx, "SetOverdraftLimit: limit out of range", zap.Stringer("limit", limit))
+		return domain.CodeAmountOverflow
+	}
+
+	if err := ws.walletRepo.SetOverdraftLimit(ctx, address, limit); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			ws.log.Warn(ctx, "SetOverdraftLimit: wallet not found", zap.Error(err))
+			return domain.CodeWalletNotFound
+		case errors.Is(err, domain.ErrOverdraftInUse):
+			ws.log.Warn(ctx, "SetOverdraftLimit: limit is below credit used", zap.Error(err))
+			return domain.CodeOverdraftInUse
+		case errors.Is(err, domain.ErrAmountOverflow):
+			ws.log.Warn(ctx, "SetOverdraftLimit", zap.Error(err))
+			return domain.CodeAmountOverflow
+		case errors.Is(err, domain.ErrTransactionConflict):
+			ws.log.Warn(ctx, "SetOverdraftLimit", zap.Error(err))
+			return domain.CodeTransactionConflict
+		default:
+			ws.log.Error(ctx, "SetOverdraftLimit", zap.Error(err))
 			return domain.CodeInternal
 		}
 	}
-	ws.log.Info(ctx, "UpdateBalance: success update wallet", zap.String("address", address), zap.Float64("newBalance", newBalance))
+
+	ws.log.Info(ctx, "SetOverdraftLimit: overdraft limit updated", zap.String("address", address), zap.Stringer("limit", limit))
 	return domain.CodeOK
 }
 
+// GetWalletEntries возвращает последние проводки журнала по кошельку
+func (ws *WalletService) GetWalletEntries(ctx context.Context, address string, limit int) ([]domain.Posting, domain.ErrorCode) {
+	if limit <= 0 {
+		ws.log.Warn(ctx, "GetWalletEntries: limit must be greater than zero")
+		return nil, domain.CodeInvalidLimit
+	}
+
+	if _, err := ws.walletRepo.GetWalletBalance(ctx, address); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			ws.log.Warn(ctx, "GetWalletEntries: wallet not found", zap.Error(err))
+			return nil, domain.CodeWalletNotFound
+		}
+		ws.log.Error(ctx, "GetWalletEntries", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+
+	postings, err := ws.ledgerRepo.GetAccountPostings(ctx, address, limit)
+	if err != nil {
+		ws.log.Error(ctx, "GetWalletEntries", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	ws.log.Info(ctx, "GetWalletEntries: success get entries", zap.String("address", address), zap.Int("count", len(postings)))
+	return postings, domain.CodeOK
+}
+
 func (ws *WalletService) RemoveWallet(ctx context.Context, address string) domain.ErrorCode {
 	err := ws.walletRepo.RemoveWallet(ctx, address)
 	if err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
 			ws.log.Warn(ctx, "RemoveWallet: wallet not found", zap.Error(err))
 			return domain.CodeWalletNotFound
+		case errors.Is(err, domain.ErrWalletHasHistory):
+			ws.log.Warn(ctx, "RemoveWallet: wallet has history, it can only be closed", zap.Error(err))
+			return domain.CodeWalletHasHistory
+		default:
+			ws.log.Error(ctx, "RemoveWallet", zap.Error(err))
+			return domain.CodeInternal
 		}
-		ws.log.Error(ctx, "RemoveWallet", zap.Error(err))
-		return domain.CodeInternal
 	}
 	ws.log.Info(ctx, "UpdateBalance: success remove wallet", zap.String("address", address))
 	return domain.CodeOK
 }
 
+// FreezeWallet временно запрещает переводы с кошелька и на него
+func (ws *WalletService) FreezeWallet(ctx context.Context, address, reason string) (*domain.WalletStatusChange, domain.ErrorCode) {
+	return ws.changeStatus(ctx, "FreezeWallet", address, domain.WalletFrozen, reason)
+}
+
+// UnfreezeWallet возвращает замороженный кошелек в активное состояние
+func (ws *WalletService) UnfreezeWallet(ctx context.Context, address, reason string) (*domain.WalletStatusChange, domain.ErrorCode) {
+	return ws.changeStatus(ctx, "UnfreezeWallet", address, domain.WalletActive, reason)
+}
+
+// CloseWallet окончательно закрывает активный кошелек с нулевым балансом
+func (ws *WalletService) CloseWallet(ctx context.Context, address, reason string) (*domain.WalletStatusChange, domain.ErrorCode) {
+	return ws.changeStatus(ctx, "CloseWallet", address, domain.WalletClosed, reason)
+}
+
+// changeStatus переводит кошелек в статус to, если переход разрешен, и записывает причину в историю.
+// Кошелек блокируется на время смены, поэтому закрытие не пересекается с переводом, пополняющим кошелек.
+func (ws *WalletService) changeStatus(ctx context.Context, operation, address string, to domain.WalletStatus, reason string) (*domain.WalletStatusChange, domain.ErrorCode) {
+	if reason == "" {
+		ws.log.Warn(ctx, operation+": reason is required")
+		return nil, domain.CodeInvalidRequestBody
+	}
+
+	tx, err := ws.walletRepo.BeginTX(ctx)
+	if err != nil {
+		ws.log.Error(ctx, operation+": failed to begin transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	wallet, err := ws.walletRepo.GetWalletForUpdateTx(ctx, tx, address)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			ws.log.Warn(ctx, operation+": wallet not found", zap.Error(err))
+			return nil, domain.CodeWalletNotFound
+		case errors.Is(err, domain.ErrTransactionConflict):
+			ws.log.Warn(ctx, operation+": conflict while locking wallet", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		default:
+			ws.log.Error(ctx, operation+": failed to lock wallet", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+
+	if !wallet.Status.CanTransitionTo(to) {
+		ws.log.Warn(ctx, operation+": status transition not allowed",
+			zap.String("address", address), zap.String("from", string(wallet.Status)), zap.String("to", string(to)))
+		return nil, domain.CodeInvalidWalletStatus
+	}
+	if to == domain.WalletClosed && wallet.Balance != 0 {
+		ws.log.Warn(ctx, operation+": wallet balance is not zero",
+			zap.String("address", address), zap.Stringer("balance", wallet.Balance))
+		return nil, domain.CodeWalletNotEmpty
+	}
+	// Резервы закрытого кошелька нельзя провести, и до истечения они держали бы средства заблокированными
+	if to == domain.WalletClosed && wallet.Held != 0 {
+		ws.log.Warn(ctx, operation+": wallet has active holds",
+			zap.String("address", address), zap.Stringer("held", wallet.Held))
+		return nil, domain.CodeWalletHasHolds
+	}
+
+	change, err := ws.walletRepo.ChangeWalletStatusTx(ctx, tx, domain.WalletStatusChange{
+		Address:    address,
+		FromStatus: wallet.Status,
+		ToStatus:   to,
+		Reason:     reason,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			ws.log.Warn(ctx, operation+": wallet not found", zap.Error(err))
+			return nil, domain.CodeWalletNotFound
+		case errors.Is(err, domain.ErrTransactionConflict):
+			ws.log.Warn(ctx, operation+": conflict while changing status", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		default:
+			ws.log.Error(ctx, operation+": failed to change status", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		if errors.Is(err, domain.ErrTransactionConflict) {
+			ws.log.Warn(ctx, operation+": conflict on commit", zap.Error(err))
+			return nil, domain.CodeTransactionConflict
+		}
+		ws.log.Error(ctx, operation+": failed to commit transaction", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	committed = true
+
+	ws.log.Info(ctx, operation+": wallet status changed",
+		zap.String("address", address),
+		zap.String("from", string(change.FromStatus)),
+		zap.String("to", string(change.ToStatus)),
+		zap.String("reason", reason))
+	return change, domain.CodeOK
+}
+
+// GetWalletStatusHistory возвращает последние смены статуса кошелька с причинами
+func (ws *WalletService) GetWalletStatusHistory(ctx context.Context, address string, limit int) ([]domain.WalletStatusChange, domain.ErrorCode) {
+	if limit <= 0 {
+		ws.log.Warn(ctx, "GetWalletStatusHistory: limit must be greater than zero")
+		return nil, domain.CodeInvalidLimit
+	}
+
+	if _, code := ws.GetWallet(ctx, address); code != domain.CodeOK {
+		return nil, code
+	}
+
+	changes, err := ws.walletRepo.GetWalletStatusChanges(ctx, address, limit)
+	if err != nil {
+		ws.log.Error(ctx, "GetWalletStatusHistory", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	ws.log.Info(ctx, "GetWalletStatusHistory: success get status history", zap.String("address", address), zap.Int("count", len(changes)))
+	return changes, domain.CodeOK
+}
+
+// GetBalanceAdjustments возвращает последние ручные изменения баланса кошелька с причинами
+func (ws *WalletService) GetBalanceAdjustments(ctx context.Context, address string, limit int) ([]domain.BalanceAdjustment, domain.ErrorCode) {
+	if limit <= 0 {
+		ws.log.Warn(ctx, "GetBalanceAdjustments: limit must be greater than zero")
+		return nil, domain.CodeInvalidLimit
+	}
+
+	if _, code := ws.GetWallet(ctx, address); code != domain.CodeOK {
+		return nil, code
+	}
+
+	adjustments, err := ws.walletRepo.GetBalanceAdjustments(ctx, address, limit)
+	if err != nil {
+		ws.log.Error(ctx, "GetBalanceAdjustments", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	ws.log.Info(ctx, "GetBalanceAdjustments: success get balance adjustments", zap.String("address", address), zap.Int("count", len(adjustments)))
+	return adjustments, domain.CodeOK
+}
+
+// GetWalletLimits возвращает лимиты кошелька: заданные для него и действующие с учетом значений по умолчанию
+func (ws *WalletService) GetWalletLimits(ctx context.Context, address string) (*domain.WalletLimitSettings, domain.ErrorCode) {
+	if _, code := ws.GetWallet(ctx, address); code != domain.CodeOK {
+		return nil, code
+	}
+
+	overrides, err := ws.walletRepo.GetWalletLimits(ctx, address)
+	if err != nil {
+		ws.log.Error(ctx, "GetWalletLimits", zap.Error(err))
+		return nil, domain.CodeInternal
+	}
+	ws.log.Info(ctx, "GetWalletLimits: success get wallet limits", zap.String("address", address))
+	return ws.limitSettings(address, overrides), domain.CodeOK
+}
+
+// SetWalletLimits заменяет лимиты кошелька. Лимит, не заданный в overrides, возвращается к значению по умолчанию.
+func (ws *WalletService) SetWalletLimits(ctx context.Context, address string, overrides domain.LimitOverrides) (*domain.WalletLimitSettings, domain.ErrorCode) {
+	if err := overrides.Apply(domain.WalletLimits{}).Validate(); err != nil {
+		if errors.Is(err, domain.ErrAmountOverflow) {
+			ws.log.Warn(ctx, "SetWalletLimits: limit out of range", zap.Error(err))
+			return nil, domain.CodeAmountOverflow
+		}
+		ws.log.Warn(ctx, "SetWalletLimits: invalid limits", zap.Error(err))
+		return nil, domain.CodeInvalidLimit
+	}
+
+	if err := ws.walletRepo.SetWalletLimits(ctx, address, overrides); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			ws.log.Warn(ctx, "SetWalletLimits: wallet not found", zap.Error(err))
+			return nil, domain.CodeWalletNotFound
+		case errors.Is(err, domain.ErrAmountOverflow):
+			ws.log.Warn(ctx, "SetWalletLimits: limit out of range", zap.Error(err))
+			return nil, domain.CodeAmountOverflow
+		default:
+			ws.log.Error(ctx, "SetWalletLimits: failed to set limits", zap.Error(err))
+			return nil, domain.CodeInternal
+		}
+	}
+
+	settings := ws.limitSettings(address, overrides)
+	ws.log.Info(ctx, "SetWalletLimits: wallet limits updated",
+		zap.String("address", address),
+		zap.Stringer("max_transfer", settings.Effective.MaxTransfer),
+		zap.Stringer("max_daily_outflow", settings.Effective.MaxDailyOutflow),
+		zap.Int("max_daily_count", settings.Effective.MaxDailyCount),
+		zap.Stringer("max_balance", settings.Effective.MaxBalance))
+	return settings, domain.CodeOK
+}
+
+func (ws *WalletService) limitSettings(address string, overrides domain.LimitOverrides) *domain.WalletLimitSettings {
+	return &domain.WalletLimitSettings{
+		Address:   address,
+		Overrides: overrides,
+		Effective: overrides.Apply(ws.limits.Defaults),
+		Window:    ws.limits.Window,
+	}
+}
+
+// walletStatusCode возвращает код ошибки, если кошелек не может участвовать в переводе
+func walletStatusCode(wallet *domain.Wallet) domain.ErrorCode {
+	switch wallet.Status {
+	case domain.WalletFrozen:
+		return domain.CodeWalletFrozen
+	case domain.WalletClosed:
+		return domain.CodeWalletClosed
+	default:
+		return domain.CodeOK
+	}
+}
+
+// transferWalletCode дополняет walletStatusCode запретом кошельков казначейства: их средства
+// двигаются только пополнениями, выводами и отменами этих операций
+func transferWalletCode(wallet *domain.Wallet) domain.ErrorCode {
+	if wallet.Treasury {
+		return domain.CodeTreasuryWallet
+	}
+	return walletStatusCode(wallet)
+}
+
 func (ws *WalletService) CreateWalletsForSeeding(
 	ctx context.Context,
 	count int,
-	balance float64,
+	balance domain.Money,
 	failOnError bool,
 ) (<-chan string, <-chan error, bool) {
 	done := make(chan string)
 	errChan := make(chan error, count)
+	currency := ws.currencies.Default().Code
 
 	tx, err := ws.walletRepo.BeginTX(ctx)
 	if err != nil {
@@ -147,7 +701,7 @@ func (ws *WalletService) CreateWalletsForSeeding(
 				return
 			default:
 				addr := uuid.New().String()
-				if err := ws.walletRepo.CreateWalletTx(ctx, tx, addr, balance); err != nil {
+				if err := ws.openWalletTx(ctx, tx, addr, currency, balance, true); err != nil {
 					errChan <- err
 					switch {
 					case errors.Is(err, domain.ErrInternal): // Для ускорения проверок
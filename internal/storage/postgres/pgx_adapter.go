@@ -101,7 +101,14 @@ type txAdapter struct {
 }
 
 func (t *txAdapter) Commit(ctx context.Context) error {
-	return t.tx.Commit(ctx)
+	err := t.tx.Commit(ctx)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			return dbErrorAdapter{pgErr}
+		}
+	}
+	return err
 }
 func (t *txAdapter) Rollback(ctx context.Context) error {
 	return t.tx.Rollback(ctx)
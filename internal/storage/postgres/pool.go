@@ -6,9 +6,44 @@ import (
 	"time"
 
 	"TransactionTest/config"
+	"TransactionTest/internal/logger"
+
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// defaultApplicationName application_name соединений, если postgres.ApplicationName не задан
+const defaultApplicationName = "transaction-test"
+
+// resetTimeout ограничивает возврат application_name соединению после запроса
+const resetTimeout = 5 * time.Second
+
+// tagConnection выставляет соединению application_name с ID запроса из ctx, чтобы запросы в логах
+// и pg_stat_activity можно было связать с логами сервиса. Выдачи без ID запроса соединение не трогают:
+// имя приложения ему уже вернул resetConnection при возврате в пул, поэтому лишний запрос к БД
+// делают только выдачи с ID запроса. Postgres обрезает application_name до 63 байт.
+func tagConnection(ctx context.Context, conn *pgx.Conn) {
+	id := logger.RequestIDFromContext(ctx)
+	if id == "" || conn.PgConn().ParameterStatus("application_name") == id {
+		return
+	}
+	// Ошибка не мешает выполнить сам запрос: в худшем случае у него останется прежнее имя
+	_, _ = conn.Exec(ctx, "SELECT set_config('application_name', $1, false)", id)
+}
+
+// resetConnection возвращает соединению имя приложения, чтобы ID запроса не достался следующему
+// владельцу соединения. pgxpool вызывает AfterRelease в отдельной горутине, поэтому сброс не задерживает
+// ответ на запрос. Если сбросить имя не удалось, соединение закрывается.
+func resetConnection(conn *pgx.Conn, applicationName string) bool {
+	if conn.PgConn().ParameterStatus("application_name") == applicationName {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), resetTimeout)
+	defer cancel()
+	_, err := conn.Exec(ctx, "SELECT set_config('application_name', $1, false)", applicationName)
+	return err == nil
+}
+
 func Connect(ctx context.Context, cfg *config.PostgresConfig) (*pgxpool.Pool, error) {
 	// устанавливаем search_path
 	connString := cfg.Pool.ConnConfig.ConnString()
@@ -26,6 +61,19 @@ func Connect(ctx context.Context, cfg *config.PostgresConfig) (*pgxpool.Pool, er
 	poolCfg.MaxConnIdleTime = cfg.Pool.MaxConnIdleTime
 	poolCfg.HealthCheckPeriod = cfg.Pool.HealthCheckPeriod
 
+	applicationName := cfg.ApplicationName
+	if applicationName == "" {
+		applicationName = defaultApplicationName
+	}
+	poolCfg.ConnConfig.RuntimeParams["application_name"] = applicationName
+	poolCfg.BeforeAcquire = func(ctx context.Context, conn *pgx.Conn) bool {
+		tagConnection(ctx, conn)
+		return true
+	}
+	poolCfg.AfterRelease = func(conn *pgx.Conn) bool {
+		return resetConnection(conn, applicationName)
+	}
+
 	for attempt := 0; attempt <= cfg.ConnectRetries; attempt++ {
 		pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 		if err == nil {
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"TransactionTest/config"
+	"TransactionTest/internal/domain"
 )
 
 // Ошибки для обработки в вызывающем коде (main)
@@ -15,8 +16,22 @@ var (
 	ErrCompleted = errors.New("seeder already executed")
 )
 
+// StatusOf переводит результат SeedWallets в статус для метрик и проверок готовности
+func StatusOf(err error) domain.SeedingStatus {
+	switch {
+	case err == nil:
+		return domain.SeedingSeeded
+	case errors.Is(err, ErrDisabled):
+		return domain.SeedingDisabled
+	case errors.Is(err, ErrCompleted):
+		return domain.SeedingAlreadySeeded
+	default:
+		return domain.SeedingFailed
+	}
+}
+
 // CreateWalletsForSeeding сигнатруа функции, содержащей бизнес логику "seeding"
-type CreateWalletsForSeeding func(context.Context, int, float64, bool) (<-chan string, <-chan error, bool)
+type CreateWalletsForSeeding func(context.Context, int, domain.Money, bool) (<-chan string, <-chan error, bool)
 
 // LogError сигнатруа функции, которая будет логировать ошибки при ErrDisabled = false
 type LogError func(context.Context, error)
@@ -42,6 +57,7 @@ func SeedWallets(ctx context.Context, cfg config.WalletsSeedConfig, createStart
 	defer markerFile.Close()
 
 	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	done, errChan, flag := createStart(
 		ctx,
 		cfg.Count,
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"TransactionTest/config"
+	"TransactionTest/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -21,7 +22,7 @@ type mockCreateFunc struct {
 func (m *mockCreateFunc) create(
 	ctx context.Context,
 	count int,
-	balance float64,
+	balance domain.Money,
 	failOnError bool,
 ) (<-chan string, <-chan error, bool) {
 	args := m.Called(ctx, count, balance, failOnError)
@@ -40,7 +41,7 @@ func TestSeedWallets_Success(t *testing.T) {
 	cfg := config.WalletsSeedConfig{
 		Enabled:    true,
 		Count:      3,
-		Balance:    100.0,
+		Balance:    domain.MustParseMoney("100"),
 		MarkerFile: markerFile,
 	}
 
@@ -75,10 +76,11 @@ func TestSeedWallets_ErrorFromCreator(t *testing.T) {
 	defer os.Remove(markerFile)
 
 	cfg := config.WalletsSeedConfig{
-		Enabled:    true,
-		Count:      1,
-		Balance:    100.0,
-		MarkerFile: markerFile,
+		Enabled:     true,
+		FailOnError: true,
+		Count:       1,
+		Balance:     domain.MustParseMoney("100"),
+		MarkerFile:  markerFile,
 	}
 
 	doneChan := make(chan string)
@@ -110,7 +112,7 @@ func TestSeedWallets_WriteError(t *testing.T) {
 	cfg := config.WalletsSeedConfig{
 		Enabled:    true,
 		Count:      1,
-		Balance:    100.0,
+		Balance:    domain.MustParseMoney("100"),
 		MarkerFile: invalidPath,
 	}
 
@@ -133,3 +135,10 @@ func TestSeedWallets_WriteError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "marker")
 }
+
+func TestStatusOf(t *testing.T) {
+	assert.Equal(t, domain.SeedingSeeded, StatusOf(nil))
+	assert.Equal(t, domain.SeedingDisabled, StatusOf(ErrDisabled))
+	assert.Equal(t, domain.SeedingAlreadySeeded, StatusOf(fmt.Errorf("seeding: %w", ErrCompleted)))
+	assert.Equal(t, domain.SeedingFailed, StatusOf(errors.New("boom")))
+}
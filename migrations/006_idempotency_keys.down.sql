@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS {{.Schema}}.idx_idempotency_keys_expires_at;
+
+DROP TABLE IF EXISTS {{.Schema}}.idempotency_keys;
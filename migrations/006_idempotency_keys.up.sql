@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS {{.Schema}}.idempotency_keys (
+    key TEXT PRIMARY KEY,
+    request_hash TEXT NOT NULL,
+    transaction_id INT,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_idempotency_transaction FOREIGN KEY (transaction_id) REFERENCES {{.Schema}}.transactions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at
+ON {{.Schema}}.idempotency_keys (expires_at);
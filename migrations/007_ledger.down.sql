@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS trg_postings_balanced ON {{.Schema}}.postings;
+
+DROP FUNCTION IF EXISTS {{.Schema}}.check_entry_balanced();
+
+DROP INDEX IF EXISTS {{.Schema}}.idx_journal_entries_transaction;
+
+DROP INDEX IF EXISTS {{.Schema}}.idx_postings_entry;
+
+DROP INDEX IF EXISTS {{.Schema}}.idx_postings_account_created_at;
+
+DROP TABLE IF EXISTS {{.Schema}}.postings;
+
+DROP TABLE IF EXISTS {{.Schema}}.journal_entries;
@@ -0,0 +1,79 @@
+CREATE TABLE IF NOT EXISTS {{.Schema}}.journal_entries (
+    id BIGSERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    transaction_id INT,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_entry_transaction FOREIGN KEY (transaction_id) REFERENCES {{.Schema}}.transactions(id) ON DELETE SET NULL
+);
+
+-- amount > 0 - кредит счета (баланс растет), amount < 0 - дебет
+CREATE TABLE IF NOT EXISTS {{.Schema}}.postings (
+    id BIGSERIAL PRIMARY KEY,
+    entry_id BIGINT NOT NULL,
+    account TEXT NOT NULL,
+    amount DECIMAL(18, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_posting_entry FOREIGN KEY (entry_id) REFERENCES {{.Schema}}.journal_entries(id),
+    CONSTRAINT chk_posting_amount_nonzero CHECK (amount <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_postings_account_created_at
+ON {{.Schema}}.postings (account, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_postings_entry
+ON {{.Schema}}.postings (entry_id);
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_transaction
+ON {{.Schema}}.journal_entries (transaction_id);
+
+-- Проводки одной записи журнала в сумме должны давать ноль. Проверяется при коммите.
+CREATE OR REPLACE FUNCTION {{.Schema}}.check_entry_balanced() RETURNS trigger AS $$
+BEGIN
+    IF (SELECT COALESCE(SUM(amount), 0) FROM {{.Schema}}.postings WHERE entry_id = NEW.entry_id) <> 0 THEN
+        RAISE EXCEPTION 'journal entry % is not balanced', NEW.entry_id
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'chk_entry_balanced';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER trg_postings_balanced
+AFTER INSERT ON {{.Schema}}.postings
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION {{.Schema}}.check_entry_balanced();
+
+-- Переносим существующую историю в журнал: переводы из transactions и проводки открытия счета
+-- на сумму, которую история переводов не объясняет.
+DO $$
+DECLARE
+    t RECORD;
+    w RECORD;
+    new_entry BIGINT;
+BEGIN
+    FOR t IN SELECT id, from_wallet, to_wallet, amount, created_at FROM {{.Schema}}.transactions ORDER BY id LOOP
+        INSERT INTO {{.Schema}}.journal_entries (kind, transaction_id, created_at)
+        VALUES ('transfer', t.id, COALESCE(t.created_at, now()))
+        RETURNING id INTO new_entry;
+
+        INSERT INTO {{.Schema}}.postings (entry_id, account, amount, created_at) VALUES
+            (new_entry, t.from_wallet, -t.amount, COALESCE(t.created_at, now())),
+            (new_entry, t.to_wallet, t.amount, COALESCE(t.created_at, now()));
+    END LOOP;
+
+    FOR w IN
+        SELECT wl.address, wl.created_at,
+               wl.balance - COALESCE((SELECT SUM(p.amount) FROM {{.Schema}}.postings p WHERE p.account = wl.address), 0) AS opening
+        FROM {{.Schema}}.wallets wl
+    LOOP
+        CONTINUE WHEN w.opening = 0;
+
+        INSERT INTO {{.Schema}}.journal_entries (kind, created_at)
+        VALUES ('opening', COALESCE(w.created_at, now()))
+        RETURNING id INTO new_entry;
+
+        INSERT INTO {{.Schema}}.postings (entry_id, account, amount, created_at) VALUES
+            (new_entry, w.address, w.opening, COALESCE(w.created_at, now())),
+            (new_entry, 'system:equity', -w.opening, COALESCE(w.created_at, now()));
+    END LOOP;
+END
+$$;
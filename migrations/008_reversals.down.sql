@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS {{.Schema}}.uq_transactions_reversal_of;
+
+ALTER TABLE {{.Schema}}.transactions
+DROP CONSTRAINT IF EXISTS fk_transaction_reversal_of;
+
+ALTER TABLE {{.Schema}}.transactions
+DROP COLUMN IF EXISTS reversal_of;
@@ -0,0 +1,10 @@
+ALTER TABLE {{.Schema}}.transactions
+ADD COLUMN IF NOT EXISTS reversal_of INT;
+
+ALTER TABLE {{.Schema}}.transactions
+ADD CONSTRAINT fk_transaction_reversal_of FOREIGN KEY (reversal_of) REFERENCES {{.Schema}}.transactions(id) ON DELETE SET NULL;
+
+-- У транзакции может быть не больше одной отмены
+CREATE UNIQUE INDEX IF NOT EXISTS uq_transactions_reversal_of
+ON {{.Schema}}.transactions (reversal_of)
+WHERE reversal_of IS NOT NULL;
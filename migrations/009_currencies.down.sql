@@ -0,0 +1,18 @@
+CREATE OR REPLACE FUNCTION {{.Schema}}.check_entry_balanced() RETURNS trigger AS $$
+BEGIN
+    IF (SELECT COALESCE(SUM(amount), 0) FROM {{.Schema}}.postings WHERE entry_id = NEW.entry_id) <> 0 THEN
+        RAISE EXCEPTION 'journal entry % is not balanced', NEW.entry_id
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'chk_entry_balanced';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE {{.Schema}}.postings
+DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE {{.Schema}}.transactions
+DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE {{.Schema}}.wallets
+DROP COLUMN IF EXISTS currency;
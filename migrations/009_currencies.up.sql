@@ -0,0 +1,51 @@
+-- Кошельки, созданные до появления валют, считаются долларовыми
+ALTER TABLE {{.Schema}}.wallets
+ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE {{.Schema}}.wallets
+ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE {{.Schema}}.transactions
+ADD COLUMN IF NOT EXISTS currency CHAR(3);
+
+UPDATE {{.Schema}}.transactions t
+SET currency = w.currency
+FROM {{.Schema}}.wallets w
+WHERE w.address = t.from_wallet AND t.currency IS NULL;
+
+ALTER TABLE {{.Schema}}.transactions
+ALTER COLUMN currency SET NOT NULL;
+
+ALTER TABLE {{.Schema}}.postings
+ADD COLUMN IF NOT EXISTS currency CHAR(3);
+
+UPDATE {{.Schema}}.postings p
+SET currency = w.currency
+FROM {{.Schema}}.wallets w
+WHERE w.address = p.account AND p.currency IS NULL;
+
+-- Проводки по системным счетам получают валюту второй стороны записи
+UPDATE {{.Schema}}.postings p
+SET currency = COALESCE(
+    (SELECT q.currency FROM {{.Schema}}.postings q WHERE q.entry_id = p.entry_id AND q.currency IS NOT NULL LIMIT 1),
+    'USD')
+WHERE p.currency IS NULL;
+
+ALTER TABLE {{.Schema}}.postings
+ALTER COLUMN currency SET NOT NULL;
+
+-- Запись журнала должна быть сбалансирована по каждой валюте отдельно
+CREATE OR REPLACE FUNCTION {{.Schema}}.check_entry_balanced() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM {{.Schema}}.postings
+        WHERE entry_id = NEW.entry_id
+        GROUP BY currency
+        HAVING SUM(amount) <> 0
+    ) THEN
+        RAISE EXCEPTION 'journal entry % is not balanced', NEW.entry_id
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'chk_entry_balanced';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,8 @@
+ALTER TABLE {{.Schema}}.transactions
+DROP CONSTRAINT IF EXISTS chk_transaction_exchange;
+
+ALTER TABLE {{.Schema}}.transactions
+DROP COLUMN IF EXISTS rate_at,
+DROP COLUMN IF EXISTS rate,
+DROP COLUMN IF EXISTS to_amount,
+DROP COLUMN IF EXISTS to_currency;
@@ -0,0 +1,12 @@
+-- Детали конвертации заполняются только у переводов между кошельками в разных валютах
+ALTER TABLE {{.Schema}}.transactions
+ADD COLUMN IF NOT EXISTS to_currency CHAR(3),
+ADD COLUMN IF NOT EXISTS to_amount DECIMAL(18, 2),
+ADD COLUMN IF NOT EXISTS rate DECIMAL(20, 8),
+ADD COLUMN IF NOT EXISTS rate_at TIMESTAMP;
+
+ALTER TABLE {{.Schema}}.transactions
+ADD CONSTRAINT chk_transaction_exchange CHECK (
+    (to_currency IS NULL AND to_amount IS NULL AND rate IS NULL AND rate_at IS NULL)
+    OR (to_currency IS NOT NULL AND to_amount > 0 AND rate > 0 AND rate_at IS NOT NULL)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE {{.Schema}}.transactions
+DROP CONSTRAINT IF EXISTS chk_transaction_fee_non_negative;
+
+ALTER TABLE {{.Schema}}.transactions
+DROP COLUMN IF EXISTS fee;
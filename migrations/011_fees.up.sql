@@ -0,0 +1,6 @@
+-- Комиссия списывается с отправителя сверх суммы перевода и зачисляется на кошелек-сборщик
+ALTER TABLE {{.Schema}}.transactions
+ADD COLUMN IF NOT EXISTS fee DECIMAL(18, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE {{.Schema}}.transactions
+ADD CONSTRAINT chk_transaction_fee_non_negative CHECK (fee >= 0);
@@ -0,0 +1,25 @@
+-- Резервы средств. Активный резерв уменьшает доступный баланс кошелька from_wallet,
+-- но не меняет wallets.balance и журнал: деньги списываются только при захвате.
+CREATE TABLE IF NOT EXISTS {{.Schema}}.holds (
+    id BIGSERIAL PRIMARY KEY,
+    from_wallet TEXT NOT NULL,
+    to_wallet TEXT NOT NULL,
+    amount DECIMAL(18, 2) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active',
+    captured_amount DECIMAL(18, 2) NOT NULL DEFAULT 0,
+    transaction_id INT,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_hold_from FOREIGN KEY (from_wallet) REFERENCES {{.Schema}}.wallets(address),
+    CONSTRAINT fk_hold_to FOREIGN KEY (to_wallet) REFERENCES {{.Schema}}.wallets(address),
+    CONSTRAINT fk_hold_transaction FOREIGN KEY (transaction_id) REFERENCES {{.Schema}}.transactions(id) ON DELETE SET NULL,
+    CONSTRAINT chk_hold_amount_positive CHECK (amount > 0),
+    CONSTRAINT chk_hold_status CHECK (status IN ('active', 'captured', 'voided', 'expired')),
+    CONSTRAINT chk_hold_captured_amount CHECK (captured_amount >= 0 AND captured_amount <= amount)
+);
+
+-- Сумма активных резервов кошелька считается при каждой блокировке кошелька
+CREATE INDEX IF NOT EXISTS idx_holds_active_from
+ON {{.Schema}}.holds (from_wallet, expires_at)
+WHERE status = 'active';
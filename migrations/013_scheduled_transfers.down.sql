@@ -0,0 +1 @@
+DROP TABLE IF EXISTS {{.Schema}}.scheduled_transfers;
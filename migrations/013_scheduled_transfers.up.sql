@@ -0,0 +1,27 @@
+-- Отложенные переводы. Фоновая задача забирает наступившие переводы (status = 'running')
+-- и выполняет их обычным переводом, результат записывается в status/transaction_id/failure_code.
+CREATE TABLE IF NOT EXISTS {{.Schema}}.scheduled_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    from_wallet TEXT NOT NULL,
+    to_wallet TEXT NOT NULL,
+    amount DECIMAL(18, 2) NOT NULL,
+    allow_convert BOOLEAN NOT NULL DEFAULT false,
+    execute_at TIMESTAMP NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    transaction_id INT,
+    failure_code TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    started_at TIMESTAMP,
+    executed_at TIMESTAMP,
+    CONSTRAINT fk_scheduled_from FOREIGN KEY (from_wallet) REFERENCES {{.Schema}}.wallets(address),
+    CONSTRAINT fk_scheduled_to FOREIGN KEY (to_wallet) REFERENCES {{.Schema}}.wallets(address),
+    CONSTRAINT fk_scheduled_transaction FOREIGN KEY (transaction_id) REFERENCES {{.Schema}}.transactions(id) ON DELETE SET NULL,
+    CONSTRAINT chk_scheduled_amount_positive CHECK (amount > 0),
+    CONSTRAINT chk_scheduled_no_self_transfer CHECK (from_wallet <> to_wallet),
+    CONSTRAINT chk_scheduled_status CHECK (status IN ('pending', 'running', 'succeeded', 'failed', 'canceled'))
+);
+
+-- Фоновая задача ищет наступившие и зависшие переводы
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_due
+ON {{.Schema}}.scheduled_transfers (execute_at)
+WHERE status IN ('pending', 'running');
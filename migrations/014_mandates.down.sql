@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS {{.Schema}}.mandate_runs;
+DROP TABLE IF EXISTS {{.Schema}}.mandates;
@@ -0,0 +1,55 @@
+-- Регулярные переводы (поручения). Фоновая задача выполняет наступившие запуски через обычный перевод,
+-- каждая попытка записывается в mandate_runs.
+CREATE TABLE IF NOT EXISTS {{.Schema}}.mandates (
+    id BIGSERIAL PRIMARY KEY,
+    from_wallet TEXT NOT NULL,
+    to_wallet TEXT NOT NULL,
+    amount DECIMAL(18, 2) NOT NULL,
+    allow_convert BOOLEAN NOT NULL DEFAULT false,
+    frequency TEXT NOT NULL,
+    repeat_interval INT NOT NULL DEFAULT 0,
+    cron TEXT NOT NULL DEFAULT '',
+    start_at TIMESTAMP NOT NULL,
+    end_at TIMESTAMP,
+    max_runs INT NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'active',
+    runs_count INT NOT NULL DEFAULT 0,
+    occurrence INT NOT NULL DEFAULT 1,
+    attempt INT NOT NULL DEFAULT 1,
+    scheduled_at TIMESTAMP NOT NULL,
+    next_run_at TIMESTAMP,
+    locked_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_mandate_from FOREIGN KEY (from_wallet) REFERENCES {{.Schema}}.wallets(address),
+    CONSTRAINT fk_mandate_to FOREIGN KEY (to_wallet) REFERENCES {{.Schema}}.wallets(address),
+    CONSTRAINT chk_mandate_amount_positive CHECK (amount > 0),
+    CONSTRAINT chk_mandate_no_self_transfer CHECK (from_wallet <> to_wallet),
+    CONSTRAINT chk_mandate_frequency CHECK (frequency IN ('daily', 'weekly', 'monthly', 'cron')),
+    CONSTRAINT chk_mandate_status CHECK (status IN ('active', 'completed', 'canceled')),
+    CONSTRAINT chk_mandate_max_runs CHECK (max_runs >= 0)
+);
+
+-- Фоновая задача ищет активные поручения с наступившей попыткой
+CREATE INDEX IF NOT EXISTS idx_mandates_due
+ON {{.Schema}}.mandates (next_run_at)
+WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS {{.Schema}}.mandate_runs (
+    id BIGSERIAL PRIMARY KEY,
+    mandate_id BIGINT NOT NULL,
+    occurrence INT NOT NULL,
+    attempt INT NOT NULL,
+    scheduled_at TIMESTAMP NOT NULL,
+    status TEXT NOT NULL,
+    transaction_id INT,
+    failure_code TEXT NOT NULL DEFAULT '',
+    executed_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_mandate_run_mandate FOREIGN KEY (mandate_id) REFERENCES {{.Schema}}.mandates(id) ON DELETE CASCADE,
+    CONSTRAINT fk_mandate_run_transaction FOREIGN KEY (transaction_id) REFERENCES {{.Schema}}.transactions(id) ON DELETE SET NULL,
+    CONSTRAINT chk_mandate_run_status CHECK (status IN ('succeeded', 'retrying', 'failed')),
+    -- Одна попытка записывается один раз, даже если ее выполнили повторно после сбоя
+    CONSTRAINT uq_mandate_runs_attempt UNIQUE (mandate_id, occurrence, attempt)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mandate_runs_mandate
+ON {{.Schema}}.mandate_runs (mandate_id, id DESC);
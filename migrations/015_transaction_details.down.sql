@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS {{.Schema}}.uq_transactions_external_reference;
+
+ALTER TABLE {{.Schema}}.transactions
+DROP COLUMN IF EXISTS metadata,
+DROP COLUMN IF EXISTS external_reference,
+DROP COLUMN IF EXISTS memo;
@@ -0,0 +1,10 @@
+-- Сопроводительные данные перевода для сверки с внешними системами
+ALTER TABLE {{.Schema}}.transactions
+ADD COLUMN IF NOT EXISTS memo TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS external_reference VARCHAR(128),
+ADD COLUMN IF NOT EXISTS metadata JSONB;
+
+-- Внешний идентификатор уникален в пределах отправителя; индекс также используется для поиска по нему
+CREATE UNIQUE INDEX IF NOT EXISTS uq_transactions_external_reference
+ON {{.Schema}}.transactions (external_reference, from_wallet)
+WHERE external_reference IS NOT NULL;
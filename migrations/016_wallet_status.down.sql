@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS {{.Schema}}.wallet_status_changes;
+
+ALTER TABLE {{.Schema}}.wallets
+DROP CONSTRAINT IF EXISTS chk_wallet_status;
+
+ALTER TABLE {{.Schema}}.wallets
+DROP COLUMN IF EXISTS status;
@@ -0,0 +1,21 @@
+-- Жизненный цикл кошелька: active -> frozen -> active, active -> closed.
+-- Замороженный и закрытый кошельки не участвуют в переводах, закрыть можно только кошелек с нулевым балансом.
+ALTER TABLE {{.Schema}}.wallets
+ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active';
+
+ALTER TABLE {{.Schema}}.wallets
+ADD CONSTRAINT chk_wallet_status CHECK (status IN ('active', 'frozen', 'closed'));
+
+-- История смен статуса с причиной
+CREATE TABLE IF NOT EXISTS {{.Schema}}.wallet_status_changes (
+    id BIGSERIAL PRIMARY KEY,
+    address TEXT NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_wallet_status_change_wallet FOREIGN KEY (address) REFERENCES {{.Schema}}.wallets(address) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_status_changes_address
+ON {{.Schema}}.wallet_status_changes (address, id);
@@ -0,0 +1,14 @@
+-- Лимиты кошелька. NULL - действует значение по умолчанию из секции limits конфигурации, 0 - без ограничения.
+CREATE TABLE IF NOT EXISTS {{.Schema}}.wallet_limits (
+    address TEXT PRIMARY KEY,
+    max_transfer DECIMAL(18, 2),
+    max_daily_outflow DECIMAL(18, 2),
+    max_daily_count INT,
+    max_balance DECIMAL(18, 2),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_wallet_limits_wallet FOREIGN KEY (address) REFERENCES {{.Schema}}.wallets(address) ON DELETE CASCADE,
+    CONSTRAINT chk_wallet_limits_non_negative CHECK (
+        max_transfer >= 0 AND max_daily_outflow >= 0 AND max_daily_count >= 0 AND max_balance >= 0
+    )
+);
+
@@ -0,0 +1,12 @@
+-- Откат невозможен, пока у кошельков есть задолженность по кредитной линии
+ALTER TABLE {{.Schema}}.wallets
+DROP CONSTRAINT IF EXISTS chk_balance_within_overdraft;
+
+ALTER TABLE {{.Schema}}.wallets
+ADD CONSTRAINT chk_balance_nonnegative CHECK (balance >= 0);
+
+ALTER TABLE {{.Schema}}.wallets
+DROP CONSTRAINT IF EXISTS chk_overdraft_limit_nonnegative;
+
+ALTER TABLE {{.Schema}}.wallets
+DROP COLUMN IF EXISTS overdraft_limit;
@@ -0,0 +1,13 @@
+-- Кредитная линия кошелька: баланс может уйти в минус, но не ниже -overdraft_limit.
+-- Проверка неотрицательного баланса заменяется проверкой баланса относительно лимита.
+ALTER TABLE {{.Schema}}.wallets
+ADD COLUMN IF NOT EXISTS overdraft_limit DECIMAL(18, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE {{.Schema}}.wallets
+ADD CONSTRAINT chk_overdraft_limit_nonnegative CHECK (overdraft_limit >= 0);
+
+ALTER TABLE {{.Schema}}.wallets
+DROP CONSTRAINT IF EXISTS chk_balance_nonnegative;
+
+ALTER TABLE {{.Schema}}.wallets
+ADD CONSTRAINT chk_balance_within_overdraft CHECK (balance >= -overdraft_limit);
@@ -0,0 +1,9 @@
+-- Снимки балансов счетов: balance - сумма всех проводок счета с created_at <= as_of.
+-- Баланс на момент T = последний снимок не позже T плюс проводки после снимка до T.
+CREATE TABLE IF NOT EXISTS {{.Schema}}.balance_snapshots (
+    account TEXT NOT NULL,
+    as_of TIMESTAMP NOT NULL,
+    balance DECIMAL(18, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (account, as_of)
+);
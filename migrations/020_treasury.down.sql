@@ -0,0 +1,17 @@
+-- Откат невозможен, пока баланс казначейства отрицательный
+DROP TABLE IF EXISTS {{.Schema}}.balance_adjustments;
+
+ALTER TABLE {{.Schema}}.transactions
+DROP CONSTRAINT IF EXISTS chk_transaction_kind;
+
+ALTER TABLE {{.Schema}}.transactions
+DROP COLUMN IF EXISTS kind;
+
+ALTER TABLE {{.Schema}}.wallets
+DROP CONSTRAINT IF EXISTS chk_balance_within_overdraft;
+
+ALTER TABLE {{.Schema}}.wallets
+ADD CONSTRAINT chk_balance_within_overdraft CHECK (balance >= -overdraft_limit);
+
+ALTER TABLE {{.Schema}}.wallets
+DROP COLUMN IF EXISTS treasury;
@@ -0,0 +1,33 @@
+-- Кошельки казначейства: через них деньги попадают в систему (пополнение) и выходят из нее (вывод).
+-- Баланс казначейства может быть отрицательным без ограничения - это сумма выпущенных в систему денег.
+ALTER TABLE {{.Schema}}.wallets
+ADD COLUMN IF NOT EXISTS treasury BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE {{.Schema}}.wallets
+DROP CONSTRAINT IF EXISTS chk_balance_within_overdraft;
+
+ALTER TABLE {{.Schema}}.wallets
+ADD CONSTRAINT chk_balance_within_overdraft CHECK (treasury OR balance >= -overdraft_limit);
+
+-- Тип транзакции: перевод между кошельками, пополнение или вывод через казначейство
+ALTER TABLE {{.Schema}}.transactions
+ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'transfer';
+
+ALTER TABLE {{.Schema}}.transactions
+ADD CONSTRAINT chk_transaction_kind CHECK (kind IN ('transfer', 'deposit', 'withdrawal'));
+
+-- Аудит ручных изменений баланса через PUT /api/wallet/{address}/balance
+CREATE TABLE IF NOT EXISTS {{.Schema}}.balance_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    address TEXT NOT NULL,
+    previous_balance DECIMAL(18, 2) NOT NULL,
+    new_balance DECIMAL(18, 2) NOT NULL,
+    reason TEXT NOT NULL,
+    entry_id BIGINT,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_balance_adjustment_wallet FOREIGN KEY (address) REFERENCES {{.Schema}}.wallets(address),
+    CONSTRAINT fk_balance_adjustment_entry FOREIGN KEY (entry_id) REFERENCES {{.Schema}}.journal_entries(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_adjustments_address
+ON {{.Schema}}.balance_adjustments (address, id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS {{.Schema}}.api_keys;
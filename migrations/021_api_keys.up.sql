@@ -0,0 +1,13 @@
+-- API-ключи: хранится только sha256 хеш ключа, сам ключ выдается один раз при создании
+CREATE TABLE IF NOT EXISTS {{.Schema}}.api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    key_hash TEXT NOT NULL,
+    scopes TEXT[] NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMP,
+    CONSTRAINT uq_api_keys_key_hash UNIQUE (key_hash),
+    CONSTRAINT chk_api_key_scopes CHECK (
+        cardinality(scopes) > 0 AND scopes <@ ARRAY['read', 'transfer', 'admin']::TEXT[]
+    )
+);
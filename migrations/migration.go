@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"os"
 	"path/filepath"
 	"text/template"
 
@@ -14,13 +15,45 @@ import (
 	"github.com/golang-migrate/migrate/v4/source/file"
 )
 
-// Ошибка для обработки в вызывающем коде (main)
-var ErrNoChange = migrate.ErrNoChange
+// Ошибки для обработки в вызывающем коде (main)
+var (
+	ErrNoChange   = migrate.ErrNoChange
+	ErrNilVersion = migrate.ErrNilVersion
+)
 
 func New(sourceURL, databaseURL string) (*migrate.Migrate, error) {
 	return migrate.New(sourceURL, databaseURL)
 }
 
+// LatestVersion возвращает версию самой новой up-миграции в каталоге dir.
+// После успешного запуска с этим каталогом база должна быть на этой версии.
+func LatestVersion(dir string) (uint, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	var latest uint
+	found := false
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		m, err := source.DefaultParse(e.Name())
+		if err != nil || m.Direction != source.Up {
+			continue
+		}
+		if !found || m.Version > latest {
+			latest = m.Version
+			found = true
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("no up migrations in %s", dir)
+	}
+	return latest, nil
+}
+
 func init() {
 	source.Register("custom-file-sprintf", &fmtSprintfSource{})
 }